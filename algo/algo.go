@@ -0,0 +1,120 @@
+// Package algo is the stable facade of go-algorithm.
+//
+// The packages under the repository, such as bptree and utilhub, keep changing while the algorithms are tuned.
+// This package only re-exports the parts that downstream users should depend on, and it follows semantic versioning:
+// nothing here is removed or changed in an incompatible way without a new major version.
+//
+//	tree := algo.NewTree(32)
+//	_ = tree.InsertValue(algo.Item{Key: 1, Val: "one"})
+//	item, found := tree.Get(1)
+//
+// Only the B plus tree is stable so far. The progress bar stays in utilhub, because utilhub loads the
+// unit test config when it is imported, and there is no cache or filter package yet.
+package algo
+
+import (
+	"io"
+
+	bpTree "github.com/panhongrainbow/go-algorithm/bptree"
+)
+
+// Version is the version of this facade.
+const Version = "v0.1.0"
+
+// ➡️ tree
+
+type (
+	Tree            = bpTree.BpTree                       // Tree is the B plus tree, it is safe for concurrent use.
+	Item            = bpTree.BpItem                       // Item is a key and its value stored in the tree.
+	Tx              = bpTree.BpTx                         // Tx is a transaction on the tree.
+	Iterator        = bpTree.Iterator[int64, interface{}] // Iterator walks the items in ascending key order.
+	LockStats       = bpTree.LockStats                    // LockStats reports the contention of the tree lock.
+	Validator       = bpTree.Validator                    // Validator checks every item before it is inserted.
+	ConstraintError = bpTree.ConstraintError              // ConstraintError is returned when a validator rejects an item.
+)
+
+// NewTree creates a B plus tree with the given width, the minimum width is 3.
+func NewTree(width int) *Tree {
+	return bpTree.NewBpTree(width)
+}
+
+// MergeIterators merges sorted iterators into one sorted iterator.
+func MergeIterators(its ...Iterator) Iterator {
+	return bpTree.MergeIterators(its...)
+}
+
+// Join performs a sorted merge of two trees and calls emit for every common key, while both trees are locked.
+func Join(a, b *Tree, emit func(key int64, va, vb interface{})) {
+	bpTree.Join(a, b, emit)
+}
+
+// Equal reports whether two trees hold the same items in the same order, no matter how their nodes are shaped.
+func Equal(a, b *Tree) bool {
+	return bpTree.Equal(a, b)
+}
+
+// KeyRange returns a validator that rejects keys outside [min, max].
+func KeyRange(min, max int64) Validator {
+	return bpTree.KeyRange(min, max)
+}
+
+// UniquePrefix returns a validator which allows only one key for each prefix, the low suffixBits bits are the suffix.
+func UniquePrefix(suffixBits uint) Validator {
+	return bpTree.UniquePrefix(suffixBits)
+}
+
+// The errors returned by the tree.
+var (
+	ErrConstraintViolation = bpTree.ErrConstraintViolation
+	ErrTxClosed            = bpTree.ErrTxClosed
+	ErrTxConflict          = bpTree.ErrTxConflict
+	ErrTxNotFound          = bpTree.ErrTxNotFound
+	ErrTxNoSavepoint       = bpTree.ErrTxNoSavepoint
+)
+
+// ➡️ watch
+
+type (
+	ChangeKind  = bpTree.ChangeKind  // ChangeKind describes what kind of change happened to a key.
+	ChangeEvent = bpTree.ChangeEvent // ChangeEvent is delivered to the watchers.
+	WatchPolicy = bpTree.WatchPolicy // WatchPolicy decides what happens when a watcher is too slow.
+	WatchOption = bpTree.WatchOption // WatchOption configures a watcher.
+)
+
+const (
+	ChangeInsert    = bpTree.ChangeInsert
+	ChangeDelete    = bpTree.ChangeDelete
+	WatchBlock      = bpTree.WatchBlock
+	WatchDropNewest = bpTree.WatchDropNewest
+	WatchDropOldest = bpTree.WatchDropOldest
+)
+
+// WithWatchBuffer sets the buffer size of the events channel.
+func WithWatchBuffer(size int) WatchOption {
+	return bpTree.WithWatchBuffer(size)
+}
+
+// WithWatchPolicy sets the backpressure policy when the buffer is full.
+func WithWatchPolicy(policy WatchPolicy) WatchOption {
+	return bpTree.WithWatchPolicy(policy)
+}
+
+// ➡️ load
+
+type (
+	Source       = bpTree.Source       // Source yields the items to be ingested.
+	IngestStats  = bpTree.IngestStats  // IngestStats reports the result of Ingest.
+	IngestOption = bpTree.IngestOption // IngestOption configures Ingest.
+	LoadReport   = bpTree.LoadReport   // LoadReport reports the result of a bulk load.
+	RowError     = bpTree.RowError     // RowError is a rejected row of a bulk load.
+)
+
+// NewNDJSONSource creates a source reading newline delimited JSON, such as os.Stdin.
+func NewNDJSONSource(r io.Reader, keyField string, valueFields ...string) Source {
+	return bpTree.NewNDJSONSource(r, keyField, valueFields...)
+}
+
+// NewChanSource creates a source receiving from the channel, the stream ends when the channel is closed.
+func NewChanSource(items <-chan Item) Source {
+	return bpTree.NewChanSource(items)
+}
@@ -0,0 +1,34 @@
+package algo
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Facade checks the stable API through the facade only.
+func Test_Facade(t *testing.T) {
+	tree := NewTree(4)
+	tree.AddValidator(KeyRange(1, 100))
+
+	// Insert, search and reject through the facade types.
+	for key := int64(1); key <= 10; key++ {
+		require.NoError(t, tree.InsertValue(Item{Key: key, Val: key * 10}))
+	}
+	item, found := tree.Get(5)
+	require.True(t, found)
+	require.Equal(t, int64(50), item.Val)
+
+	err := tree.InsertValue(Item{Key: 1000})
+	require.True(t, errors.Is(err, ErrConstraintViolation))
+	var constraintErr *ConstraintError
+	require.True(t, errors.As(err, &constraintErr))
+
+	// Iterate and merge.
+	var keys []int64
+	for it := MergeIterators(tree.Iterator(), NewTree(4).Iterator()); it.Next(); {
+		keys = append(keys, it.Key())
+	}
+	require.Equal(t, []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, keys)
+}
@@ -0,0 +1,75 @@
+package bpTree
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/panhongrainbow/go-algorithm/utilhub"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_BpTree_AllocProfile 🧫 attributes the allocations to Insert, Delete, Search and RangeScan,
+// and prints the per-op allocation table, which is shown with go test -v.
+func Test_BpTree_AllocProfile(t *testing.T) {
+	// The inserts and the deletes below need a copy of the shared tree.
+	tree := sharedSearchTree(t, 1, fixtureConfig{Width: 32, Count: 20000}).Writable()
+	rng := rand.New(rand.NewSource(1))
+	profile := utilhub.NewAllocProfile()
+	const runs = 1000
+
+	// ➡️ Insert new keys above the existing ones.
+	var inserted int64
+	profile.Measure("Insert", runs, func() {
+		inserted++
+		_ = tree.InsertValue(BpItem{Key: 1_000_000 + inserted})
+	})
+
+	// ➡️ Delete the keys inserted above, one by one.
+	var removed int64
+	profile.Measure("Delete", runs, func() {
+		removed++
+		_, _, _, _ = tree.RemoveValue(BpItem{Key: 1_000_000 + removed})
+	})
+	require.Equal(t, inserted, removed)
+
+	// ➡️ Search random keys.
+	keys := make([]int64, runs)
+	for i := range keys {
+		keys[i] = rng.Int63n(200000) + 1
+	}
+	var searched int
+	search := func() {
+		tree.Get(keys[searched%len(keys)])
+		searched++
+	}
+	profile.Measure("Search", runs, search)
+
+	// Cross-check with testing.AllocsPerRun, a single search does not allocate.
+	require.Zero(t, testing.AllocsPerRun(runs, search))
+
+	// ➡️ Scan 100 keys from a random start.
+	profile.Measure("RangeScan", runs, func() {
+		start := keys[searched%len(keys)]
+		searched++
+		it, count := tree.Iterator(), 0
+		for it.Next() && count < 100 {
+			if it.Key() >= start {
+				count++
+			}
+		}
+	})
+
+	// Print the table.
+	var buf bytes.Buffer
+	profile.Report(&buf)
+	t.Log("\n" + buf.String())
+
+	// Every operation type is in the table.
+	stats := profile.Stats()
+	require.Len(t, stats, 4)
+	for i, op := range []string{"Insert", "Delete", "Search", "RangeScan"} {
+		require.Equal(t, op, stats[i].Op)
+		require.Equal(t, runs, stats[i].Runs)
+	}
+}
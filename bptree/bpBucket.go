@@ -0,0 +1,90 @@
+package bpTree
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ➡️ bucket view operation
+
+// BucketCount is the number of keys in the bucket [Start, Start+width).
+type BucketCount struct {
+	Start int64 // The smallest key of the bucket, a multiple of the width.
+	Count int   // The number of keys in the bucket, the same key inserted twice counts twice.
+}
+
+// CountByBucket returns the number of keys in every non-empty bucket of the width in ascending key order,
+// such as for a histogram, or for choosing the boundaries of the shards. (分桶计数视图)
+// The first call for a width counts the whole tree once and keeps the counts as a view,
+// which every insert and delete updates from then on, so the later calls only copy the counts.
+// DropBucketView stops updating the view when it is no longer needed.
+func (tree *BpTree) CountByBucket(width int64) ([]BucketCount, error) {
+	if width <= 0 {
+		return nil, fmt.Errorf("the width of a bucket must be positive, not %d", width)
+	}
+
+	// Acquire a lock to ensure thread safety.
+	tree.mutex.Lock()
+
+	// Release the lock to allow other threads to access the tree.
+	defer tree.mutex.Unlock()
+
+	// Count the tree for a new view.
+	view, ok := tree.bucketViews[width]
+	if !ok {
+		view = make(map[int64]int)
+		for _, data := range tree.root.dataNodes() {
+			for _, item := range data.Items {
+				if !item.Mask {
+					view[bucketOf(item.Key, width)]++
+				}
+			}
+		}
+		if tree.bucketViews == nil {
+			tree.bucketViews = make(map[int64]map[int64]int)
+		}
+		tree.bucketViews[width] = view
+	}
+
+	// Copy the counts in key order.
+	counts := make([]BucketCount, 0, len(view))
+	for bucket, count := range view {
+		counts = append(counts, BucketCount{Start: bucket * width, Count: count})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		return counts[i].Start < counts[j].Start
+	})
+	return counts, nil
+}
+
+// DropBucketView stops updating the view of the width, and reports whether there was one.
+func (tree *BpTree) DropBucketView(width int64) bool {
+	// Acquire a lock to ensure thread safety.
+	tree.mutex.Lock()
+
+	// Release the lock to allow other threads to access the tree.
+	defer tree.mutex.Unlock()
+
+	_, ok := tree.bucketViews[width]
+	delete(tree.bucketViews, width)
+	return ok
+}
+
+// countBucket adds the change to the bucket of the key in every view, the lock must be held by the caller.
+func (tree *BpTree) countBucket(key int64, change int) {
+	for width, view := range tree.bucketViews {
+		bucket := bucketOf(key, width)
+		if view[bucket] += change; view[bucket] <= 0 {
+			delete(view, bucket) // Only the non-empty buckets are kept.
+		}
+	}
+}
+
+// bucketOf returns the bucket of the key, rounded down, so the negative keys go into the buckets below zero.
+func bucketOf(key, width int64) int64 {
+	bucket := key / width
+	if key%width < 0 {
+		bucket--
+	}
+	return bucket
+}
@@ -0,0 +1,78 @@
+package bpTree
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// bucketCounts 🧫 counts the keys of the tree into the buckets of the width from scratch.
+func bucketCounts(tree *BpTree, width int64) (counts []BucketCount) {
+	for it := tree.Iterator(); it.Next(); {
+		start := bucketOf(it.Key(), width) * width
+		if len(counts) == 0 || counts[len(counts)-1].Start != start {
+			counts = append(counts, BucketCount{Start: start})
+		}
+		counts[len(counts)-1].Count++
+	}
+	return
+}
+
+// Test_Check_BpTree_CountByBucket 🧫 checks that the bucket views follow every insert and delete.
+func Test_Check_BpTree_CountByBucket(t *testing.T) {
+	random := rand.New(rand.NewSource(2046))
+	tree := NewBpTree(4)
+	for _, key := range random.Perm(300) {
+		require.NoError(t, tree.InsertValue(BpItem{Key: int64(key - 100)}))
+	}
+
+	// The first call counts the tree, the negative keys go into the buckets below zero.
+	counts, err := tree.CountByBucket(50)
+	require.NoError(t, err)
+	require.Equal(t, []BucketCount{{-100, 50}, {-50, 50}, {0, 50}, {50, 50}, {100, 50}, {150, 50}}, counts)
+	_, err = tree.CountByBucket(7)
+	require.NoError(t, err)
+
+	// The views are updated on every write, the duplicates count twice and the empty buckets disappear.
+	require.NoError(t, tree.InsertValue(BpItem{Key: 10}))
+	require.NoError(t, tree.InsertValue(BpItem{Key: 1000}))
+	for key := int64(-100); key < -50; key++ {
+		_, _, _, err = tree.RemoveValue(BpItem{Key: key})
+		require.NoError(t, err)
+	}
+	for _, key := range random.Perm(200) {
+		if key%3 == 0 {
+			_, _, _, err = tree.RemoveValue(BpItem{Key: int64(key)})
+			require.NoError(t, err)
+		}
+	}
+	_, _, _, err = tree.RemoveValue(BpItem{Key: 5000}) // Nothing is deleted.
+	require.NoError(t, err)
+
+	for _, width := range []int64{50, 7} {
+		counts, err = tree.CountByBucket(width)
+		require.NoError(t, err)
+		require.Equal(t, bucketCounts(tree, width), counts, "width %d", width)
+	}
+	counts, _ = tree.CountByBucket(50)
+	require.Equal(t, BucketCount{Start: 1000, Count: 1}, counts[len(counts)-1])
+
+	// A clone counts on its own, and a dropped view is counted again from scratch.
+	clone := tree.Clone()
+	require.NoError(t, clone.InsertValue(BpItem{Key: 1001}))
+	counts, _ = clone.CountByBucket(50)
+	require.Equal(t, BucketCount{Start: 1000, Count: 2}, counts[len(counts)-1])
+	counts, _ = tree.CountByBucket(50)
+	require.Equal(t, BucketCount{Start: 1000, Count: 1}, counts[len(counts)-1])
+
+	require.True(t, tree.DropBucketView(7))
+	require.False(t, tree.DropBucketView(7))
+	require.NoError(t, tree.InsertValue(BpItem{Key: 3}))
+	counts, err = tree.CountByBucket(7)
+	require.NoError(t, err)
+	require.Equal(t, bucketCounts(tree, 7), counts)
+
+	_, err = tree.CountByBucket(0)
+	require.Error(t, err)
+}
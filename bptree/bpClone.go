@@ -0,0 +1,150 @@
+package bpTree
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"maps"
+	"reflect"
+	"unsafe"
+)
+
+// ➡️ clone operation
+
+// Clone returns a deep copy of the tree, which has the same structure and shares nothing with the original.
+// The values are copied as they are, so a pointer value still points to the same object.
+// The validators and the bucket views are kept, while the watchers and the open transactions stay with the original tree.
+func (tree *BpTree) Clone() *BpTree {
+	// Acquire a lock to ensure thread safety.
+	tree.mutex.Lock()
+
+	// Release the lock to allow other threads to access the tree.
+	defer tree.mutex.Unlock()
+
+	// Copy the nodes, and then link the copied data nodes in the same way as the original ones.
+	copies := make(map[*BpData]*BpData)
+	root := tree.root.clone(copies)
+	for original, copied := range copies {
+		copied.Previous = copies[original.Previous]
+		copied.Next = copies[original.Next]
+	}
+
+	// Copy the counts of the bucket views, which go on counting on their own.
+	var views map[int64]map[int64]int
+	for width, view := range tree.bucketViews {
+		if views == nil {
+			views = make(map[int64]map[int64]int, len(tree.bucketViews))
+		}
+		views[width] = maps.Clone(view)
+	}
+
+	return &BpTree{
+		root:        root,
+		width:       tree.width,
+		halfWidth:   tree.halfWidth,
+		version:     tree.version,
+		validators:  append([]Validator(nil), tree.validators...),
+		bucketViews: views,
+	}
+}
+
+// clone copies the index node and everything under it, and records every copied data node in copies.
+func (inode *BpIndex) clone(copies map[*BpData]*BpData) *BpIndex {
+	copied := &BpIndex{
+		Index:      append(make([]int64, 0, cap(inode.Index)), inode.Index...),
+		IndexNodes: make([]*BpIndex, 0, cap(inode.IndexNodes)),
+		DataNodes:  make([]*BpData, 0, cap(inode.DataNodes)),
+	}
+
+	// Entering the Recursive Function. 🔁
+	for _, indexNode := range inode.IndexNodes {
+		copied.IndexNodes = append(copied.IndexNodes, indexNode.clone(copies))
+	}
+	for _, data := range inode.DataNodes {
+		copiedData := &BpData{
+			Items:            append(make([]BpItem, 0, cap(data.Items)), data.Items...),
+			ShouldRenewIndex: data.ShouldRenewIndex,
+		}
+		copies[data] = copiedData
+		copied.DataNodes = append(copied.DataNodes, copiedData)
+	}
+
+	return copied
+}
+
+// Equal reports whether two trees hold the same items in the same order, no matter how their nodes are shaped.
+// The values are compared with reflect.DeepEqual.
+func Equal(a, b *BpTree) bool {
+	if a == b {
+		return true
+	}
+
+	// Acquire the locks in a fixed order to prevent deadlocks when two comparisons run in opposite directions.
+	first, second := a, b
+	if uintptr(unsafe.Pointer(first)) > uintptr(unsafe.Pointer(second)) {
+		first, second = second, first
+	}
+	first.mutex.Lock()
+	defer first.mutex.Unlock()
+	second.mutex.Lock()
+	defer second.mutex.Unlock()
+
+	// Walk both trees from the smallest key, and stop at the first difference.
+	cursorA := newDataCursor(a.root)
+	cursorB := newDataCursor(b.root)
+	for cursorA.valid() && cursorB.valid() {
+		itemA, itemB := cursorA.item(), cursorB.item()
+		if itemA.Key != itemB.Key || !reflect.DeepEqual(itemA.Val, itemB.Val) {
+			return false
+		}
+		cursorA.next()
+		cursorB.next()
+	}
+
+	// Both trees must run out of items at the same time.
+	return !cursorA.valid() && !cursorB.valid()
+}
+
+// Hash returns a structural hash of the tree, which covers the shape of the nodes, the index keys and the items.
+// Two trees with the same items but different shapes have different hashes, so a golden file can catch
+// a change in how the tree splits and merges. Use Equal to compare the items only.
+// The values are hashed by their type and their fmt %v form.
+func (tree *BpTree) Hash() uint64 {
+	// Acquire a lock to ensure thread safety.
+	tree.mutex.Lock()
+
+	// Release the lock to allow other threads to access the tree.
+	defer tree.mutex.Unlock()
+
+	h := fnv.New64a()
+	tree.root.hash(h)
+	return h.Sum64()
+}
+
+// hash writes the index node and everything under it into the hash, with the counts marking where every node ends.
+func (inode *BpIndex) hash(h io.Writer) {
+	writeInt := func(v int64) {
+		_, _ = h.Write(binary.LittleEndian.AppendUint64(nil, uint64(v)))
+	}
+
+	writeInt(int64(len(inode.Index)))
+	for _, key := range inode.Index {
+		writeInt(key)
+	}
+
+	// Entering the Recursive Function. 🔁
+	writeInt(int64(len(inode.IndexNodes)))
+	for _, indexNode := range inode.IndexNodes {
+		indexNode.hash(h)
+	}
+
+	writeInt(int64(len(inode.DataNodes)))
+	for _, data := range inode.DataNodes {
+		writeInt(int64(len(data.Items)))
+		for _, item := range data.Items {
+			writeInt(item.Key)
+			_, _ = fmt.Fprintf(h, "%T:%v;", item.Val, item.Val)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package bpTree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Check_BpTree_Clone 🧫 checks the deep copy, the equality and the structural hash of the trees.
+func Test_Check_BpTree_Clone(t *testing.T) {
+	tree := NewBpTree(4)
+	for key := int64(1); key <= 50; key++ {
+		require.NoError(t, tree.InsertValue(BpItem{Key: key, Val: key * 10}))
+	}
+
+	t.Run("Clone shares nothing", func(t *testing.T) {
+		clone := tree.Clone()
+		require.True(t, Equal(tree, clone))
+		require.Equal(t, tree.Hash(), clone.Hash())
+
+		// The writes to the clone do not reach the original.
+		require.NoError(t, clone.InsertValue(BpItem{Key: 100, Val: int64(1000)}))
+		_, _, _, err := clone.RemoveValue(BpItem{Key: 1})
+		require.NoError(t, err)
+		require.False(t, Equal(tree, clone))
+		require.NotEqual(t, tree.Hash(), clone.Hash())
+		_, found := tree.Get(100)
+		require.False(t, found)
+		_, found = tree.Get(1)
+		require.True(t, found)
+
+		// The data nodes of the clone are linked among themselves.
+		nodes := clone.root.dataNodes()
+		for i := 1; i < len(nodes); i++ {
+			if nodes[i].Previous != nil {
+				require.Contains(t, nodes, nodes[i].Previous)
+			}
+			if nodes[i].Next != nil {
+				require.Contains(t, nodes, nodes[i].Next)
+			}
+		}
+	})
+
+	t.Run("Equal ignores the shape", func(t *testing.T) {
+		// The same items inserted in the reverse order into a wider tree.
+		other := NewBpTree(6)
+		for key := int64(50); key >= 1; key-- {
+			require.NoError(t, other.InsertValue(BpItem{Key: key, Val: key * 10}))
+		}
+		require.True(t, Equal(tree, other))
+		require.True(t, Equal(other, tree))
+		require.True(t, Equal(tree, tree))
+
+		// A different value or a missing item makes them unequal.
+		changed := tree.Clone()
+		_, _, _, err := changed.RemoveValue(BpItem{Key: 25})
+		require.NoError(t, err)
+		require.False(t, Equal(tree, changed))
+		require.NoError(t, changed.InsertValue(BpItem{Key: 25, Val: "250"}))
+		require.False(t, Equal(tree, changed))
+		require.False(t, Equal(tree, NewBpTree(4)))
+	})
+
+	t.Run("Hash is stable", func(t *testing.T) {
+		// The same inserts give the same shape, so the hash works for golden files.
+		again := NewBpTree(4)
+		for key := int64(1); key <= 50; key++ {
+			require.NoError(t, again.InsertValue(BpItem{Key: key, Val: key * 10}))
+		}
+		require.Equal(t, tree.Hash(), again.Hash())
+		require.NotEqual(t, tree.Hash(), NewBpTree(4).Hash())
+	})
+}
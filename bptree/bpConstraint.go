@@ -0,0 +1,131 @@
+package bpTree
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ➡️ constraint operation
+
+// ErrConstraintViolation is wrapped by every ConstraintError, so errors.Is can be used to detect any violation.
+var ErrConstraintViolation = errors.New("constraint violation")
+
+// ConstraintError is returned when an item is rejected by a validator before it is inserted.
+type ConstraintError struct {
+	Constraint string // The name of the constraint which rejects the item.
+	Key        int64  // The key of the rejected item.
+	Reason     string // Why the item is rejected.
+}
+
+// Error implements the error interface.
+func (e *ConstraintError) Error() string {
+	return fmt.Sprintf("%s: %s rejects key %d: %s", ErrConstraintViolation, e.Constraint, e.Key, e.Reason)
+}
+
+// Unwrap returns ErrConstraintViolation.
+func (e *ConstraintError) Unwrap() error {
+	return ErrConstraintViolation
+}
+
+// ConstraintView gives the validators read-only access to the tree while the lock is held.
+// ⚠️ The validators must use the view instead of the methods of the tree, otherwise they deadlock.
+type ConstraintView struct {
+	root *BpIndex
+}
+
+// Contains reports whether any key in [start, end] exists in the tree.
+func (view ConstraintView) Contains(start, end int64) bool {
+	return view.root.containsRange(start, end)
+}
+
+// Validator checks an item before it is inserted, and returns an error to reject it.
+type Validator func(view ConstraintView, item BpItem) error
+
+// AddValidator registers a validator, which is called in the registered order before every insert.
+func (tree *BpTree) AddValidator(validator Validator) {
+	// Acquire a lock to ensure thread safety.
+	tree.mutex.Lock()
+
+	// Release the lock to allow other threads to access the tree.
+	defer tree.mutex.Unlock()
+
+	tree.validators = append(tree.validators, validator)
+}
+
+// validate runs all the validators against the item, the lock must be held by the caller.
+func (tree *BpTree) validate(item BpItem) error {
+	view := ConstraintView{root: tree.root}
+	for _, validator := range tree.validators {
+		if err := validator(view, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// KeyRange returns a validator that rejects keys outside [min, max].
+func KeyRange(min, max int64) Validator {
+	return func(view ConstraintView, item BpItem) error {
+		if item.Key < min || item.Key > max {
+			return &ConstraintError{
+				Constraint: "KeyRange",
+				Key:        item.Key,
+				Reason:     fmt.Sprintf("out of range [%d, %d]", min, max),
+			}
+		}
+		return nil
+	}
+}
+
+// UniquePrefix returns a validator for composite keys, where the high bits are the prefix and
+// the low suffixBits bits are the suffix. Only one key is allowed for each prefix.
+// For example, with suffixBits 32, the keys 0x1_00000001 and 0x1_00000002 share the prefix 1. (复合键前缀唯一)
+func UniquePrefix(suffixBits uint) Validator {
+	return func(view ConstraintView, item BpItem) error {
+		// Calculate the key range sharing the same prefix.
+		start := item.Key >> suffixBits << suffixBits
+		end := start | (int64(1)<<suffixBits - 1)
+
+		if view.Contains(start, end) {
+			return &ConstraintError{
+				Constraint: "UniquePrefix",
+				Key:        item.Key,
+				Reason:     fmt.Sprintf("prefix %d already exists", item.Key>>suffixBits),
+			}
+		}
+		return nil
+	}
+}
+
+// containsRange reports whether any key in [start, end] exists under the index node.
+// It only visits the children which may contain the range.
+func (inode *BpIndex) containsRange(start, end int64) bool {
+	// Find the children covering the range, starting one child earlier for the keys equal to the index.
+	first := sort.Search(len(inode.Index), func(i int) bool {
+		return inode.Index[i] >= start
+	})
+	last := sort.Search(len(inode.Index), func(i int) bool {
+		return inode.Index[i] > end
+	})
+
+	// Go down the index nodes.
+	if len(inode.IndexNodes) > 0 {
+		for i := first; i <= last && i < len(inode.IndexNodes); i++ {
+			if inode.IndexNodes[i].containsRange(start, end) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Search the data nodes at the bottom level.
+	for i := first; i <= last && i < len(inode.DataNodes); i++ {
+		for _, item := range inode.DataNodes[i].Items {
+			if item.Key >= start && item.Key <= end {
+				return true
+			}
+		}
+	}
+	return false
+}
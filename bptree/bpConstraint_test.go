@@ -0,0 +1,88 @@
+package bpTree
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Check_BpTree_Constraint 🧫 checks that the validators reject items before they are inserted.
+func Test_Check_BpTree_Constraint(t *testing.T) {
+	t.Run("Keys outside the range are rejected", func(t *testing.T) {
+		tree := NewBpTree(4)
+		tree.AddValidator(KeyRange(10, 20))
+
+		require.NoError(t, tree.InsertValue(BpItem{Key: 10}))
+		require.NoError(t, tree.InsertValue(BpItem{Key: 20}))
+
+		err := tree.InsertValue(BpItem{Key: 21})
+		require.ErrorIs(t, err, ErrConstraintViolation)
+		var violation *ConstraintError
+		require.True(t, errors.As(err, &violation))
+		require.Equal(t, "KeyRange", violation.Constraint)
+		require.Equal(t, int64(21), violation.Key)
+
+		require.Equal(t, []int64{10, 20}, collectKeys(tree))
+	})
+
+	t.Run("Only one key for each prefix", func(t *testing.T) {
+		tree := NewBpTree(3)
+		tree.AddValidator(UniquePrefix(8))
+
+		// The prefixes 1, 2 and 3 are used once.
+		require.NoError(t, tree.InsertValue(BpItem{Key: 1<<8 | 5}))
+		require.NoError(t, tree.InsertValue(BpItem{Key: 2<<8 | 0}))
+		require.NoError(t, tree.InsertValue(BpItem{Key: 3<<8 | 255}))
+
+		// The same prefix with another suffix is rejected.
+		require.ErrorIs(t, tree.InsertValue(BpItem{Key: 1<<8 | 6}), ErrConstraintViolation)
+		require.ErrorIs(t, tree.InsertValue(BpItem{Key: 3<<8 | 0}), ErrConstraintViolation)
+
+		// The prefix can be used again after its key is removed.
+		_, _, _, err := tree.RemoveValue(BpItem{Key: 1<<8 | 5})
+		require.NoError(t, err)
+		require.NoError(t, tree.InsertValue(BpItem{Key: 1<<8 | 6}))
+	})
+
+	t.Run("Custom validator and transaction", func(t *testing.T) {
+		tree := NewBpTree(4)
+		tree.AddValidator(func(view ConstraintView, item BpItem) error {
+			if item.Val == nil {
+				return &ConstraintError{Constraint: "NotNull", Key: item.Key, Reason: "value is nil"}
+			}
+			return nil
+		})
+		require.NoError(t, tree.InsertValue(BpItem{Key: 1, Val: "one"}))
+
+		// The rejected insert undoes the whole transaction.
+		tx := tree.Begin()
+		require.NoError(t, tx.InsertValue(BpItem{Key: 2, Val: "two"}))
+		require.NoError(t, tx.RemoveValue(BpItem{Key: 1}))
+		require.NoError(t, tx.InsertValue(BpItem{Key: 3}))
+		require.ErrorIs(t, tx.Commit(), ErrConstraintViolation)
+
+		require.Equal(t, []int64{1}, collectKeys(tree))
+	})
+
+	t.Run("Contains matches the remaining keys", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(11))
+		tree, remain := prepareSearchTree(t, 4, 1000, rng)
+		view := ConstraintView{root: tree.root}
+
+		for i := 0; i < 1000; i++ {
+			start := rng.Int63n(10200) - 100
+			end := start + rng.Int63n(40)
+
+			expected := false
+			for key := range remain {
+				if key >= start && key <= end {
+					expected = true
+					break
+				}
+			}
+			require.Equal(t, expected, view.Contains(start, end), "range [%d, %d]", start, end)
+		}
+	})
+}
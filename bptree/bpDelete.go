@@ -8,7 +8,7 @@ import (
 // ➡️ The functions related to direction.
 
 // delFromRoot is responsible for deleting an item from the root of the B Plus tree. // 这是 B 加树的删除入口
-func (inode *BpIndex) delFromRoot(item BpItem) (deleted, updated bool, ix int, edgeValue int64, err error) {
+func (inode *BpIndex) delFromRoot(tree *BpTree, item BpItem) (deleted, updated bool, ix int, edgeValue int64, err error) {
 	// 这里根节点规模太小，根节点直接就是索引节点
 
 	if len(inode.Index) == 0 &&
@@ -23,12 +23,18 @@ func (inode *BpIndex) delFromRoot(item BpItem) (deleted, updated bool, ix int, e
 			return inode.DataNodes[0].Items[i].Key >= item.Key // no equal sign ‼️ no equal sign means delete to the right ‼️
 		})
 
-		// 删除 💢
-		if inode.DataNodes[0].Items[ix].Key == item.Key {
+		// 删除 💢 (the key may be larger than every item, then ix is out of range)
+		if ix < len(inode.DataNodes[0].Items) && inode.DataNodes[0].Items[ix].Key == item.Key {
 			inode.DataNodes[0].Items = append(inode.DataNodes[0].Items[0:ix], inode.DataNodes[0].Items[ix+1:]...)
 			deleted = true
+			if trace := tree.trace; trace != nil {
+				trace.printf("the root holds a single data node, key %d is removed from it and it holds %v", item.Key, inode.DataNodes[0].keys())
+			}
 			return
 		}
+		if trace := tree.trace; trace != nil {
+			trace.printf("the root holds a single data node %v, key %d is not in it", inode.DataNodes[0].keys(), item.Key)
+		}
 
 		// 没删到时，就要立刻中止
 	} else {
@@ -36,7 +42,7 @@ func (inode *BpIndex) delFromRoot(item BpItem) (deleted, updated bool, ix int, e
 		// ❌ not ( ▶️ 索引节点数量 0 🗂️ 资料节点数量 1 ⛷️ 层数数量 0 )
 
 		// Call the delAndDir method to handle deletion and direction.
-		deleted, updated, ix, edgeValue, err = inode.delAndDir(item) // 在这里加入方向性
+		deleted, updated, ix, edgeValue, err = inode.delAndDir(tree, item) // 在这里加入方向性
 		if err != nil {
 			return
 		}
@@ -49,7 +55,7 @@ func (inode *BpIndex) delFromRoot(item BpItem) (deleted, updated bool, ix int, e
 // deleteBottomItem will remove data from the bottom layer. (只隔一个索引 ‼️)
 // If the node is too small, it will clear the entire index. (索引可能失效‼️)
 // 一层 BpData 资料层，加上一个索引切片，就是一个 Bottom
-func (inode *BpIndex) deleteBottomItem(item BpItem) (deleted, updated bool, ix int, edgeValue int64, status int) {
+func (inode *BpIndex) deleteBottomItem(tree *BpTree, item BpItem) (deleted, updated bool, ix int, edgeValue int64, status int) {
 	// 初始化回传值
 	edgeValue = -1
 
@@ -62,6 +68,16 @@ func (inode *BpIndex) deleteBottomItem(item BpItem) (deleted, updated bool, ix i
 	deleted, _, edgeValue, status = inode.DataNodes[ix]._delete(item)
 	// _delete 函式状况会回传 (1) 边界值没改变 (2) 边界值已改变 (3) 边界值为空
 
+	// Explain the comparison choosing the data node, and whether the key was there.
+	if trace := tree.trace; trace != nil {
+		trace.descend(inode.Index, item.Key, ix, false)
+		if deleted {
+			trace.printf("key %d is removed from data node %d, which holds %v", item.Key, ix, inode.DataNodes[ix].keys())
+		} else {
+			trace.printf("key %d is not in data node %d, nothing is removed", item.Key, ix)
+		}
+	}
+
 	if deleted == true { // 如果资料真的删除的反应
 		// The BpDatda node is too small then the index is invalid.
 		if len(inode.DataNodes) < 2 {
@@ -93,7 +109,7 @@ func (inode *BpIndex) deleteBottomItem(item BpItem) (deleted, updated bool, ix i
 // borrowFromDataNode 🛠️ only borrows a portion of data from the neighbor nodes.
 // As for the direction, it may be borrowing data from the left data node, but it may also be borrowing data from the right one. (向左右两方借资料)
 // The whole operation is complicated, please refer to the documentation Chapter 2.3.1 Borrow from Neighbor.
-func (inode *BpIndex) borrowFromDataNode(ix int) (borrowed bool, outerEdgeValue int64, err error) {
+func (inode *BpIndex) borrowFromDataNode(tree *BpTree, ix int) (borrowed bool, outerEdgeValue int64, err error) {
 	// ⚙️ Pre-operation and inspection.
 
 	// Initialization Outer-Edge-Value.
@@ -105,6 +121,20 @@ func (inode *BpIndex) borrowFromDataNode(ix int) (borrowed bool, outerEdgeValue
 		return
 	}
 
+	// Count the rebalance for the thrash monitor.
+	tree.structural++
+
+	// Explain the borrowing and its outcome.
+	if trace := tree.trace; trace != nil {
+		defer func() {
+			if borrowed {
+				trace.printf("data node %d is empty, it borrows from a neighbor, and the data nodes hold %v", ix, inode.dataKeys())
+			} else {
+				trace.printf("data node %d is empty, and no neighbor has a key to lend", ix)
+			}
+		}()
+	}
+
 	// ⚙️ Processing of **statuses 1** and **3**, borrowing data from the right neighbor data node.
 
 	// This is due to the fact that for most conditions, the right neighbor data node has a higher number of data.
@@ -173,7 +203,15 @@ func (inode *BpIndex) borrowFromDataNode(ix int) (borrowed bool, outerEdgeValue
 // The differences between the borrowFromBottomIndexNode function ⚙️ and borrowFromIndexNode are as follows:
 // `borrowFromBottomIndexNode` performs borrowing operations from the bottom-level index node, while also handling index nodes and data nodes.
 // On the other hand, `borrowFromIndexNode` only deals with index nodes.
-func (inode *BpIndex) borrowFromBottomIndexNode(ix int) (borrowed bool, newIx int, edgeValue int64, err error, status int) {
+func (inode *BpIndex) borrowFromBottomIndexNode(tree *BpTree, ix int) (borrowed bool, newIx int, edgeValue int64, err error, status int) {
+	// Count the rebalance for the thrash monitor.
+	tree.structural++
+
+	// Explain the rebalance before it changes the index nodes.
+	if trace := tree.trace; trace != nil {
+		trace.printf("bottom index node %d has lost all its index keys, it borrows from or merges with a neighbor", ix)
+	}
+
 	// The return value is initialized to a negative value first, because the indices in the database are all positive and there won't be any negative values.
 	// (初始化为负值，有更改易发现)
 	newIx = -1
@@ -472,11 +510,11 @@ func (inode *BpIndex) borrowFromBottomIndexNode(ix int) (borrowed bool, newIx in
 	return
 }
 
-func (inode *BpIndex) borrowFromRootIndexNode(ix int, edgeValue int64) (err error) {
+func (inode *BpIndex) borrowFromRootIndexNode(tree *BpTree, ix int, edgeValue int64) (err error) {
 	if len(inode.IndexNodes[ix].Index) == 0 {
 		inode.IndexNodes[ix].Index = []int64{edgeValue}
 	}
-	_, _, _, err = inode.borrowFromIndexNode(ix)
+	_, _, _, err = inode.borrowFromIndexNode(tree, ix)
 	return
 }
 
@@ -486,7 +524,7 @@ func (inode *BpIndex) borrowFromRootIndexNode(ix int, edgeValue int64) (err erro
 // The reason B Plus Tree borrows data is to quickly adjust its index to ensure the normal operation of the B Plus Tree.
 // Scanning the entire B Plus tree and making large-scale adjustments is impractical and may cause performance bottlenecks. (借资料维持整个树的运作)
 // Therefore, I believe that the operations of deleting data in a B P Tree may be slower than adding new data's. (我认为 B 加树删除操作会比新增较慢)
-func (inode *BpIndex) borrowFromIndexNode(ix int) (newIx int, edgeValue int64, status int, err error) {
+func (inode *BpIndex) borrowFromIndexNode(tree *BpTree, ix int) (newIx int, edgeValue int64, status int, err error) {
 
 	// 🩻 The index at position ix must be set first, otherwise the number of indexes and nodes won't match up later.
 	if len(inode.IndexNodes[ix].Index) == 0 {
@@ -494,6 +532,14 @@ func (inode *BpIndex) borrowFromIndexNode(ix int) (newIx int, edgeValue int64, s
 		return
 	}
 
+	// Count the rebalance for the thrash monitor.
+	tree.structural++
+
+	// Explain the rebalance before it changes the index nodes.
+	if trace := tree.trace; trace != nil {
+		trace.printf("index node %d holds too few index keys %v, it borrows from or merges with a neighbor", ix, inode.IndexNodes[ix].Index)
+	}
+
 	// There is a neighbor node on the left.
 	if ix-1 >= 0 && ix-1 <= len(inode.IndexNodes)-1 {
 
@@ -507,10 +553,10 @@ func (inode *BpIndex) borrowFromIndexNode(ix int) (newIx int, edgeValue int64, s
 		// which makes the merging less likely to be too large and thus safer. (优先向左合拼)
 
 		// There is a neighbor node on the left.
-		if len(inode.IndexNodes[ix-1].Index)+1 < BpWidth { // That's right, "Degree" is for the index. ‼️
+		if len(inode.IndexNodes[ix-1].Index)+1 < tree.width { // That's right, "Degree" is for the index. ‼️
 
 			// Merge into the left neighbor node first.
-			inode.combineToLeftNeighborNode(ix)
+			inode.combineToLeftNeighborNode(tree, ix)
 
 			// ⚠️ Here, because the node is too small after merging, the data borrowing might fail, leading the upper-level node to continue borrowing data. (合并后太小了)
 
@@ -525,10 +571,10 @@ func (inode *BpIndex) borrowFromIndexNode(ix int) (newIx int, edgeValue int64, s
 
 			return
 
-		} else if len(inode.IndexNodes[ix-1].Index)+1 >= BpWidth {
+		} else if len(inode.IndexNodes[ix-1].Index)+1 >= tree.width {
 
 			// Merge into the left neighbor node first.
-			inode.combineToLeftNeighborNode(ix)
+			inode.combineToLeftNeighborNode(tree, ix)
 
 			// 🦺 The index of the merged node becomes excessively large, requiring reallocation using either protrudeInOddBpWidth or protrudeInEvenBpWidth.
 
@@ -543,11 +589,11 @@ func (inode *BpIndex) borrowFromIndexNode(ix int) (newIx int, edgeValue int64, s
 
 			// The merged nodes are subjected to reallocation.
 			if len(inode.IndexNodes[ix-1].Index)%2 == 1 { // For odd quantity of index, reallocate using the odd function.
-				if embedNode, err = inode.IndexNodes[ix-1].protrudeInOddBpWidth(); err != nil {
+				if embedNode, err = inode.IndexNodes[ix-1].protrudeInOddBpWidth(tree); err != nil {
 					return
 				}
 			} else if len(inode.IndexNodes[ix-1].Index)%2 == 0 { // For even quantity of index, reallocate using the even function.
-				if embedNode, err = inode.IndexNodes[ix-1].protrudeInEvenBpWidth(); err != nil {
+				if embedNode, err = inode.IndexNodes[ix-1].protrudeInEvenBpWidth(tree); err != nil {
 					return
 				}
 			}
@@ -601,10 +647,10 @@ func (inode *BpIndex) borrowFromIndexNode(ix int) (newIx int, edgeValue int64, s
 		// Therefore, it is crucial to use 'else if' here.
 	} else if ix+1 >= 0 && ix+1 <= len(inode.IndexNodes)-1 { // 不能连续借资料，必用 else if ⚠️
 
-		if len(inode.IndexNodes[ix+1].Index)+1 < BpWidth { // 没错，Degree 是针对 Index
+		if len(inode.IndexNodes[ix+1].Index)+1 < tree.width { // 没错，Degree 是针对 Index
 
 			// Merge into the right neighbor node first.
-			inode.combineToRightNeighborNode(ix)
+			inode.combineToRightNeighborNode(tree, ix)
 
 			// ⚠️ Here, because the node is too small after merging, the data borrowing might fail, leading the upper-level node to continue borrowing data. (合并后太小了)
 
@@ -619,10 +665,10 @@ func (inode *BpIndex) borrowFromIndexNode(ix int) (newIx int, edgeValue int64, s
 
 			return
 
-		} else if len(inode.IndexNodes[ix+1].Index)+1 >= BpWidth {
+		} else if len(inode.IndexNodes[ix+1].Index)+1 >= tree.width {
 
 			// Merge into the right neighbor node first.
-			inode.combineToRightNeighborNode(ix)
+			inode.combineToRightNeighborNode(tree, ix)
 
 			// 🦺 The index of the merged node becomes excessively large, requiring reallocation using either protrudeInOddBpWidth or protrudeInEvenBpWidth.
 
@@ -643,12 +689,12 @@ func (inode *BpIndex) borrowFromIndexNode(ix int) (newIx int, edgeValue int64, s
 			// The merged nodes are subjected to reallocation.
 			if len(inode.IndexNodes[ix].Index)%2 == 1 { // For odd quantity of index, reallocate using the odd function.
 				// 当索引为奇数时
-				if embedNode, err = inode.IndexNodes[ix].protrudeInOddBpWidth(); err != nil {
+				if embedNode, err = inode.IndexNodes[ix].protrudeInOddBpWidth(tree); err != nil {
 					return
 				}
 			} else if len(inode.IndexNodes[ix].Index)%2 == 0 { // For even quantity of index, reallocate using the even function.
 				// 当索引为偶数时
-				if embedNode, err = inode.IndexNodes[ix].protrudeInEvenBpWidth(); err != nil {
+				if embedNode, err = inode.IndexNodes[ix].protrudeInEvenBpWidth(tree); err != nil {
 					return
 				}
 			}
@@ -692,7 +738,7 @@ func (inode *BpIndex) borrowFromIndexNode(ix int) (newIx int, edgeValue int64, s
 
 // combineToLeftNeighborNode is part of borrowFromIndexNode, where the current index node will be merged into the left neighbor node.
 // (borrowFromIndexNode 的一部份)
-func (inode *BpIndex) combineToLeftNeighborNode(ix int) {
+func (inode *BpIndex) combineToLeftNeighborNode(tree *BpTree, ix int) {
 	// The data merges with the left neighbor node.
 	inode.IndexNodes[ix-1].Index = append(inode.IndexNodes[ix-1].Index, inode.IndexNodes[ix].Index...)
 	inode.IndexNodes[ix-1].IndexNodes = append(inode.IndexNodes[ix-1].IndexNodes, inode.IndexNodes[ix].IndexNodes...)
@@ -700,12 +746,17 @@ func (inode *BpIndex) combineToLeftNeighborNode(ix int) {
 	// Deleting the data node at position ix will result in the original data being at position ix - 1. (原资料就在 ix -1)
 	inode.Index = append(inode.Index[:ix-1], inode.Index[ix:]...)
 	inode.IndexNodes = append(inode.IndexNodes[:ix], inode.IndexNodes[ix+1:]...)
+
+	// Explain the merge.
+	if trace := tree.trace; trace != nil {
+		trace.printf("index node %d merges into its left neighbor, which holds %v", ix, inode.IndexNodes[ix-1].Index)
+	}
 	return
 }
 
 // combineToRightNeighborNode is part of borrowFromIndexNode, where the current index node will be merged into the right neighbor node.
 // (borrowFromIndexNode 的一部份)
-func (inode *BpIndex) combineToRightNeighborNode(ix int) {
+func (inode *BpIndex) combineToRightNeighborNode(tree *BpTree, ix int) {
 	// The data merges with the right neighbor node.
 	inode.IndexNodes[ix].Index = append([]int64{inode.IndexNodes[ix+1].edgeValue()}, inode.IndexNodes[ix+1].Index...)
 	inode.IndexNodes[ix].IndexNodes = append(inode.IndexNodes[ix].IndexNodes, inode.IndexNodes[ix+1].IndexNodes...)
@@ -717,5 +768,10 @@ func (inode *BpIndex) combineToRightNeighborNode(ix int) {
 	// 之后，再抹除 ix 位置上的索引节点，原始料料又回到位置 ix
 	inode.Index = append(inode.Index[:ix], inode.Index[ix+1:]...)
 	inode.IndexNodes = append(inode.IndexNodes[:ix+1], inode.IndexNodes[ix+2:]...)
+
+	// Explain the merge.
+	if trace := tree.trace; trace != nil {
+		trace.printf("index node %d merges with its right neighbor, which holds %v", ix, inode.IndexNodes[ix].Index)
+	}
 	return
 }
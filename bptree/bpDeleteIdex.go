@@ -11,7 +11,7 @@ import (
  为何要先优先向左删除资料，因最左边的相同值被删除时，就会被后面相同时递补，比较不会更动到边界值 ✌️
 */
 
-func (inode *BpIndex) delAndDir(item BpItem) (deleted, updated bool, ix int, edgeValue int64, err error) {
+func (inode *BpIndex) delAndDir(tree *BpTree, item BpItem) (deleted, updated bool, ix int, edgeValue int64, err error) {
 	// 搜寻 🔍 (最右边 ➡️)
 	// Use binary search to find the index (ix) where the key should be deleted.
 	ix = sort.Search(len(inode.Index), func(i int) bool {
@@ -40,7 +40,7 @@ func (inode *BpIndex) delAndDir(item BpItem) (deleted, updated bool, ix int, edg
 
 	// 搜寻 🔍 (最右边 ➡️)
 	// If it is discontinuous data (different values) (5 - 5 - 5 - 5 - 5❌ - 6 - 7 - 8)
-	deleted, updated, edgeValue, _, ix, err = inode.deleteToRight(item) // Delete to the rightmost node ‼️ (向右砍)
+	deleted, updated, edgeValue, _, ix, err = inode.deleteToRight(tree, item) // Delete to the rightmost node ‼️ (向右砍)
 
 	// Return the results.
 	return
@@ -49,7 +49,7 @@ func (inode *BpIndex) delAndDir(item BpItem) (deleted, updated bool, ix int, edg
 // deleteToRight is designed to delete from the rightmost side within continuous data.  (5 - 5 - 5 - 5 - 5❌ - 6 - 7 - 8)
 
 // deleteToRight 先放前面，因为 deleteToLeft 会抄 deleteToRight 的内容
-func (inode *BpIndex) deleteToRight(item BpItem) (deleted, updated bool, edgeValue int64, status int, ix int, err error) {
+func (inode *BpIndex) deleteToRight(tree *BpTree, item BpItem) (deleted, updated bool, edgeValue int64, status int, ix int, err error) {
 	// Initialize the return value first.
 	status = edgeValueInit
 	edgeValue = -1
@@ -63,8 +63,13 @@ func (inode *BpIndex) deleteToRight(item BpItem) (deleted, updated bool, edgeVal
 			return inode.Index[i] > item.Key // 在最右边 ‼️
 		})
 
+		// Explain the comparison choosing the child.
+		if trace := tree.trace; trace != nil {
+			trace.descend(inode.Index, item.Key, ix, true)
+		}
+
 		// Entering the Recursive Function. 🔁
-		deleted, updated, edgeValue, status, _, err = inode.IndexNodes[ix].deleteToRight(item)
+		deleted, updated, edgeValue, status, _, err = inode.IndexNodes[ix].deleteToRight(tree, item)
 
 		// Mechanism for updating edge values.
 		if ix > 0 && status == edgeValueUpload {
@@ -108,7 +113,7 @@ func (inode *BpIndex) deleteToRight(item BpItem) (deleted, updated bool, edgeVal
 				/*if item.Key == 1824 {
 					fmt.Println("skip")
 				}*/
-				_, _, edgeValue, err, status = inode.borrowFromBottomIndexNode(ix)
+				_, _, edgeValue, err, status = inode.borrowFromBottomIndexNode(tree, ix)
 				return
 			}
 
@@ -122,7 +127,7 @@ func (inode *BpIndex) deleteToRight(item BpItem) (deleted, updated bool, edgeVal
 					inode.IndexNodes[ix].Index = []int64{edgeValue}
 				}
 
-				ix, edgeValue, status, err = inode.borrowFromIndexNode(ix) // 这里没有及时更新索引
+				ix, edgeValue, status, err = inode.borrowFromIndexNode(tree, ix) // 这里没有及时更新索引
 				if ix == 0 && status == edgeValueChanges {
 					status = edgeValueUpload
 					return
@@ -135,7 +140,7 @@ func (inode *BpIndex) deleteToRight(item BpItem) (deleted, updated bool, edgeVal
 					inode.IndexNodes[ix].Index = []int64{edgeValue}
 				}
 
-				ix, edgeValue, status, err = inode.borrowFromIndexNode(ix)
+				ix, edgeValue, status, err = inode.borrowFromIndexNode(tree, ix)
 				if ix == 0 && status == edgeValueChanges {
 					status = edgeValueUpload
 					return
@@ -154,8 +159,8 @@ func (inode *BpIndex) deleteToRight(item BpItem) (deleted, updated bool, edgeVal
 		// Here, adjustments may be made to IX (IX 在这里可能会被修改) ‼️
 		// var edgeValue int64
 
-		deleted, updated, ix, edgeValue, status = inode.deleteBottomItem(item) // 🖐️ for data node 针对资料节点
-		if ix == 0 && status == edgeValueChangesOfBottomByDelete {             // 当 ix 为 0 时，才要处理边界值的问题 (ix == 0，是特别加入的)
+		deleted, updated, ix, edgeValue, status = inode.deleteBottomItem(tree, item) // 🖐️ for data node 针对资料节点
+		if ix == 0 && status == edgeValueChangesOfBottomByDelete {                   // 当 ix 为 0 时，才要处理边界值的问题 (ix == 0，是特别加入的)
 			status = edgeValueOfIndexMustRenew
 		}
 
@@ -165,7 +170,7 @@ func (inode *BpIndex) deleteToRight(item BpItem) (deleted, updated bool, edgeVal
 		// it is necessary to start borrowing data from neighboring nodes.
 		if len(inode.DataNodes[ix].Items) == 0 { // 会有一边的资料节点没有任何资料
 			var borrowed bool
-			if borrowed, edgeValue, err = inode.borrowFromDataNode(ix); err != nil { // Will borrow part of the data node. (向资料节点借资料)
+			if borrowed, edgeValue, err = inode.borrowFromDataNode(tree, ix); err != nil { // Will borrow part of the data node. (向资料节点借资料)
 				status = statusError
 				return
 			}
@@ -187,6 +192,9 @@ func (inode *BpIndex) deleteToRight(item BpItem) (deleted, updated bool, edgeVal
 			if len(inode.DataNodes) <= 2 { // 资料节点数量过少
 
 				inode.Index = []int64{}
+				if trace := tree.trace; trace != nil {
+					trace.printf("the index node has too few data nodes left, its index keys are cleared for the parent to rebalance")
+				}
 
 				// 状况更新
 				updated = true
@@ -229,6 +237,9 @@ func (inode *BpIndex) deleteToRight(item BpItem) (deleted, updated bool, edgeVal
 					edgeValue = inode.DataNodes[0].Items[0].Key
 					status = edgeValueOfIndexMustRenew
 				}
+				if trace := tree.trace; trace != nil {
+					trace.printf("the empty data node %d is removed from the leaf chain, and the index node holds %v", ix, inode.Index)
+				}
 			}
 		}
 
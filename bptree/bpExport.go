@@ -0,0 +1,80 @@
+package bpTree
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+)
+
+// ➡️ export operation
+
+// exportNode is the model of a node for the exporters, which keeps the keys and the shape of the tree without the values.
+type exportNode struct {
+	Kind     string        `json:"kind"`             // "index" or "data".
+	Keys     []int64       `json:"keys"`             // The index keys, or the keys of the items.
+	Masked   []int64       `json:"masked,omitempty"` // The keys of the items deleted but still in the data node.
+	Children []*exportNode `json:"children,omitempty"`
+	Leaf     int           `json:"leaf"` // The position of the data node in the leaf chain, -1 for an index node.
+	Next     int           `json:"next"` // The position of the next data node in the leaf chain, -1 for none.
+}
+
+// model builds the export model of the tree, the lock must be held by the caller.
+func (tree *BpTree) model() *exportNode {
+	// Number the data nodes from left to right, so the leaf chain can point to them.
+	leaves := make(map[*BpData]int)
+	for i, data := range tree.root.dataNodes() {
+		leaves[data] = i
+	}
+	return tree.root.model(leaves)
+}
+
+// model builds the export model of the index node and everything under it.
+func (inode *BpIndex) model(leaves map[*BpData]int) *exportNode {
+	node := &exportNode{Kind: "index", Keys: append([]int64{}, inode.Index...), Leaf: -1, Next: -1}
+	for _, child := range inode.IndexNodes {
+		node.Children = append(node.Children, child.model(leaves))
+	}
+	for _, data := range inode.DataNodes {
+		leaf := &exportNode{Kind: "data", Keys: []int64{}, Leaf: leaves[data], Next: -1}
+		for _, item := range data.Items {
+			leaf.Keys = append(leaf.Keys, item.Key)
+			if item.Mask {
+				leaf.Masked = append(leaf.Masked, item.Key)
+			}
+		}
+		if next, ok := leaves[data.Next]; ok {
+			leaf.Next = next
+		}
+		node.Children = append(node.Children, leaf)
+	}
+	return node
+}
+
+//go:embed bpExport.html
+var exportPage string
+
+// exportTemplate renders the standalone page, the model is embedded as JSON and drawn by the inline script.
+var exportTemplate = template.Must(template.New("export").Parse(exportPage))
+
+// ExportHTML writes a standalone HTML page drawing the tree, with the index nodes, the data nodes and the leaf chain.
+// A click on a node folds or unfolds its children, so a large tree can be browsed where a static graph is unreadable.
+// The page needs no network access, its script and its styles are inline.
+func (tree *BpTree) ExportHTML(w io.Writer, title string) error {
+	// Acquire a lock to ensure thread safety.
+	tree.mutex.Lock()
+	model, width := tree.model(), tree.width
+	tree.mutex.Unlock()
+
+	// The JSON escapes <, > and &, so it can not close the script element.
+	data, err := json.Marshal(model)
+	if err != nil {
+		return fmt.Errorf("failed to encode the tree: %w", err)
+	}
+	return exportTemplate.Execute(w, struct {
+		Title string
+		Width int
+		Tree  template.JS
+	}{Title: title, Width: width, Tree: template.JS(data)})
+}
@@ -0,0 +1,65 @@
+package bpTree
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Check_BpTree_ExportHTML 🧫 checks the export model and the standalone page drawn from it.
+func Test_Check_BpTree_ExportHTML(t *testing.T) {
+	tree := NewBpTree(4)
+	for key := int64(1); key <= 50; key++ {
+		require.NoError(t, tree.InsertValue(BpItem{Key: key}))
+	}
+
+	t.Run("The model keeps the keys and the leaf chain", func(t *testing.T) {
+		tree.mutex.Lock()
+		model := tree.model()
+		tree.mutex.Unlock()
+
+		// Walk the data nodes from left to right, each one points to the next one in the chain.
+		var leaves []*exportNode
+		var walk func(node *exportNode)
+		walk = func(node *exportNode) {
+			if node.Kind == "data" {
+				leaves = append(leaves, node)
+			}
+			for _, child := range node.Children {
+				walk(child)
+			}
+		}
+		walk(model)
+
+		var keys []int64
+		for i, leaf := range leaves {
+			require.Equal(t, i, leaf.Leaf)
+			if i < len(leaves)-1 {
+				require.Equal(t, i+1, leaf.Next)
+			}
+			keys = append(keys, leaf.Keys...)
+		}
+		require.Equal(t, collectKeys(tree), keys)
+		require.Equal(t, -1, model.Leaf)
+	})
+
+	t.Run("The page is standalone and embeds the model", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, tree.ExportHTML(&buf, "Tree <50 keys>"))
+		page := buf.String()
+		require.True(t, strings.HasPrefix(page, "<!DOCTYPE html>"))
+		require.Contains(t, page, "<title>Tree &lt;50 keys&gt;</title>")
+		require.NotContains(t, page, "<script src")
+
+		// The model after "const root = " is the JSON of the tree.
+		start := strings.Index(page, "const root = ") + len("const root = ")
+		end := strings.Index(page[start:], ";\n")
+		var model exportNode
+		require.NoError(t, json.Unmarshal([]byte(page[start:start+end]), &model))
+		require.Equal(t, "index", model.Kind)
+		require.NotEmpty(t, model.Children)
+	})
+}
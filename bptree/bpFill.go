@@ -0,0 +1,85 @@
+package bpTree
+
+// ➡️ fill operation
+
+// FillBuckets are the upper bounds of the fill-factor histogram, the last bucket counts the nodes over their width.
+var FillBuckets = [...]float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9, 1}
+
+// LevelFill reports how full the nodes of one level are, a long-lived index with many deletes drifts to the low buckets.
+// The fill factor of an index node is its children over the width, and of a data node its items over width-1.
+// (节点填充率)
+type LevelFill struct {
+	Level     int                          // The level, 0 is the root, and the data nodes are the deepest one.
+	Data      bool                         // Whether the level holds the data nodes.
+	Nodes     int                          // The number of nodes on the level.
+	Sum       float64                      // The sum of the fill factors, Sum/Nodes is the average.
+	Histogram [len(FillBuckets) + 1]uint64 // The nodes counted by FillBuckets, not cumulative.
+}
+
+// Average returns the average fill factor of the level, 0 when it has no node.
+func (fill LevelFill) Average() float64 {
+	if fill.Nodes == 0 {
+		return 0
+	}
+	return fill.Sum / float64(fill.Nodes)
+}
+
+// FillStats ensures thread safety, returns the fill factors of the nodes by level from the root down, release lock.
+// The deleted items which are still masked in the data nodes are not counted.
+func (tree *BpTree) FillStats() []LevelFill {
+	// Acquire a lock to ensure thread safety.
+	tree.mutex.Lock()
+
+	// Release the lock to allow other threads to access the tree.
+	defer tree.mutex.Unlock()
+
+	return tree.root.fillStats(tree.width)
+}
+
+// fillStats walks the nodes level by level against the width of their tree, the lock must be held by the caller.
+func (inode *BpIndex) fillStats(width int) (levels []LevelFill) {
+	for current := []*BpIndex{inode}; len(current) > 0; {
+		// Count the index nodes of this level, and collect the next level.
+		level := LevelFill{Level: len(levels)}
+		var next []*BpIndex
+		var data []*BpData
+		for _, node := range current {
+			children := len(node.IndexNodes) + len(node.DataNodes)
+			level.add(float64(children) / float64(width))
+			next = append(next, node.IndexNodes...)
+			data = append(data, node.DataNodes...)
+		}
+		levels = append(levels, level)
+
+		// The data nodes hang under the last level of index nodes.
+		if len(next) == 0 {
+			level = LevelFill{Level: len(levels), Data: true}
+			for _, node := range data {
+				items := 0
+				for _, item := range node.Items {
+					if !item.Mask {
+						items++
+					}
+				}
+				level.add(float64(items) / float64(width-1))
+			}
+			levels = append(levels, level)
+		}
+		current = next
+	}
+	return
+}
+
+// add counts a node with the fill factor into the level.
+func (fill *LevelFill) add(factor float64) {
+	fill.Nodes++
+	fill.Sum += factor
+	bucket := len(FillBuckets)
+	for i, bound := range FillBuckets {
+		if factor <= bound {
+			bucket = i
+			break
+		}
+	}
+	fill.Histogram[bucket]++
+}
@@ -0,0 +1,52 @@
+package bpTree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Check_BpTree_FillStats 🧫 checks the fill factors by level, and that they drop as the items are deleted.
+func Test_Check_BpTree_FillStats(t *testing.T) {
+	tree := NewBpTree(5)
+
+	// An empty tree is a root with one empty data node.
+	levels := tree.FillStats()
+	require.Len(t, levels, 2)
+	require.Equal(t, 1, levels[1].Nodes)
+	require.True(t, levels[1].Data)
+	require.Equal(t, uint64(1), levels[1].Histogram[0])
+
+	for key := int64(0); key < 1000; key++ {
+		require.NoError(t, tree.InsertValue(BpItem{Key: key}))
+	}
+	levels = tree.FillStats()
+	require.Len(t, levels, tree.Height()+1)
+	items := 0.0
+	for i, level := range levels {
+		require.Equal(t, i, level.Level)
+		require.Equal(t, i == len(levels)-1, level.Data)
+		require.Greater(t, level.Average(), 0.0)
+		require.LessOrEqual(t, level.Average(), 1.0)
+		total := uint64(0)
+		for _, count := range level.Histogram {
+			total += count
+		}
+		require.Equal(t, uint64(level.Nodes), total)
+		if level.Data {
+			items = level.Sum * float64(tree.width-1)
+		}
+	}
+	require.InDelta(t, 1000, items, 1e-6, "every item is in a data node")
+
+	// Deleting most of the items leaves the data nodes emptier.
+	before := levels[len(levels)-1].Average()
+	for key := int64(0); key < 1000; key++ {
+		if key%4 != 0 {
+			_, _, _, err := tree.RemoveValue(BpItem{Key: key})
+			require.NoError(t, err)
+		}
+	}
+	levels = tree.Stats().Fill
+	require.Less(t, levels[len(levels)-1].Average(), before)
+}
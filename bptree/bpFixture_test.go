@@ -0,0 +1,93 @@
+package bpTree
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// =====================================================================================================================
+//                  🛠️ Tree Fixture (Tool)
+// Tree Fixture builds a prepared tree once and shares it read-only with every subtest asking for the same seed and config,
+// such as the Get and the GetMany subtests of the same width, so the inserts and the deletes are not repeated. (共享测试树)
+// A subtest which writes must take its own copy with Writable, the shared tree is checked against its hash at the end.
+// =====================================================================================================================
+
+// fixtureConfig 🧫 is how a prepared tree is built.
+type fixtureConfig struct {
+	Width int // The width of the tree.
+	Count int // The unique random keys inserted, about half of them are removed again.
+}
+
+// hash 🧫 returns the hash of the config, which keys the prepared tree together with the seed.
+func (config fixtureConfig) hash() uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write(binary.LittleEndian.AppendUint64(nil, uint64(config.Width)))
+	_, _ = h.Write(binary.LittleEndian.AppendUint64(nil, uint64(config.Count)))
+	return h.Sum64()
+}
+
+// fixtureKey 🧫 identifies a prepared tree.
+type fixtureKey struct {
+	seed   int64  // The seed of the random keys.
+	config uint64 // The hash of the config.
+}
+
+// treeFixture 🧫 is a prepared tree shared by the subtests, which must only read it.
+type treeFixture struct {
+	Tree   *BpTree            // The shared tree.
+	Remain map[int64]struct{} // The keys still inside the tree, also read-only.
+	config fixtureConfig      // How the tree was built.
+	hash   uint64             // The structural hash right after building, to catch a subtest writing into the tree.
+}
+
+// treeFixtures 🧫 keeps every prepared tree until the test binary ends.
+var treeFixtures = struct {
+	sync.Mutex
+	trees map[fixtureKey]*treeFixture
+}{trees: make(map[fixtureKey]*treeFixture)}
+
+// sharedSearchTree 🧫 returns the tree prepareSearchTree builds from the seed and the config, and builds it only the first time.
+// The test fails at its end when the shared tree has been changed.
+func sharedSearchTree(t testing.TB, seed int64, config fixtureConfig) *treeFixture {
+	treeFixtures.Lock()
+	key := fixtureKey{seed: seed, config: config.hash()}
+	fixture, ok := treeFixtures.trees[key]
+	if !ok {
+		tree, remain := prepareSearchTree(t, config.Width, config.Count, rand.New(rand.NewSource(seed)))
+		fixture = &treeFixture{Tree: tree, Remain: remain, config: config, hash: tree.Hash()}
+		treeFixtures.trees[key] = fixture
+	}
+	treeFixtures.Unlock()
+
+	t.Cleanup(func() {
+		if fixture.Tree.Hash() != fixture.hash {
+			t.Errorf("the shared tree of seed %d and config %+v has been changed, use Writable before writing", seed, config)
+		}
+	})
+	return fixture
+}
+
+// Writable 🧫 returns a private copy of the shared tree, which the caller can write into.
+func (fixture *treeFixture) Writable() *BpTree {
+	return fixture.Tree.Clone()
+}
+
+// Test_Check_BpTree_Fixture 🧫 checks that a prepared tree is built once per seed and config, and its copy is independent.
+func Test_Check_BpTree_Fixture(t *testing.T) {
+	first := sharedSearchTree(t, 3, fixtureConfig{Width: 4, Count: 100})
+	require.Same(t, first, sharedSearchTree(t, 3, fixtureConfig{Width: 4, Count: 100}))
+	require.NotSame(t, first, sharedSearchTree(t, 4, fixtureConfig{Width: 4, Count: 100}))
+	require.NotSame(t, first, sharedSearchTree(t, 3, fixtureConfig{Width: 5, Count: 100}))
+
+	// Writing into the copy leaves the shared tree as it was.
+	tree := first.Writable()
+	require.NoError(t, tree.InsertValue(BpItem{Key: -1}))
+	_, found := first.Tree.Get(-1)
+	require.False(t, found)
+	require.Len(t, collectKeys(first.Tree), len(first.Remain))
+}
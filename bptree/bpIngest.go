@@ -0,0 +1,262 @@
+package bpTree
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ➡️ ingest operation
+
+// Source yields the records of a stream, such as stdin, a message queue or a channel.
+// Next blocks until a record is available, and returns io.EOF when the stream ends.
+// A RowError means the record is bad, so it is skipped and the ingestion goes on.
+// Any other error stops the ingestion.
+// A Kafka consumer, for example, can be plugged in by implementing Next on top of its fetch loop.
+type Source interface {
+	Next(ctx context.Context) (BpItem, error)
+}
+
+// IngestStats summarizes an ingestion.
+type IngestStats struct {
+	Ingested int // The number of records inserted into the tree.
+	Rejected int // The number of bad records and records rejected by the validators.
+	Batches  int // The number of batches inserted.
+}
+
+// ingester drives the records of a source into a tree.
+type ingester struct {
+	batchSize     int           // The maximum number of records inserted under one lock.
+	flushInterval time.Duration // The longest time a record waits in a partial batch.
+	bufferSize    int           // The number of records read ahead from the source. (背压)
+	onError       func(error)   // Called for every rejected record, can be nil.
+}
+
+// IngestOption defines a function type for configuring the ingestion.
+type IngestOption func(*ingester)
+
+// WithIngestBatch sets the maximum number of records inserted under one lock.
+func WithIngestBatch(size int) IngestOption {
+	return func(in *ingester) {
+		in.batchSize = size
+	}
+}
+
+// WithIngestFlushInterval sets the longest time a record waits before a partial batch is inserted.
+func WithIngestFlushInterval(interval time.Duration) IngestOption {
+	return func(in *ingester) {
+		in.flushInterval = interval
+	}
+}
+
+// WithIngestBuffer sets how many records are read ahead from the source.
+// When the buffer is full, the source is not read anymore until the tree catches up.
+func WithIngestBuffer(size int) IngestOption {
+	return func(in *ingester) {
+		in.bufferSize = size
+	}
+}
+
+// WithIngestErrorHandler sets a function called for every rejected record.
+func WithIngestErrorHandler(handler func(error)) IngestOption {
+	return func(in *ingester) {
+		in.onError = handler
+	}
+}
+
+// sourceResult carries a record or an error from the reading goroutine.
+type sourceResult struct {
+	item BpItem
+	err  error
+}
+
+// Ingest reads the source continuously and inserts the records in batches until the source ends or ctx is done.
+// A batch is inserted when it is full or when the flush interval has passed since its first record.
+// By default, the batch size is 1024, the flush interval is 100 milliseconds and the read-ahead buffer is 4096 records.
+// It returns nil when the source ends with io.EOF, otherwise the error of the source or of ctx.
+// The records already read are always inserted before returning, and the reading goroutine has stopped by then.
+func (tree *BpTree) Ingest(ctx context.Context, src Source, opts ...IngestOption) (stats IngestStats, err error) {
+	// Create an ingester with the default settings.
+	in := &ingester{
+		batchSize:     1024,
+		flushInterval: 100 * time.Millisecond,
+		bufferSize:    4096,
+	}
+
+	// Apply any optional configurations to the ingester.
+	for _, opt := range opts {
+		opt(in)
+	}
+	if in.batchSize < 1 {
+		in.batchSize = 1
+	}
+	if in.bufferSize < 0 {
+		in.bufferSize = 0
+	}
+
+	// Read the source in another goroutine, the bounded channel blocks it when the tree falls behind.
+	// Every record read is sent, since the results are always received until the channel is closed.
+	readCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make(chan sourceResult, in.bufferSize)
+	go func() {
+		defer close(results)
+		for {
+			item, nextErr := src.Next(readCtx)
+			results <- sourceResult{item: item, err: nextErr}
+			if (nextErr != nil && !isRowError(nextErr)) || readCtx.Err() != nil {
+				return
+			}
+		}
+	}()
+
+	// Insert the batch and count the results.
+	batch := make([]BpItem, 0, in.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		for _, insertErr := range tree.insertBatch(batch) {
+			if insertErr != nil {
+				in.reject(&stats, insertErr)
+				continue
+			}
+			stats.Ingested++
+		}
+		stats.Batches++
+		batch = batch[:0]
+	}
+	defer flush()
+
+	// The timer runs only while a partial batch is waiting.
+	timer := time.NewTimer(in.flushInterval)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case result, ok := <-results:
+			if !ok {
+				// The reader stops on its own only after sending the final error.
+				return stats, ctx.Err()
+			}
+			switch {
+			case result.err == nil:
+			case isRowError(result.err):
+				in.reject(&stats, result.err)
+				continue
+			case errors.Is(result.err, io.EOF):
+				return stats, nil
+			default:
+				return stats, result.err
+			}
+
+			// Start the timer with the first record of the batch.
+			if len(batch) == 0 {
+				timer.Reset(in.flushInterval)
+			}
+			batch = append(batch, result.item)
+			if len(batch) >= in.batchSize {
+				timer.Stop()
+				flush()
+			}
+		case <-timer.C:
+			flush()
+		case <-ctx.Done():
+			// Stop the reader, and keep the records it has read until it is gone, the deferred flush inserts them.
+			cancel()
+			for result := range results {
+				switch {
+				case result.err == nil:
+					if batch = append(batch, result.item); len(batch) >= in.batchSize {
+						flush()
+					}
+				case isRowError(result.err):
+					in.reject(&stats, result.err)
+				}
+			}
+			return stats, ctx.Err()
+		}
+	}
+}
+
+// reject counts a rejected record and reports it to the error handler.
+func (in *ingester) reject(stats *IngestStats, err error) {
+	stats.Rejected++
+	if in.onError != nil {
+		in.onError(err)
+	}
+}
+
+// isRowError reports whether the error is about a bad record only.
+func isRowError(err error) bool {
+	var rowErr RowError
+	return errors.As(err, &rowErr)
+}
+
+// ndjsonSource reads one JSON object per line.
+type ndjsonSource struct {
+	scanner     *bufio.Scanner // The line scanner.
+	line        int            // The number of the last line read.
+	keyField    string         // The field holding the key.
+	valueFields []string       // The fields mapped to the value.
+}
+
+// NewNDJSONSource creates a source reading newline delimited JSON, such as os.Stdin.
+// The key and the value are mapped in the same way as Loader.LoadFromJSONLines.
+// ⚠️ Reading from r cannot be interrupted, so a cancelled ingestion waits until Next returns from r, close r to stop it.
+func NewNDJSONSource(r io.Reader, keyField string, valueFields ...string) Source {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024) // Allow long lines up to 16 MiB.
+	return &ndjsonSource{
+		scanner:     scanner,
+		keyField:    keyField,
+		valueFields: valueFields,
+	}
+}
+
+// Next returns the record of the next non-empty line.
+func (src *ndjsonSource) Next(ctx context.Context) (BpItem, error) {
+	for src.scanner.Scan() {
+		src.line++
+		raw := bytes.TrimSpace(src.scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+		item, err := decodeJSONLine(raw, src.keyField, src.valueFields)
+		if err != nil {
+			return BpItem{}, RowError{Line: src.line, Err: err}
+		}
+		return item, nil
+	}
+	if err := src.scanner.Err(); err != nil {
+		return BpItem{}, err
+	}
+	return BpItem{}, io.EOF
+}
+
+// chanSource receives the records from a channel.
+type chanSource struct {
+	items <-chan BpItem
+}
+
+// NewChanSource creates a source receiving from the channel, the stream ends when the channel is closed.
+func NewChanSource(items <-chan BpItem) Source {
+	return &chanSource{items: items}
+}
+
+// Next waits for the next record.
+func (src *chanSource) Next(ctx context.Context) (BpItem, error) {
+	select {
+	case item, ok := <-src.items:
+		if !ok {
+			return BpItem{}, io.EOF
+		}
+		return item, nil
+	case <-ctx.Done():
+		return BpItem{}, ctx.Err()
+	}
+}
@@ -0,0 +1,138 @@
+package bpTree
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Check_BpTree_Ingest 🧫 checks the continuous ingestion from the sources.
+func Test_Check_BpTree_Ingest(t *testing.T) {
+	t.Run("NDJSON until the end of the stream", func(t *testing.T) {
+		input := strings.Join([]string{
+			`{"id": 1, "name": "alice"}`,
+			`not json`,
+			`{"id": 2, "name": "bob"}`,
+			``,
+			`{"id": 3, "name": "carol"}`,
+		}, "\n")
+
+		tree := NewBpTree(3)
+		var rejected []error
+		stats, err := tree.Ingest(context.Background(), NewNDJSONSource(strings.NewReader(input), "id", "name"),
+			WithIngestBatch(2),
+			WithIngestErrorHandler(func(err error) { rejected = append(rejected, err) }),
+		)
+		require.NoError(t, err)
+		require.Equal(t, IngestStats{Ingested: 3, Rejected: 1, Batches: 2}, stats)
+		require.Len(t, rejected, 1)
+		var rowErr RowError
+		require.True(t, errors.As(rejected[0], &rowErr))
+		require.Equal(t, 2, rowErr.Line)
+
+		require.Equal(t, []int64{1, 2, 3}, collectKeys(tree))
+	})
+
+	t.Run("Partial batches are flushed by time", func(t *testing.T) {
+		tree := NewBpTree(4)
+		items := make(chan BpItem)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var stats IngestStats
+		var err error
+		done := make(chan struct{})
+		go func() {
+			stats, err = tree.Ingest(ctx, NewChanSource(items), WithIngestBatch(100), WithIngestFlushInterval(10*time.Millisecond))
+			close(done)
+		}()
+
+		// The batch is far from full, but the records become visible after the flush interval.
+		items <- BpItem{Key: 1}
+		items <- BpItem{Key: 2}
+		require.Eventually(t, func() bool {
+			_, found := tree.Get(2)
+			return found
+		}, time.Second, 5*time.Millisecond)
+
+		// Cancelling stops the ingestion.
+		cancel()
+		<-done
+		require.ErrorIs(t, err, context.Canceled)
+		require.Equal(t, 2, stats.Ingested)
+	})
+
+	t.Run("Cancelling inserts the records already read", func(t *testing.T) {
+		tree := NewBpTree(4)
+		items := make(chan BpItem)
+		ctx, cancel := context.WithCancel(context.Background())
+
+		// Neither the batch size nor the flush interval is reached, so every record waits in the batch or the buffer.
+		var stats IngestStats
+		var err error
+		done := make(chan struct{})
+		go func() {
+			stats, err = tree.Ingest(ctx, NewChanSource(items), WithIngestBatch(1000), WithIngestFlushInterval(time.Hour), WithIngestBuffer(64))
+			close(done)
+		}()
+		for key := int64(1); key <= 50; key++ {
+			items <- BpItem{Key: key}
+		}
+
+		// Every record sent is inserted, and the reader has stopped, so it no longer receives.
+		cancel()
+		<-done
+		require.ErrorIs(t, err, context.Canceled)
+		require.Equal(t, 50, stats.Ingested)
+		require.Len(t, collectKeys(tree), 50)
+		select {
+		case items <- BpItem{Key: 51}:
+			t.Fatal("the reader is still running")
+		case <-time.After(20 * time.Millisecond):
+		}
+	})
+
+	t.Run("A slow tree pushes back on the source", func(t *testing.T) {
+		tree := NewBpTree(4)
+
+		// A watcher nobody receives from blocks every insert.
+		events := tree.Watch(0, 1000, WithWatchBuffer(0))
+
+		// Count how many records the producer manages to send.
+		items := make(chan BpItem)
+		var sent atomic.Int64
+		stop := make(chan struct{})
+		go func() {
+			defer close(items)
+			for key := int64(1); key <= 1000; key++ {
+				select {
+				case items <- BpItem{Key: key}:
+					sent.Add(1)
+				case <-stop:
+					return
+				}
+			}
+		}()
+
+		done := make(chan IngestStats)
+		go func() {
+			stats, _ := tree.Ingest(context.Background(), NewChanSource(items), WithIngestBatch(4), WithIngestBuffer(8))
+			done <- stats
+		}()
+
+		// The producer stops after filling one batch, the read-ahead buffer and the reader.
+		time.Sleep(100 * time.Millisecond)
+		require.LessOrEqual(t, sent.Load(), int64(4+8+1))
+
+		// Releasing the watcher lets everything through.
+		close(stop)
+		require.True(t, tree.Unwatch(events))
+		stats := <-done
+		require.Equal(t, int(sent.Load()), stats.Ingested)
+	})
+}
@@ -100,19 +100,22 @@ func (data *BpData) insertAmong2(item BpItem) {
 }
 
 // split divides the BpData node into two nodes if it contains more items than the specified width.
-func (data *BpData) split() (side *BpData, err error) {
+func (data *BpData) split(tree *BpTree) (side *BpData, err error) {
+	// Count the split for the thrash monitor.
+	tree.structural++
+
 	// Create a new BpData node to store the items that will be moved.移动资料了
 	side = &BpData{} // It is the new node.
 	length := len(data.Items)
-	side.Items = append(side.Items, data.Items[(length-BpHalfWidth):length]...) // Add the last BpHalfWidth items from data.Items to the new node.后半部的旧资料移动到新节点
+	side.Items = append(side.Items, data.Items[(length-tree.halfWidth):length]...) // Add the last tree.halfWidth items from data.Items to the new node.后半部的旧资料移动到新节点
 
 	// Adjust pointers for the first old node and the new node.
 	side.Previous = data  // The previous node of the new node is the first old node.新节点 的上一个节点为 第1旧节点
 	side.Next = data.Next // The next node of the new node is the next node of the current node.新节点 的下一个节点为 第2旧节点
 
 	// Reduce the data in the original node (first old node)
-	data.Items = data.Items[:(length - BpHalfWidth)] // Remove the last BpHalfWidth items from data.Items to the end in the first old node.上面一行切到 length-BpHalfWidth 為基準
-	data.Next = side                                 // Set the next node of the first old node to the new node.第1旧节点 的下一个节点为 新节点
+	data.Items = data.Items[:(length - tree.halfWidth)] // Remove the last tree.halfWidth items from data.Items to the end in the first old node.上面一行切到 length-tree.halfWidth 為基準
+	data.Next = side                                    // Set the next node of the first old node to the new node.第1旧节点 的下一个节点为 新节点
 
 	// Correct the connections between nodes!
 	// There is an error here, so it needs to be corrected.
@@ -38,7 +38,7 @@ type BpIndex struct {
 
 // insertBpDataValue inserts a new index into the BpIndex.
 // 经由 BpIndex 直接在新增
-func (inode *BpIndex) insertItem(newNode *BpIndex, item BpItem) (popIx int, popKey int64, popNode *BpIndex, status int, err error) {
+func (inode *BpIndex) insertItem(tree *BpTree, newNode *BpIndex, item BpItem) (popIx int, popKey int64, popNode *BpIndex, status int, err error) {
 	var newIndex int64
 	var sideDataNode *BpData
 	status = statusNormal // status is used to inform the root node that it is not the root node here, so the state becomes Normal !.
@@ -59,6 +59,12 @@ func (inode *BpIndex) insertItem(newNode *BpIndex, item BpItem) (popIx int, popK
 			return inode.Index[i] >= item.Key
 		})
 
+		// Explain the comparison choosing the child.
+		trace := tree.trace
+		if trace != nil {
+			trace.descend(inode.Index, item.Key, ix, len(inode.IndexNodes) > 0)
+		}
+
 		// >>>>> >>>>> >>>>> 进入递归
 
 		if len(inode.IndexNodes) > 0 {
@@ -69,7 +75,7 @@ func (inode *BpIndex) insertItem(newNode *BpIndex, item BpItem) (popIx int, popK
 
 			// If there are index nodes, recursively insert the item into the appropriate node.
 			// (这里有递回去找到接近资料切片的地方)
-			popIx, popKey, popNode, status, err = inode.IndexNodes[ix].insertItem(nil, item)
+			popIx, popKey, popNode, status, err = inode.IndexNodes[ix].insertItem(tree, nil, item)
 
 			status = statusProtrudeInode
 			if popKey != 0 {
@@ -84,11 +90,17 @@ func (inode *BpIndex) insertItem(newNode *BpIndex, item BpItem) (popIx int, popK
 				popNode = nil
 			}
 
-			if len(inode.Index) >= BpWidth && len(inode.Index)%2 != 0 { // 进行 pop 和奇数
-				popNode, err = inode.protrudeInOddBpWidth()
+			if len(inode.Index) >= tree.width && len(inode.Index)%2 != 0 { // 进行 pop 和奇数
+				popNode, err = inode.protrudeInOddBpWidth(tree)
+				if trace != nil && err == nil {
+					trace.printf("the index node has reached the width %d, its middle key %v moves up to the parent", tree.width, popNode.Index)
+				}
 				return
-			} else if len(inode.Index) >= BpWidth && len(inode.Index)%2 == 0 { // 进行 pop 和奇数
-				popNode, err = inode.protrudeInEvenBpWidth()
+			} else if len(inode.Index) >= tree.width && len(inode.Index)%2 == 0 { // 进行 pop 和奇数
+				popNode, err = inode.protrudeInEvenBpWidth(tree)
+				if trace != nil && err == nil {
+					trace.printf("the index node has reached the width %d, its middle key %v moves up to the parent", tree.width, popNode.Index)
+				}
 				return
 			}
 
@@ -105,9 +117,12 @@ func (inode *BpIndex) insertItem(newNode *BpIndex, item BpItem) (popIx int, popK
 			// >>>>> 进入第 1 个资料结点入口
 
 			inode.DataNodes[ix].insert(item) // Insert item at index ix.
+			if trace != nil {
+				trace.printf("data node %d takes key %d and holds %v", ix, item.Key, inode.DataNodes[ix].keys())
+			}
 
-			if len(inode.DataNodes[ix].Items) >= BpWidth {
-				sideDataNode, err = inode.DataNodes[ix].split()
+			if len(inode.DataNodes[ix].Items) >= tree.width {
+				sideDataNode, err = inode.DataNodes[ix].split(tree)
 				if err != nil {
 					return
 				}
@@ -117,15 +132,23 @@ func (inode *BpIndex) insertItem(newNode *BpIndex, item BpItem) (popIx int, popK
 				inode.DataNodes[ix+1] = sideDataNode
 
 				inode.insertBpIX(sideDataNode.Items[0].Key)
+				if trace != nil {
+					trace.printf("the data node has reached the width %d, it splits into %v and %v, and key %d is added to the index node %v",
+						tree.width, inode.DataNodes[ix].keys(), sideDataNode.keys(), sideDataNode.Items[0].Key, inode.Index)
+				}
 			}
 
-			if len(inode.Index) >= BpWidth {
-				popKey, popNode, err = inode.splitWithDnode()
+			if len(inode.Index) >= tree.width {
+				popKey, popNode, err = inode.splitWithDnode(tree)
 				status = statusProtrudeDnode
 				popIx = ix
 				if err != nil {
 					return
 				}
+				if trace != nil {
+					trace.printf("the index node has reached the width %d, it splits into %v and %v, and key %d moves up to the parent",
+						tree.width, inode.Index, popNode.Index, popKey)
+				}
 			}
 
 			return
@@ -143,29 +166,37 @@ func (inode *BpIndex) insertItem(newNode *BpIndex, item BpItem) (popIx int, popK
 			return
 		}
 		inode.DataNodes[0].insert(item) // >>>>> (add to DataNodes)
+		trace := tree.trace
+		if trace != nil {
+			trace.printf("the root holds a single data node, it takes key %d and holds %v", item.Key, inode.DataNodes[0].keys())
+		}
 
-		if inode.DataNodes[0].dataLength() >= BpWidth {
-			sideDataNode, err = inode.DataNodes[0].split() // newIndex
+		if inode.DataNodes[0].dataLength() >= tree.width {
+			sideDataNode, err = inode.DataNodes[0].split(tree) // newIndex
 			if err != nil {
 				return
 			}
 
 			inode.DataNodes = append(inode.DataNodes, sideDataNode)
 			newIndex = sideDataNode.Items[0].Key
+			if trace != nil {
+				trace.printf("the data node has reached the width %d, it splits into %v and %v, and key %d becomes the first index key",
+					tree.width, inode.DataNodes[0].keys(), sideDataNode.keys(), newIndex)
+			}
 		}
 	}
 
 	if sideDataNode != nil {
 		inode.insertBpIX(newIndex)
 
-		if len(inode.Index) >= BpWidth && len(inode.Index)%2 != 0 { // 进行 pop 和奇数 (可能没在使用)
+		if len(inode.Index) >= tree.width && len(inode.Index)%2 != 0 { // 进行 pop 和奇数 (可能没在使用)
 			var node *BpIndex
-			node, err = inode.protrudeInOddBpWidth()
+			node, err = inode.protrudeInOddBpWidth(tree)
 			*inode = *node
 			return
-		} else if len(inode.Index) >= BpWidth && len(inode.Index)%2 == 0 { // 进行 pop 和奇数 (可能没在使用)
+		} else if len(inode.Index) >= tree.width && len(inode.Index)%2 == 0 { // 进行 pop 和奇数 (可能没在使用)
 			var node *BpIndex
-			node, err = inode.protrudeInEvenBpWidth()
+			node, err = inode.protrudeInEvenBpWidth(tree)
 			*inode = *node
 			return
 		}
@@ -233,11 +264,14 @@ func (inode *BpIndex) insertBpIX(newIx int64) {
 }
 
 // protrudeInOddBpWidth performs index upgrade; when the middle value of the index slice pops out, it gets upgraded to the upper-level index.
-// This is used when the width of BpWidth is odd.
+// This is used when the width of tree.width is odd.
 // (进行索引升级，当索引切片的中间值会弹出升级成上层的索引)
-func (inode *BpIndex) protrudeInOddBpWidth() (middle *BpIndex, err error) {
+func (inode *BpIndex) protrudeInOddBpWidth(tree *BpTree) (middle *BpIndex, err error) {
+	// Count the split for the thrash monitor.
+	tree.structural++
+
 	// At the beginning, a check is performed.
-	// This function is designed to handle cases where the BpWidth is an odd number,
+	// This function is designed to handle cases where the tree.width is an odd number,
 	// meaning the length of the Index slice is odd,
 	// and the length of the IndexNodes slice is even,
 	// with a difference of 1 in the lengths.(Index 切片 和 IndexNodes 切片长度 差 1)
@@ -276,11 +310,14 @@ func (inode *BpIndex) protrudeInOddBpWidth() (middle *BpIndex, err error) {
 }
 
 // protrudeInOddBpWidth performs index upgrade; when the middle value of the index slice pops out, it gets upgraded to the upper-level index.
-// This is used when the width of BpWidth is even.
+// This is used when the width of tree.width is even.
 // (进行索引升级，当索引切片的中间值会弹出升级成上层的索引)
-func (inode *BpIndex) protrudeInEvenBpWidth() (popMiddleNode *BpIndex, err error) {
+func (inode *BpIndex) protrudeInEvenBpWidth(tree *BpTree) (popMiddleNode *BpIndex, err error) {
+	// Count the split for the thrash monitor.
+	tree.structural++
+
 	// At the beginning, a check is performed.
-	// This function is designed to handle cases where the BpWidth is an odd number,
+	// This function is designed to handle cases where the tree.width is an odd number,
 	// meaning the length of the Index slice is even,
 	// and the length of the IndexNodes slice is odd,
 	// with a difference of 1 in the lengths.(Index 切片 和 IndexNodes 切片长度 差 1)
@@ -321,7 +358,10 @@ func (inode *BpIndex) protrudeInEvenBpWidth() (popMiddleNode *BpIndex, err error
 // >>>>> >>>>> >>>>> split and merge the bottom-level index node.
 
 // splitWithDnode splits the bottom-level index node effectively and returns a new independent key and index node.
-func (inode *BpIndex) splitWithDnode() (key int64, side *BpIndex, err error) {
+func (inode *BpIndex) splitWithDnode(tree *BpTree) (key int64, side *BpIndex, err error) {
+	// Count the split for the thrash monitor.
+	tree.structural++
+
 	// Check if both IndexNodes and DataNodes have data,
 	// which is incorrect as we don't know the type of node.
 	if len(inode.IndexNodes) != 0 && len(inode.DataNodes) != 0 {
@@ -336,34 +376,34 @@ func (inode *BpIndex) splitWithDnode() (key int64, side *BpIndex, err error) {
 		length := len(inode.DataNodes)
 
 		// Append a portion of the Index and DataNodes to the 'side' structure.
-		side.Index = append(side.Index, inode.Index[(length-BpHalfWidth):]...)
-		// This is equivalent to side.Index = append(side.Index, inode.Index[(length-BpHalfWidth):len(inode.Index)])
-		// 这里等于 side.Index = append(side.Index, inode.Index[(length-BpHalfWidth):len(inode.Index)])
+		side.Index = append(side.Index, inode.Index[(length-tree.halfWidth):]...)
+		// This is equivalent to side.Index = append(side.Index, inode.Index[(length-tree.halfWidth):len(inode.Index)])
+		// 这里等于 side.Index = append(side.Index, inode.Index[(length-tree.halfWidth):len(inode.Index)])
 
-		side.DataNodes = append(side.DataNodes, inode.DataNodes[(length-BpHalfWidth):]...)
-		// This is equivalent to side.DataNodes = append(side.DataNodes, inode.DataNodes[(length-BpHalfWidth):len(inode.DataNodes)]),
+		side.DataNodes = append(side.DataNodes, inode.DataNodes[(length-tree.halfWidth):]...)
+		// This is equivalent to side.DataNodes = append(side.DataNodes, inode.DataNodes[(length-tree.halfWidth):len(inode.DataNodes)]),
 		// where len(inode.DataNodes) will be one more than len(inode.Index)
 		// Hence, side.DataNodes will be one more than side.Index, so the slicing operation is correct.
 
-		// 这里等于 side.DataNodes = append(side.DataNodes, inode.DataNodes[(length-BpHalfWidth):len(inode.DataNodes)])，len(inode.DataNodes) 会比 len(inode.Index) 多 1 个
+		// 这里等于 side.DataNodes = append(side.DataNodes, inode.DataNodes[(length-tree.halfWidth):len(inode.DataNodes)])，len(inode.DataNodes) 会比 len(inode.Index) 多 1 个
 		// 最后 side.DataNodes 会比 side.Index 多 1 个，所以切割操作正确
 
 		// The logic here is a bit complex, where the length is the length of the DataNode slice,
-		// and the expression [(length-BpHalfWidth):] determines how much data the new node should take.
-		// When [(length-BpHalfWidth):] is applied to the index code, side.Index = append(side.Index, inode.Index[(length-BpHalfWidth):]...),
+		// and the expression [(length-tree.halfWidth):] determines how much data the new node should take.
+		// When [(length-tree.halfWidth):] is applied to the index code, side.Index = append(side.Index, inode.Index[(length-tree.halfWidth):]...),
 		// the length will be one less than side.DataNodes. This ensures that DataNodes has one more element than Index,
 		// so the overall logic is correct.
 
-		// 这里的程式码有点复杂，其中长度 length 为 DataNode 切片的长度，那式子 [(length-BpHalfWidth):] 中的 BpHalfWidth 意思就为新节点要取多少笔资料，
-		// 再把 [(length-BpHalfWidth):] 套上 index 的代码中，side.Index = append(side.Index, inode.Index[(length-BpHalfWidth):]...)，长度会比 side.DataNodes 少 1 个
+		// 这里的程式码有点复杂，其中长度 length 为 DataNode 切片的长度，那式子 [(length-tree.halfWidth):] 中的 tree.halfWidth 意思就为新节点要取多少笔资料，
+		// 再把 [(length-tree.halfWidth):] 套上 index 的代码中，side.Index = append(side.Index, inode.Index[(length-tree.halfWidth):]...)，长度会比 side.DataNodes 少 1 个
 		// 这样就符合 DataNodes 的切片长度比 Index 多 1，整个逻辑是正确的
 
 		// Update the 'key' assignment with a value from the original Index.
-		key = inode.Index[length-BpHalfWidth-1]
+		key = inode.Index[length-tree.halfWidth-1]
 
 		// Update the original Index and DataNodes by removing the appended portion.
-		inode.Index = inode.Index[0 : length-BpHalfWidth-1]
-		inode.DataNodes = inode.DataNodes[0 : length-BpHalfWidth]
+		inode.Index = inode.Index[0 : length-tree.halfWidth-1]
+		inode.DataNodes = inode.DataNodes[0 : length-tree.halfWidth]
 	}
 
 	// Just return and don't worry about anything.
@@ -0,0 +1,103 @@
+package bpTree
+
+import (
+	"cmp"
+	"iter"
+)
+
+// ➡️ iterator adapter operation
+
+// Pair is a key and its value collected from an iterator.
+type Pair[K, V any] struct {
+	Key   K
+	Value V
+}
+
+// All returns all items in ascending key order, for use with range-over-func.
+// The snapshot is taken when a loop starts, so the sequence can be ranged over again, each time over the current items.
+//
+//	for key, value := range tree.All() {
+//		...
+//	}
+func (tree *BpTree) All() iter.Seq2[int64, interface{}] {
+	return func(yield func(int64, interface{}) bool) {
+		for it := tree.Iterator(); it.Next(); {
+			if !yield(it.Key(), it.Value()) {
+				return
+			}
+		}
+	}
+}
+
+// Range returns the items with keys in [from, to) in ascending key order, for use with range-over-func.
+// Like All, the snapshot is taken when a loop starts. The explicit cursor API stays available as RangeIterator.
+func (tree *BpTree) Range(from, to int64) iter.Seq2[int64, interface{}] {
+	return func(yield func(int64, interface{}) bool) {
+		for it := tree.RangeIterator(from, to); it.Next(); {
+			if !yield(it.Key(), it.Value()) {
+				return
+			}
+		}
+	}
+}
+
+// Seq turns an Iterator into a range-over-func sequence.
+// The iterator is consumed, so the sequence can only be ranged over once.
+func Seq[K cmp.Ordered, V any](it Iterator[K, V]) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for it.Next() {
+			if !yield(it.Key(), it.Value()) {
+				return
+			}
+		}
+	}
+}
+
+// Filter yields only the pairs for which keep returns true.
+func Filter[K, V any](seq iter.Seq2[K, V], keep func(K, V) bool) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for k, v := range seq {
+			if keep(k, v) && !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// Map yields every key with its value converted by fn.
+func Map[K, V, W any](seq iter.Seq2[K, V], fn func(K, V) W) iter.Seq2[K, W] {
+	return func(yield func(K, W) bool) {
+		for k, v := range seq {
+			if !yield(k, fn(k, v)) {
+				return
+			}
+		}
+	}
+}
+
+// Take yields at most the first n pairs, and stops pulling from seq after that.
+func Take[K, V any](seq iter.Seq2[K, V], n int) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		if n <= 0 {
+			return
+		}
+		count := 0
+		for k, v := range seq {
+			if !yield(k, v) {
+				return
+			}
+			count++
+			if count >= n {
+				return
+			}
+		}
+	}
+}
+
+// Collect gathers all pairs into a slice in their order.
+func Collect[K, V any](seq iter.Seq2[K, V]) (pairs []Pair[K, V]) {
+	for k, v := range seq {
+		pairs = append(pairs, Pair[K, V]{Key: k, Value: v})
+	}
+	return
+}
@@ -0,0 +1,88 @@
+package bpTree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Check_BpTree_IterAdapter 🧫 checks composing the tree iterators with range-over-func.
+func Test_Check_BpTree_IterAdapter(t *testing.T) {
+	tree := NewBpTree(4)
+	for key := int64(1); key <= 20; key++ {
+		require.NoError(t, tree.InsertValue(BpItem{Key: key, Val: key * 10}))
+	}
+
+	t.Run("Range over the tree", func(t *testing.T) {
+		var keys []int64
+		for key, value := range tree.All() {
+			require.Equal(t, key*10, value)
+			keys = append(keys, key)
+		}
+		require.Len(t, keys, 20)
+		require.IsIncreasing(t, keys)
+	})
+
+	t.Run("Range over part of the tree", func(t *testing.T) {
+		var keys []int64
+		for key, value := range tree.Range(5, 9) {
+			require.Equal(t, key*10, value)
+			keys = append(keys, key)
+		}
+		require.Equal(t, []int64{5, 6, 7, 8}, keys)
+
+		// The cursor API gives the same items.
+		it := tree.RangeIterator(5, 9)
+		for _, key := range keys {
+			require.True(t, it.Next())
+			require.Equal(t, key, it.Key())
+		}
+		require.False(t, it.Next())
+
+		// Empty and out of bound ranges.
+		require.Empty(t, Collect(tree.Range(9, 9)))
+		require.Empty(t, Collect(tree.Range(30, 40)))
+		require.Len(t, Collect(tree.Range(-10, 100)), 20)
+	})
+
+	t.Run("Filter, map, take and collect", func(t *testing.T) {
+		even := Filter(tree.All(), func(key int64, _ interface{}) bool { return key%2 == 0 })
+		halves := Map(even, func(_ int64, value interface{}) int64 { return value.(int64) / 2 })
+		pairs := Collect(Take(halves, 3))
+		require.Equal(t, []Pair[int64, int64]{{2, 10}, {4, 20}, {6, 30}}, pairs)
+	})
+
+	t.Run("Take stops pulling", func(t *testing.T) {
+		// The endless iterator only works when Take stops pulling from it.
+		pairs := Collect(Take(Seq[int64, interface{}](&countIterator{key: 5, step: 5}), 4))
+		require.Len(t, pairs, 4)
+		require.Equal(t, int64(20), pairs[3].Key)
+		require.Empty(t, Collect(Take(tree.All(), 0)))
+	})
+
+	t.Run("Break out of the loop", func(t *testing.T) {
+		count := 0
+		for range Filter(tree.All(), func(int64, interface{}) bool { return true }) {
+			count++
+			if count == 5 {
+				break
+			}
+		}
+		require.Equal(t, 5, count)
+	})
+
+	t.Run("Every loop takes its own snapshot", func(t *testing.T) {
+		tree := NewBpTree(4)
+		all, part := tree.All(), tree.Range(0, 10)
+		require.Empty(t, Collect(all))
+
+		// The items inserted after the sequences are made show up in the next loops, and a loop can be repeated.
+		for key := int64(1); key <= 12; key++ {
+			require.NoError(t, tree.InsertValue(BpItem{Key: key}))
+		}
+		require.Len(t, Collect(all), 12)
+		require.Len(t, Collect(all), 12)
+		require.Len(t, Collect(part), 9)
+		require.Len(t, Collect(part), 9)
+	})
+}
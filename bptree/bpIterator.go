@@ -0,0 +1,175 @@
+package bpTree
+
+import (
+	"cmp"
+	"container/heap"
+)
+
+// ➡️ iterator operation
+
+// Iterator walks through key/value pairs in ascending key order.
+// Next must be called before the first Key and Value, and it returns false when nothing is left.
+type Iterator[K cmp.Ordered, V any] interface {
+	Next() bool
+	Key() K
+	Value() V
+}
+
+// sliceIterator walks through a sorted slice of items.
+type sliceIterator struct {
+	items []BpItem // The items in ascending key order.
+	ix    int      // The position of the current item, starting before the first one.
+}
+
+// Iterator returns an iterator over a snapshot of all items in ascending key order.
+// The items are copied under the lock, so the later writes to the tree do not affect the iterator.
+func (tree *BpTree) Iterator() Iterator[int64, interface{}] {
+	// Acquire a lock to ensure thread safety.
+	tree.mutex.Lock()
+
+	// Release the lock to allow other threads to access the tree.
+	defer tree.mutex.Unlock()
+
+	// Copy the items of every data node from left to right.
+	var items []BpItem
+	for _, data := range tree.root.dataNodes() {
+		items = append(items, data.Items...)
+	}
+
+	return NewSliceIterator(items)
+}
+
+// RangeIterator returns an iterator over a snapshot of the items with keys in [from, to), in ascending key order.
+func (tree *BpTree) RangeIterator(from, to int64) Iterator[int64, interface{}] {
+	// Acquire a lock to ensure thread safety.
+	tree.mutex.Lock()
+
+	// Release the lock to allow other threads to access the tree.
+	defer tree.mutex.Unlock()
+
+	// Copy only the items inside the range, and stop at the first key past it.
+	var items []BpItem
+	for _, data := range tree.root.dataNodes() {
+		for _, item := range data.Items {
+			if item.Key >= to {
+				return NewSliceIterator(items)
+			}
+			if item.Key >= from {
+				items = append(items, item)
+			}
+		}
+	}
+
+	return NewSliceIterator(items)
+}
+
+// NewSliceIterator creates an iterator over items which are already sorted by key.
+func NewSliceIterator(items []BpItem) Iterator[int64, interface{}] {
+	return &sliceIterator{items: items, ix: -1}
+}
+
+// Next moves to the next item.
+func (it *sliceIterator) Next() bool {
+	if it.ix < len(it.items) {
+		it.ix++
+	}
+	return it.ix < len(it.items)
+}
+
+// Key returns the key of the current item.
+func (it *sliceIterator) Key() int64 {
+	return it.items[it.ix].Key
+}
+
+// Value returns the value of the current item.
+func (it *sliceIterator) Value() interface{} {
+	return it.items[it.ix].Val
+}
+
+// mergeIterator merges several sorted iterators with a min-heap. (多路归并)
+type mergeIterator[K cmp.Ordered, V any] struct {
+	heap    mergeHeap[K, V]     // The iterators which still have items, ordered by their current keys.
+	current Iterator[K, V]      // The iterator holding the current item, which is the top of the heap.
+	started bool                // Indicates whether the heap has been filled.
+	sources []mergeSource[K, V] // The input iterators.
+}
+
+// mergeSource pairs an iterator with its position in the input, so equal keys keep the input order.
+type mergeSource[K cmp.Ordered, V any] struct {
+	it    Iterator[K, V] // The input iterator.
+	order int            // The position in the input.
+}
+
+// mergeHeap is a min-heap of the input iterators ordered by key, and then by input position.
+type mergeHeap[K cmp.Ordered, V any] []mergeSource[K, V]
+
+// Len, Less, Swap, Push and Pop implement heap.Interface.
+func (h mergeHeap[K, V]) Len() int { return len(h) }
+func (h mergeHeap[K, V]) Less(i, j int) bool {
+	if c := cmp.Compare(h[i].it.Key(), h[j].it.Key()); c != 0 {
+		return c < 0
+	}
+	return h[i].order < h[j].order
+}
+func (h mergeHeap[K, V]) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *mergeHeap[K, V]) Push(x any)   { *h = append(*h, x.(mergeSource[K, V])) }
+func (h *mergeHeap[K, V]) Pop() any {
+	old := *h
+	last := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return last
+}
+
+// MergeIterators returns a single iterator over all the sorted input iterators in ascending key order.
+// When several inputs have the same key, the item of the earlier input comes first.
+// Each input is moved forward lazily, so the inputs may be large or even endless.
+func MergeIterators[K cmp.Ordered, V any](its ...Iterator[K, V]) Iterator[K, V] {
+	merged := &mergeIterator[K, V]{}
+	for i, it := range its {
+		merged.sources = append(merged.sources, mergeSource[K, V]{it: it, order: i})
+	}
+	return merged
+}
+
+// Next moves to the smallest item among all the inputs.
+func (m *mergeIterator[K, V]) Next() bool {
+	// Fill the heap with the first item of every input on the first call.
+	if !m.started {
+		m.started = true
+		for _, source := range m.sources {
+			if source.it.Next() {
+				m.heap = append(m.heap, source)
+			}
+		}
+		heap.Init(&m.heap)
+	}
+
+	// Move the input of the previous item forward, and push it back if it still has items.
+	if m.current != nil {
+		if m.current.Next() {
+			heap.Fix(&m.heap, 0)
+		} else {
+			heap.Pop(&m.heap)
+		}
+	}
+
+	// Nothing is left.
+	if len(m.heap) == 0 {
+		m.current = nil
+		return false
+	}
+
+	// The top of the heap holds the smallest key.
+	m.current = m.heap[0].it
+	return true
+}
+
+// Key returns the key of the current item.
+func (m *mergeIterator[K, V]) Key() K {
+	return m.current.Key()
+}
+
+// Value returns the value of the current item.
+func (m *mergeIterator[K, V]) Value() V {
+	return m.current.Value()
+}
@@ -0,0 +1,96 @@
+package bpTree
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// countIterator 🧫 yields the endless keys start, start+step, start+2*step and so on.
+type countIterator struct {
+	key, step int64
+	started   bool
+}
+
+func (it *countIterator) Next() bool {
+	if it.started {
+		it.key += it.step
+	}
+	it.started = true
+	return true
+}
+func (it *countIterator) Key() int64         { return it.key }
+func (it *countIterator) Value() interface{} { return it.step }
+
+// Test_Check_BpTree_MergeIterators 🧫 checks the k-way merge of sorted iterators.
+func Test_Check_BpTree_MergeIterators(t *testing.T) {
+	t.Run("Merge several trees", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(5))
+
+		// Prepare three trees with random keys, and collect all keys as the answer.
+		var expected []int64
+		var its []Iterator[int64, interface{}]
+		for i := 0; i < 3; i++ {
+			tree, remain := prepareSearchTree(t, 4, 300, rng)
+			for key := range remain {
+				expected = append(expected, key)
+			}
+			its = append(its, tree.Iterator())
+		}
+		sort.Slice(expected, func(i, j int) bool { return expected[i] < expected[j] })
+
+		// An empty input does not matter.
+		its = append(its, NewBpTree(4).Iterator())
+
+		var merged []int64
+		for it := MergeIterators(its...); it.Next(); {
+			merged = append(merged, it.Key())
+		}
+		require.Equal(t, expected, merged)
+	})
+
+	t.Run("Equal keys keep the input order", func(t *testing.T) {
+		first := NewSliceIterator([]BpItem{{Key: 1, Val: "a1"}, {Key: 2, Val: "a2"}})
+		second := NewSliceIterator([]BpItem{{Key: 1, Val: "b1"}, {Key: 2, Val: "b2"}, {Key: 3, Val: "b3"}})
+
+		var values []interface{}
+		it := MergeIterators(first, second)
+		for it.Next() {
+			values = append(values, it.Value())
+		}
+		require.Equal(t, []interface{}{"a1", "b1", "a2", "b2", "b3"}, values)
+
+		// Nothing is left after the end.
+		require.False(t, it.Next())
+		require.False(t, MergeIterators[int64, interface{}]().Next())
+	})
+
+	t.Run("Endless inputs are moved lazily", func(t *testing.T) {
+		it := MergeIterators[int64, interface{}](&countIterator{key: 0, step: 3}, &countIterator{key: 1, step: 5})
+
+		var keys []int64
+		for len(keys) < 8 && it.Next() {
+			keys = append(keys, it.Key())
+		}
+		require.Equal(t, []int64{0, 1, 3, 6, 6, 9, 11, 12}, keys)
+	})
+
+	t.Run("The tree iterator is a snapshot", func(t *testing.T) {
+		tree := NewBpTree(3)
+		for key := int64(1); key <= 10; key++ {
+			tree.InsertValue(BpItem{Key: key})
+		}
+		it := tree.Iterator()
+		tree.InsertValue(BpItem{Key: 11})
+		_, _, _, err := tree.RemoveValue(BpItem{Key: 1})
+		require.NoError(t, err)
+
+		count := 0
+		for it.Next() {
+			count++
+		}
+		require.Equal(t, 10, count)
+	})
+}
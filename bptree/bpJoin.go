@@ -0,0 +1,115 @@
+package bpTree
+
+import (
+	"unsafe"
+)
+
+// ➡️ join operation
+
+// Join performs a sorted merge over the data nodes of two B plus trees and calls emit for every common key.
+// It is used for index intersection, for example, finding the documents that contain two words at the same time.
+// When a key repeats in both trees, emit is called for every pair of them.
+// ⚠️ emit is called while both trees are locked, so it must not call any method of the two trees.
+func Join(a, b *BpTree, emit func(key int64, va, vb interface{})) {
+	// Acquire the locks in a fixed order to prevent deadlocks when two joins run in opposite directions.
+	// (固定顺序上锁，避免死锁)
+	first, second := a, b
+	if uintptr(unsafe.Pointer(first)) > uintptr(unsafe.Pointer(second)) {
+		first, second = second, first
+	}
+	first.mutex.Lock()
+	defer first.mutex.Unlock()
+	if second != first {
+		second.mutex.Lock()
+		defer second.mutex.Unlock()
+	}
+
+	// Walk both trees from the smallest key.
+	cursorA := newDataCursor(a.root)
+	cursorB := newDataCursor(b.root)
+
+	// Merge both sides, moving the side with the smaller key forward.
+	for cursorA.valid() && cursorB.valid() {
+		keyA, keyB := cursorA.item().Key, cursorB.item().Key
+		switch {
+		case keyA < keyB:
+			cursorA.next()
+		case keyA > keyB:
+			cursorB.next()
+		default:
+			// Take the values of the same key from both sides.
+			valuesA := cursorA.takeSameKey(keyA)
+			valuesB := cursorB.takeSameKey(keyB)
+
+			// Emit every pair of the same key.
+			for _, va := range valuesA {
+				for _, vb := range valuesB {
+					emit(keyA, va, vb)
+				}
+			}
+		}
+	}
+}
+
+// dataCursor walks through the items of a B plus tree in ascending order.
+type dataCursor struct {
+	nodes []*BpData // The data nodes from left to right.
+	node  int       // The position of the current data node.
+	ix    int       // The position of the current item in the data node.
+}
+
+// newDataCursor creates a cursor that points to the smallest item of the tree.
+func newDataCursor(root *BpIndex) *dataCursor {
+	cursor := &dataCursor{nodes: root.dataNodes()}
+	cursor.skipEmpty()
+	return cursor
+}
+
+// valid reports whether the cursor still points to an item.
+func (cursor *dataCursor) valid() bool {
+	return cursor.node < len(cursor.nodes)
+}
+
+// item returns the item that the cursor points to.
+func (cursor *dataCursor) item() BpItem {
+	return cursor.nodes[cursor.node].Items[cursor.ix]
+}
+
+// next moves the cursor to the next item.
+func (cursor *dataCursor) next() {
+	cursor.ix++
+	cursor.skipEmpty()
+}
+
+// skipEmpty moves the cursor over the end of data nodes and empty data nodes.
+func (cursor *dataCursor) skipEmpty() {
+	for cursor.node < len(cursor.nodes) && cursor.ix >= len(cursor.nodes[cursor.node].Items) {
+		cursor.node++
+		cursor.ix = 0
+	}
+}
+
+// takeSameKey collects the values of the items with the given key and moves the cursor over them.
+func (cursor *dataCursor) takeSameKey(key int64) (values []interface{}) {
+	for cursor.valid() && cursor.item().Key == key {
+		values = append(values, cursor.item().Val)
+		cursor.next()
+	}
+	return
+}
+
+// dataNodes collects the data nodes from left to right by walking the tree structure.
+// The Previous and Next pointers are not used here, because they may not be updated on time after deletion.
+// (删除后链结可能没有及时更新，所以依照树的结构走访)
+func (inode *BpIndex) dataNodes() (nodes []*BpData) {
+	var walk func(current *BpIndex)
+	walk = func(current *BpIndex) {
+		// Entering the Recursive Function. 🔁
+		for _, indexNode := range current.IndexNodes {
+			walk(indexNode)
+		}
+		nodes = append(nodes, current.DataNodes...)
+	}
+	walk(inode)
+	return
+}
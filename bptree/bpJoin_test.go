@@ -0,0 +1,75 @@
+package bpTree
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Check_BpTree_Join 🧫 checks that Join finds exactly the common keys of two trees.
+func Test_Check_BpTree_Join(t *testing.T) {
+	t.Run("Common keys after random deletion", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(7))
+
+		// Prepare two trees with overlapping keys.
+		treeA, remainA := prepareSearchTree(t, 5, 1500, rng)
+		treeB, remainB := prepareSearchTree(t, 5, 1500, rng)
+
+		// Calculate the expected common keys with maps.
+		var expected []int64
+		for key := range remainA {
+			if _, exists := remainB[key]; exists {
+				expected = append(expected, key)
+			}
+		}
+		sort.Slice(expected, func(i, j int) bool { return expected[i] < expected[j] })
+		require.NotEmpty(t, expected)
+
+		// Join both trees.
+		var joined []int64
+		Join(treeA, treeB, func(key int64, va, vb interface{}) {
+			joined = append(joined, key)
+		})
+
+		// The keys must be the same and in ascending order.
+		require.Equal(t, expected, joined)
+	})
+
+	t.Run("Values and repeated keys", func(t *testing.T) {
+		treeA := NewBpTree(4)
+		treeB := NewBpTree(4)
+
+		treeA.InsertValue(BpItem{Key: 1, Val: "a1"})
+		treeA.InsertValue(BpItem{Key: 2, Val: "a2"})
+		treeA.InsertValue(BpItem{Key: 2, Val: "a2'"})
+		treeA.InsertValue(BpItem{Key: 5, Val: "a5"})
+
+		treeB.InsertValue(BpItem{Key: 2, Val: "b2"})
+		treeB.InsertValue(BpItem{Key: 3, Val: "b3"})
+		treeB.InsertValue(BpItem{Key: 5, Val: "b5"})
+
+		// Every pair of the repeated key is emitted.
+		var pairs [][2]interface{}
+		Join(treeA, treeB, func(key int64, va, vb interface{}) {
+			pairs = append(pairs, [2]interface{}{va, vb})
+		})
+		require.Len(t, pairs, 3)
+		require.Equal(t, [2]interface{}{"a5", "b5"}, pairs[2])
+	})
+
+	t.Run("Join a tree with itself", func(t *testing.T) {
+		tree := NewBpTree(3)
+		for i := int64(1); i <= 20; i++ {
+			tree.InsertValue(BpItem{Key: i})
+		}
+
+		// The same tree is locked only once, so no deadlock happens.
+		count := 0
+		Join(tree, tree, func(key int64, va, vb interface{}) {
+			count++
+		})
+		require.Equal(t, 20, count)
+	})
+}
@@ -0,0 +1,101 @@
+package bpTree
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ➡️ latch operation
+
+// LockWaitBuckets are the upper bounds of the wait-time histogram, the last bucket counts the longer waits.
+var LockWaitBuckets = [...]time.Duration{
+	time.Microsecond, 10 * time.Microsecond, 100 * time.Microsecond,
+	time.Millisecond, 10 * time.Millisecond, 100 * time.Millisecond,
+}
+
+// LockStats reports the contention of the tree lock, and how full the nodes are.
+// The whole tree is protected by one latch, so there is only one latch level for now.
+type LockStats struct {
+	Acquisitions  uint64                           // How many times the lock has been taken.
+	Contended     uint64                           // How many times the lock was already held and had to be waited for.
+	WaitTime      time.Duration                    // The total time spent waiting.
+	WaitHistogram [len(LockWaitBuckets) + 1]uint64 // The contended waits counted by LockWaitBuckets.
+	Fill          []LevelFill                      // The fill factors of the nodes by level, see FillStats.
+}
+
+// latch is the tree lock, which also counts the waits when the lock stats are enabled.
+type latch struct {
+	mu           sync.Mutex                              // The lock itself.
+	enabled      atomic.Bool                             // Whether the waits are counted.
+	acquisitions atomic.Uint64                           // How many times the lock has been taken.
+	contended    atomic.Uint64                           // How many times the lock had to be waited for.
+	waitTime     atomic.Int64                            // The total wait time in nanoseconds.
+	histogram    [len(LockWaitBuckets) + 1]atomic.Uint64 // The contended waits by bucket.
+}
+
+// Lock takes the lock, and measures the wait only when the lock is already held by someone else.
+func (l *latch) Lock() {
+	// Without the stats, it is a plain mutex.
+	if !l.enabled.Load() {
+		l.mu.Lock()
+		return
+	}
+
+	l.acquisitions.Add(1)
+	if l.mu.TryLock() {
+		return
+	}
+
+	// The lock is contended, so measure how long it takes.
+	start := time.Now()
+	l.mu.Lock()
+	wait := time.Since(start)
+
+	l.contended.Add(1)
+	l.waitTime.Add(int64(wait))
+	bucket := len(LockWaitBuckets)
+	for i, bound := range LockWaitBuckets {
+		if wait <= bound {
+			bucket = i
+			break
+		}
+	}
+	l.histogram[bucket].Add(1)
+}
+
+// Unlock releases the lock.
+func (l *latch) Unlock() {
+	l.mu.Unlock()
+}
+
+// EnableLockStats turns the lock instrumentation on or off, it is off by default because it costs a little.
+// Turning it on clears the previous stats.
+func (tree *BpTree) EnableLockStats(enable bool) {
+	if enable {
+		tree.mutex.acquisitions.Store(0)
+		tree.mutex.contended.Store(0)
+		tree.mutex.waitTime.Store(0)
+		for i := range tree.mutex.histogram {
+			tree.mutex.histogram[i].Store(0)
+		}
+	}
+	tree.mutex.enabled.Store(enable)
+}
+
+// Stats returns the lock contention collected since EnableLockStats, and the fill factors of the nodes right now.
+// The fill factors are counted whether the lock stats are enabled or not, by walking the whole tree.
+func (tree *BpTree) Stats() (stats LockStats) {
+	stats.Acquisitions = tree.mutex.acquisitions.Load()
+	stats.Contended = tree.mutex.contended.Load()
+	stats.WaitTime = time.Duration(tree.mutex.waitTime.Load())
+	for i := range tree.mutex.histogram {
+		stats.WaitHistogram[i] = tree.mutex.histogram[i].Load()
+	}
+
+	// Walk the tree under the plain mutex, so the walk is not counted as an acquisition.
+	tree.mutex.mu.Lock()
+	stats.Fill = tree.root.fillStats(tree.width)
+	tree.mutex.mu.Unlock()
+	return
+}
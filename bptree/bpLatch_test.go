@@ -0,0 +1,94 @@
+package bpTree
+
+import (
+	"math/rand"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// watchdog 🧫 dumps the stacks of all goroutines when an operation runs longer than the timeout,
+// so a deadlock in a concurrent test shows where every goroutine is stuck.
+type watchdog struct {
+	t       testing.TB
+	timeout time.Duration
+}
+
+// run 🧫 runs the operation under the watchdog.
+func (w watchdog) run(op string, f func()) {
+	timer := time.AfterFunc(w.timeout, func() {
+		buf := make([]byte, 1<<20)
+		n := runtime.Stack(buf, true)
+		w.t.Errorf("%s exceeds %v, goroutine stacks:\n%s", op, w.timeout, buf[:n])
+	})
+	defer timer.Stop()
+	f()
+}
+
+// Test_Check_BpTree_LockStats 🧫 checks the lock instrumentation under concurrent operations.
+func Test_Check_BpTree_LockStats(t *testing.T) {
+	t.Run("Concurrent operations are counted", func(t *testing.T) {
+		tree := NewBpTree(5)
+		tree.EnableLockStats(true)
+		dog := watchdog{t: t, timeout: 10 * time.Second}
+
+		// Insert, search and delete from several goroutines.
+		var wg sync.WaitGroup
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				rng := rand.New(rand.NewSource(int64(g)))
+				for i := 0; i < 500; i++ {
+					key := int64(g*1000 + i)
+					dog.run("insert", func() { _ = tree.InsertValue(BpItem{Key: key}) })
+					dog.run("get", func() { tree.Get(rng.Int63n(8000)) })
+					dog.run("remove", func() { _, _, _, _ = tree.RemoveValue(BpItem{Key: key}) })
+				}
+			}(g)
+		}
+		wg.Wait()
+
+		// Every operation takes the lock once.
+		stats := tree.Stats()
+		require.Equal(t, uint64(8*500*3), stats.Acquisitions)
+		var histogram uint64
+		for _, count := range stats.WaitHistogram {
+			histogram += count
+		}
+		require.Equal(t, stats.Contended, histogram)
+	})
+
+	t.Run("A held lock is measured", func(t *testing.T) {
+		tree := NewBpTree(5)
+		tree.EnableLockStats(true)
+
+		// Hold the lock, so the search has to wait for about 20 milliseconds.
+		tree.mutex.Lock()
+		done := make(chan struct{})
+		go func() {
+			tree.Get(1)
+			close(done)
+		}()
+		time.Sleep(20 * time.Millisecond)
+		tree.mutex.Unlock()
+		<-done
+
+		stats := tree.Stats()
+		require.Equal(t, uint64(2), stats.Acquisitions)
+		require.Equal(t, uint64(1), stats.Contended)
+		require.GreaterOrEqual(t, stats.WaitTime, 20*time.Millisecond)
+		require.Equal(t, uint64(1), stats.WaitHistogram[5]+stats.WaitHistogram[6]) // Longer than 10 milliseconds.
+	})
+
+	t.Run("Disabled by default", func(t *testing.T) {
+		tree := NewBpTree(5)
+		_ = tree.InsertValue(BpItem{Key: 1})
+		stats := tree.Stats()
+		stats.Fill = nil // The fill factors are counted anyway.
+		require.Equal(t, LockStats{}, stats)
+	})
+}
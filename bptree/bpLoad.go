@@ -0,0 +1,348 @@
+package bpTree
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// ➡️ load operation
+
+// ProgressUpdater is updated once for every row, *utilhub.ProgressBar satisfies it.
+type ProgressUpdater interface {
+	UpdateBar()
+}
+
+// RowError records a row that could not be loaded, the loading goes on with the next row.
+type RowError struct {
+	Line int   // The line number of the row, starting from 1 and including the header.
+	Err  error // Why the row is rejected.
+}
+
+// Error implements the error interface.
+func (e RowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e RowError) Unwrap() error {
+	return e.Err
+}
+
+// LoadReport summarizes a loading.
+type LoadReport struct {
+	Loaded  int        // The number of rows inserted into the tree.
+	BadRows []RowError // The rows which were skipped.
+}
+
+// Loader streams rows from CSV or JSON lines into a B plus tree in batches.
+type Loader struct {
+	tree      *BpTree         // The tree receiving the rows.
+	batchSize int             // The number of rows inserted under one lock.
+	progress  ProgressUpdater // Updated for every row, can be nil.
+}
+
+// LoaderOption defines a function type for configuring the loader.
+type LoaderOption func(*Loader)
+
+// WithLoadBatch sets how many rows are inserted under one lock.
+func WithLoadBatch(size int) LoaderOption {
+	return func(l *Loader) {
+		l.batchSize = size
+	}
+}
+
+// WithLoadProgress updates the progress bar once for every row.
+func WithLoadProgress(progress ProgressUpdater) LoaderOption {
+	return func(l *Loader) {
+		l.progress = progress
+	}
+}
+
+// NewLoader creates a loader for the tree, the default batch size is 1024 rows.
+func NewLoader(tree *BpTree, opts ...LoaderOption) *Loader {
+	l := &Loader{
+		tree:      tree,
+		batchSize: 1024,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if l.batchSize < 1 {
+		l.batchSize = 1
+	}
+	return l
+}
+
+// LoadFromCSV loads a CSV export with the default loader, see Loader.LoadFromCSV.
+func (tree *BpTree) LoadFromCSV(r io.Reader, keyColumn string, valueColumns ...string) (LoadReport, error) {
+	return NewLoader(tree).LoadFromCSV(r, keyColumn, valueColumns...)
+}
+
+// LoadFromJSONLines loads a JSON lines export with the default loader, see Loader.LoadFromJSONLines.
+func (tree *BpTree) LoadFromJSONLines(r io.Reader, keyField string, valueFields ...string) (LoadReport, error) {
+	return NewLoader(tree).LoadFromJSONLines(r, keyField, valueFields...)
+}
+
+// LoadFromCSV reads a CSV stream whose first row is the header.
+// The key column must be an integer. The value is mapped from the value columns:
+//   - one column: the value is the string of that column.
+//   - several columns: the value is a map[string]string of those columns.
+//   - no column: the value is a map[string]string of all columns except the key.
+//
+// Bad rows are collected in the report, and the returned error is only for failures of the stream itself.
+func (l *Loader) LoadFromCSV(r io.Reader, keyColumn string, valueColumns ...string) (report LoadReport, err error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1 // The length of every row is checked here, so a bad row does not stop the loading.
+
+	// Read the header and map the column names to their positions.
+	header, err := reader.Read()
+	if err != nil {
+		return report, fmt.Errorf("read csv header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[name] = i
+	}
+	keyIx, exists := columns[keyColumn]
+	if !exists {
+		return report, fmt.Errorf("key column %q is not in the header", keyColumn)
+	}
+	if len(valueColumns) == 0 {
+		for _, name := range header {
+			if name != keyColumn {
+				valueColumns = append(valueColumns, name)
+			}
+		}
+	}
+	valueIx := make([]int, len(valueColumns))
+	for i, name := range valueColumns {
+		if valueIx[i], exists = columns[name]; !exists {
+			return report, fmt.Errorf("value column %q is not in the header", name)
+		}
+	}
+
+	// Stream the rows into the tree.
+	batch := l.newBatch(&report)
+	for {
+		record, readErr := reader.Read()
+		if readErr == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(readErr, &parseErr) {
+			batch.reject(parseErr.StartLine, readErr)
+			continue
+		}
+		if readErr != nil {
+			return report, readErr
+		}
+		line, _ := reader.FieldPos(0) // A quoted field may span several lines, so the line is taken from the reader.
+		if len(record) != len(header) {
+			batch.reject(line, fmt.Errorf("expected %d columns, got %d", len(header), len(record)))
+			continue
+		}
+
+		// Parse the key.
+		key, convErr := strconv.ParseInt(record[keyIx], 10, 64)
+		if convErr != nil {
+			batch.reject(line, fmt.Errorf("key %q: %w", record[keyIx], convErr))
+			continue
+		}
+
+		// Map the value.
+		var value interface{}
+		if len(valueIx) == 1 {
+			value = record[valueIx[0]]
+		} else {
+			fields := make(map[string]string, len(valueIx))
+			for i, ix := range valueIx {
+				fields[valueColumns[i]] = record[ix]
+			}
+			value = fields
+		}
+
+		batch.add(line, BpItem{Key: key, Val: value})
+	}
+	batch.finish()
+
+	return report, nil
+}
+
+// LoadFromJSONLines reads one JSON object per line, empty lines are skipped.
+// The key field must be an integer. The value is mapped from the value fields:
+//   - one field: the value is the decoded value of that field.
+//   - several fields: the value is a map[string]interface{} of those fields.
+//   - no field: the value is a map[string]interface{} of all fields except the key.
+//
+// The numbers are decoded as json.Number, so large integers keep their precision.
+// Bad rows are collected in the report, and the returned error is only for failures of the stream itself.
+func (l *Loader) LoadFromJSONLines(r io.Reader, keyField string, valueFields ...string) (report LoadReport, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024) // Allow long lines up to 16 MiB.
+
+	// Stream the rows into the tree.
+	batch := l.newBatch(&report)
+	for line := 1; scanner.Scan(); line++ {
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+
+		// Decode the row into an item.
+		item, decodeErr := decodeJSONLine(raw, keyField, valueFields)
+		if decodeErr != nil {
+			batch.reject(line, decodeErr)
+			continue
+		}
+
+		batch.add(line, item)
+	}
+	batch.finish()
+
+	return report, scanner.Err()
+}
+
+// decodeJSONLine decodes a JSON object into an item, see Loader.LoadFromJSONLines for the value mapping.
+func decodeJSONLine(raw []byte, keyField string, valueFields []string) (item BpItem, err error) {
+	// Decode the object with the numbers kept as json.Number.
+	var object map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err = decoder.Decode(&object); err != nil {
+		return
+	}
+
+	// Parse the key.
+	number, ok := object[keyField].(json.Number)
+	if !ok {
+		err = fmt.Errorf("key field %q is missing or not a number", keyField)
+		return
+	}
+	if item.Key, err = number.Int64(); err != nil {
+		err = fmt.Errorf("key %s: %w", number, err)
+		return
+	}
+
+	// Map the value.
+	switch len(valueFields) {
+	case 0:
+		delete(object, keyField)
+		item.Val = object
+	case 1:
+		item.Val = object[valueFields[0]]
+	default:
+		fields := make(map[string]interface{}, len(valueFields))
+		for _, name := range valueFields {
+			fields[name] = object[name]
+		}
+		item.Val = fields
+	}
+
+	return
+}
+
+// loadBatch collects the parsed rows and inserts them together.
+type loadBatch struct {
+	loader *Loader     // The loader owning the batch.
+	report *LoadReport // The report being filled.
+	lines  []int       // The line numbers of the items.
+	items  []BpItem    // The items waiting to be inserted.
+}
+
+// newBatch creates an empty batch writing into the report.
+func (l *Loader) newBatch(report *LoadReport) *loadBatch {
+	return &loadBatch{
+		loader: l,
+		report: report,
+		lines:  make([]int, 0, l.batchSize),
+		items:  make([]BpItem, 0, l.batchSize),
+	}
+}
+
+// reject records a bad row.
+func (b *loadBatch) reject(line int, err error) {
+	b.report.BadRows = append(b.report.BadRows, RowError{Line: line, Err: err})
+	b.update()
+}
+
+// add appends an item, and inserts the batch when it is full.
+func (b *loadBatch) add(line int, item BpItem) {
+	b.lines = append(b.lines, line)
+	b.items = append(b.items, item)
+	if len(b.items) >= b.loader.batchSize {
+		b.flush()
+	}
+}
+
+// flush inserts all items of the batch under one lock, the items rejected by the validators become bad rows.
+func (b *loadBatch) flush() {
+	if len(b.items) == 0 {
+		return
+	}
+
+	// Insert the batch, and collect the rejected items.
+	errs := b.loader.tree.insertBatch(b.items)
+	for i, err := range errs {
+		if err != nil {
+			b.report.BadRows = append(b.report.BadRows, RowError{Line: b.lines[i], Err: err})
+			continue
+		}
+		b.report.Loaded++
+	}
+
+	// Update the progress for every row of the batch.
+	for range b.items {
+		b.update()
+	}
+
+	b.lines = b.lines[:0]
+	b.items = b.items[:0]
+}
+
+// finish inserts the remaining items, and sorts the bad rows by line.
+func (b *loadBatch) finish() {
+	b.flush()
+	sort.SliceStable(b.report.BadRows, func(i, j int) bool {
+		return b.report.BadRows[i].Line < b.report.BadRows[j].Line
+	})
+}
+
+// insertBatch ensures thread safety, inserts many items under one lock, release lock.
+// The returned errors are aligned with the items, and errs[i] is not nil when items[i] is rejected by a validator.
+func (tree *BpTree) insertBatch(items []BpItem) (errs []error) {
+	errs = make([]error, len(items))
+	inserted := make([]ChangeEvent, 0, len(items))
+
+	// Acquire a lock to ensure thread safety.
+	tree.mutex.Lock()
+	for i, item := range items {
+		if errs[i] = tree.validate(item); errs[i] != nil {
+			continue
+		}
+		tree.insert(item)
+		inserted = append(inserted, ChangeEvent{Kind: ChangeInsert, Item: item})
+	}
+	turn := tree.notifyTurn()
+
+	// Release the lock to allow other threads to access the tree.
+	tree.mutex.Unlock()
+
+	// Notify the watchers after the lock is released, in the order of the writes.
+	tree.notifyWatchers(turn, inserted...)
+
+	return
+}
+
+// update moves the progress forward by one row.
+func (b *loadBatch) update() {
+	if b.loader.progress != nil {
+		b.loader.progress.UpdateBar()
+	}
+}
@@ -0,0 +1,103 @@
+package bpTree
+
+import (
+	"encoding/json"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// countingProgress 🧫 counts the updates instead of drawing a progress bar.
+type countingProgress struct {
+	updates atomic.Int64
+}
+
+// UpdateBar counts one update.
+func (p *countingProgress) UpdateBar() {
+	p.updates.Add(1)
+}
+
+// Test_Check_BpTree_Load 🧫 checks loading CSV and JSON lines exports into the tree.
+func Test_Check_BpTree_Load(t *testing.T) {
+	t.Run("CSV with bad rows", func(t *testing.T) {
+		input := strings.Join([]string{
+			"id,name,city",
+			"3,carol,paris",
+			"1,alice,tokyo",
+			"x,bad,key",
+			"2,bob",
+			`4,"dave`,
+			`smith",berlin`,
+			"5,erin,rome",
+		}, "\n")
+
+		tree := NewBpTree(3)
+		progress := &countingProgress{}
+		report, err := NewLoader(tree, WithLoadBatch(2), WithLoadProgress(progress)).LoadFromCSV(strings.NewReader(input), "id", "name")
+		require.NoError(t, err)
+
+		// The bad key and the short row are collected with their lines.
+		require.Equal(t, 4, report.Loaded)
+		require.Len(t, report.BadRows, 2)
+		require.Equal(t, 4, report.BadRows[0].Line)
+		require.Equal(t, 5, report.BadRows[1].Line)
+		require.Equal(t, int64(6), progress.updates.Load())
+
+		require.Equal(t, []int64{1, 3, 4, 5}, collectKeys(tree))
+		item, found := tree.Get(4)
+		require.True(t, found)
+		require.Equal(t, "dave\nsmith", item.Val)
+	})
+
+	t.Run("CSV with several value columns", func(t *testing.T) {
+		tree := NewBpTree(4)
+		report, err := tree.LoadFromCSV(strings.NewReader("name,id,city\nalice,1,tokyo\n"), "id")
+		require.NoError(t, err)
+		require.Equal(t, 1, report.Loaded)
+
+		item, _ := tree.Get(1)
+		require.Equal(t, map[string]string{"name": "alice", "city": "tokyo"}, item.Val)
+
+		// A missing column stops the loading before anything is read.
+		_, err = tree.LoadFromCSV(strings.NewReader("name,id\n"), "id", "city")
+		require.Error(t, err)
+	})
+
+	t.Run("JSON lines with bad rows", func(t *testing.T) {
+		input := strings.Join([]string{
+			`{"id": 9007199254740993, "name": "big"}`,
+			`{"id": 2, "name": "bob", "age": 30}`,
+			``,
+			`{"id": "3", "name": "string key"}`,
+			`{"id": 4, "name": `,
+			`{"name": "no key"}`,
+		}, "\n")
+
+		tree := NewBpTree(4)
+		tree.AddValidator(KeyRange(0, 1<<60))
+		report, err := tree.LoadFromJSONLines(strings.NewReader(input), "id", "name")
+		require.NoError(t, err)
+		require.Equal(t, 2, report.Loaded)
+
+		var lines []int
+		for _, bad := range report.BadRows {
+			lines = append(lines, bad.Line)
+		}
+		require.Equal(t, []int{4, 5, 6}, lines)
+
+		// The large key keeps its precision.
+		item, found := tree.Get(9007199254740993)
+		require.True(t, found)
+		require.Equal(t, "big", item.Val)
+
+		// The rejected rows of the validators are collected too.
+		report, err = tree.LoadFromJSONLines(strings.NewReader(`{"id": -1}`+"\n"+`{"id": 7, "age": 1}`), "id")
+		require.NoError(t, err)
+		require.Equal(t, 1, report.Loaded)
+		require.ErrorIs(t, report.BadRows[0], ErrConstraintViolation)
+		item, _ = tree.Get(7)
+		require.Equal(t, map[string]interface{}{"age": json.Number("1")}, item.Val)
+	})
+}
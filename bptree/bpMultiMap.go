@@ -0,0 +1,178 @@
+package bpTree
+
+import (
+	"iter"
+	"math"
+	"slices"
+)
+
+// ➡️ multimap operation
+
+// MultiMap is an ordered multimap backed by a B plus tree, for the common index with repeated keys. (有序多值映射)
+// Every key is one item of the tree holding all the values of the key, like BpOrderedMap keeps one item for every key,
+// so the values keep the order they were put in, and a key with many values does not spread over many data nodes.
+type MultiMap[K SetKey, V comparable] struct {
+	tree   *BpTree // The tree holding one item for every key, its value is a *[]V.
+	length int     // The number of values under all the keys, guarded by the lock of the tree.
+}
+
+// NewMultiMap creates an empty multimap backed by a B plus tree with the given width.
+func NewMultiMap[K SetKey, V comparable](width int) *MultiMap[K, V] {
+	return &MultiMap[K, V]{tree: NewBpTree(width)}
+}
+
+// Put adds the value after the values already under the key, even an equal one.
+// It panics when an int or an int64 key is out of the range a MultiMap can hold.
+func (m *MultiMap[K, V]) Put(key K, value V) {
+	k := mustTreeKey(key)
+
+	// Acquire a lock to ensure thread safety.
+	m.tree.mutex.Lock()
+
+	// Release the lock to allow other threads to access the tree.
+	defer m.tree.mutex.Unlock()
+
+	if values := m.values(key); values != nil {
+		*values = append(*values, value)
+	} else {
+		m.tree.insert(BpItem{Key: k, Val: &[]V{value}})
+	}
+	m.length++
+}
+
+// GetAll returns a copy of the values of the key in the order they were put in, nil when the key is not there.
+func (m *MultiMap[K, V]) GetAll(key K) []V {
+	// Acquire a lock to ensure thread safety.
+	m.tree.mutex.Lock()
+
+	// Release the lock to allow other threads to access the tree.
+	defer m.tree.mutex.Unlock()
+
+	if values := m.values(key); values != nil {
+		return slices.Clone(*values)
+	}
+	return nil
+}
+
+// Values returns a snapshot of the values of the key in the order they were put in, for use with range-over-func.
+func (m *MultiMap[K, V]) Values(key K) iter.Seq[V] {
+	return slices.Values(m.GetAll(key))
+}
+
+// Count returns the number of values of the key.
+func (m *MultiMap[K, V]) Count(key K) int {
+	// Acquire a lock to ensure thread safety.
+	m.tree.mutex.Lock()
+
+	// Release the lock to allow other threads to access the tree.
+	defer m.tree.mutex.Unlock()
+
+	if values := m.values(key); values != nil {
+		return len(*values)
+	}
+	return 0
+}
+
+// DeleteOne removes the first value equal to the given one from the key, and reports whether there was one.
+// The key is removed with its last value.
+func (m *MultiMap[K, V]) DeleteOne(key K, value V) bool {
+	// Acquire a lock to ensure thread safety.
+	m.tree.mutex.Lock()
+
+	// Release the lock to allow other threads to access the tree.
+	defer m.tree.mutex.Unlock()
+
+	values := m.values(key)
+	if values == nil {
+		return false
+	}
+	ix := slices.Index(*values, value)
+	if ix < 0 {
+		return false
+	}
+	if *values = slices.Delete(*values, ix, ix+1); len(*values) == 0 {
+		_, _, _, _ = m.tree.remove(BpItem{Key: mustTreeKey(key)})
+	}
+	m.length--
+	return true
+}
+
+// DeleteAll removes the key with all its values, and returns how many values there were.
+func (m *MultiMap[K, V]) DeleteAll(key K) int {
+	// Acquire a lock to ensure thread safety.
+	m.tree.mutex.Lock()
+
+	// Release the lock to allow other threads to access the tree.
+	defer m.tree.mutex.Unlock()
+
+	values := m.values(key)
+	if values == nil {
+		return 0
+	}
+	_, _, _, _ = m.tree.remove(BpItem{Key: mustTreeKey(key)})
+	m.length -= len(*values)
+	return len(*values)
+}
+
+// Len returns the number of values under all the keys.
+func (m *MultiMap[K, V]) Len() int {
+	// Acquire a lock to ensure thread safety.
+	m.tree.mutex.Lock()
+
+	// Release the lock to allow other threads to access the tree.
+	defer m.tree.mutex.Unlock()
+
+	return m.length
+}
+
+// Keys returns a snapshot of the keys in ascending order, for use with range-over-func.
+func (m *MultiMap[K, V]) Keys() iter.Seq[K] {
+	keys := m.tree.keys()
+	return func(yield func(K) bool) {
+		for _, key := range keys {
+			if !yield(fromTreeKey[K](key)) {
+				return
+			}
+		}
+	}
+}
+
+// All returns a snapshot of every key and value in ascending key order, for use with range-over-func.
+// A key with several values comes once for every value, in the order they were put in.
+func (m *MultiMap[K, V]) All() iter.Seq2[K, V] {
+	// Acquire a lock to ensure thread safety.
+	m.tree.mutex.Lock()
+
+	// Copy every value, and release the lock before they are yielded.
+	var items []BpItem
+	m.tree.root.collectRange(math.MinInt64, math.MaxInt64, &items)
+	copied := make([][]V, len(items))
+	for i, item := range items {
+		copied[i] = slices.Clone(*item.Val.(*[]V))
+	}
+	m.tree.mutex.Unlock()
+
+	return func(yield func(K, V) bool) {
+		for i, item := range items {
+			for _, value := range copied[i] {
+				if !yield(fromTreeKey[K](item.Key), value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// values returns the values of the key, which are changed in place, or nil. The lock must be held by the caller.
+func (m *MultiMap[K, V]) values(key K) *[]V {
+	k, ok := treeKey(key)
+	if !ok {
+		return nil
+	}
+	item, found := m.tree.root.search(k)
+	if !found {
+		return nil
+	}
+	values, _ := item.Val.(*[]V)
+	return values
+}
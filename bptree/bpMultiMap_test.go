@@ -0,0 +1,103 @@
+package bpTree
+
+import (
+	"math/rand"
+	"slices"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Check_BpTree_MultiMap 🧫 checks the multimap against a built-in map of slices, with many values for every key.
+func Test_Check_BpTree_MultiMap(t *testing.T) {
+	random := rand.New(rand.NewSource(2049))
+	m := NewMultiMap[int32, int](3)
+	model := map[int32][]int{}
+
+	for i := 0; i < 4000; i++ {
+		key := int32(random.Intn(30) - 15) // The negative keys too.
+		switch op := random.Intn(10); {
+		case op < 6:
+			value := random.Intn(5) // Equal values under one key are kept apart.
+			m.Put(key, value)
+			model[key] = append(model[key], value)
+		case op < 9:
+			value := random.Intn(5)
+			ix := slices.Index(model[key], value)
+			require.Equal(t, ix >= 0, m.DeleteOne(key, value))
+			if ix >= 0 {
+				model[key] = slices.Delete(model[key], ix, ix+1)
+			}
+			if len(model[key]) == 0 {
+				delete(model, key) // The key goes with its last value.
+			}
+		default:
+			require.Equal(t, len(model[key]), m.DeleteAll(key))
+			delete(model, key)
+		}
+		require.Equal(t, model[key], m.GetAll(key), "key %d", key) // In the order they were put in.
+		require.Equal(t, len(model[key]), m.Count(key))
+	}
+
+	// Every key comes once, and every value of it comes in All.
+	var keys []int32
+	length := 0
+	for key, values := range model {
+		keys = append(keys, key)
+		length += len(values)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	require.Equal(t, keys, slices.Collect(m.Keys()))
+	require.Equal(t, length, m.Len())
+	all := map[int32][]int{}
+	previous := int32(-15)
+	for key, value := range m.All() {
+		require.GreaterOrEqual(t, key, previous)
+		previous = key
+		all[key] = append(all[key], value)
+	}
+	for _, key := range keys {
+		require.Equal(t, model[key], all[key])
+	}
+
+	// A missing key has nothing, and an iterator can stop early.
+	require.Nil(t, m.GetAll(1000))
+	require.False(t, m.DeleteOne(1000, 0))
+	require.Zero(t, m.DeleteAll(1000))
+	for value := range m.Values(keys[0]) {
+		require.Equal(t, model[keys[0]][0], value)
+		break
+	}
+
+	// A returned slice is a copy, changing it does not change the map.
+	values := m.GetAll(keys[0])
+	values[0] = -1
+	require.Equal(t, model[keys[0]], m.GetAll(keys[0]))
+}
+
+// Test_Check_BpTree_MultiMap_Concurrent 🧫 checks that every value is counted once when values are put and deleted concurrently.
+func Test_Check_BpTree_MultiMap_Concurrent(t *testing.T) {
+	m := NewMultiMap[int, int](4)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := -50; key < 50; key++ {
+				m.Put(key, g)
+				if key%2 == 0 {
+					m.DeleteOne(key, g)
+				}
+				require.LessOrEqual(t, m.Len(), 8*100)
+			}
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, 8*50, m.Len())
+	for key := -49; key < 50; key += 2 {
+		require.Equal(t, 8, m.Count(key))
+	}
+}
@@ -0,0 +1,67 @@
+package bpTree
+
+// ➡️ ordered map operation
+
+// BpOrderedMap adapts the B plus tree to the orderedmap.OrderedMap interface.
+// The tree keeps duplicate keys, so Put replaces the item of an existing key instead of adding another one.
+// The keys are shifted into the non-negative keys of the tree like the keys of a Set, so they must be from -2^62 to 2^62-1.
+type BpOrderedMap[V any] struct {
+	tree   *BpTree // The tree holding the items.
+	length int     // The number of keys.
+}
+
+// NewBpOrderedMap creates an empty ordered map backed by a B plus tree with the given width.
+func NewBpOrderedMap[V any](width int) *BpOrderedMap[V] {
+	return &BpOrderedMap[V]{tree: NewBpTree(width)}
+}
+
+// Put inserts the key, or replaces the value when the key is already there.
+// It panics when the key is out of the range the map can hold.
+func (m *BpOrderedMap[V]) Put(key int64, value V) {
+	k := mustTreeKey(key)
+	m.Delete(key)
+	_ = m.tree.InsertValue(BpItem{Key: k, Val: value})
+	m.length++
+}
+
+// Get returns the value of the key and whether it is found.
+func (m *BpOrderedMap[V]) Get(key int64) (value V, found bool) {
+	k, ok := treeKey(key)
+	if !ok {
+		return
+	}
+	item, found := m.tree.Get(k)
+	if found {
+		value, _ = item.Val.(V)
+	}
+	return
+}
+
+// Delete removes the key and reports whether it was there.
+func (m *BpOrderedMap[V]) Delete(key int64) bool {
+	k, ok := treeKey(key)
+	if !ok {
+		return false
+	}
+	deleted, _, _, err := m.tree.RemoveValue(BpItem{Key: k})
+	if deleted && err == nil {
+		m.length--
+		return true
+	}
+	return false
+}
+
+// Len returns the number of keys.
+func (m *BpOrderedMap[V]) Len() int {
+	return m.length
+}
+
+// Ascend calls fn for every key in ascending order until fn returns false, over a snapshot of the tree.
+func (m *BpOrderedMap[V]) Ascend(fn func(key int64, value V) bool) {
+	for it := m.tree.Iterator(); it.Next(); {
+		value, _ := it.Value().(V)
+		if !fn(fromTreeKey[int64](it.Key()), value) {
+			return
+		}
+	}
+}
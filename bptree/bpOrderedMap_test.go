@@ -0,0 +1,38 @@
+package bpTree
+
+import (
+	"testing"
+
+	"github.com/panhongrainbow/go-algorithm/orderedmap"
+	"github.com/stretchr/testify/require"
+)
+
+// newOrderedMap 🧫 creates a narrow tree, so the suites go through many splits and merges.
+func newOrderedMap() orderedmap.OrderedMap[int64, int64] {
+	return NewBpOrderedMap[int64](4)
+}
+
+// Test_Check_BpTree_OrderedMap 🧫 runs the ordered map conformance suite on the B plus tree.
+func Test_Check_BpTree_OrderedMap(t *testing.T) {
+	orderedmap.RunConformance(t, newOrderedMap, orderedmap.Int64Keys, orderedmap.Int64Keys)
+
+	// The negative keys are shifted like the keys of a Set, so the edge sentinel of the tree is never a key.
+	t.Run("Negative keys", func(t *testing.T) {
+		orderedmap.RunConformance(t, newOrderedMap, orderedmap.CenteredInt64Keys, orderedmap.Int64Keys)
+	})
+	m := NewBpOrderedMap[int64](3)
+	for key := int64(-50); key < 50; key++ {
+		m.Put(key, key)
+	}
+	require.Equal(t, 100, m.Len())
+	require.Panics(t, func() { m.Put(1<<62, 0) })
+	_, found := m.Get(1 << 62)
+	require.False(t, found)
+	require.False(t, m.Delete(-1<<62-1))
+}
+
+// Benchmark_BpTree_OrderedMap 🧫 runs the ordered map benchmark suite on the B plus tree.
+func Benchmark_BpTree_OrderedMap(b *testing.B) {
+	orderedmap.RunBenchmarks(b, func() orderedmap.OrderedMap[int64, int64] { return NewBpOrderedMap[int64](32) },
+		orderedmap.Int64Keys, orderedmap.Int64Keys, 10000)
+}
@@ -0,0 +1,225 @@
+package bpTree
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"sort"
+	"strings"
+)
+
+// ➡️ plan operation
+
+// ErrKeyLayout is returned when a composite key or a query does not fit its key layout.
+var ErrKeyLayout = errors.New("does not fit the key layout")
+
+// KeyLayout describes the composite keys packed into the int64 key, like UniquePrefix does with two components.
+// Every component is a non-negative integer, and the widths in bits go from the most significant component down,
+// so the keys sort by the first component, then by the second, and so on. (复合键布局)
+type KeyLayout []uint
+
+// NewKeyLayout returns the layout of the components with the widths in bits, at most 63 bits in total,
+// so every composite key stays non-negative and keeps the order of its components.
+func NewKeyLayout(bits ...uint) (KeyLayout, error) {
+	total := uint(0)
+	for _, width := range bits {
+		if width == 0 {
+			return nil, fmt.Errorf("a component of 0 bits %w", ErrKeyLayout)
+		}
+		total += width
+	}
+	if len(bits) == 0 || total > 63 {
+		return nil, fmt.Errorf("%d components of %d bits in total %w, which needs 1 to 63 bits", len(bits), total, ErrKeyLayout)
+	}
+	return append(KeyLayout(nil), bits...), nil
+}
+
+// shift returns how far the component is shifted left in the key.
+func (layout KeyLayout) shift(component int) (shift uint) {
+	for _, width := range layout[component+1:] {
+		shift += width
+	}
+	return
+}
+
+// limit returns the number of values of the component, the exclusive upper bound of its values.
+func (layout KeyLayout) limit(component int) int64 {
+	return int64(1) << layout[component]
+}
+
+// Key packs the components into a key, a missing component at the end is 0.
+func (layout KeyLayout) Key(components ...int64) (int64, error) {
+	if len(components) > len(layout) {
+		return 0, fmt.Errorf("%d components %w of %d components", len(components), ErrKeyLayout, len(layout))
+	}
+	key := int64(0)
+	for i, value := range components {
+		if value < 0 || value >= layout.limit(i) {
+			return 0, fmt.Errorf("component %d = %d %w, which holds [0, %d)", i, value, ErrKeyLayout, layout.limit(i))
+		}
+		key |= value << layout.shift(i)
+	}
+	return key, nil
+}
+
+// Split unpacks the key into its components.
+func (layout KeyLayout) Split(key int64) []int64 {
+	components := make([]int64, len(layout))
+	for i := range layout {
+		components[i] = key >> layout.shift(i) & (layout.limit(i) - 1)
+	}
+	return components
+}
+
+// ScanPlan is the key range to scan for a query on composite keys, see KeyLayout.Plan.
+type ScanPlan struct {
+	Layout KeyLayout // The layout the plan is made for.
+	Prefix []int64   // The values of the leading components, which must be equal.
+	Ranged bool      // Whether the component after the prefix is limited to [From, To).
+	From   int64     // The smallest value of the ranged component, clamped into its values.
+	To     int64     // The value past the largest one of the ranged component, clamped into its values.
+	Low    int64     // The smallest key to scan.
+	High   int64     // The largest key to scan, included.
+	Empty  bool      // Whether no key can match, so nothing is scanned.
+}
+
+// PlanOption defines a function type for configuring the plan.
+type PlanOption func(*ScanPlan)
+
+// WithNextRange limits the component after the prefix to [from, to), the values outside the component are clamped.
+func WithNextRange(from, to int64) PlanOption {
+	return func(plan *ScanPlan) {
+		plan.Ranged, plan.From, plan.To = true, from, to
+	}
+}
+
+// Plan turns the equality on the leading components, and optionally a range on the next one,
+// into the tightest key range to scan, because the keys sharing a prefix are next to each other in the tree.
+// (前缀扫描计划)
+//
+//	layout, _ := NewKeyLayout(16, 16, 31) // tenant, table, row
+//	plan, _ := layout.Plan([]int64{tenant, table}, WithNextRange(100, 200))
+//	for key, value := range tree.Scan(plan) {
+//		...
+//	}
+func (layout KeyLayout) Plan(prefix []int64, opts ...PlanOption) (ScanPlan, error) {
+	plan := ScanPlan{Layout: layout, Prefix: append([]int64(nil), prefix...)}
+	for _, opt := range opts {
+		opt(&plan)
+	}
+
+	// The prefix must fit the layout, and a range needs a component after it.
+	if len(layout) == 0 {
+		return ScanPlan{}, fmt.Errorf("a query on no components %w", ErrKeyLayout)
+	}
+	if _, err := layout.Key(prefix...); err != nil {
+		return ScanPlan{}, err
+	}
+	next := len(prefix)
+	if plan.Ranged && next == len(layout) {
+		return ScanPlan{}, fmt.Errorf("a range after %d components %w of %d components", next, ErrKeyLayout, len(layout))
+	}
+
+	// Without a range, the whole next component is taken.
+	if !plan.Ranged && next < len(layout) {
+		plan.From, plan.To = 0, layout.limit(next)
+	}
+	if next < len(layout) {
+		plan.From = max(plan.From, 0)
+		plan.To = min(plan.To, layout.limit(next))
+		if plan.From >= plan.To {
+			plan.Empty = true
+			return plan, nil
+		}
+	}
+
+	// The low bound fills the rest with zeros, and the high bound with ones, so no overflow is possible.
+	plan.Low, _ = layout.Key(prefix...)
+	plan.High = plan.Low
+	if next < len(layout) {
+		suffixBits := layout.shift(next)
+		plan.Low |= plan.From << suffixBits
+		plan.High |= (plan.To-1)<<suffixBits | (int64(1)<<suffixBits - 1)
+	}
+
+	return plan, nil
+}
+
+// Explain describes the bounds chosen by the plan, such as for a log or a slow query report.
+func (plan ScanPlan) Explain() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "prefix scan on the key layout %v bits\n", []uint(plan.Layout))
+
+	var equal []string
+	for i, value := range plan.Prefix {
+		equal = append(equal, fmt.Sprintf("c%d = %d", i, value))
+	}
+	if len(equal) == 0 {
+		equal = append(equal, "none, the scan starts at the first component")
+	}
+	fmt.Fprintf(&sb, "  equal: %s\n", strings.Join(equal, ", "))
+
+	next := len(plan.Prefix)
+	switch {
+	case next == len(plan.Layout):
+		sb.WriteString("  range: none, the whole key is fixed\n")
+	case plan.Ranged:
+		fmt.Fprintf(&sb, "  range: c%d in [%d, %d)\n", next, plan.From, plan.To)
+	default:
+		fmt.Fprintf(&sb, "  range: c%d is any value\n", next)
+	}
+
+	if plan.Empty {
+		sb.WriteString("  keys:  none, the range is empty and nothing is scanned")
+		return sb.String()
+	}
+	fmt.Fprintf(&sb, "  keys:  [%#x, %#x], %d keys at most", plan.Low, plan.High, uint64(plan.High-plan.Low)+1)
+	return sb.String()
+}
+
+// Scan returns a snapshot of the items in the key range of the plan in ascending key order, for use with range-over-func.
+// Only the index nodes and the data nodes overlapping the range are visited, instead of every data node from the left.
+func (tree *BpTree) Scan(plan ScanPlan) iter.Seq2[int64, interface{}] {
+	if plan.Empty {
+		return Seq(NewSliceIterator(nil))
+	}
+
+	// Acquire a lock to ensure thread safety.
+	tree.mutex.Lock()
+
+	// Release the lock to allow other threads to access the tree.
+	defer tree.mutex.Unlock()
+
+	var items []BpItem
+	tree.root.collectRange(plan.Low, plan.High, &items)
+	return Seq(NewSliceIterator(items))
+}
+
+// collectRange collects the items with keys in [low, high] under the index node, skipping the masked ones.
+// It only visits the children which may contain the range, like containsRange.
+func (inode *BpIndex) collectRange(low, high int64, items *[]BpItem) {
+	// Find the children covering the range, starting one child earlier for the keys equal to the index.
+	first := sort.Search(len(inode.Index), func(i int) bool {
+		return inode.Index[i] >= low
+	})
+	last := sort.Search(len(inode.Index), func(i int) bool {
+		return inode.Index[i] > high
+	})
+
+	// Go down the index nodes. 🔁
+	if len(inode.IndexNodes) > 0 {
+		for i := first; i <= last && i < len(inode.IndexNodes); i++ {
+			inode.IndexNodes[i].collectRange(low, high, items)
+		}
+		return
+	}
+
+	// Collect from the data nodes at the bottom level.
+	for i := first; i <= last && i < len(inode.DataNodes); i++ {
+		for _, item := range inode.DataNodes[i].Items {
+			if item.Key >= low && item.Key <= high && !item.Mask {
+				*items = append(*items, item)
+			}
+		}
+	}
+}
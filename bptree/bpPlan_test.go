@@ -0,0 +1,92 @@
+package bpTree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Check_BpTree_Plan 🧫 checks that the planned scans return exactly the keys matching the query.
+func Test_Check_BpTree_Plan(t *testing.T) {
+	// tenant (4 bits), table (4 bits), row (8 bits)
+	layout, err := NewKeyLayout(4, 4, 8)
+	require.NoError(t, err)
+
+	tree := NewBpTree(4)
+	for tenant := int64(0); tenant < 16; tenant += 3 {
+		for table := int64(0); table < 4; table++ {
+			for row := int64(0); row < 256; row += 5 {
+				key, err := layout.Key(tenant, table, row)
+				require.NoError(t, err)
+				require.NoError(t, tree.InsertValue(BpItem{Key: key, Val: row}))
+			}
+		}
+	}
+
+	// scan returns the components of the scanned keys.
+	scan := func(plan ScanPlan) (found [][]int64) {
+		for key := range tree.Scan(plan) {
+			found = append(found, layout.Split(key))
+		}
+		return
+	}
+
+	t.Run("A prefix with a range on the next component", func(t *testing.T) {
+		plan, err := layout.Plan([]int64{3, 2}, WithNextRange(10, 30))
+		require.NoError(t, err)
+		require.Equal(t, [][]int64{{3, 2, 10}, {3, 2, 15}, {3, 2, 20}, {3, 2, 25}}, scan(plan))
+		require.Equal(t, "prefix scan on the key layout [4 4 8] bits\n"+
+			"  equal: c0 = 3, c1 = 2\n"+
+			"  range: c2 in [10, 30)\n"+
+			"  keys:  [0x320a, 0x321d], 20 keys at most", plan.Explain())
+	})
+
+	t.Run("A prefix alone takes everything under it", func(t *testing.T) {
+		plan, err := layout.Plan([]int64{6})
+		require.NoError(t, err)
+		found := scan(plan)
+		require.Len(t, found, 4*52)
+		for _, components := range found {
+			require.Equal(t, int64(6), components[0])
+		}
+
+		// The range clamps into the component, and the last tenant ends at the largest key.
+		plan, err = layout.Plan([]int64{15}, WithNextRange(-5, 100))
+		require.NoError(t, err)
+		require.Equal(t, int64(0xf000), plan.Low)
+		require.Equal(t, int64(0xffff), plan.High)
+		require.Len(t, scan(plan), 4*52)
+	})
+
+	t.Run("A whole key and a range on the first component", func(t *testing.T) {
+		plan, err := layout.Plan([]int64{9, 1, 5})
+		require.NoError(t, err)
+		require.Equal(t, [][]int64{{9, 1, 5}}, scan(plan))
+		require.Contains(t, plan.Explain(), "the whole key is fixed")
+
+		plan, err = layout.Plan(nil, WithNextRange(1, 4))
+		require.NoError(t, err)
+		require.Len(t, scan(plan), 4*52)
+	})
+
+	t.Run("An empty range scans nothing", func(t *testing.T) {
+		plan, err := layout.Plan([]int64{3}, WithNextRange(2, 2))
+		require.NoError(t, err)
+		require.True(t, plan.Empty)
+		require.Empty(t, scan(plan))
+		require.Contains(t, plan.Explain(), "nothing is scanned")
+	})
+
+	t.Run("The queries not fitting the layout are rejected", func(t *testing.T) {
+		_, err := layout.Plan([]int64{16})
+		require.ErrorIs(t, err, ErrKeyLayout)
+		_, err = layout.Plan([]int64{1, 2, 3}, WithNextRange(0, 1))
+		require.ErrorIs(t, err, ErrKeyLayout)
+		_, err = layout.Key(1, 2, 3, 4)
+		require.ErrorIs(t, err, ErrKeyLayout)
+		_, err = NewKeyLayout(32, 32)
+		require.ErrorIs(t, err, ErrKeyLayout)
+		_, err = NewKeyLayout(8, 0)
+		require.ErrorIs(t, err, ErrKeyLayout)
+	})
+}
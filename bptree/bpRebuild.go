@@ -0,0 +1,134 @@
+package bpTree
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ➡️ rebuild operation
+
+// ErrRebuildRunning is returned when a rebuild is started while another one of the same tree is still running.
+var ErrRebuildRunning = errors.New("a rebuild of the tree is already running")
+
+// RebuildWithWidth rebuilds the tree with the new width without stopping the writes, such as after the auto-tuner
+// recommends another width for a long-running service. (在线调整宽度)
+// The items are copied under the lock, and the new tree is built from them bottom-up without the lock,
+// while the writes go on into the old tree and are recorded. Then, under the lock, the recorded writes are replayed
+// onto the new tree and it replaces the old one at once, so the writes only wait for the replay.
+// The deleted items which are still masked in the data nodes are left out of the new tree.
+// Only this tree changes its width, the other trees keep theirs.
+func (tree *BpTree) RebuildWithWidth(newWidth int) error {
+	if newWidth < 3 { // The minimum width for B plus tree is 3.
+		return fmt.Errorf("the width of a B plus tree must be at least 3, not %d", newWidth)
+	}
+
+	// Copy the items, and start recording the writes.
+	tree.mutex.Lock()
+	if tree.rebuildLog != nil {
+		tree.mutex.Unlock()
+		return ErrRebuildRunning
+	}
+	var items []BpItem
+	for _, data := range tree.root.dataNodes() {
+		for _, item := range data.Items {
+			if !item.Mask {
+				items = append(items, item)
+			}
+		}
+	}
+	tree.rebuildLog = &[]txOp{}
+	tree.mutex.Unlock()
+
+	// Build the new tree while the old one keeps taking the writes.
+	root := buildBottomUp(items, newWidth)
+
+	// Replay the recorded writes with the new width, and swap the roots.
+	tree.mutex.Lock()
+	defer tree.mutex.Unlock()
+	shadow := &BpTree{root: root, width: newWidth, halfWidth: halfWidthOf(newWidth)}
+	for _, op := range *tree.rebuildLog {
+		if op.kind == txInsert {
+			shadow.insert(op.item)
+		} else {
+			_, _, _, _ = shadow.remove(op.item)
+		}
+	}
+	tree.root, tree.width, tree.halfWidth = shadow.root, shadow.width, shadow.halfWidth
+	tree.rebuildLog = nil
+
+	return nil
+}
+
+// recordRebuild records a write while a rebuild is running, the lock must be held by the caller.
+func (tree *BpTree) recordRebuild(kind int, item BpItem) {
+	if tree.rebuildLog != nil {
+		*tree.rebuildLog = append(*tree.rebuildLog, txOp{kind: kind, item: item})
+	}
+}
+
+// buildBottomUp builds the nodes of a tree with the width from the items sorted by key, and returns the root.
+// The data nodes are filled evenly up to width-1 items and linked, and every level of index nodes is filled
+// evenly up to width children, until a single index node is left as the root.
+func buildBottomUp(items []BpItem, width int) *BpIndex {
+	// Fill the data nodes and link them from left to right.
+	leaves := make([]*BpData, 0, len(items)/(width-1)+1)
+	for _, part := range evenParts(len(items), width-1) {
+		data := &BpData{Items: append(make([]BpItem, 0, width), items[part[0]:part[1]]...)}
+		if len(leaves) > 0 {
+			data.Previous = leaves[len(leaves)-1]
+			data.Previous.Next = data
+		}
+		leaves = append(leaves, data)
+	}
+	if len(leaves) == 0 {
+		leaves = append(leaves, &BpData{})
+	}
+
+	// The index nodes right above the data nodes.
+	level := make([]*BpIndex, 0, len(leaves)/width+1)
+	for _, part := range evenParts(len(leaves), width) {
+		inode := &BpIndex{DataNodes: append(make([]*BpData, 0, width+1), leaves[part[0]:part[1]]...)}
+		for _, data := range inode.DataNodes[1:] {
+			inode.Index = append(inode.Index, data.Items[0].Key)
+		}
+		level = append(level, inode)
+	}
+
+	// The upper index nodes, each key is the smallest key under its right child.
+	for len(level) > 1 {
+		upper := make([]*BpIndex, 0, len(level)/width+1)
+		for _, part := range evenParts(len(level), width) {
+			inode := &BpIndex{IndexNodes: append(make([]*BpIndex, 0, width+1), level[part[0]:part[1]]...)}
+			for _, child := range inode.IndexNodes[1:] {
+				inode.Index = append(inode.Index, child.smallestKey())
+			}
+			upper = append(upper, inode)
+		}
+		level = upper
+	}
+
+	return level[0]
+}
+
+// evenParts splits n elements into the fewest parts of at most size elements, as evenly as possible,
+// and returns the start and the end of every part.
+func evenParts(n, size int) (parts [][2]int) {
+	count := (n + size - 1) / size
+	for i, start := 0, 0; i < count; i++ {
+		end := start + n/count
+		if i < n%count {
+			end++
+		}
+		parts = append(parts, [2]int{start, end})
+		start = end
+	}
+	return
+}
+
+// smallestKey returns the key of the leftmost item under the index node.
+func (inode *BpIndex) smallestKey() int64 {
+	for len(inode.IndexNodes) > 0 {
+		inode = inode.IndexNodes[0]
+	}
+	return inode.DataNodes[0].Items[0].Key
+}
@@ -0,0 +1,154 @@
+package bpTree
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// rebuildKeys 🧫 returns the keys of the tree, checking that they are in order.
+func rebuildKeys(t *testing.T, tree *BpTree) (keys []int64) {
+	for it := tree.Iterator(); it.Next(); {
+		keys = append(keys, it.Key())
+	}
+	require.IsNonDecreasing(t, keys)
+	return
+}
+
+// Test_Check_BpTree_RebuildWithWidth 🧫 checks that a rebuilt tree keeps its items and takes inserts and deletes.
+func Test_Check_BpTree_RebuildWithWidth(t *testing.T) {
+	random := rand.New(rand.NewSource(2044))
+
+	for _, width := range []int{3, 4, 5, 6, 7, 8, 12} {
+		for _, size := range []int{0, 1, 2, 5, 37, 500} {
+			// Build a tree of another width in random order, with a deleted item.
+			tree := NewBpTree(5)
+			for _, key := range random.Perm(size) {
+				require.NoError(t, tree.InsertValue(BpItem{Key: int64(key), Val: key}))
+			}
+			if size > 0 {
+				_, _, _, err := tree.RemoveValue(BpItem{Key: int64(size / 2)})
+				require.NoError(t, err)
+			}
+			before := rebuildKeys(t, tree)
+
+			require.NoError(t, tree.RebuildWithWidth(width))
+			require.Equal(t, width, tree.Width())
+			require.Equal(t, before, rebuildKeys(t, tree), "width %d, size %d", width, size)
+
+			// Every item is found, removed one by one in random order, and the tree still takes new items.
+			for _, key := range before {
+				item, found := tree.Get(key)
+				require.True(t, found, "width %d, size %d, key %d", width, size, key)
+				require.Equal(t, int(key), item.Val)
+			}
+			for i, j := range random.Perm(len(before)) {
+				deleted, _, _, err := tree.RemoveValue(BpItem{Key: before[j]})
+				require.NoError(t, err)
+				require.True(t, deleted, "width %d, size %d, key %d", width, size, before[j])
+				require.Len(t, rebuildKeys(t, tree), len(before)-i-1)
+			}
+			for _, key := range random.Perm(50) {
+				require.NoError(t, tree.InsertValue(BpItem{Key: int64(key)}))
+			}
+			require.Len(t, rebuildKeys(t, tree), 50)
+		}
+	}
+
+	require.Error(t, NewBpTree(4).RebuildWithWidth(2))
+}
+
+// Test_Check_BpTree_RebuildWithWidth_Writes 🧫 checks that the writes made during a rebuild are kept.
+func Test_Check_BpTree_RebuildWithWidth_Writes(t *testing.T) {
+	tree := NewBpTree(4)
+	for key := int64(0); key < 50000; key += 2 {
+		require.NoError(t, tree.InsertValue(BpItem{Key: key}))
+	}
+
+	// Replace every even key with the odd key above it while the tree is rebuilt.
+	done := make(chan struct{})
+	var writer sync.WaitGroup
+	writer.Add(1)
+	var written []int64
+	var writeErr error
+	go func() {
+		defer writer.Done()
+		for key := int64(1); ; key += 2 {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if key < 50000 {
+				if writeErr = tree.InsertValue(BpItem{Key: key}); writeErr == nil {
+					_, _, _, writeErr = tree.RemoveValue(BpItem{Key: key - 1})
+				}
+				if writeErr != nil {
+					return
+				}
+				written = append(written, key)
+			}
+		}
+	}()
+	require.NoError(t, tree.RebuildWithWidth(7))
+	close(done)
+	writer.Wait()
+	require.NoError(t, writeErr)
+
+	// The even keys below the last write are replaced by the odd ones, the rest is untouched.
+	last := int64(-1)
+	if len(written) > 0 {
+		last = written[len(written)-1]
+	}
+	var expected []int64
+	for key := int64(0); key < 50000; key++ {
+		if (key%2 == 1 && key <= last) || (key%2 == 0 && key > last) {
+			expected = append(expected, key)
+		}
+	}
+	require.Equal(t, expected, rebuildKeys(t, tree))
+	require.Equal(t, 7, tree.width)
+}
+
+// Test_Check_BpTree_Width_PerTree 🧫 checks that trees of different widths keep their own, and a rebuild changes only its tree.
+func Test_Check_BpTree_Width_PerTree(t *testing.T) {
+	narrow, wide := NewBpTree(3), NewBpTree(64)
+	for key := int64(0); key < 1000; key++ {
+		require.NoError(t, narrow.InsertValue(BpItem{Key: key}))
+		require.NoError(t, wide.InsertValue(BpItem{Key: key}))
+	}
+
+	// The narrow tree created first is not widened by the wide one.
+	require.Greater(t, narrow.Height(), wide.Height())
+	require.Equal(t, 3, narrow.width)
+	require.Equal(t, 64, wide.width)
+
+	// Rebuilding the narrow tree leaves the wide one alone.
+	height := wide.Height()
+	require.NoError(t, narrow.RebuildWithWidth(5))
+	require.Equal(t, 5, narrow.width)
+	require.Equal(t, 64, wide.width)
+	for key := int64(1000); key < 2000; key++ {
+		require.NoError(t, narrow.InsertValue(BpItem{Key: key}))
+	}
+	for key := int64(0); key < 2000; key++ {
+		_, found := narrow.Get(key)
+		require.True(t, found, "key %d", key)
+	}
+	require.Equal(t, height, wide.Height())
+
+	// Trees created at the same time take nothing from each other.
+	var wg sync.WaitGroup
+	for width := 3; width < 40; width++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tree := NewBpTree(width)
+			require.Equal(t, width, tree.Width())
+			require.Equal(t, halfWidthOf(width), tree.halfWidth)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,174 @@
+package bpTree
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// scheduleOp 🧫 is one operation run by a goroutine of the interleaving-order test.
+type scheduleOp struct {
+	kind string // "insert", "remove" or "get".
+	key  int64  // The key of the operation.
+}
+
+// interleavings 🧫 lists every order of the goroutines' operations, each goroutine keeps its own order.
+// counts[g] is the number of operations of goroutine g, and every schedule is a list of goroutine numbers.
+func interleavings(counts []int) (schedules [][]int) {
+	remain := append([]int(nil), counts...)
+	var walk func(prefix []int)
+	walk = func(prefix []int) {
+		done := true
+		for g := range remain {
+			if remain[g] == 0 {
+				continue
+			}
+			done = false
+			remain[g]--
+			walk(append(prefix, g))
+			remain[g]++
+		}
+		if done {
+			schedules = append(schedules, append([]int(nil), prefix...))
+		}
+	}
+	walk(nil)
+	return
+}
+
+// runInterleavings 🧫 runs the goroutines' operations in every interleaving order, instead of relying on chance.
+// Each goroutine waits for its turn between operations and the next turn is handed out only after the operation returns,
+// so two operations are never in flight together. This checks that every order of whole operations leaves the tree
+// consistent with a map replaying the same order; it does not look for races inside an operation, which the tree lock
+// rules out because every operation holds it from start to end.
+func runInterleavings(t *testing.T, width int, threads [][]scheduleOp) (explored int) {
+	counts := make([]int, len(threads))
+	for g, ops := range threads {
+		counts[g] = len(ops)
+	}
+
+	for _, schedule := range interleavings(counts) {
+		tree := NewBpTree(width)
+
+		// Start one goroutine for each operation list, which runs an operation only when it gets the turn.
+		turns := make([]chan struct{}, len(threads))
+		results := make([][]bool, len(threads))
+		done := make(chan struct{})
+		for g := range threads {
+			turns[g] = make(chan struct{})
+			go func(g int) {
+				for _, op := range threads[g] {
+					<-turns[g]
+					results[g] = append(results[g], runScheduleOpSafely(t, tree, op, schedule))
+					done <- struct{}{}
+				}
+			}(g)
+		}
+
+		// Hand out the turns in the order of the schedule, and replay the same order on the model.
+		model := make(map[int64]int)
+		expected := make([][]bool, len(threads))
+		next := make([]int, len(threads))
+		for _, g := range schedule {
+			turns[g] <- struct{}{}
+			<-done
+
+			op := threads[g][next[g]]
+			next[g]++
+			expected[g] = append(expected[g], runModelOp(model, op))
+		}
+
+		// Every operation and the final content must match the model.
+		require.Equal(t, expected, results, "schedule %v", schedule)
+		var keys []int64
+		for it := tree.Iterator(); it.Next(); {
+			keys = append(keys, it.Key())
+		}
+		var modelKeys []int64
+		for key, count := range model {
+			for i := 0; i < count; i++ {
+				modelKeys = append(modelKeys, key)
+			}
+		}
+		require.ElementsMatch(t, modelKeys, keys, "schedule %v", schedule)
+		require.IsNonDecreasing(t, keys, "schedule %v", schedule)
+
+		explored++
+	}
+
+	return
+}
+
+// runScheduleOp 🧫 runs one operation on the tree and returns whether it found or deleted the key.
+func runScheduleOp(tree *BpTree, op scheduleOp) bool {
+	switch op.kind {
+	case "insert":
+		return tree.InsertValue(BpItem{Key: op.key}) == nil
+	case "remove":
+		deleted, _, _, err := tree.RemoveValue(BpItem{Key: op.key})
+		return deleted && err == nil
+	case "get":
+		_, found := tree.Get(op.key)
+		return found
+	}
+	panic(fmt.Sprintf("unknown operation %q", op.kind))
+}
+
+// runScheduleOpSafely 🧫 runs one operation and reports a panic together with the schedule, so it can be reproduced.
+func runScheduleOpSafely(t *testing.T, tree *BpTree, op scheduleOp, schedule []int) (result bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf("schedule %v: %s %d panics: %v", schedule, op.kind, op.key, r)
+		}
+	}()
+	return runScheduleOp(tree, op)
+}
+
+// runModelOp 🧫 runs one operation on the model, which counts the items of every key.
+func runModelOp(model map[int64]int, op scheduleOp) bool {
+	switch op.kind {
+	case "insert":
+		model[op.key]++
+		return true
+	case "remove":
+		if model[op.key] == 0 {
+			return false
+		}
+		model[op.key]--
+		if model[op.key] == 0 {
+			delete(model, op.key)
+		}
+		return true
+	case "get":
+		return model[op.key] > 0
+	}
+	panic(fmt.Sprintf("unknown operation %q", op.kind))
+}
+
+// Test_Check_BpTree_Interleavings 🧫 runs every interleaving order of small operation sets from several goroutines.
+func Test_Check_BpTree_Interleavings(t *testing.T) {
+	t.Run("Interleavings", func(t *testing.T) {
+		// 2 goroutines with 2 operations each have 4!/(2!*2!) = 6 schedules.
+		require.Len(t, interleavings([]int{2, 2}), 6)
+		require.Len(t, interleavings([]int{3, 3, 3}), 1680)
+	})
+
+	t.Run("Insert and remove the same keys", func(t *testing.T) {
+		explored := runInterleavings(t, 3, [][]scheduleOp{
+			{{"insert", 5}, {"insert", 6}, {"remove", 5}},
+			{{"insert", 5}, {"get", 5}, {"remove", 6}},
+			{{"remove", 5}, {"insert", 4}, {"get", 6}},
+		})
+		require.Equal(t, 1680, explored)
+	})
+
+	t.Run("Splits and merges in a narrow tree", func(t *testing.T) {
+		// Width 3 splits after a few inserts, so the schedules cross the split and merge paths.
+		explored := runInterleavings(t, 3, [][]scheduleOp{
+			{{"insert", 1}, {"insert", 2}, {"insert", 3}, {"remove", 2}},
+			{{"insert", 4}, {"insert", 5}, {"remove", 1}, {"remove", 4}},
+		})
+		require.Equal(t, 70, explored)
+	})
+}
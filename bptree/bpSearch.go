@@ -0,0 +1,215 @@
+package bpTree
+
+import (
+	"slices"
+	"sort"
+)
+
+// searchKey pairs a key with its position in the input keys, so the results can be written back after sorting.
+type searchKey struct {
+	key      int64 // The key to search for.
+	position int   // The position of the key in the input keys.
+}
+
+// ➡️ search operation
+
+// Get ensures thread safety, searches for the item with the given key in B plus tree index, release lock.
+func (tree *BpTree) Get(key int64) (item BpItem, found bool) {
+	// Acquire a lock to ensure thread safety.
+	tree.mutex.Lock()
+
+	// Release the lock to allow other threads to access the tree.
+	defer tree.mutex.Unlock()
+
+	// Descend from the root to the data node and search there.
+	item, found = tree.root.search(key)
+
+	// Performing a return.
+	return
+}
+
+// GetMany ensures thread safety, searches for many keys in a single coordinated pass, release lock.
+// The results are aligned with the input keys, so items[i] and found[i] belong to keys[i].
+// The keys are sorted first, so every index node on the way down is visited at most once,
+// instead of being visited again for every single key. (所有键值一起往下走，上层节点共用)
+func (tree *BpTree) GetMany(keys []int64) (items []BpItem, found []bool) {
+	// Prepare the output slices aligned with the input keys.
+	items = make([]BpItem, len(keys))
+	found = make([]bool, len(keys))
+
+	// Nothing to search for.
+	if len(keys) == 0 {
+		return
+	}
+
+	// Sort a copy of the keys together with their positions, so the input slice is left untouched.
+	sorted := make([]searchKey, len(keys))
+	for i, key := range keys {
+		sorted[i] = searchKey{key: key, position: i}
+	}
+	slices.SortFunc(sorted, func(a, b searchKey) int {
+		switch {
+		case a.key < b.key:
+			return -1
+		case a.key > b.key:
+			return 1
+		}
+		return 0
+	})
+
+	// Acquire a lock to ensure thread safety.
+	tree.mutex.Lock()
+
+	// Release the lock to allow other threads to access the tree.
+	defer tree.mutex.Unlock()
+
+	// Descend from the root with all keys at once.
+	tree.root.searchMany(sorted, items, found)
+
+	// Performing a return.
+	return
+}
+
+// search descends from the index node to the data node where the key is supposed to be, and then searches it.
+// The direction is the same as the insertion, Index[i] > key, so the data on the far right is found. (和新增的方向一致)
+func (inode *BpIndex) search(key int64) (item BpItem, found bool) {
+	current := inode
+
+	// Go down the index nodes until the bottom level is reached.
+	for len(current.IndexNodes) > 0 {
+		ix := sort.Search(len(current.Index), func(i int) bool {
+			return current.Index[i] > key // No equal sign ‼️
+		})
+
+		// Preventive check, the index may not be updated on time.
+		if ix > len(current.IndexNodes)-1 {
+			ix = len(current.IndexNodes) - 1
+		}
+
+		current = current.IndexNodes[ix]
+	}
+
+	// There is no data node at all.
+	if len(current.DataNodes) == 0 {
+		return
+	}
+
+	// Find the data node at the bottom level.
+	ix := sort.Search(len(current.Index), func(i int) bool {
+		return current.Index[i] > key // No equal sign ‼️
+	})
+
+	// Preventive check, the index may not be updated on time.
+	if ix > len(current.DataNodes)-1 {
+		ix = len(current.DataNodes) - 1
+	}
+
+	// Search the data node.
+	return current.DataNodes[ix].search(key)
+}
+
+// searchMany distributes the sorted keys to the child nodes, each child node receives a continuous part of them.
+// The results are written back by the positions kept in the sorted keys.
+func (inode *BpIndex) searchMany(sorted []searchKey, items []BpItem, found []bool) {
+	// Decide whether the children are index nodes or data nodes.
+	children := len(inode.IndexNodes)
+	if children == 0 {
+		children = len(inode.DataNodes)
+	}
+
+	// There is no child node at all.
+	if children == 0 {
+		return
+	}
+
+	// Walk through the children from left to right, and the sorted keys follow along.
+	start := 0
+	for child := 0; child < children && start < len(sorted); child++ {
+		// The keys smaller than the next index value belong to this child; the last child takes the rest.
+		end := len(sorted)
+		if child < len(inode.Index) && child < children-1 {
+			end = start
+			for end < len(sorted) && sorted[end].key < inode.Index[child] { // Index[i] > key means the key stays on the left.
+				end++
+			}
+		}
+
+		// Skip the child if no key belongs to it.
+		if end == start {
+			continue
+		}
+
+		// Entering the Recursive Function. 🔁
+		if len(inode.IndexNodes) > 0 {
+			inode.IndexNodes[child].searchMany(sorted[start:end], items, found)
+		} else {
+			inode.DataNodes[child].searchMany(sorted[start:end], items, found)
+		}
+
+		start = end
+	}
+}
+
+// search uses binary search to find the item with the given key in the data node.
+func (data *BpData) search(key int64) (item BpItem, found bool) {
+	// Use binary search to find the index where the key should be.
+	ix := sort.Search(len(data.Items), func(i int) bool {
+		return data.Items[i].Key >= key
+	})
+
+	// If the item is found in the current node, return it.
+	if ix < len(data.Items) && data.Items[ix].Key == key {
+		item = data.Items[ix]
+		found = true
+	}
+
+	return
+}
+
+// searchMany merges the sorted keys with the sorted items of the data node in a single pass.
+func (data *BpData) searchMany(sorted []searchKey, items []BpItem, found []bool) {
+	ix := 0
+	for _, each := range sorted {
+		// Move forward until the item is not smaller than the key.
+		for ix < len(data.Items) && data.Items[ix].Key < each.key {
+			ix++
+		}
+
+		// If the item is found, write it back by position.
+		if ix < len(data.Items) && data.Items[ix].Key == each.key {
+			items[each.position] = data.Items[ix]
+			found[each.position] = true
+		}
+	}
+}
+
+// Height ensures thread safety, returns the number of index levels from the root to the data nodes, release lock.
+// A tree whose root holds the data nodes directly has a height of 1.
+func (tree *BpTree) Height() (height int) {
+	// Acquire a lock to ensure thread safety.
+	tree.mutex.Lock()
+
+	// Release the lock to allow other threads to access the tree.
+	defer tree.mutex.Unlock()
+
+	// Every path from the root to the data nodes has the same length, so the leftmost path is followed.
+	height = 1
+	for inode := tree.root; len(inode.IndexNodes) > 0; inode = inode.IndexNodes[0] {
+		height++
+	}
+
+	// Performing a return.
+	return
+}
+
+// Width ensures thread safety, returns the width of the tree, release lock.
+// Every tree keeps its own width, which only RebuildWithWidth changes.
+func (tree *BpTree) Width() int {
+	// Acquire a lock to ensure thread safety.
+	tree.mutex.Lock()
+
+	// Release the lock to allow other threads to access the tree.
+	defer tree.mutex.Unlock()
+
+	return tree.width
+}
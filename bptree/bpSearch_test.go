@@ -0,0 +1,161 @@
+package bpTree
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/panhongrainbow/go-algorithm/utilhub"
+	"github.com/stretchr/testify/require"
+)
+
+// prepareSearchTree 🧫 builds a B plus tree with unique random keys and removes about half of them.
+// It returns the tree and the keys which are still inside the tree.
+func prepareSearchTree(t testing.TB, width int, count int, rng *rand.Rand) (tree *BpTree, remain map[int64]struct{}) {
+	// Create a new B plus tree and a pool for checking.
+	tree = NewBpTree(width)
+	remain = make(map[int64]struct{}, count)
+
+	// Insert unique random keys.
+	keys := make([]int64, 0, count)
+	for len(keys) < count {
+		key := rng.Int63n(int64(count)*10) + 1
+		if _, exists := remain[key]; exists {
+			continue
+		}
+		remain[key] = struct{}{}
+		keys = append(keys, key)
+		tree.InsertValue(BpItem{Key: key})
+	}
+
+	// Remove about half of the keys.
+	shuffleSlice(keys, rng)
+	for i := 0; i < len(keys)/2; i++ {
+		deleted, _, _, err := tree.RemoveValue(BpItem{Key: keys[i]})
+		require.True(t, deleted)
+		require.NoError(t, err)
+		delete(remain, keys[i])
+	}
+
+	return
+}
+
+// Test_Check_BpTree_Search 🧫 checks Get and GetMany against a map after random insertion and deletion.
+// Both query modes read the same shared tree of each width.
+func Test_Check_BpTree_Search(t *testing.T) {
+	for _, width := range []int{3, 4, 5, 6, 7, 8} {
+		fixture := sharedSearchTree(t, int64(width), fixtureConfig{Width: width, Count: 2000})
+
+		// Collect keys to search, including the keys that were never inserted or have been removed.
+		keys := make([]int64, 0, 4000)
+		for i := int64(0); i <= 20000; i += 5 {
+			keys = append(keys, i)
+		}
+		shuffleSlice(keys, rand.New(rand.NewSource(int64(width))))
+
+		// Search one by one.
+		t.Run(fmt.Sprintf("Get width %d", width), func(t *testing.T) {
+			for _, key := range keys {
+				item, found := fixture.Tree.Get(key)
+				_, expected := fixture.Remain[key]
+				require.Equal(t, expected, found, "key %d", key)
+				if found {
+					require.Equal(t, key, item.Key)
+				}
+			}
+		})
+
+		// Search all at once, and the results must be aligned with the input keys.
+		t.Run(fmt.Sprintf("GetMany width %d", width), func(t *testing.T) {
+			items, found := fixture.Tree.GetMany(keys)
+			require.Len(t, items, len(keys))
+			for i, key := range keys {
+				_, expected := fixture.Remain[key]
+				require.Equal(t, expected, found[i], "key %d", key)
+				if found[i] {
+					require.Equal(t, key, items[i].Key)
+				}
+			}
+		})
+	}
+
+	t.Run("Empty tree", func(t *testing.T) {
+		tree := NewBpTree(4)
+		_, found := tree.Get(10)
+		require.False(t, found)
+
+		items, founds := tree.GetMany([]int64{10, 20})
+		require.Equal(t, []bool{false, false}, founds)
+		require.Len(t, items, 2)
+	})
+
+	t.Run("Height", func(t *testing.T) {
+		// The root holds the data nodes until the first split, and every split of the root adds one level.
+		tree := NewBpTree(3)
+		require.Equal(t, 1, tree.Height())
+		last := 1
+		for key := int64(1); key <= 200; key++ {
+			require.NoError(t, tree.InsertValue(BpItem{Key: key}))
+			height := tree.Height()
+			require.GreaterOrEqual(t, height, last)
+			require.LessOrEqual(t, height, last+1)
+			last = height
+		}
+		require.Greater(t, last, 2)
+	})
+}
+
+// Benchmark_BpTree_Search 🧫 compares GetMany with a naive loop of Get.
+func Benchmark_BpTree_Search(b *testing.B) {
+	tree := sharedSearchTree(b, 1, fixtureConfig{Width: 32, Count: 200000}).Tree
+	rng := rand.New(rand.NewSource(1))
+
+	// Prepare the keys to search.
+	keys := make([]int64, 10000)
+	for i := range keys {
+		keys[i] = rng.Int63n(2000000) + 1
+	}
+
+	b.Run("Get loop", func(b *testing.B) {
+		withPerfCounters(b, func() {
+			for i := 0; i < b.N; i++ {
+				for _, key := range keys {
+					tree.Get(key)
+				}
+			}
+		})
+	})
+
+	b.Run("GetMany", func(b *testing.B) {
+		withPerfCounters(b, func() {
+			for i := 0; i < b.N; i++ {
+				tree.GetMany(keys)
+			}
+		})
+	})
+}
+
+// withPerfCounters 🧫 wraps the benchmark loop with the hardware counters when algobench turns them on,
+// and reports them per operation next to ns/op.
+func withPerfCounters(b *testing.B, run func()) {
+	if !utilhub.PerfEnabled() {
+		run()
+		return
+	}
+
+	// Run without the counters when the system does not support them.
+	counters, err := utilhub.StartPerfCounters()
+	if err != nil {
+		b.Logf("perf counters are skipped: %v", err)
+		run()
+		return
+	}
+
+	run()
+	result, err := counters.Stop()
+	if err != nil {
+		b.Logf("perf counters are skipped: %v", err)
+		return
+	}
+	result.ReportPerOp(b, b.N)
+}
@@ -0,0 +1,196 @@
+package bpTree
+
+import (
+	"fmt"
+	"iter"
+)
+
+// ➡️ set operation
+
+// SetKey is the key type of a Set and a MultiMap, every integer type which fits into the int64 key of the tree in the same order.
+// The keys are shifted into the non-negative keys of the tree, so the negative ones keep their order too,
+// but an int or an int64 key must be from -2^62 to 2^62-1.
+type SetKey interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint8 | ~uint16 | ~uint32
+}
+
+// setKeyShift shifts the keys of a Set and a MultiMap into the non-negative keys of the tree.
+const setKeyShift = 1 << 62
+
+// treeKey returns the key of the tree for the key of a Set or a MultiMap, and reports whether the key can be held at all.
+func treeKey[K SetKey](key K) (int64, bool) {
+	k := int64(key)
+	if k < -setKeyShift || k >= setKeyShift {
+		return 0, false
+	}
+	return k + setKeyShift, true
+}
+
+// mustTreeKey returns the key of the tree for a key being added, and panics when the key can not be held.
+func mustTreeKey[K SetKey](key K) int64 {
+	k, ok := treeKey(key)
+	if !ok {
+		panic(fmt.Sprintf("bpTree: the key %d is out of the range of a Set or a MultiMap, from -2^62 to 2^62-1", int64(key)))
+	}
+	return k
+}
+
+// fromTreeKey returns the key of a Set or a MultiMap for the key of the tree.
+func fromTreeKey[K SetKey](key int64) K {
+	return K(key - setKeyShift)
+}
+
+// Set is an ordered set backed by a B plus tree, for ordered set semantics without the index-level API. (有序集合)
+// Union, Intersect and Difference merge two sorted snapshots in one pass, and build the result bottom-up.
+type Set[K SetKey] struct {
+	tree   *BpTree // The tree holding the keys, without values.
+	length int     // The number of keys, guarded by the lock of the tree.
+}
+
+// NewSet creates an empty set backed by a B plus tree with the given width.
+func NewSet[K SetKey](width int, keys ...K) *Set[K] {
+	set := &Set[K]{tree: NewBpTree(width)}
+	for _, key := range keys {
+		set.Add(key)
+	}
+	return set
+}
+
+// Add inserts the key, and reports whether it was not in the set yet.
+// It panics when an int or an int64 key is out of the range a Set can hold.
+func (set *Set[K]) Add(key K) bool {
+	k := mustTreeKey(key)
+
+	// Acquire a lock to ensure thread safety.
+	set.tree.mutex.Lock()
+
+	// Release the lock to allow other threads to access the tree.
+	defer set.tree.mutex.Unlock()
+
+	if _, found := set.tree.root.search(k); found {
+		return false
+	}
+	set.tree.insert(BpItem{Key: k})
+	set.length++
+	return true
+}
+
+// Remove deletes the key, and reports whether it was in the set.
+func (set *Set[K]) Remove(key K) bool {
+	k, ok := treeKey(key)
+	if !ok {
+		return false
+	}
+
+	// Acquire a lock to ensure thread safety.
+	set.tree.mutex.Lock()
+
+	// Release the lock to allow other threads to access the tree.
+	defer set.tree.mutex.Unlock()
+
+	deleted, _, _, err := set.tree.remove(BpItem{Key: k})
+	if deleted && err == nil {
+		set.length--
+		return true
+	}
+	return false
+}
+
+// Contains reports whether the key is in the set.
+func (set *Set[K]) Contains(key K) bool {
+	k, ok := treeKey(key)
+	if !ok {
+		return false
+	}
+	_, found := set.tree.Get(k)
+	return found
+}
+
+// Len returns the number of keys.
+func (set *Set[K]) Len() int {
+	// Acquire a lock to ensure thread safety.
+	set.tree.mutex.Lock()
+
+	// Release the lock to allow other threads to access the tree.
+	defer set.tree.mutex.Unlock()
+
+	return set.length
+}
+
+// All returns a snapshot of the keys in ascending order, for use with range-over-func.
+func (set *Set[K]) All() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for _, key := range set.tree.keys() {
+			if !yield(fromTreeKey[K](key)) {
+				return
+			}
+		}
+	}
+}
+
+// Union returns a new set with the keys in either set.
+func (set *Set[K]) Union(other *Set[K]) *Set[K] {
+	return set.merge(other, true, true, true)
+}
+
+// Intersect returns a new set with the keys in both sets.
+func (set *Set[K]) Intersect(other *Set[K]) *Set[K] {
+	return set.merge(other, false, true, false)
+}
+
+// Difference returns a new set with the keys in this set but not in the other one.
+func (set *Set[K]) Difference(other *Set[K]) *Set[K] {
+	return set.merge(other, true, false, false)
+}
+
+// merge walks the snapshots of both sets side by side, and keeps the keys found only in this set, in both,
+// or only in the other one, as asked. The result has the width of this set.
+func (set *Set[K]) merge(other *Set[K], onlyThis, both, onlyOther bool) *Set[K] {
+	a, b := set.tree.keys(), other.tree.keys()
+
+	var items []BpItem
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case j == len(b) || (i < len(a) && a[i] < b[j]):
+			if onlyThis {
+				items = append(items, BpItem{Key: a[i]})
+			}
+			i++
+		case i == len(a) || b[j] < a[i]:
+			if onlyOther {
+				items = append(items, BpItem{Key: b[j]})
+			}
+			j++
+		default: // The same key in both sets.
+			if both {
+				items = append(items, BpItem{Key: a[i]})
+			}
+			i, j = i+1, j+1
+		}
+	}
+
+	set.tree.mutex.Lock()
+	width := set.tree.width
+	set.tree.mutex.Unlock()
+	tree := &BpTree{root: buildBottomUp(items, width), width: width, halfWidth: halfWidthOf(width)}
+	return &Set[K]{tree: tree, length: len(items)}
+}
+
+// keys copies the keys of the tree in ascending order, without the deleted ones still masked in the data nodes.
+func (tree *BpTree) keys() (keys []int64) {
+	// Acquire a lock to ensure thread safety.
+	tree.mutex.Lock()
+
+	// Release the lock to allow other threads to access the tree.
+	defer tree.mutex.Unlock()
+
+	for _, data := range tree.root.dataNodes() {
+		for _, item := range data.Items {
+			if !item.Mask {
+				keys = append(keys, item.Key)
+			}
+		}
+	}
+	return
+}
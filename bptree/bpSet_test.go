@@ -0,0 +1,121 @@
+package bpTree
+
+import (
+	"math/rand"
+	"slices"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// setModel 🧫 returns the keys of the model set in ascending order.
+func setModel(model map[int16]bool) (keys []int16) {
+	for key := range model {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return
+}
+
+// Test_Check_BpTree_Set 🧫 checks the set against a built-in map, and the set operations against each other.
+func Test_Check_BpTree_Set(t *testing.T) {
+	random := rand.New(rand.NewSource(2048))
+	a, b := NewSet[int16](4), NewSet[int16](4)
+	modelA, modelB := map[int16]bool{}, map[int16]bool{}
+
+	// Add and remove random keys, the negative ones too, and follow them in the models.
+	for i := 0; i < 3000; i++ {
+		set, model := a, modelA
+		if i%2 == 1 {
+			set, model = b, modelB
+		}
+		key := int16(random.Intn(400) - 200)
+		if random.Intn(3) == 0 {
+			require.Equal(t, model[key], set.Remove(key))
+			delete(model, key)
+		} else {
+			require.Equal(t, !model[key], set.Add(key))
+			model[key] = true
+		}
+		require.Equal(t, len(model), set.Len())
+		require.Equal(t, model[key], set.Contains(key))
+	}
+	require.Equal(t, setModel(modelA), slices.Collect(a.All()))
+
+	// The operations match the models, and the results are sets of their own.
+	union, intersect, difference := map[int16]bool{}, map[int16]bool{}, map[int16]bool{}
+	for key := range modelA {
+		union[key] = true
+		if modelB[key] {
+			intersect[key] = true
+		} else {
+			difference[key] = true
+		}
+	}
+	for key := range modelB {
+		union[key] = true
+	}
+	for _, check := range []struct {
+		set   *Set[int16]
+		model map[int16]bool
+	}{{a.Union(b), union}, {a.Intersect(b), intersect}, {a.Difference(b), difference}} {
+		require.Equal(t, setModel(check.model), slices.Collect(check.set.All()))
+		require.Equal(t, len(check.model), check.set.Len())
+		require.True(t, check.set.Add(1000))
+		require.True(t, check.set.Remove(1000))
+		for key := range check.model {
+			require.True(t, check.set.Remove(key))
+		}
+		require.Zero(t, check.set.Len())
+	}
+
+	// A set with itself, and with an empty set.
+	require.Equal(t, a.Len(), a.Union(a).Len())
+	require.Zero(t, a.Difference(a).Len())
+	require.Zero(t, a.Intersect(NewSet[int16](4)).Len())
+	require.Equal(t, []int16{1, 2, 3}, slices.Collect(NewSet[int16](4, 3, 1, 2, 3).All()))
+}
+
+// Test_Check_BpTree_Set_Keys 🧫 checks the order of the negative keys, and the range of the int64 keys.
+func Test_Check_BpTree_Set_Keys(t *testing.T) {
+	// The negative keys come before the others, in order.
+	require.Equal(t, []int8{-128, -1, 0, 1, 127}, slices.Collect(NewSet[int8](3, 1, 127, -1, 0, -128).All()))
+	require.Equal(t, []uint32{0, 1 << 31, 1<<32 - 1}, slices.Collect(NewSet[uint32](3, 1<<32-1, 0, 1<<31).All()))
+
+	// An int64 key out of the range can not be added, and is never found.
+	set := NewSet[int64](3, -1<<62, 1<<62-1)
+	require.Equal(t, []int64{-1 << 62, 1<<62 - 1}, slices.Collect(set.All()))
+	require.Panics(t, func() { set.Add(1 << 62) })
+	require.Panics(t, func() { set.Add(-1<<62 - 1) })
+	require.False(t, set.Contains(1<<62))
+	require.False(t, set.Remove(1<<62))
+	require.Equal(t, 2, set.Len())
+}
+
+// Test_Check_BpTree_Set_Concurrent 🧫 checks that every key is counted once when the same keys are added and removed concurrently.
+func Test_Check_BpTree_Set_Concurrent(t *testing.T) {
+	set := NewSet[int](4)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := -100; key < 100; key++ {
+				set.Add(key)
+				if key%3 == 0 {
+					set.Remove(key)
+				}
+				require.LessOrEqual(t, set.Len(), 200)
+			}
+		}()
+	}
+	wg.Wait()
+
+	keys := slices.Collect(set.All())
+	require.Equal(t, len(keys), set.Len())
+	for _, key := range keys {
+		require.NotZero(t, key%3)
+	}
+}
@@ -0,0 +1,275 @@
+package bpTree
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// ➡️ snapshot operation
+
+// The layout of a snapshot, all integers in little endian:
+//
+//	header: magic "BPSNAP01", codec (1 byte), width (uint32)
+//	frames: the items of some leaves, compressed on their own, every item is
+//	        key (int64), length of the value (uvarint), value (JSON)
+//	index:  one snapshotFrame for every frame, in key order
+//	footer: offset of the index (int64), number of frames (int64), magic "BPSNAP01"
+//
+// The index at the end lets a reader seek to the frames overlapping a key range and decompress only those,
+// so part of a huge archived tree is loaded without reading the rest.
+
+// snapshotMagic marks the start and the end of a snapshot.
+const snapshotMagic = "BPSNAP01"
+
+// snapshotCodecFlate is the codec of the frames, DEFLATE from the standard library.
+const snapshotCodecFlate byte = 1
+
+// snapshotHeaderSize and snapshotFooterSize are the fixed sizes around the frames.
+const (
+	snapshotHeaderSize = len(snapshotMagic) + 1 + 4
+	snapshotFooterSize = 8 + 8 + len(snapshotMagic)
+)
+
+// ErrBadSnapshot is returned when the data is not a snapshot, or it is damaged.
+var ErrBadSnapshot = errors.New("bad snapshot")
+
+// snapshotFrame is the index entry of a frame, the keys of its items are in [First, Last].
+type snapshotFrame struct {
+	Offset int64 // Where the compressed frame starts.
+	Length int64 // The length of the compressed frame.
+	First  int64 // The smallest key of the frame.
+	Last   int64 // The largest key of the frame.
+	Items  int64 // The number of items of the frame.
+}
+
+// snapshotWriter holds the settings of WriteSnapshot.
+type snapshotWriter struct {
+	frameLeaves int // The number of leaves compressed together into one frame.
+}
+
+// SnapshotOption defines a function type for configuring the snapshot.
+type SnapshotOption func(*snapshotWriter)
+
+// WithSnapshotFrameLeaves sets how many leaves are compressed together into one frame, 64 by default.
+// Smaller frames load a narrow range with less reading, and larger frames compress better.
+func WithSnapshotFrameLeaves(leaves int) SnapshotOption {
+	return func(s *snapshotWriter) {
+		if leaves > 0 {
+			s.frameLeaves = leaves
+		}
+	}
+}
+
+// WriteSnapshot writes the items of the tree into a seekable compressed snapshot, see LoadRange.
+// The deleted items are left out, and the values are stored as JSON,
+// so they come back like from LoadFromJSONLines: the numbers as json.Number and the objects as maps.
+func (tree *BpTree) WriteSnapshot(w io.Writer, opts ...SnapshotOption) error {
+	settings := snapshotWriter{frameLeaves: 64}
+	for _, opt := range opts {
+		opt(&settings)
+	}
+
+	// Copy the leaves under the lock, so the snapshot is consistent while the tree goes on being written.
+	tree.mutex.Lock()
+	var leaves [][]BpItem
+	for _, data := range tree.root.dataNodes() {
+		leaves = append(leaves, append([]BpItem(nil), data.Items...))
+	}
+	width := tree.width
+	tree.mutex.Unlock()
+
+	// Write the header.
+	var header bytes.Buffer
+	header.WriteString(snapshotMagic)
+	header.WriteByte(snapshotCodecFlate)
+	_ = binary.Write(&header, binary.LittleEndian, uint32(width))
+	if _, err := w.Write(header.Bytes()); err != nil {
+		return err
+	}
+	offset := int64(header.Len())
+
+	// Compress every group of leaves into its own frame.
+	var frames []snapshotFrame
+	for start := 0; start < len(leaves); start += settings.frameLeaves {
+		var items []BpItem
+		for _, leaf := range leaves[start:min(start+settings.frameLeaves, len(leaves))] {
+			for _, item := range leaf {
+				if !item.Mask {
+					items = append(items, item)
+				}
+			}
+		}
+		if len(items) == 0 {
+			continue
+		}
+
+		compressed, err := compressFrame(items)
+		if err != nil {
+			return err
+		}
+		if _, err = w.Write(compressed); err != nil {
+			return err
+		}
+		frames = append(frames, snapshotFrame{Offset: offset, Length: int64(len(compressed)),
+			First: items[0].Key, Last: items[len(items)-1].Key, Items: int64(len(items))})
+		offset += int64(len(compressed))
+	}
+
+	// Write the index and the footer pointing at it.
+	var tail bytes.Buffer
+	_ = binary.Write(&tail, binary.LittleEndian, frames)
+	_ = binary.Write(&tail, binary.LittleEndian, [2]int64{offset, int64(len(frames))})
+	tail.WriteString(snapshotMagic)
+	_, err := w.Write(tail.Bytes())
+	return err
+}
+
+// compressFrame encodes and compresses the items of a frame.
+func compressFrame(items []BpItem) ([]byte, error) {
+	var buf bytes.Buffer
+	zw, _ := flate.NewWriter(&buf, flate.DefaultCompression)
+	var scratch [binary.MaxVarintLen64]byte
+	for _, item := range items {
+		value, err := json.Marshal(item.Val)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode the value of key %d: %w", item.Key, err)
+		}
+		_ = binary.Write(zw, binary.LittleEndian, item.Key)
+		_, _ = zw.Write(scratch[:binary.PutUvarint(scratch[:], uint64(len(value)))])
+		_, _ = zw.Write(value)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// LoadSnapshot loads the whole snapshot of the given size into a new tree of the width it was written with.
+func LoadSnapshot(r io.ReaderAt, size int64) (*BpTree, error) {
+	return loadSnapshot(r, size, math.MinInt64, math.MaxInt64, true)
+}
+
+// LoadRange loads only the items with keys in [from, to) from the snapshot of the given size into a new tree.
+// Only the frames overlapping the range are read and decompressed, so a small range of a huge snapshot loads quickly,
+// from a file or from anything else with ReadAt, such as an object downloaded in ranges.
+func LoadRange(r io.ReaderAt, size int64, from, to int64) (*BpTree, error) {
+	return loadSnapshot(r, size, from, to, false)
+}
+
+// loadSnapshot loads the items with keys in [from, to), or every item when all is set, since to is excluded.
+func loadSnapshot(r io.ReaderAt, size int64, from, to int64, all bool) (*BpTree, error) {
+	width, frames, err := readSnapshotIndex(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := NewBpTree(width)
+	for _, frame := range frames {
+		if !all && (frame.Last < from || frame.First >= to) {
+			continue // The frame is outside the range, it is not even read.
+		}
+		items, err := readFrame(r, frame)
+		if err != nil {
+			return nil, err
+		}
+		var inRange []BpItem
+		for _, item := range items {
+			if all || (item.Key >= from && item.Key < to) {
+				inRange = append(inRange, item)
+			}
+		}
+		tree.insertBatch(inRange)
+	}
+
+	return tree, nil
+}
+
+// readSnapshotIndex checks the header and the footer, and reads the width and the index of the frames.
+func readSnapshotIndex(r io.ReaderAt, size int64) (width int, frames []snapshotFrame, err error) {
+	if size < int64(snapshotHeaderSize+snapshotFooterSize) {
+		return 0, nil, fmt.Errorf("%w: only %d bytes", ErrBadSnapshot, size)
+	}
+
+	header := make([]byte, snapshotHeaderSize)
+	if _, err = r.ReadAt(header, 0); err != nil {
+		return 0, nil, err
+	}
+	if string(header[:len(snapshotMagic)]) != snapshotMagic {
+		return 0, nil, fmt.Errorf("%w: no snapshot header", ErrBadSnapshot)
+	}
+	if codec := header[len(snapshotMagic)]; codec != snapshotCodecFlate {
+		return 0, nil, fmt.Errorf("%w: unknown codec %d", ErrBadSnapshot, codec)
+	}
+	width = int(binary.LittleEndian.Uint32(header[len(snapshotMagic)+1:]))
+
+	footer := make([]byte, snapshotFooterSize)
+	if _, err = r.ReadAt(footer, size-int64(snapshotFooterSize)); err != nil {
+		return 0, nil, err
+	}
+	if string(footer[16:]) != snapshotMagic {
+		return 0, nil, fmt.Errorf("%w: no snapshot footer", ErrBadSnapshot)
+	}
+	indexOffset := int64(binary.LittleEndian.Uint64(footer))
+	count := int64(binary.LittleEndian.Uint64(footer[8:]))
+	entrySize := int64(binary.Size(snapshotFrame{}))
+	if count < 0 || count > size/entrySize || indexOffset < int64(snapshotHeaderSize) || indexOffset+count*entrySize != size-int64(snapshotFooterSize) {
+		return 0, nil, fmt.Errorf("%w: the index does not fit", ErrBadSnapshot)
+	}
+
+	frames = make([]snapshotFrame, count)
+	if err = binary.Read(io.NewSectionReader(r, indexOffset, count*entrySize), binary.LittleEndian, frames); err != nil {
+		return 0, nil, err
+	}
+
+	// Every frame must lie between the header and the index, so a damaged index is not trusted.
+	for _, frame := range frames {
+		if frame.Offset < int64(snapshotHeaderSize) || frame.Length < 0 || frame.Offset > indexOffset-frame.Length || frame.Items < 0 {
+			return 0, nil, fmt.Errorf("%w: the frame at %d does not fit", ErrBadSnapshot, frame.Offset)
+		}
+	}
+	return width, frames, nil
+}
+
+// readFrame reads and decompresses the items of the frame.
+func readFrame(r io.ReaderAt, frame snapshotFrame) (items []BpItem, err error) {
+	zr := flate.NewReader(io.NewSectionReader(r, frame.Offset, frame.Length))
+	defer func() { _ = zr.Close() }()
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: frame at %d: %v", ErrBadSnapshot, frame.Offset, err)
+	}
+
+	// Every item takes at least 10 bytes, the key, the length and the value, which bounds the count of the index.
+	items = make([]BpItem, 0, min(frame.Items, int64(len(data)/10)))
+	for len(data) > 0 {
+		if len(data) < 8 {
+			return nil, fmt.Errorf("%w: frame at %d is cut", ErrBadSnapshot, frame.Offset)
+		}
+		key := int64(binary.LittleEndian.Uint64(data))
+		length, n := binary.Uvarint(data[8:])
+		if n <= 0 || uint64(len(data)-8-n) < length {
+			return nil, fmt.Errorf("%w: frame at %d is cut", ErrBadSnapshot, frame.Offset)
+		}
+		data = data[8+n:]
+
+		// Decode the numbers as json.Number, so large integers keep their precision.
+		decoder := json.NewDecoder(bytes.NewReader(data[:length]))
+		decoder.UseNumber()
+		var value interface{}
+		if err = decoder.Decode(&value); err != nil {
+			return nil, fmt.Errorf("%w: the value of key %d: %v", ErrBadSnapshot, key, err)
+		}
+		items = append(items, BpItem{Key: key, Val: value})
+		data = data[length:]
+	}
+	if int64(len(items)) != frame.Items {
+		return nil, fmt.Errorf("%w: frame at %d has %d items instead of %d", ErrBadSnapshot, frame.Offset, len(items), frame.Items)
+	}
+	return items, nil
+}
@@ -0,0 +1,108 @@
+package bpTree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// countingReaderAt 🧫 counts the bytes read through ReadAt.
+type countingReaderAt struct {
+	r    io.ReaderAt
+	read int64
+}
+
+// ReadAt reads from the wrapped reader, and counts the bytes.
+func (c *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	n, err := c.r.ReadAt(p, off)
+	c.read += int64(n)
+	return n, err
+}
+
+// snapshotKeys 🧫 returns the keys of the tree in ascending order.
+func snapshotKeys(tree *BpTree) (keys []int64) {
+	for it := tree.Iterator(); it.Next(); {
+		keys = append(keys, it.Key())
+	}
+	return
+}
+
+// Test_Check_BpTree_Snapshot 🧫 checks writing a snapshot, and loading all of it or only a key range.
+func Test_Check_BpTree_Snapshot(t *testing.T) {
+	tree := NewBpTree(4)
+	for key := int64(1); key <= 2000; key++ {
+		require.NoError(t, tree.InsertValue(BpItem{Key: key, Val: map[string]interface{}{"name": "item", "n": key}}))
+	}
+	_, _, _, err := tree.RemoveValue(BpItem{Key: 1000})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, tree.WriteSnapshot(&buf, WithSnapshotFrameLeaves(8)))
+	snapshot := buf.Bytes()
+
+	t.Run("LoadSnapshot loads every item", func(t *testing.T) {
+		loaded, err := LoadSnapshot(bytes.NewReader(snapshot), int64(len(snapshot)))
+		require.NoError(t, err)
+		keys := snapshotKeys(loaded)
+		require.Len(t, keys, 1999)
+		require.NotContains(t, keys, int64(1000))
+		require.Equal(t, 4, loaded.width)
+
+		// The values come back from JSON, with the numbers as json.Number.
+		value, found := loaded.Get(1999)
+		require.True(t, found)
+		require.Equal(t, map[string]interface{}{"name": "item", "n": json.Number("1999")}, value.Val)
+	})
+
+	t.Run("LoadRange reads only the overlapping frames", func(t *testing.T) {
+		reader := &countingReaderAt{r: bytes.NewReader(snapshot)}
+		loaded, err := LoadRange(reader, int64(len(snapshot)), 990, 1010)
+		require.NoError(t, err)
+		keys := snapshotKeys(loaded)
+		require.Len(t, keys, 19)
+		require.Equal(t, int64(990), keys[0])
+		require.Equal(t, int64(1009), keys[len(keys)-1])
+		require.Less(t, reader.read*2, int64(len(snapshot)), "a narrow range reads the index and a few frames")
+
+		// A range outside the keys loads an empty tree.
+		loaded, err = LoadRange(bytes.NewReader(snapshot), int64(len(snapshot)), 5000, 6000)
+		require.NoError(t, err)
+		require.Empty(t, snapshotKeys(loaded))
+	})
+
+	t.Run("Damaged snapshots are rejected", func(t *testing.T) {
+		_, err := LoadSnapshot(bytes.NewReader([]byte("short")), 5)
+		require.ErrorIs(t, err, ErrBadSnapshot)
+		cut := snapshot[:len(snapshot)-1]
+		_, err = LoadSnapshot(bytes.NewReader(cut), int64(len(cut)))
+		require.ErrorIs(t, err, ErrBadSnapshot)
+		broken := bytes.Clone(snapshot)
+		broken[snapshotHeaderSize+2] ^= 0xff
+		_, err = LoadSnapshot(bytes.NewReader(broken), int64(len(broken)))
+		require.ErrorIs(t, err, ErrBadSnapshot)
+
+		// A damaged index entry of the first frame is rejected, instead of being trusted for the reads and the allocations.
+		index := int(binary.LittleEndian.Uint64(snapshot[len(snapshot)-snapshotFooterSize:]))
+		for _, field := range []struct {
+			at    int // The field in the entry: 0 Offset, 8 Length, 32 Items.
+			value int64
+		}{{32, 1 << 62}, {32, -1}, {0, -1}, {0, int64(index)}, {8, -1}, {8, 1 << 62}} {
+			broken = bytes.Clone(snapshot)
+			binary.LittleEndian.PutUint64(broken[index+field.at:], uint64(field.value))
+			_, err = LoadSnapshot(bytes.NewReader(broken), int64(len(broken)))
+			require.ErrorIs(t, err, ErrBadSnapshot, "field %d set to %d", field.at, field.value)
+		}
+	})
+
+	t.Run("An empty tree has no frames", func(t *testing.T) {
+		buf.Reset()
+		require.NoError(t, NewBpTree(5).WriteSnapshot(&buf))
+		loaded, err := LoadSnapshot(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		require.NoError(t, err)
+		require.Empty(t, snapshotKeys(loaded))
+	})
+}
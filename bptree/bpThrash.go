@@ -0,0 +1,157 @@
+package bpTree
+
+import (
+	"sync"
+	"time"
+)
+
+// ➡️ thrash operation
+
+// maxThrashWindows is how many flagged windows are kept, the oldest ones are dropped first.
+const maxThrashWindows = 100
+
+// ThrashWindow is a window of time in which the tree changed its structure too often for the operations it served.
+type ThrashWindow struct {
+	Start, End time.Time // The window, the end is when the next window began.
+	Logical    uint64    // The inserts and deletes in the window.
+	Structural uint64    // The splits and the rebalances in the window.
+	MinKey     int64     // The smallest key whose operation changed the structure.
+	MaxKey     int64     // The largest key whose operation changed the structure.
+}
+
+// Ratio returns the structural operations per logical operation.
+func (w ThrashWindow) Ratio() float64 {
+	if w.Logical == 0 {
+		return 0
+	}
+	return float64(w.Structural) / float64(w.Logical)
+}
+
+// ThrashMonitor flags the windows where the splits and the rebalances exceed a threshold relative to the inserts and deletes,
+// such as a width too small for a skewed workload, which keeps splitting and merging the same key range. (结构抖动)
+type ThrashMonitor struct {
+	mu        sync.Mutex
+	window    time.Duration      // The length of a window.
+	threshold float64            // The structural operations per logical operation above which a window is flagged.
+	minOps    uint64             // The fewest logical operations of a window that can be flagged.
+	onThrash  func(ThrashWindow) // Called with every flagged window, nil calls nothing.
+	now       func() time.Time   // The clock, time.Now by default.
+	current   ThrashWindow       // The window being counted.
+	flagged   []ThrashWindow     // The latest flagged windows.
+}
+
+// ThrashOption defines a function type for configuring the thrash monitor.
+type ThrashOption func(*ThrashMonitor)
+
+// WithThrashMinOps sets the fewest inserts and deletes of a window that can be flagged, 100 by default,
+// so a quiet window with a single split is not flagged.
+func WithThrashMinOps(n uint64) ThrashOption {
+	return func(m *ThrashMonitor) {
+		m.minOps = n
+	}
+}
+
+// WithThrashHandler sets the function called with every flagged window.
+// It is called while the tree lock is held, so it must not call the methods of the tree.
+func WithThrashHandler(fn func(ThrashWindow)) ThrashOption {
+	return func(m *ThrashMonitor) {
+		m.onThrash = fn
+	}
+}
+
+// NewThrashMonitor flags the windows of the given length where the structural operations per logical operation exceed the threshold.
+// A window of 0 means 1 second.
+func NewThrashMonitor(window time.Duration, threshold float64, opts ...ThrashOption) *ThrashMonitor {
+	if window <= 0 {
+		window = time.Second
+	}
+	m := &ThrashMonitor{window: window, threshold: threshold, minOps: 100, now: time.Now}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// MonitorThrash starts feeding the inserts and deletes of the tree to the monitor, nil stops it.
+func (tree *BpTree) MonitorThrash(m *ThrashMonitor) {
+	// Acquire a lock to ensure thread safety.
+	tree.mutex.Lock()
+
+	// Release the lock to allow other threads to access the tree.
+	defer tree.mutex.Unlock()
+
+	tree.thrash = m
+}
+
+// observeStructure feeds one operation to the monitor with the structural operations it caused, the lock must be held by the caller.
+func (tree *BpTree) observeStructure(key int64, before uint64) {
+	tree.thrash.observe(key, tree.structural-before)
+}
+
+// observe counts one logical operation, and closes the window when its time is up.
+func (m *ThrashMonitor) observe(key int64, structural uint64) {
+	m.mu.Lock()
+	now := m.now()
+	var flagged *ThrashWindow
+	switch {
+	case m.current.Start.IsZero():
+		m.current.Start = now
+	case now.Sub(m.current.Start) >= m.window:
+		flagged = m.closeWindow(now)
+	}
+
+	m.current.Logical++
+	if structural > 0 {
+		if m.current.Structural == 0 || key < m.current.MinKey {
+			m.current.MinKey = key
+		}
+		if m.current.Structural == 0 || key > m.current.MaxKey {
+			m.current.MaxKey = key
+		}
+		m.current.Structural += structural
+	}
+	m.mu.Unlock()
+
+	if flagged != nil && m.onThrash != nil {
+		m.onThrash(*flagged)
+	}
+}
+
+// closeWindow ends the current window and starts the next one, the mutex must be held by the caller.
+// It returns the ended window when it is flagged.
+func (m *ThrashMonitor) closeWindow(end time.Time) (flagged *ThrashWindow) {
+	window := m.current
+	window.End = end
+	m.current = ThrashWindow{Start: end}
+	if window.Logical < m.minOps || window.Ratio() <= m.threshold {
+		return nil
+	}
+
+	if len(m.flagged) == maxThrashWindows {
+		m.flagged = append(m.flagged[:0], m.flagged[1:]...)
+	}
+	m.flagged = append(m.flagged, window)
+	return &window
+}
+
+// Flush ends the current window, so the last operations of a run are checked too.
+func (m *ThrashMonitor) Flush() {
+	m.mu.Lock()
+	var flagged *ThrashWindow
+	if !m.current.Start.IsZero() {
+		flagged = m.closeWindow(m.now())
+		m.current = ThrashWindow{}
+	}
+	m.mu.Unlock()
+
+	if flagged != nil && m.onThrash != nil {
+		m.onThrash(*flagged)
+	}
+}
+
+// Windows returns the flagged windows, the oldest first.
+func (m *ThrashMonitor) Windows() []ThrashWindow {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]ThrashWindow(nil), m.flagged...)
+}
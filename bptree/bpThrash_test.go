@@ -0,0 +1,106 @@
+package bpTree
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock 🧫 moves forward by the step every time it is read, so every operation takes the same time.
+func fakeClock(step time.Duration) func() time.Time {
+	now := time.Unix(0, 0)
+	return func() time.Time {
+		now = now.Add(step)
+		return now
+	}
+}
+
+// Test_Check_BpTree_Thrash 🧫 checks that a narrow tree under sequential inserts is flagged, and a wide one is not.
+func Test_Check_BpTree_Thrash(t *testing.T) {
+	// Every window has 100 operations, with 1 millisecond for each.
+	run := func(width int) (windows []ThrashWindow, handled int) {
+		monitor := NewThrashMonitor(100*time.Millisecond, 0.2, WithThrashMinOps(50), WithThrashHandler(func(ThrashWindow) { handled++ }))
+		monitor.now = fakeClock(time.Millisecond)
+		tree := NewBpTree(width)
+		tree.MonitorThrash(monitor)
+		for key := int64(1); key <= 1000; key++ {
+			require.NoError(t, tree.InsertValue(BpItem{Key: key}))
+		}
+		monitor.Flush()
+		return monitor.Windows(), handled
+	}
+
+	// The narrow tree splits on almost every other insert.
+	windows, handled := run(3)
+	require.NotEmpty(t, windows)
+	require.Equal(t, len(windows), handled)
+	for _, window := range windows {
+		require.Greater(t, window.Ratio(), 0.2)
+		require.GreaterOrEqual(t, window.Logical, uint64(50))
+		require.LessOrEqual(t, window.MinKey, window.MaxKey)
+		require.True(t, window.End.After(window.Start))
+	}
+	require.GreaterOrEqual(t, windows[0].MinKey, int64(1))
+	require.LessOrEqual(t, windows[len(windows)-1].MaxKey, int64(1000))
+
+	// The wide tree rarely splits.
+	windows, handled = run(64)
+	require.Empty(t, windows)
+	require.Zero(t, handled)
+
+	// The deletes count their rebalances, and the monitor can be removed.
+	monitor := NewThrashMonitor(time.Hour, 0, WithThrashMinOps(1))
+	tree := NewBpTree(3)
+	for key := int64(1); key <= 100; key++ {
+		require.NoError(t, tree.InsertValue(BpItem{Key: key}))
+	}
+	tree.MonitorThrash(monitor)
+	for key := int64(1); key <= 100; key++ {
+		deleted, _, _, err := tree.RemoveValue(BpItem{Key: key})
+		require.True(t, deleted)
+		require.NoError(t, err)
+	}
+	tree.MonitorThrash(nil)
+	require.NoError(t, tree.InsertValue(BpItem{Key: 1}))
+	monitor.Flush()
+	windows = monitor.Windows()
+	require.Len(t, windows, 1)
+	require.Equal(t, uint64(100), windows[0].Logical)
+	require.Positive(t, windows[0].Structural)
+}
+
+// Test_Check_BpTree_Thrash_PerTree 🧫 checks that the splits of another tree, written at the same time, are not counted.
+func Test_Check_BpTree_Thrash_PerTree(t *testing.T) {
+	// The monitored tree only inserts and deletes a single key, which never splits it.
+	monitor := NewThrashMonitor(time.Hour, 0, WithThrashMinOps(1))
+	tree := NewBpTree(3)
+	tree.MonitorThrash(monitor)
+
+	// The other tree keeps splitting until the monitored tree is done.
+	other := NewBpTree(3)
+	stop, done := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(done)
+		for key := int64(1); ; key++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			require.NoError(t, other.InsertValue(BpItem{Key: key}))
+		}
+	}()
+
+	for i := 0; i < 1000; i++ {
+		require.NoError(t, tree.InsertValue(BpItem{Key: 1}))
+		deleted, _, _, err := tree.RemoveValue(BpItem{Key: 1})
+		require.True(t, deleted)
+		require.NoError(t, err)
+	}
+	close(stop)
+	<-done
+
+	monitor.Flush()
+	require.Empty(t, monitor.Windows())
+}
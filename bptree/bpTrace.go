@@ -0,0 +1,90 @@
+package bpTree
+
+import (
+	"fmt"
+	"io"
+)
+
+// ➡️ trace operation
+
+// tracer explains the steps of one insert or delete at a time in plain words. (教学模式)
+type tracer struct {
+	w    io.Writer // Where the explanations are written.
+	step int       // The number of the last step of the current operation.
+}
+
+// Trace starts explaining every insert and delete of the tree to w, nil stops it.
+// Each operation is written as numbered steps: the index nodes it descends, the comparisons choosing the child,
+// and every split, borrow and merge it causes, so the tree can be followed step by step when learning how it works.
+// A traced tree is much slower, it is meant for small trees.
+func (tree *BpTree) Trace(w io.Writer) {
+	// Acquire a lock to ensure thread safety.
+	tree.mutex.Lock()
+
+	// Release the lock to allow other threads to access the tree.
+	defer tree.mutex.Unlock()
+
+	if w == nil {
+		tree.trace = nil
+		return
+	}
+	tree.trace = &tracer{w: w}
+}
+
+// beginTrace starts explaining one operation of a traced tree, the lock must be held by the caller.
+func (tree *BpTree) beginTrace(operation string, key int64) {
+	tree.trace.step = 0
+	fmt.Fprintf(tree.trace.w, "%s key %d\n", operation, key)
+}
+
+// endTrace ends the explanation of the operation with the shape of the tree, the lock must be held by the caller.
+func (tree *BpTree) endTrace() {
+	height := 1
+	for inode := tree.root; len(inode.IndexNodes) > 0; inode = inode.IndexNodes[0] {
+		height++
+	}
+	tree.trace.printf("done, the tree has a height of %d and its root holds the index keys %v", height, tree.root.Index)
+}
+
+// printf writes one numbered step of the current operation.
+func (t *tracer) printf(format string, args ...any) {
+	t.step++
+	fmt.Fprintf(t.w, "  %d. %s\n", t.step, fmt.Sprintf(format, args...))
+}
+
+// descend explains why the key goes down to the child ix of an index node.
+// The keys equal to an index key go to the right of it, both when inserting and when deleting.
+func (t *tracer) descend(index []int64, key int64, ix int, toIndexNode bool) {
+	child := "data node"
+	if toIndexNode {
+		child = "index node"
+	}
+	switch {
+	case len(index) == 0:
+		t.printf("the index node has no index keys, go down to its only %s", child)
+	case ix == 0:
+		t.printf("the index node holds %v, %d < %d, go down to the leftmost %s", index, key, index[0], child)
+	case ix == len(index):
+		t.printf("the index node holds %v, %d ≥ %d, go down to the rightmost %s %d", index, key, index[ix-1], child, ix)
+	default:
+		t.printf("the index node holds %v, %d ≤ %d < %d, go down to %s %d", index, index[ix-1], key, index[ix], child, ix)
+	}
+}
+
+// keys returns the keys of the data node, for the explanations.
+func (data *BpData) keys() []int64 {
+	keys := make([]int64, 0, len(data.Items))
+	for _, item := range data.Items {
+		keys = append(keys, item.Key)
+	}
+	return keys
+}
+
+// dataKeys returns the keys of every data node of the bottom index node, for the explanations.
+func (inode *BpIndex) dataKeys() [][]int64 {
+	keys := make([][]int64, 0, len(inode.DataNodes))
+	for _, data := range inode.DataNodes {
+		keys = append(keys, data.keys())
+	}
+	return keys
+}
@@ -0,0 +1,83 @@
+package bpTree
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Check_BpTree_Trace 🧫 checks the step-by-step explanations of the inserts and the deletes.
+func Test_Check_BpTree_Trace(t *testing.T) {
+	var buf bytes.Buffer
+	traced, plain := NewBpTree(3), NewBpTree(3)
+	traced.Trace(&buf)
+
+	// The inserts explain the descent and the splits, up to the growth of the tree.
+	for key := int64(1); key <= 7; key++ {
+		require.NoError(t, traced.InsertValue(BpItem{Key: key}))
+		require.NoError(t, plain.InsertValue(BpItem{Key: key}))
+	}
+	out := buf.String()
+	require.Contains(t, out, "insert key 3\n  1. the root holds a single data node, it takes key 3 and holds [1 2 3]\n")
+	require.Contains(t, out, "it splits into [1] and [2 3], and key 2 becomes the first index key")
+	require.Contains(t, out, "the index node holds [2], 4 ≥ 2, go down to the rightmost data node 1")
+	require.Contains(t, out, "the tree grows one level")
+	require.Contains(t, out, "done, the tree has a height of 2")
+
+	// The deletes explain the descent, the removal and the rebalancing.
+	buf.Reset()
+	for _, key := range []int64{4, 5, 9} {
+		_, _, _, err := traced.RemoveValue(BpItem{Key: key})
+		require.NoError(t, err)
+		_, _, _, err = plain.RemoveValue(BpItem{Key: key})
+		require.NoError(t, err)
+	}
+	out = buf.String()
+	require.Contains(t, out, "delete key 4\n  1. the index node holds [3 5], 3 ≤ 4 < 5, go down to index node 1\n")
+	require.Contains(t, out, "data node 1 is empty")
+	require.Contains(t, out, "borrows from")
+	require.Contains(t, out, "key 9 is not in data node 2, nothing is removed")
+
+	// Tracing changes nothing in the tree.
+	require.True(t, Equal(traced, plain))
+
+	// Another tree is not traced, and the trace stops with nil.
+	buf.Reset()
+	require.NoError(t, plain.InsertValue(BpItem{Key: 8}))
+	traced.Trace(nil)
+	require.NoError(t, traced.InsertValue(BpItem{Key: 8}))
+	require.Empty(t, buf.String())
+	require.False(t, strings.Contains(buf.String(), "insert key 8"))
+}
+
+// Test_Check_BpTree_Trace_PerTree 🧫 checks that the steps of another tree, written at the same time, are not explained.
+func Test_Check_BpTree_Trace_PerTree(t *testing.T) {
+	var buf bytes.Buffer
+	traced, plain := NewBpTree(3), NewBpTree(3)
+	traced.Trace(&buf)
+
+	// The plain tree keeps splitting, on keys the traced tree never sees, until the traced tree is done.
+	stop, done := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(done)
+		for key := int64(900000); ; key++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			require.NoError(t, plain.InsertValue(BpItem{Key: key}))
+		}
+	}()
+
+	for key := int64(1); key <= 200; key++ {
+		require.NoError(t, traced.InsertValue(BpItem{Key: key}))
+	}
+	close(stop)
+	<-done
+
+	require.Contains(t, buf.String(), "insert key 200\n")
+	require.NotContains(t, buf.String(), "900")
+}
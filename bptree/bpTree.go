@@ -6,6 +6,8 @@ import (
 )
 
 // The width and half-width for B plus tree.
+//
+// Deprecated: every tree keeps its own width, which is read with Width, and these are no longer set.
 var (
 	BpWidth     int // the width of B plus tree.
 	BpHalfWidth int // the half-width of B plus tree.
@@ -13,8 +15,29 @@ var (
 
 // BpTree is the root of Tree B plus.
 type BpTree struct {
-	mutex sync.Mutex // lock
-	root  *BpIndex   // root tree
+	mutex     latch    // lock, which can count the contention
+	root      *BpIndex // root tree
+	width     int      // the width of this tree
+	halfWidth int      // the half-width of this tree
+
+	watchMutex sync.RWMutex  // lock for the watchers
+	watchers   []*watcher    // watchers subscribing to key-range changes
+	lastNotify chan struct{} // closed when the changes of the last watched write have been sent, see notifyTurn
+
+	version uint64            // increases on every write, used by transactions to detect conflicts
+	journal []journalEntry    // writes made while transactions are open, newer than the oldest of them
+	openTx  map[uint64]uint64 // the start versions of the transactions which are still open, by their ids
+	txSeq   uint64            // the id of the last transaction begun
+
+	rebuildLog *[]txOp // writes made while RebuildWithWidth builds the new tree, nil when no rebuild runs
+
+	bucketViews map[int64]map[int64]int // the key counts of every bucket, by the width of the buckets, see CountByBucket
+
+	validators []Validator // hooks checking every item before it is inserted
+
+	thrash     *ThrashMonitor // flags the windows of too many splits and rebalances, nil when not monitored
+	structural uint64         // the splits and the rebalances of this tree, the thrash monitor reads how much it grows during each operation
+	trace      *tracer        // explains every insert and delete step by step, nil when not traced
 }
 
 // NewBpTree initializes B plus tree structure with specified width and data entries.
@@ -23,14 +46,14 @@ func NewBpTree(width int) (tree *BpTree) {
 	if width < 3 { // The minimum width for B plus tree is 3.
 		width = 3
 	}
-	BpWidth = width
-	BpHalfWidth = int((float32(BpWidth)-0.1)/2) + 1
 
 	// Create root tree instance
 	tree = &BpTree{
 		root: &BpIndex{
-			DataNodes: make([]*BpData, 0, BpWidth+1), // The addition of 1 is because data chunks may temporarily exceed the width.
+			DataNodes: make([]*BpData, 0, width+1), // The addition of 1 is because data chunks may temporarily exceed the width.
 		},
+		width:     width,
+		halfWidth: halfWidthOf(width),
 	}
 
 	// Prepare one data slice first; one data slice will not generate an index.
@@ -39,13 +62,57 @@ func NewBpTree(width int) (tree *BpTree) {
 	return
 }
 
+// halfWidthOf returns the half-width for the width, the fewest items or children a node keeps.
+func halfWidthOf(width int) int {
+	return int((float32(width)-0.1)/2) + 1
+}
+
 // InsertValue ensures thread safety, insert item in B plus tree index, release lock.
-func (tree *BpTree) InsertValue(item BpItem) {
+// It returns a *ConstraintError when a registered validator rejects the item.
+func (tree *BpTree) InsertValue(item BpItem) (err error) {
 	// Acquire a lock to ensure thread safety.
 	tree.mutex.Lock()
 
+	// Check the constraints before anything is written.
+	if err = tree.validate(item); err != nil {
+		tree.mutex.Unlock()
+		return
+	}
+
+	// Insert the item into the B plus tree index.
+	tree.insert(item)
+	turn := tree.notifyTurn()
+
+	// Release the lock to allow other threads to access the tree.
+	tree.mutex.Unlock()
+
+	// Notify the watchers after the lock is released, in the order of the writes.
+	tree.notifyWatchers(turn, ChangeEvent{Kind: ChangeInsert, Item: item})
+
+	// Performing a return.
+	return
+}
+
+// insert inserts item in B plus tree index, the lock must be held by the caller.
+func (tree *BpTree) insert(item BpItem) {
+	// Record the write for the transactions which are still open, for the running rebuild, and for the bucket views.
+	tree.recordWrite(item.Key)
+	tree.recordRebuild(txInsert, item)
+	tree.countBucket(item.Key, 1)
+
+	// Feed the splits caused by this insert to the thrash monitor.
+	if tree.thrash != nil {
+		defer tree.observeStructure(item.Key, tree.structural)
+	}
+
+	// Explain the steps of this insert when the tree is traced.
+	if tree.trace != nil {
+		tree.beginTrace("insert", item.Key)
+		defer tree.endTrace()
+	}
+
 	// Insert the item into the B plus tree index.
-	_, popKey, popNode, status, err := tree.root.insertItem(nil, item)
+	_, popKey, popNode, status, err := tree.root.insertItem(tree, nil, item)
 
 	if err != nil {
 		panic(err)
@@ -55,6 +122,9 @@ func (tree *BpTree) InsertValue(item BpItem) {
 		// Here, it will increase the entire tree's depth. (层数增加)
 		tree.root = popNode
 		status = statusNormal
+		if trace := tree.trace; trace != nil {
+			trace.printf("the root has split, its middle key %v moves up into a new root, the tree grows one level", popNode.Index)
+		}
 	}
 
 	if status == statusProtrudeDnode {
@@ -63,19 +133,25 @@ func (tree *BpTree) InsertValue(item BpItem) {
 		if err != nil {
 			return
 		}
+		if trace := tree.trace; trace != nil {
+			trace.printf("the root has split, key %d becomes the new root above both halves, the tree grows one level", popKey)
+		}
 	}
 
-	if len(tree.root.Index) >= BpWidth && len(tree.root.Index)%2 != 0 {
-		popNode, _ = tree.root.protrudeInOddBpWidth()
+	if len(tree.root.Index) >= tree.width && len(tree.root.Index)%2 != 0 {
+		popNode, _ = tree.root.protrudeInOddBpWidth(tree)
 		tree.root = popNode
-	} else if len(tree.root.Index) >= BpWidth && len(tree.root.Index)%2 == 0 {
-		popNode, _ = tree.root.protrudeInEvenBpWidth()
+		if trace := tree.trace; trace != nil {
+			trace.printf("the root has reached the width %d, its middle key %v moves up into a new root, the tree grows one level", tree.width, popNode.Index)
+		}
+	} else if len(tree.root.Index) >= tree.width && len(tree.root.Index)%2 == 0 {
+		popNode, _ = tree.root.protrudeInEvenBpWidth(tree)
 		tree.root = popNode
+		if trace := tree.trace; trace != nil {
+			trace.printf("the root has reached the width %d, its middle key %v moves up into a new root, the tree grows one level", tree.width, popNode.Index)
+		}
 	}
 
-	// Release the lock to allow other threads to access the tree.
-	tree.mutex.Unlock()
-
 	// Performing a return.
 	return
 }
@@ -84,23 +160,62 @@ func (tree *BpTree) InsertValue(item BpItem) {
 func (tree *BpTree) RemoveValue(item BpItem) (deleted, updated bool, ix int, err error) {
 	// Acquire a lock to ensure thread safety.
 	tree.mutex.Lock()
+	turn := tree.notifyTurn()
+
+	// Notify the watchers after the lock is released, in the order of the writes. (defer 后进先出，解锁后才通知)
+	defer func() {
+		if deleted {
+			tree.notifyWatchers(turn, ChangeEvent{Kind: ChangeDelete, Item: item})
+		} else {
+			tree.notifyWatchers(turn)
+		}
+	}()
 
 	// Release the lock to allow other threads to access the tree.
 	defer tree.mutex.Unlock()
 
+	// Remove the item from the B plus tree index.
+	return tree.remove(item)
+}
+
+// remove removes item in B plus tree index, the lock must be held by the caller.
+func (tree *BpTree) remove(item BpItem) (deleted, updated bool, ix int, err error) {
+	// Record the write for the transactions which are still open, for the running rebuild, and for the bucket views.
+	defer func() {
+		if deleted {
+			tree.recordWrite(item.Key)
+			tree.recordRebuild(txRemove, item)
+			tree.countBucket(item.Key, -1)
+		}
+	}()
+
+	// Feed the rebalances caused by this delete to the thrash monitor.
+	if tree.thrash != nil {
+		defer tree.observeStructure(item.Key, tree.structural)
+	}
+
 	// The deletion operation is currently managed by the root node to prevent issues with mismatched levels of child nodes.
 	// If the levels of child nodes are not correct, the B plus tree may malfunction. ‼️
 	// 删除操作由根节点管理，确保所有子节点层级相同 ‼️
 
+	// Explain the steps of this delete when the tree is traced.
+	if tree.trace != nil {
+		tree.beginTrace("delete", item.Key)
+		defer tree.endTrace()
+	}
+
 	// Performing deletion operation.
 	var edgeValue int64 = -1
-	deleted, updated, ix, edgeValue, err = tree.root.delFromRoot(item)
+	deleted, updated, ix, edgeValue, err = tree.root.delFromRoot(tree, item)
 
 	// 以下进行临时修正
 	if ix >= 0 && ix <= len(tree.root.IndexNodes)-1 && len(tree.root.IndexNodes[ix].Index) == 0 {
 		// if item.Key == 537 {
 		// fmt.Println(">>>>> 暂时的修正")
-		err = tree.root.borrowFromRootIndexNode(ix, edgeValue)
+		if trace := tree.trace; trace != nil {
+			trace.printf("index node %d under the root has lost all its index keys, it borrows from or merges with a neighbor", ix)
+		}
+		err = tree.root.borrowFromRootIndexNode(tree, ix, edgeValue)
 		// tree.root.Index = []int64{1383} // 已修正完成
 		// tree.root.IndexNodes[0].Index = []int64{229, 553}
 		// tree.root.IndexNodes[1].Index = []int64{1633} // 已修正完成
@@ -115,6 +230,9 @@ func (tree *BpTree) RemoveValue(item BpItem) (deleted, updated bool, ix int, err
 
 	if len(tree.root.Index) == 0 && len(tree.root.IndexNodes) == 1 {
 		tree.root = tree.root.IndexNodes[0]
+		if trace := tree.trace; trace != nil {
+			trace.printf("the root has a single child left, the child becomes the root and the tree shrinks one level")
+		}
 		return
 	}
 
@@ -126,6 +244,9 @@ func (tree *BpTree) RemoveValue(item BpItem) (deleted, updated bool, ix int, err
 			node.Index = append([]int64{tree.root.IndexNodes[1].edgeValue()}, tree.root.IndexNodes[1].Index...)
 			node.IndexNodes = append(tree.root.IndexNodes[0].IndexNodes, tree.root.IndexNodes[1].IndexNodes...)
 			*tree.root = *node
+			if trace := tree.trace; trace != nil {
+				trace.printf("the two children of the root merge into the root, the tree shrinks one level")
+			}
 			return
 		} else if ix == 1 {
 			fmt.Println("这里还没写完")
@@ -135,6 +256,9 @@ func (tree *BpTree) RemoveValue(item BpItem) (deleted, updated bool, ix int, err
 	// ⚠️ When there is only one remaining index child node. (索引节点的升级合拼)
 	if len(tree.root.IndexNodes) == 1 && len(tree.root.DataNodes) == 0 {
 		*tree.root = *tree.root.IndexNodes[0]
+		if trace := tree.trace; trace != nil {
+			trace.printf("the root has a single child left, the child becomes the root and the tree shrinks one level")
+		}
 		return
 	}
 
@@ -145,18 +269,24 @@ func (tree *BpTree) RemoveValue(item BpItem) (deleted, updated bool, ix int, err
 			// If the first data node is empty, replace the root node with the second data node.
 			tree.root.Index = nil
 			tree.root.DataNodes = []*BpData{tree.root.DataNodes[1]}
+			if trace := tree.trace; trace != nil {
+				trace.printf("the left data node of the root is empty, the root keeps only the right one")
+			}
 			return
 		} else if len(tree.root.DataNodes[1].Items) == 0 && len(tree.root.DataNodes[0].Items) != 0 {
 			// If the second data node is empty, replace the root node with the first data node.
 			tree.root.Index = nil
 			tree.root.DataNodes = []*BpData{tree.root.DataNodes[0]}
+			if trace := tree.trace; trace != nil {
+				trace.printf("the right data node of the root is empty, the root keeps only the left one")
+			}
 			return
 		}
 	}
 
 	// ⚠️ If there are only 2 index nodes, but the data is not a lot, they can be merged.
 	if len(tree.root.IndexNodes) == 2 &&
-		BpWidth > (len(tree.root.IndexNodes[0].Index)+len(tree.root.IndexNodes[1].Index)) { // Combine within the range of BpWidth.
+		tree.width > (len(tree.root.IndexNodes[0].Index)+len(tree.root.IndexNodes[1].Index)) { // Combine within the range of tree.width.
 
 		// Begin the merger process.
 		if len(tree.root.Index) == 0 && len(tree.root.IndexNodes) > 0 {
@@ -172,13 +302,16 @@ func (tree *BpTree) RemoveValue(item BpItem) (deleted, updated bool, ix int, err
 
 			// Replace the original root node with the new node.
 			*tree.root = *node
+			if trace := tree.trace; trace != nil {
+				trace.printf("the two children of the root are small enough, they merge into the root and the tree shrinks one level")
+			}
 		}
 	}
 
 	// ⚠️ Warning: The following code appears to perform a restructuring operation on a B Plus tree.
 	// 当根节点直接连接到资料节点，而且分支数量只有 2 个的时候，这时根节点规模会过小
 	if len(tree.root.DataNodes) == 2 &&
-		BpWidth > (len(tree.root.DataNodes[0].Items)+len(tree.root.DataNodes[1].Items)) {
+		tree.width > (len(tree.root.DataNodes[0].Items)+len(tree.root.DataNodes[1].Items)) {
 
 		// Create a new BpIndex node.
 		node := &BpIndex{}
@@ -188,6 +321,9 @@ func (tree *BpTree) RemoveValue(item BpItem) (deleted, updated bool, ix int, err
 
 		// Replace the original root node with the new node.
 		*tree.root = *node
+		if trace := tree.trace; trace != nil {
+			trace.printf("the two data nodes of the root hold less than the width %d together, they merge into one data node %v", tree.width, node.DataNodes[0].keys())
+		}
 	}
 
 	// Performing a return.
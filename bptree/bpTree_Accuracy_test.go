@@ -12,19 +12,37 @@ package bpTree
 //
 // cd /home/panhong/go/src/github.com/panhongrainbow/go-algorithm/bptree
 // go clean -cache
-// go test -v . -timeout=0 -run Test_Check_BpTree_Accuracies
+// go test -v . -timeout=0 -tags heavy -run Test_Check_BpTree_Accuracies
+//
+// Without the heavy tag, the light preset in config/DefaultConfig.json is used, so a plain go test ./... finishes quickly,
+// and go test -short skips the accuracy test altogether.
+// A smaller or larger workload is chosen with a preset, and some of the modes with suites, such as:
+//
+// go test -v . -timeout=0 -run Test_Check_BpTree_Accuracies -preset=small -suites=mode1,mode3
 
 // =====================================================================================================================
 
 import (
+	"flag"
+	"fmt"
 	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/panhongrainbow/go-algorithm/utilhub"
 	"github.com/stretchr/testify/require"
 )
 
 var (
+	// 🧪 Choose the workload size by the name of a preset, the parameters in the config are used without it.
+	testPreset = flag.String("preset", "", "the workload preset in config/DefaultConfig.json: "+strings.Join(utilhub.PresetNames(), ", "))
+
+	// 🧪 Choose the modes to run, such as mode1,mode3, the suites in the config are used without it.
+	testSuites = flag.String("suites", "", "the accuracy suites to run, such as mode1,mode3, empty runs the suites in the config")
+
 	// 🧪 Create a config instance for B plus tree unit testing and parse default values.
 	unitTestConfig = utilhub.GetDefaultConfig()
 
@@ -33,6 +51,9 @@ var (
 
 	// 🧪 Create a subdirectory named with the current date under the project.
 	recordDir = ProjectDir.MkDir(_TestTimeString("2006-01-02", "Asia/Shanghai"))
+
+	// 🧪 Catch Ctrl+C and SIGTERM during the accuracy test, so the running mode stops and its bar is marked as interrupted.
+	accuracyShutdown *utilhub.ShutdownHandler
 )
 
 // _TestTimeString gets the current time as a formatted string in the given time zone.
@@ -49,6 +70,43 @@ func _TestTimeString(format string, timeZone string) string {
 
 // Test_Check_BpTree_Accuracy 🧫 checks if the tree resets after bulk insert/delete, ensuring indexing correctness.
 func Test_Check_BpTree_Accuracies(t *testing.T) {
+	// The accuracy test is the heaviest test of the repository, so the short mode leaves it out.
+	if testing.Short() {
+		t.Skip("the accuracy test is skipped in short mode, run it with -tags heavy for the full workload")
+	}
+
+	// Replace the test size parameters with the preset, before any test data is generated.
+	// Without the heavy tag, the light preset keeps a plain go test ./... quick.
+	preset := *testPreset
+	if preset == "" && !heavyBuild {
+		preset = unitTestConfig.Parameters.LightPreset
+	}
+	if preset != "" {
+		require.NoError(t, utilhub.UsePreset(preset))
+		unitTestConfig = utilhub.GetDefaultConfig()
+		t.Logf("preset %s: %d operations, widths %v, verify every %d operations", preset,
+			unitTestConfig.Parameters.RandomTotalCount, unitTestConfig.Parameters.BpWidth, unitTestConfig.Parameters.VerifyEvery)
+	}
+
+	// The flag wins over the suites in the config.
+	suites := utilhub.ParseSuites(unitTestConfig.Parameters.Suites)
+	if *testSuites != "" {
+		suites = utilhub.ParseSuites(*testSuites)
+	}
+	t.Logf("suites: %s", suites)
+
+	// Keep another run out of the record directory, since both would write the same record files.
+	recordLock, err := recordDir.Lock(0)
+	require.NoError(t, err, "another accuracy test is using the record directory")
+	defer func() { _ = recordLock.Unlock() }()
+
+	// Upload the reports and the logs into the object store set in the config, once every mode has finished.
+	uploadRecords(t)
+
+	// Stop the modes in order on a signal, and give the signals back when the test ends normally.
+	accuracyShutdown = utilhub.NewShutdownHandler(0, nil)
+	defer accuracyShutdown.Stop()
+
 	t.Run("Pre-test checks", func(t *testing.T) {
 		// Record path must not be empty.
 		require.NotEqual(t, "", ProjectDir.Path(), "record path is empty; check path creation")
@@ -73,6 +131,10 @@ func Test_Check_BpTree_Accuracies(t *testing.T) {
 	*/
 
 	t.Run("Mode 1: Bulk Insert/Delete", func(t *testing.T) {
+		if !suites.Selected("mode1") {
+			t.Skip("mode1 is not among the suites")
+		}
+
 		// Prepare test data for mode 1.
 		prepareMode1(t)
 
@@ -84,6 +146,10 @@ func Test_Check_BpTree_Accuracies(t *testing.T) {
 	})
 
 	t.Run("Mode 2: Randomized Boundary Test", func(t *testing.T) {
+		if !suites.Selected("mode2") {
+			t.Skip("mode2 is not among the suites")
+		}
+
 		// Prepare test data for mode 2.
 		prepareMode2(t)
 
@@ -95,6 +161,10 @@ func Test_Check_BpTree_Accuracies(t *testing.T) {
 	})
 
 	t.Run("Mode 3: Single Node Endurance Test", func(t *testing.T) {
+		if !suites.Selected("mode3") {
+			t.Skip("mode3 is not among the suites")
+		}
+
 		// Prepare test data for mode 3.
 		prepareMode3(t)
 
@@ -117,3 +187,164 @@ func shuffleSlice(slice []int64, rng *rand.Rand) {
 		slice[i], slice[j] = slice[j], slice[i]
 	}
 }
+
+// configPalette 🧫 returns the palette of the progress bars and the reports selected in the config.
+func configPalette(t *testing.T) utilhub.Palette {
+	palette, err := utilhub.PaletteByName(unitTestConfig.Display.Palette)
+	require.NoError(t, err)
+	return palette
+}
+
+// failBarOnExit 🧫 fails the progress bar when the test fails, so the bar does not freeze and the report is still printed.
+// It is deferred right after the bar is created, and does nothing when the bar has been completed.
+func failBarOnExit(t *testing.T, progressBar *utilhub.ProgressBar, valueWidth int) {
+	if !t.Failed() || !progressBar.Snapshot().EndTime.IsZero() {
+		return
+	}
+	progressBar.Fail(fmt.Errorf("%s failed", t.Name()))
+	<-progressBar.WaitForPrinterStop()
+	_ = progressBar.Report(valueWidth)
+}
+
+// attachOnCI 🧫 sends the output of the progress bar through t.Log when the test runs under CI, where CI is set,
+// so the lines show with -v or on a failure, while a terminal still gets the bar redrawn in place.
+func attachOnCI(t *testing.T, progressBar *utilhub.ProgressBar) {
+	if os.Getenv("CI") != "" {
+		progressBar.AttachTesting(t)
+	}
+}
+
+// archiveReport 🧫 writes the JSON report of the progress bar into the record directory, such as mode1_width3.report.json.
+func archiveReport(t *testing.T, progressBar *utilhub.ProgressBar, name string) {
+	file, err := recordDir.Create(name + ".report" + utilhub.ReportJSON.Extension())
+	require.NoError(t, err)
+	defer func() { require.NoError(t, file.Close()) }()
+	require.NoError(t, progressBar.WriteReport(file, utilhub.ReportJSON))
+}
+
+// uploadRecords 🧫 uploads the record files matching the patterns in the config into the object store when the test ends,
+// under the same dated directory, such as test_record/2025-01-02/mode1.report.json. Without an endpoint, nothing is uploaded.
+func uploadRecords(t *testing.T) {
+	objects, err := utilhub.ConfigObjectFS()
+	require.NoError(t, err)
+	if objects == nil {
+		return
+	}
+	t.Cleanup(func() {
+		uploaded, err := recordDir.Upload(objects, filepath.Base(recordDir.Path()), utilhub.UploadPatterns()...)
+		require.NoError(t, err, "uploading the records into the object store")
+		t.Logf("uploaded %d record files into the object store", len(uploaded))
+	})
+}
+
+// openModeLog 🧫 opens the log file of the mode in the record directory, such as mode1.log, at the level set in the config.
+// The detailed log goes there, so stdout is left to the progress bars. It is closed when the test ends.
+func openModeLog(t *testing.T, mode string) *utilhub.RecordLog {
+	level, err := utilhub.ParseLogLevel(unitTestConfig.Record.LogLevel)
+	require.NoError(t, err)
+	log, err := recordDir.OpenLog(mode, level)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = log.Close() })
+	return log
+}
+
+// configDiskGuard 🧫 returns the guard of the free space on the record filesystem set in the config, nil checks nothing.
+func configDiskGuard() *utilhub.DiskGuard {
+	if unitTestConfig.Record.MinFreeMB < 0 {
+		return nil
+	}
+	return utilhub.NewDiskGuard(recordDir.Path(), uint64(unitTestConfig.Record.MinFreeMB)<<20,
+		utilhub.WithLowSpaceWait(time.Duration(unitTestConfig.Record.LowSpaceWait)*time.Second, 5*time.Second))
+}
+
+// configBarStyle 🧫 returns the look of the progress bars selected in the config.
+func configBarStyle(t *testing.T) utilhub.BarStyle {
+	style, err := utilhub.BarStyleByName(unitTestConfig.Display.BarStyle)
+	require.NoError(t, err)
+	return style
+}
+
+// configNumberFormat 🧫 returns the number format of the progress bars and the reports selected in the config.
+func configNumberFormat(t *testing.T) utilhub.NumberFormat {
+	format, err := utilhub.NumberFormatByLocale(unitTestConfig.Display.NumberLocale)
+	require.NoError(t, err)
+	return format
+}
+
+// releasePhaseMemory 🧫 collects the garbage left by the previous phase in the mode set in the config,
+// so the heap of the prepare phase does not skew the run. The release is false when it is turned off.
+func releasePhaseMemory(t *testing.T, phase string) (release utilhub.MemoryRelease, released bool) {
+	mode, err := utilhub.ParseReleaseMode(unitTestConfig.Parameters.ReleaseMemory)
+	require.NoError(t, err)
+	return utilhub.ReleaseMemory(phase, mode)
+}
+
+// verifyDue 🧫 reports whether the whole tree should be checked after this many operations.
+func verifyDue(operations int64) bool {
+	every := unitTestConfig.Parameters.VerifyEvery
+	return every > 0 && operations%every == 0
+}
+
+// verifyTree 🧫 checks the whole tree, so a broken index is found near where it happens.
+// The keys must be in order, and their number must match the items which are still in the tree.
+func verifyTree(t *testing.T, root *BpTree, operations, items int64) {
+	var keys []int64
+	for it := root.Iterator(); it.Next(); {
+		keys = append(keys, it.Key())
+	}
+	require.Len(t, keys, int(items), "after %d operations", operations)
+	require.IsNonDecreasing(t, keys, "after %d operations", operations)
+}
+
+// phaseTimer 🧫 times every run of the same operation and feeds it to the progress bar as a checkpoint of its phase,
+// so the report shows the insert and the delete throughput separately. The phase is also shown as the stage of the bar.
+type phaseTimer struct {
+	progressBar *utilhub.ProgressBar // The progress bar receiving the checkpoints.
+	phase       string               // The phase of the current run, empty when no run is timed.
+	steps       uint64               // The operations of the current run.
+	start       time.Time            // When the current run started.
+}
+
+// step 🧫 counts one operation of the phase, and starts a new run when the phase changes.
+func (timer *phaseTimer) step(phase string) {
+	if phase != timer.phase {
+		timer.flush()
+		timer.phase, timer.start = phase, time.Now()
+		timer.progressBar.SetStage(strings.ToLower(phase))
+	}
+	timer.steps++
+}
+
+// flush 🧫 sends the current run as a checkpoint, it is also called before waiting or verifying, so that time is not counted.
+func (timer *phaseTimer) flush() {
+	if timer.steps > 0 {
+		timer.progressBar.Checkpoint(timer.phase, timer.steps, time.Since(timer.start))
+	}
+	timer.phase, timer.steps = "", 0
+}
+
+// opTimer 🧫 times every single operation and keeps the slowest ones with the tree height, when the config turns it on.
+type opTimer struct {
+	slowOps *utilhub.SlowOps // The slowest operations, nil when the operations are not timed.
+	root    *BpTree          // The tree whose height is recorded.
+	start   time.Time        // When the current operation started.
+}
+
+// newOpTimer 🧫 keeps the slowest operations set in the config, and the progress bar reports them with WithSlowOps.
+func newOpTimer(root *BpTree) *opTimer {
+	return &opTimer{slowOps: utilhub.NewSlowOps(int(unitTestConfig.Parameters.SlowestOps)), root: root}
+}
+
+// begin 🧫 starts timing an operation, it costs nothing when the operations are not timed.
+func (timer *opTimer) begin() {
+	if timer.slowOps != nil {
+		timer.start = time.Now()
+	}
+}
+
+// end 🧫 records the operation on the key, with the height of the tree right after it.
+func (timer *opTimer) end(op string, key int64) {
+	if timer.slowOps != nil {
+		timer.slowOps.Observe(op, key, timer.root.Height(), time.Since(timer.start))
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !heavy
+
+package bpTree
+
+// heavyBuild 🧫 is false without the heavy tag, so a plain go test ./... runs the accuracy test with the light preset.
+const heavyBuild = false
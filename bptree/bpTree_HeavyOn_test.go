@@ -0,0 +1,7 @@
+//go:build heavy
+
+package bpTree
+
+// heavyBuild 🧫 is true when the tests are built with the heavy tag, go test -tags heavy,
+// so the accuracy test runs the full workload of the config instead of the light preset.
+const heavyBuild = true
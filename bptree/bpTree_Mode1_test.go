@@ -22,6 +22,7 @@ import (
 
 // prepareMode1 🧫 prepares test data for Mode 1.
 func prepareMode1(t *testing.T) {
+	log := openModeLog(t, "mode1")
 
 	// === Init test model and record file ===
 
@@ -37,6 +38,7 @@ func prepareMode1(t *testing.T) {
 	// Generate a random set: half positive, half negative.
 	testDataSet, err := bptest1.GenerateRandomSet(uint64(unitTestConfig.Parameters.RandomMin), uint64(unitTestConfig.Parameters.RandomHitCollisionPercentage))
 	require.NoError(t, err, "failed to generate test data")
+	log.Info("test data generated", "operations", len(testDataSet))
 
 	// === Set write parameters ===
 
@@ -55,8 +57,10 @@ func prepareMode1(t *testing.T) {
 		"Mode 1: Bulk Insert/Delete - Backup",
 		utilhub.BrightCyan,
 		70,
+		configDiskGuard(),
 	)
 	require.NoError(t, err)
+	log.Info("test data written", "file", "mode1.do_not_open")
 
 	// Data check is done in the next test case.
 }
@@ -65,7 +69,7 @@ func prepareMode1(t *testing.T) {
 func verifyMode1(t *testing.T) {
 	// Read test data with progress bar.
 	testDataSet, err := recordDir.ReadAllBytesWithProgress(
-		uint32(unitTestConfig.Parameters.RandomTotalCount),
+		uint64(unitTestConfig.Parameters.RandomTotalCount),
 		"mode1.do_not_open", 800,
 		binary.LittleEndian,
 		"Mode 1: Bulk Insert/Delete - read test data",
@@ -79,11 +83,12 @@ func verifyMode1(t *testing.T) {
 	// Validate test data.
 	err = bptest1.CheckRandomSet(testDataSet)
 	require.NoError(t, err, "failed to validate test data")
+	openModeLog(t, "mode1").Info("test data validated", "operations", len(testDataSet))
 }
 
 // runMode1 🧫 runs the actual test cases for Mode 1.
 func runMode1(t *testing.T) {
-	for bpWidth := 0; bpWidth < len(unitTestConfig.Parameters.BpWidth); bpWidth++ {
+	for bpWidth := 0; bpWidth < len(unitTestConfig.Parameters.BpWidth) && !accuracyShutdown.Interrupted(); bpWidth++ {
 		_runMode1(t, bpWidth)
 	}
 }
@@ -92,7 +97,14 @@ func runMode1(t *testing.T) {
 func _runMode1(t *testing.T, bpWidth int) {
 	dtatChan, errChan, finsishChan := recordDir.ReadBytesInChunksWithProgress("mode1.do_not_open", 8, binary.LittleEndian)
 
+	log := openModeLog(t, "mode1")
+	log.Info("run started", "width", unitTestConfig.Parameters.BpWidth[bpWidth])
+
+	// Collect the garbage of the prepare phase and of the previous width before the tree is built.
+	release, released := releasePhaseMemory(t, "before run")
+
 	root := NewBpTree(unitTestConfig.Parameters.BpWidth[bpWidth])
+	ops := newOpTimer(root)
 
 	// testMode1Name := "Mode 1: Execution; Width: " + strconv.Itoa(unitTestConfig.Parameters.BpWidth[bpWidth])
 	testMode1Name := fmt.Sprintf("Mode 1: Bulk Insert/Delete - run; Width: %3d", unitTestConfig.Parameters.BpWidth[bpWidth])
@@ -101,18 +113,42 @@ func _runMode1(t *testing.T, bpWidth int) {
 	progressBar, _ := utilhub.NewProgressBar(
 		testMode1Name,
 		// "Mode 1: Execution   ",                             // Progress bar title.
-		uint32(unitTestConfig.Parameters.RandomTotalCount), // Total number of operations.
-		70,                                       // Progress bar width.
-		utilhub.WithTracking(5),                  // Update interval.
-		utilhub.WithTimeZone("Asia/Taipei"),      // Time zone.
-		utilhub.WithTimeControl(500),             // Update interval in milliseconds.
-		utilhub.WithDisplay(utilhub.BrightGreen), // Display style.
+		uint64(unitTestConfig.Parameters.RandomTotalCount), // Total number of operations.
+		70,                                              // Progress bar width.
+		utilhub.WithTracking(5),                         // Update interval.
+		utilhub.WithTimeZone("Asia/Taipei"),             // Time zone.
+		utilhub.WithTimeControl(500),                    // Update interval in milliseconds.
+		utilhub.WithElapsed(true),                       // Running elapsed time after the percentage.
+		utilhub.WithDisplay(utilhub.BrightGreen),        // Display style.
+		utilhub.WithPalette(configPalette(t)),           // Colors selected in the config.
+		utilhub.WithStyle(configBarStyle(t)),            // Look of the bar selected in the config.
+		utilhub.WithNumberFormat(configNumberFormat(t)), // Thousands separators selected in the config.
+		utilhub.WithSlowOps(ops.slowOps),                // Slowest operations, when the config times them.
 	)
 
+	if released {
+		progressBar.AddMemoryRelease(release)
+		log.Debug("memory released", "phase", release.Phase, "heapBefore", release.HeapBefore, "heapAfter", release.HeapAfter, "duration", release.Duration)
+	}
+
+	// ▓▒░ Under CI, the progress bar logs through the test, so the go test output stays well-formed.
+	attachOnCI(t, progressBar)
+
 	// ▓▒░ Start the progress bar printer in a separate goroutine.
-	go func() {
-		progressBar.ListenPrinter()
-	}()
+	progressBar.Start()
+	// Tear the progress bar down last, so the printer never outlives this mode, even when it stops early.
+	defer func() { _ = progressBar.Close() }()
+
+	// ▓▒░ The shutdown waits for this mode to stop, and then marks the progress bar as interrupted.
+	accuracyShutdown.TrackBar(progressBar)
+	defer accuracyShutdown.Worker()()
+	defer failBarOnExit(t, progressBar, len(testMode1Name+"; Width: XX"))
+
+	// Count the operations and the items still in the tree for the periodic verification.
+	var operations, items int64
+
+	// Time the insert and the delete phases separately for the report.
+	phases := &phaseTimer{progressBar: progressBar}
 
 Loop:
 	for {
@@ -120,25 +156,45 @@ Loop:
 		case data := <-dtatChan:
 			for j := 0; j < len(data); j++ {
 				if data[j] >= 0 {
+					phases.step("Insert")
+					ops.begin()
 					root.InsertValue(BpItem{Key: data[j]})
+					ops.end("Insert", data[j])
 					progressBar.UpdateBar()
+					items++
 				}
 				if data[j] < 0 {
+					phases.step("Delete")
+					ops.begin()
 					deleted, _, _, err := root.RemoveValue(BpItem{Key: -1 * data[j]})
+					ops.end("Delete", -1*data[j])
 					require.True(t, deleted)
 					require.NoError(t, err)
 					progressBar.UpdateBar()
+					items--
+				}
+				operations++
+				if verifyDue(operations) {
+					phases.flush()
+					progressBar.SetStage("verify")
+					verifyTree(t, root, operations, items)
+					log.Debug("tree verified", "operations", operations, "items", items)
 				}
 			}
+			phases.flush()
 		case err := <-errChan:
-			fmt.Println(err)
+			log.Warn("failed to read test data", "error", err)
 		case <-finsishChan:
 			break Loop
+		case <-accuracyShutdown.Context().Done():
+			log.Warn("run interrupted", "operations", operations, "items", items)
+			return
 		}
 	}
 
 	// ▓▒░ Mark the progress bar as complete.
 	progressBar.Complete()
+	log.Info("run finished", "operations", operations, "items", items, "height", root.Height(), "elapsed", progressBar.Snapshot().Elapsed)
 
 	// ▓▒░ Wait for the progress bar printer to stop.
 	<-progressBar.WaitForPrinterStop()
@@ -146,6 +202,7 @@ Loop:
 	// Print a final report.
 	err := progressBar.Report(len(testMode1Name + "; Width: XX"))
 	assert.NoError(t, err)
+	archiveReport(t, progressBar, fmt.Sprintf("mode1_width%d", unitTestConfig.Parameters.BpWidth[bpWidth]))
 
 	// Print the B Plus tree structure.
 	root.root.Print()
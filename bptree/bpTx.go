@@ -0,0 +1,337 @@
+package bpTree
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+)
+
+// ➡️ transaction operation
+
+// The errors returned by the transactions.
+var (
+	ErrTxClosed      = errors.New("transaction has already been committed or rolled back")
+	ErrTxConflict    = errors.New("transaction conflicts with a concurrent write")
+	ErrTxNotFound    = errors.New("transaction removes a key which does not exist")
+	ErrTxNoSavepoint = errors.New("transaction has no such savepoint")
+)
+
+// The kinds of operations buffered by a transaction.
+const (
+	txInsert = iota + 1
+	txRemove
+)
+
+// txOp is an operation buffered by a transaction.
+type txOp struct {
+	kind int    // txInsert or txRemove.
+	item BpItem // The item to be inserted or removed.
+}
+
+// savepoint marks how many operations had been buffered when it was created.
+type savepoint struct {
+	name string // The name of the savepoint.
+	ops  int    // The number of buffered operations at the savepoint.
+}
+
+// journalEntry records a write made while transactions are open.
+type journalEntry struct {
+	version uint64 // The version of the tree after the write.
+	key     int64  // The key that has been written.
+}
+
+// BpTx is a transaction that buffers inserts and removes and applies them atomically on Commit.
+// Nothing is written to the tree before Commit, and the whole commit runs under the tree lock,
+// so the readers either see all the operations or none of them. (全部成功或全部失败)
+// A transaction is not safe for concurrent use by multiple goroutines.
+// A transaction dropped without Commit or Rollback is rolled back when it is garbage collected,
+// so it does not keep the journal of the tree growing forever.
+type BpTx struct {
+	tree         *BpTree            // The tree the transaction belongs to.
+	id           uint64             // The id of the transaction among the open ones of the tree.
+	ops          []txOp             // The buffered operations in order.
+	startVersion uint64             // The version of the tree when the transaction began.
+	done         bool               // Indicates whether the transaction has been committed or rolled back.
+	keys         map[int64]struct{} // The keys written by the transaction, used to detect conflicts.
+	savepoints   []savepoint        // The savepoints in the order they were created.
+}
+
+// Begin starts a new transaction on the tree.
+func (tree *BpTree) Begin() *BpTx {
+	// Acquire a lock to ensure thread safety.
+	tree.mutex.Lock()
+
+	// Release the lock to allow other threads to access the tree.
+	defer tree.mutex.Unlock()
+
+	// From now on, the writes are recorded in the journal.
+	tree.txSeq++
+	if tree.openTx == nil {
+		tree.openTx = make(map[uint64]uint64)
+	}
+	tree.openTx[tree.txSeq] = tree.version
+
+	tx := &BpTx{
+		tree:         tree,
+		id:           tree.txSeq,
+		startVersion: tree.version,
+		keys:         make(map[int64]struct{}),
+	}
+
+	// Roll back an abandoned transaction, the tree does not keep it alive. (回收被遗弃的事务)
+	runtime.SetFinalizer(tx, (*BpTx).Rollback)
+
+	return tx
+}
+
+// InsertValue buffers an insert in the transaction.
+func (tx *BpTx) InsertValue(item BpItem) error {
+	return tx.buffer(txInsert, item)
+}
+
+// RemoveValue buffers a remove in the transaction.
+func (tx *BpTx) RemoveValue(item BpItem) error {
+	return tx.buffer(txRemove, item)
+}
+
+// buffer appends an operation to the transaction.
+func (tx *BpTx) buffer(kind int, item BpItem) error {
+	// The transaction cannot be used after it is closed.
+	if tx.done {
+		return ErrTxClosed
+	}
+
+	tx.ops = append(tx.ops, txOp{kind: kind, item: item})
+	tx.keys[item.Key] = struct{}{}
+
+	return nil
+}
+
+// Savepoint creates a named savepoint at the current position of the transaction.
+// When the name already exists, the old savepoint is replaced by the new one.
+func (tx *BpTx) Savepoint(name string) error {
+	// The transaction cannot be used after it is closed.
+	if tx.done {
+		return ErrTxClosed
+	}
+
+	// Replace the savepoint with the same name.
+	if ix := tx.findSavepoint(name); ix >= 0 {
+		tx.savepoints = append(tx.savepoints[:ix], tx.savepoints[ix+1:]...)
+	}
+	tx.savepoints = append(tx.savepoints, savepoint{name: name, ops: len(tx.ops)})
+
+	return nil
+}
+
+// RollbackTo discards the operations buffered after the named savepoint.
+// The savepoint itself is kept, but the savepoints created after it are removed.
+func (tx *BpTx) RollbackTo(name string) error {
+	// The transaction cannot be used after it is closed.
+	if tx.done {
+		return ErrTxClosed
+	}
+
+	// Find the savepoint.
+	ix := tx.findSavepoint(name)
+	if ix < 0 {
+		return fmt.Errorf("%w: %s", ErrTxNoSavepoint, name)
+	}
+
+	// Discard the operations and the later savepoints.
+	tx.ops = tx.ops[:tx.savepoints[ix].ops]
+	tx.savepoints = tx.savepoints[:ix+1]
+
+	// Rebuild the written keys, the discarded keys must not cause conflicts anymore.
+	tx.keys = make(map[int64]struct{}, len(tx.ops))
+	for _, op := range tx.ops {
+		tx.keys[op.item.Key] = struct{}{}
+	}
+
+	return nil
+}
+
+// Release removes the named savepoint and the savepoints created after it, keeping all operations.
+func (tx *BpTx) Release(name string) error {
+	// The transaction cannot be used after it is closed.
+	if tx.done {
+		return ErrTxClosed
+	}
+
+	// Find the savepoint.
+	ix := tx.findSavepoint(name)
+	if ix < 0 {
+		return fmt.Errorf("%w: %s", ErrTxNoSavepoint, name)
+	}
+
+	// Remove it and the later ones.
+	tx.savepoints = tx.savepoints[:ix]
+
+	return nil
+}
+
+// findSavepoint returns the position of the named savepoint, or -1 if it does not exist.
+func (tx *BpTx) findSavepoint(name string) int {
+	for i := len(tx.savepoints) - 1; i >= 0; i-- {
+		if tx.savepoints[i].name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Commit applies all buffered operations atomically.
+// It fails without changing anything when another write touched the same keys after Begin,
+// when a remove refers to a key that does not exist at that point of the transaction,
+// or when an insert is rejected by a validator.
+func (tx *BpTx) Commit() (err error) {
+	// The transaction cannot be committed twice.
+	if tx.done {
+		return ErrTxClosed
+	}
+	tx.done = true
+
+	tree := tx.tree
+	var applied []txOp
+
+	// Acquire a lock to ensure thread safety.
+	tree.mutex.Lock()
+	turn := tree.notifyTurn()
+
+	// Notify the watchers after the lock is released, in the order of the writes. (defer 后进先出，解锁后才通知)
+	defer func() {
+		changes := make([]ChangeEvent, 0, len(applied))
+		for _, op := range applied {
+			if op.kind == txInsert {
+				changes = append(changes, ChangeEvent{Kind: ChangeInsert, Item: op.item})
+			} else {
+				changes = append(changes, ChangeEvent{Kind: ChangeDelete, Item: op.item})
+			}
+		}
+		tree.notifyWatchers(turn, changes...)
+	}()
+
+	// Release the lock to allow other threads to access the tree.
+	defer tree.mutex.Unlock()
+
+	// The transaction is no longer open after the commit.
+	defer tree.finishTx(tx)
+
+	// Check whether other writes touched the same keys after Begin.
+	for _, entry := range tree.journal {
+		if _, exists := tx.keys[entry.key]; entry.version > tx.startVersion && exists {
+			return fmt.Errorf("%w: key %d", ErrTxConflict, entry.key)
+		}
+	}
+
+	// Check that every remove has something to remove, taking the earlier operations into account.
+	available := make(map[int64]int)
+	for _, op := range tx.ops {
+		if _, exists := available[op.item.Key]; !exists {
+			if _, found := tree.root.search(op.item.Key); found {
+				available[op.item.Key] = 1
+			} else {
+				available[op.item.Key] = 0
+			}
+		}
+		if op.kind == txInsert {
+			available[op.item.Key]++
+		} else if available[op.item.Key]--; available[op.item.Key] < 0 {
+			return fmt.Errorf("%w: key %d", ErrTxNotFound, op.item.Key)
+		}
+	}
+
+	// Apply the operations, and undo the applied ones if anything goes wrong.
+	version, entries := tree.version, len(tree.journal)
+	for _, op := range tx.ops {
+		if op.kind == txInsert {
+			// The constraints are checked against the tree with the earlier operations applied.
+			if err = tree.validate(op.item); err != nil {
+				tree.undo(applied, version, entries)
+				applied = nil
+				return err
+			}
+			tree.insert(op.item)
+			applied = append(applied, op)
+			continue
+		}
+
+		// Keep the removed item, so it can be inserted back when undoing.
+		removed, _ := tree.root.search(op.item.Key)
+		deleted, _, _, removeErr := tree.remove(op.item)
+		if !deleted || removeErr != nil {
+			tree.undo(applied, version, entries)
+			applied = nil
+			if removeErr == nil {
+				removeErr = fmt.Errorf("%w: key %d", ErrTxNotFound, op.item.Key)
+			}
+			return removeErr
+		}
+		applied = append(applied, txOp{kind: txRemove, item: removed})
+	}
+
+	return nil
+}
+
+// Rollback discards all buffered operations.
+func (tx *BpTx) Rollback() error {
+	// The transaction cannot be rolled back after it is closed.
+	if tx.done {
+		return ErrTxClosed
+	}
+	tx.done = true
+	tx.ops = nil
+
+	// Acquire a lock to ensure thread safety.
+	tx.tree.mutex.Lock()
+
+	// Release the lock to allow other threads to access the tree.
+	defer tx.tree.mutex.Unlock()
+
+	// The transaction is no longer open.
+	tx.tree.finishTx(tx)
+
+	return nil
+}
+
+// undo reverts the applied operations in reverse order, the lock must be held by the caller.
+// The version and the journal go back to the given ones, taken before the operations were applied,
+// so the writes which never took effect do not cause conflicts in the other open transactions.
+func (tree *BpTree) undo(applied []txOp, version uint64, entries int) {
+	for i := len(applied) - 1; i >= 0; i-- {
+		if applied[i].kind == txInsert {
+			_, _, _, _ = tree.remove(applied[i].item)
+		} else {
+			tree.insert(applied[i].item)
+		}
+	}
+	tree.version, tree.journal = version, tree.journal[:entries]
+}
+
+// recordWrite increases the version and records the key while transactions are open.
+// The lock must be held by the caller.
+func (tree *BpTree) recordWrite(key int64) {
+	tree.version++
+	if len(tree.openTx) > 0 {
+		tree.journal = append(tree.journal, journalEntry{version: tree.version, key: key})
+	}
+}
+
+// finishTx closes a transaction, and drops the writes no open transaction can conflict with anymore,
+// which are the ones up to the oldest start version. The lock must be held by the caller.
+func (tree *BpTree) finishTx(tx *BpTx) {
+	runtime.SetFinalizer(tx, nil)
+	delete(tree.openTx, tx.id)
+	if len(tree.openTx) == 0 {
+		tree.journal = nil
+		return
+	}
+	oldest := tree.version
+	for _, start := range tree.openTx {
+		oldest = min(oldest, start)
+	}
+	tree.journal = slices.DeleteFunc(tree.journal, func(entry journalEntry) bool {
+		return entry.version <= oldest
+	})
+}
@@ -0,0 +1,293 @@
+package bpTree
+
+import (
+	"math/rand"
+	"runtime"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// collectKeys 🧫 returns all keys of the tree in ascending order.
+func collectKeys(tree *BpTree) (keys []int64) {
+	tree.mutex.Lock()
+	defer tree.mutex.Unlock()
+	for _, data := range tree.root.dataNodes() {
+		for _, item := range data.Items {
+			keys = append(keys, item.Key)
+		}
+	}
+	return
+}
+
+// Test_Check_BpTree_Transaction 🧫 checks that the transactions are applied all-or-nothing.
+func Test_Check_BpTree_Transaction(t *testing.T) {
+	t.Run("Nothing is visible before commit", func(t *testing.T) {
+		tree := NewBpTree(3)
+		tx := tree.Begin()
+		for key := int64(1); key <= 50; key++ {
+			require.NoError(t, tx.InsertValue(BpItem{Key: key}))
+		}
+
+		// The tree is still empty before commit.
+		require.Empty(t, collectKeys(tree))
+
+		require.NoError(t, tx.Commit())
+		require.Len(t, collectKeys(tree), 50)
+	})
+
+	t.Run("Rollback discards everything", func(t *testing.T) {
+		tree := NewBpTree(3)
+		tree.InsertValue(BpItem{Key: 1})
+
+		tx := tree.Begin()
+		require.NoError(t, tx.InsertValue(BpItem{Key: 2}))
+		require.NoError(t, tx.RemoveValue(BpItem{Key: 1}))
+		require.NoError(t, tx.Rollback())
+
+		require.Equal(t, []int64{1}, collectKeys(tree))
+
+		// The closed transaction cannot be used anymore.
+		require.ErrorIs(t, tx.InsertValue(BpItem{Key: 3}), ErrTxClosed)
+		require.ErrorIs(t, tx.Commit(), ErrTxClosed)
+		require.ErrorIs(t, tx.Rollback(), ErrTxClosed)
+	})
+
+	t.Run("A failed remove leaves the tree untouched", func(t *testing.T) {
+		tree := NewBpTree(4)
+		for key := int64(1); key <= 20; key++ {
+			tree.InsertValue(BpItem{Key: key})
+		}
+		before := collectKeys(tree)
+
+		tx := tree.Begin()
+		for key := int64(21); key <= 40; key++ {
+			require.NoError(t, tx.InsertValue(BpItem{Key: key}))
+		}
+		require.NoError(t, tx.RemoveValue(BpItem{Key: 5}))
+		require.NoError(t, tx.RemoveValue(BpItem{Key: 5})) // The key is already removed by the transaction itself.
+
+		require.ErrorIs(t, tx.Commit(), ErrTxNotFound)
+		require.Equal(t, before, collectKeys(tree))
+	})
+
+	t.Run("Inserted and removed inside the same transaction", func(t *testing.T) {
+		tree := NewBpTree(4)
+		tx := tree.Begin()
+		require.NoError(t, tx.InsertValue(BpItem{Key: 7}))
+		require.NoError(t, tx.RemoveValue(BpItem{Key: 7}))
+		require.NoError(t, tx.InsertValue(BpItem{Key: 8}))
+		require.NoError(t, tx.Commit())
+
+		require.Equal(t, []int64{8}, collectKeys(tree))
+	})
+
+	t.Run("Conflict with a concurrent write", func(t *testing.T) {
+		tree := NewBpTree(4)
+		txA := tree.Begin()
+		txB := tree.Begin()
+
+		require.NoError(t, txA.InsertValue(BpItem{Key: 10}))
+		require.NoError(t, txB.InsertValue(BpItem{Key: 10}))
+		require.NoError(t, txB.InsertValue(BpItem{Key: 11}))
+
+		// The first commit wins, and the second one touches the same key.
+		require.NoError(t, txA.Commit())
+		require.ErrorIs(t, txB.Commit(), ErrTxConflict)
+		require.Equal(t, []int64{10}, collectKeys(tree))
+
+		// A write outside the transactions also conflicts.
+		txC := tree.Begin()
+		require.NoError(t, txC.RemoveValue(BpItem{Key: 10}))
+		tree.InsertValue(BpItem{Key: 10, Val: "outside"})
+		require.ErrorIs(t, txC.Commit(), ErrTxConflict)
+
+		// Writes on other keys do not conflict.
+		txD := tree.Begin()
+		require.NoError(t, txD.InsertValue(BpItem{Key: 20}))
+		tree.InsertValue(BpItem{Key: 30})
+		require.NoError(t, txD.Commit())
+
+		// The journal is cleared after all transactions are closed.
+		require.Empty(t, tree.openTx)
+		require.Nil(t, tree.journal)
+	})
+
+	t.Run("The journal keeps only the writes of the open transactions", func(t *testing.T) {
+		tree := NewBpTree(4)
+		txA := tree.Begin()
+		tree.InsertValue(BpItem{Key: 1})
+		txB := tree.Begin()
+		tree.InsertValue(BpItem{Key: 2})
+		require.Len(t, tree.journal, 2)
+
+		// Closing the oldest transaction drops the writes made before the next one began.
+		require.NoError(t, txA.Rollback())
+		require.Equal(t, []journalEntry{{version: tree.version, key: 2}}, tree.journal)
+		require.NoError(t, txB.Rollback())
+		require.Nil(t, tree.journal)
+	})
+
+	t.Run("A failed commit does not conflict with the other transactions", func(t *testing.T) {
+		tree := NewBpTree(4)
+		tree.AddValidator(KeyRange(0, 100))
+		version := tree.version
+
+		// The first insert is applied and undone, when the second one is rejected.
+		txA := tree.Begin()
+		txB := tree.Begin()
+		require.NoError(t, txA.InsertValue(BpItem{Key: 5}))
+		require.NoError(t, txA.InsertValue(BpItem{Key: 500}))
+		require.NoError(t, txB.InsertValue(BpItem{Key: 5}))
+		require.Error(t, txA.Commit())
+		require.Equal(t, version, tree.version)
+		require.Empty(t, tree.journal)
+
+		// The undone write never took effect, so the other transaction commits.
+		require.NoError(t, txB.Commit())
+		require.Equal(t, []int64{5}, collectKeys(tree))
+	})
+
+	t.Run("An abandoned transaction is closed by the garbage collector", func(t *testing.T) {
+		tree := NewBpTree(4)
+		func() {
+			tx := tree.Begin()
+			require.NoError(t, tx.InsertValue(BpItem{Key: 1}))
+		}()
+
+		// The finalizer rolls it back, and the journal stops growing.
+		require.Eventually(t, func() bool {
+			runtime.GC()
+			tree.mutex.Lock()
+			defer tree.mutex.Unlock()
+			return len(tree.openTx) == 0
+		}, 5*time.Second, 10*time.Millisecond)
+		tree.InsertValue(BpItem{Key: 2})
+		require.Nil(t, tree.journal)
+		require.Equal(t, []int64{2}, collectKeys(tree))
+	})
+
+	t.Run("Watchers receive the committed changes", func(t *testing.T) {
+		tree := NewBpTree(4)
+		tree.InsertValue(BpItem{Key: 1, Val: "one"})
+		events := tree.Watch(0, 10)
+
+		tx := tree.Begin()
+		require.NoError(t, tx.InsertValue(BpItem{Key: 2}))
+		require.NoError(t, tx.RemoveValue(BpItem{Key: 1}))
+		require.NoError(t, tx.Commit())
+
+		require.True(t, tree.Unwatch(events))
+		var received []ChangeEvent
+		for event := range events {
+			received = append(received, event)
+		}
+		require.Equal(t, []ChangeEvent{
+			{Kind: ChangeInsert, Item: BpItem{Key: 2}},
+			{Kind: ChangeDelete, Item: BpItem{Key: 1, Val: "one"}},
+		}, received)
+	})
+}
+
+// Test_Check_BpTree_Savepoint 🧫 interleaves savepoint rollbacks with structural splits and verifies the restored state.
+func Test_Check_BpTree_Savepoint(t *testing.T) {
+	t.Run("Rollback to a savepoint", func(t *testing.T) {
+		tree := NewBpTree(3)
+		tx := tree.Begin()
+
+		require.NoError(t, tx.InsertValue(BpItem{Key: 1}))
+		require.NoError(t, tx.Savepoint("first"))
+		require.NoError(t, tx.InsertValue(BpItem{Key: 2}))
+		require.NoError(t, tx.Savepoint("second"))
+		require.NoError(t, tx.InsertValue(BpItem{Key: 3}))
+
+		// Rolling back to the first savepoint removes the second one as well.
+		require.NoError(t, tx.RollbackTo("first"))
+		require.ErrorIs(t, tx.RollbackTo("second"), ErrTxNoSavepoint)
+
+		// The first savepoint is kept and can be used again.
+		require.NoError(t, tx.InsertValue(BpItem{Key: 4}))
+		require.NoError(t, tx.RollbackTo("first"))
+		require.NoError(t, tx.InsertValue(BpItem{Key: 5}))
+
+		// Releasing keeps the operations.
+		require.NoError(t, tx.Release("first"))
+		require.ErrorIs(t, tx.Release("first"), ErrTxNoSavepoint)
+
+		require.NoError(t, tx.Commit())
+		require.Equal(t, []int64{1, 5}, collectKeys(tree))
+		require.ErrorIs(t, tx.Savepoint("late"), ErrTxClosed)
+	})
+
+	t.Run("Discarded keys do not conflict", func(t *testing.T) {
+		tree := NewBpTree(3)
+		tx := tree.Begin()
+		require.NoError(t, tx.Savepoint("start"))
+		require.NoError(t, tx.InsertValue(BpItem{Key: 9}))
+		require.NoError(t, tx.RollbackTo("start"))
+		require.NoError(t, tx.InsertValue(BpItem{Key: 10}))
+
+		// Another writer touches the discarded key.
+		tree.InsertValue(BpItem{Key: 9})
+		require.NoError(t, tx.Commit())
+		require.Equal(t, []int64{9, 10}, collectKeys(tree))
+	})
+
+	t.Run("Interleaved with structural splits", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(42))
+
+		// Width 3 splits very often, so every commit changes the structure.
+		tree := NewBpTree(3)
+		expected := make(map[int64]struct{})
+		next := int64(1)
+
+		for round := 0; round < 200; round++ {
+			tx := tree.Begin()
+			committed := make(map[int64]struct{}, len(expected))
+			for key := range expected {
+				committed[key] = struct{}{}
+			}
+
+			// Insert a batch, set a savepoint, then insert and remove more and roll part of it back.
+			for i := 0; i < 1+rng.Intn(6); i++ {
+				require.NoError(t, tx.InsertValue(BpItem{Key: next}))
+				committed[next] = struct{}{}
+				next++
+			}
+			require.NoError(t, tx.Savepoint("middle"))
+			for i := 0; i < 1+rng.Intn(6); i++ {
+				require.NoError(t, tx.InsertValue(BpItem{Key: next}))
+				next++
+			}
+			for key := range expected {
+				require.NoError(t, tx.RemoveValue(BpItem{Key: key}))
+				break
+			}
+			require.NoError(t, tx.RollbackTo("middle"))
+
+			// Remove a random existing key after the rollback.
+			if len(expected) > 0 && rng.Intn(2) == 0 {
+				for key := range expected {
+					require.NoError(t, tx.RemoveValue(BpItem{Key: key}))
+					delete(committed, key)
+					break
+				}
+			}
+
+			require.NoError(t, tx.Commit())
+			expected = committed
+
+			// Every expected key is found, and the rolled back keys are not.
+			var keys []int64
+			for key := range expected {
+				keys = append(keys, key)
+				_, found := tree.Get(key)
+				require.True(t, found, "round %d, key %d", round, key)
+			}
+			sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+			require.Equal(t, keys, collectKeys(tree), "round %d", round)
+		}
+	})
+}
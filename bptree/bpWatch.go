@@ -0,0 +1,214 @@
+package bpTree
+
+import (
+	"sync"
+)
+
+// ➡️ watch operation
+
+// ChangeKind describes what kind of change happened to a key.
+type ChangeKind int
+
+const (
+	ChangeInsert ChangeKind = iota + 1 // The item has been inserted.
+	ChangeDelete                       // The item has been deleted.
+)
+
+// ChangeEvent is delivered to the watchers when a key inside the watched range changes.
+type ChangeEvent struct {
+	Kind ChangeKind // Insert or delete.
+	Item BpItem     // The item that has been inserted or deleted.
+}
+
+// WatchPolicy decides what happens when the watcher does not receive the events fast enough.
+type WatchPolicy int
+
+const (
+	WatchBlock      WatchPolicy = iota + 1 // The writer waits until the watcher receives the event. (写入端等待)
+	WatchDropNewest                        // The new event is dropped when the buffer is full. (丢弃最新的)
+	WatchDropOldest                        // The oldest event in the buffer is dropped to make room. (丢弃最旧的)
+)
+
+// watcher subscribes to the changes of keys in [start, end].
+type watcher struct {
+	start, end int64              // The watched key range, both ends are included.
+	bufferSize int                // The buffer size of the events channel.
+	policy     WatchPolicy        // The backpressure policy.
+	events     chan ChangeEvent   // The channel delivering the events.
+	done       chan struct{}      // Closed when the watcher is removed, to release any blocked writer.
+	closeOnce  sync.Once          // Prevent the done channel from being closed twice.
+	receiver   <-chan ChangeEvent // The receive-only view handed to the caller, used to find the watcher again.
+}
+
+// WatchOption defines a function type for configuring the watcher.
+type WatchOption func(*watcher)
+
+// WithWatchBuffer sets the buffer size of the events channel.
+func WithWatchBuffer(size int) WatchOption {
+	return func(w *watcher) {
+		w.bufferSize = size
+	}
+}
+
+// WithWatchPolicy sets the backpressure policy when the buffer is full.
+func WithWatchPolicy(policy WatchPolicy) WatchOption {
+	return func(w *watcher) {
+		w.policy = policy
+	}
+}
+
+// Watch subscribes to insert and delete notifications for keys in [start, end].
+// By default, the channel has a buffer of 64 events and the writer blocks when it is full.
+// The events are sent after the tree lock is released, so the receiver may call the methods of the tree.
+// They arrive in the order the writes were made, even from concurrent writers, so a watcher which does not
+// receive its events with WatchBlock holds up the events of the later writes for every watcher.
+// Call Unwatch to stop receiving events, then the channel is closed.
+func (tree *BpTree) Watch(start, end int64, opts ...WatchOption) <-chan ChangeEvent {
+	// Create a watcher with the default settings.
+	w := &watcher{
+		start:      start,
+		end:        end,
+		bufferSize: 64,
+		policy:     WatchBlock,
+		done:       make(chan struct{}),
+	}
+
+	// Apply any optional configurations to the watcher.
+	for _, opt := range opts {
+		opt(w)
+	}
+	if w.bufferSize < 0 {
+		w.bufferSize = 0
+	}
+	w.events = make(chan ChangeEvent, w.bufferSize)
+	w.receiver = w.events
+
+	// Register the watcher.
+	tree.watchMutex.Lock()
+	tree.watchers = append(tree.watchers, w)
+	tree.watchMutex.Unlock()
+
+	return w.receiver
+}
+
+// Unwatch removes the watcher owning the channel and closes the channel.
+// It returns false if the channel does not belong to any watcher of the tree.
+func (tree *BpTree) Unwatch(events <-chan ChangeEvent) bool {
+	// Find the watcher first.
+	tree.watchMutex.RLock()
+	var target *watcher
+	for _, w := range tree.watchers {
+		if w.receiver == events {
+			target = w
+			break
+		}
+	}
+	tree.watchMutex.RUnlock()
+
+	if target == nil {
+		return false
+	}
+
+	// Release any writer blocked on this watcher before waiting for the lock.
+	target.closeOnce.Do(func() { close(target.done) })
+
+	// Remove the watcher, no writer is sending to it after the lock is acquired.
+	tree.watchMutex.Lock()
+	defer tree.watchMutex.Unlock()
+	for i, w := range tree.watchers {
+		if w == target {
+			tree.watchers = append(tree.watchers[:i], tree.watchers[i+1:]...)
+			close(w.events)
+			return true
+		}
+	}
+
+	return false
+}
+
+// notifyTurn is the place of a write in the order of the writes, so its changes are sent after the changes of the earlier writes,
+// even though they are sent after the tree lock is released.
+type notifyTurn struct {
+	previous <-chan struct{} // Closed when the changes of the previous write have been sent, nil for the first write.
+	done     chan struct{}   // Closed when the changes of this write have been sent, nil when nothing watches the tree.
+}
+
+// notifyTurn takes the turn of a write, the lock must be held by the caller,
+// and the turn must be passed to notifyWatchers once the lock is released, even when nothing has changed.
+func (tree *BpTree) notifyTurn() (turn notifyTurn) {
+	tree.watchMutex.RLock()
+	watched := len(tree.watchers) > 0
+	tree.watchMutex.RUnlock()
+	if !watched {
+		return
+	}
+
+	turn = notifyTurn{previous: tree.lastNotify, done: make(chan struct{})}
+	tree.lastNotify = turn.done
+	return
+}
+
+// notifyWatchers waits for the turn, and sends the changes to every watcher whose range contains their keys.
+func (tree *BpTree) notifyWatchers(turn notifyTurn, changes ...ChangeEvent) {
+	if turn.done == nil {
+		return
+	}
+	defer close(turn.done)
+	if turn.previous != nil {
+		<-turn.previous
+	}
+
+	tree.watchMutex.RLock()
+	defer tree.watchMutex.RUnlock()
+
+	for _, change := range changes {
+		for _, w := range tree.watchers {
+			if change.Item.Key >= w.start && change.Item.Key <= w.end {
+				w.send(change)
+			}
+		}
+	}
+}
+
+// send delivers the event according to the backpressure policy.
+func (w *watcher) send(event ChangeEvent) {
+	switch w.policy {
+	case WatchDropNewest:
+		select {
+		case w.events <- event:
+		default:
+			// The buffer is full, so the new event is dropped.
+		}
+	case WatchDropOldest:
+		// Without a buffer there is nothing old to drop, so it behaves like WatchDropNewest.
+		if cap(w.events) == 0 {
+			select {
+			case w.events <- event:
+			default:
+			}
+			return
+		}
+		for {
+			select {
+			case w.events <- event:
+				return
+			default:
+			}
+
+			// The buffer is full, so the oldest event is dropped to make room.
+			select {
+			case <-w.events:
+			case <-w.done:
+				return
+			default:
+				// The receiver has just taken it, try again.
+			}
+		}
+	default:
+		select {
+		case w.events <- event:
+		case <-w.done:
+			// The watcher has been removed while waiting.
+		}
+	}
+}
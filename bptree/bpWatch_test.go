@@ -0,0 +1,161 @@
+package bpTree
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// hasKey 🧫 reports whether the key is in the tree.
+func hasKey(tree *BpTree, key int64) bool {
+	_, ok := tree.Get(key)
+	return ok
+}
+
+// Test_Check_BpTree_Watch 🧫 checks the watch API for key-range changes.
+func Test_Check_BpTree_Watch(t *testing.T) {
+	t.Run("Only keys inside the range are delivered", func(t *testing.T) {
+		tree := NewBpTree(4)
+		events := tree.Watch(10, 20)
+
+		// Insert keys inside and outside the range.
+		for key := int64(1); key <= 30; key++ {
+			tree.InsertValue(BpItem{Key: key})
+		}
+		deleted, _, _, err := tree.RemoveValue(BpItem{Key: 15})
+		require.True(t, deleted)
+		require.NoError(t, err)
+
+		// A key which is not in the tree is not deleted, so no event is sent.
+		deleted, _, _, _ = tree.RemoveValue(BpItem{Key: 100})
+		require.False(t, deleted)
+
+		// Stop watching, then the channel is closed and can be drained.
+		require.True(t, tree.Unwatch(events))
+		var received []ChangeEvent
+		for event := range events {
+			received = append(received, event)
+		}
+
+		require.Len(t, received, 12)
+		require.Equal(t, ChangeEvent{Kind: ChangeInsert, Item: BpItem{Key: 10}}, received[0])
+		require.Equal(t, ChangeEvent{Kind: ChangeDelete, Item: BpItem{Key: 15}}, received[11])
+	})
+
+	t.Run("Drop newest when the buffer is full", func(t *testing.T) {
+		tree := NewBpTree(4)
+		events := tree.Watch(0, 100, WithWatchBuffer(3), WithWatchPolicy(WatchDropNewest))
+		for key := int64(1); key <= 10; key++ {
+			tree.InsertValue(BpItem{Key: key})
+		}
+		require.True(t, tree.Unwatch(events))
+
+		var keys []int64
+		for event := range events {
+			keys = append(keys, event.Item.Key)
+		}
+		require.Equal(t, []int64{1, 2, 3}, keys)
+	})
+
+	t.Run("Drop oldest when the buffer is full", func(t *testing.T) {
+		tree := NewBpTree(4)
+		events := tree.Watch(0, 100, WithWatchBuffer(3), WithWatchPolicy(WatchDropOldest))
+		for key := int64(1); key <= 10; key++ {
+			tree.InsertValue(BpItem{Key: key})
+		}
+		require.True(t, tree.Unwatch(events))
+
+		var keys []int64
+		for event := range events {
+			keys = append(keys, event.Item.Key)
+		}
+		require.Equal(t, []int64{8, 9, 10}, keys)
+	})
+
+	t.Run("Unwatch releases a blocked writer", func(t *testing.T) {
+		tree := NewBpTree(4)
+		events := tree.Watch(0, 100, WithWatchBuffer(0))
+
+		// Nobody receives the event, so the writer blocks.
+		finished := make(chan struct{})
+		go func() {
+			tree.InsertValue(BpItem{Key: 1})
+			close(finished)
+		}()
+
+		// The writer is released after Unwatch.
+		time.Sleep(50 * time.Millisecond)
+		require.True(t, tree.Unwatch(events))
+		select {
+		case <-finished:
+		case <-time.After(time.Second):
+			t.Fatal("the writer is still blocked after Unwatch")
+		}
+
+		// An unknown channel cannot be removed.
+		require.False(t, tree.Unwatch(make(chan ChangeEvent)))
+	})
+
+	t.Run("Concurrent writers deliver in the order of the writes", func(t *testing.T) {
+		tree := NewBpTree(4)
+		events := tree.Watch(0, 9)
+
+		// The receiver follows the tree from the events, a delete before its insert would take a count below zero.
+		counts := make(map[int64]int)
+		followed := make(chan error, 1)
+		go func() {
+			var err error
+			for event := range events {
+				if event.Kind == ChangeInsert {
+					counts[event.Item.Key]++
+				} else if counts[event.Item.Key]--; counts[event.Item.Key] < 0 && err == nil {
+					err = fmt.Errorf("key %d is deleted before it is inserted", event.Item.Key)
+				}
+			}
+			followed <- err
+		}()
+
+		// Every key has one writer inserting it when it is missing, and another one deleting it when it is found,
+		// so the deletes follow the inserts of another writer without duplicate keys.
+		var wg sync.WaitGroup
+		for g := 0; g < 4; g++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 1000; i++ {
+					if key := int64(g + 4*(i%2)); !hasKey(tree, key) {
+						require.NoError(t, tree.InsertValue(BpItem{Key: key}))
+					}
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 1000; i++ {
+					if key := int64(g + 4*(i%2)); hasKey(tree, key) {
+						deleted, _, _, err := tree.RemoveValue(BpItem{Key: key})
+						require.True(t, deleted)
+						require.NoError(t, err)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		require.True(t, tree.Unwatch(events))
+		require.NoError(t, <-followed)
+
+		// The counts followed from the events match the tree.
+		expected := make(map[int64]int)
+		for _, key := range tree.keys() {
+			expected[key]++
+		}
+		for key, count := range counts {
+			if count == 0 {
+				delete(counts, key)
+			}
+		}
+		require.Equal(t, expected, counts)
+	})
+}
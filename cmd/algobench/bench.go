@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// =====================================================================================================================
+//                  🛠️ Algo Bench (Tool)
+// Algo Bench runs the selected benchmarks of the data structures from a config file, captures their profiles,
+// and compares the results against a baseline, so performance work has a repeatable driver.
+// The output is the same as `go test -bench`, so it can also be fed to benchstat.
+// =====================================================================================================================
+
+// BenchConfig ⛏️ is the config file of algobench.
+type BenchConfig struct {
+	Count      int           `json:"count"`      // How many times each benchmark runs, benchstat needs several runs.
+	BenchTime  string        `json:"benchtime"`  // The -benchtime flag of go test, such as "1s" or "1000x".
+	ProfileDir string        `json:"profileDir"` // Where the profiles and test binaries are written.
+	Baseline   string        `json:"baseline"`   // The baseline result file to compare with, optional.
+	Benchmarks []BenchTarget `json:"benchmarks"` // The benchmarks to run.
+}
+
+// BenchTarget ⛏️ selects the benchmarks of one package.
+type BenchTarget struct {
+	Name       string `json:"name"`       // The name used for the profile files.
+	Package    string `json:"package"`    // The package path, such as "./bptree".
+	Pattern    string `json:"pattern"`    // The -bench regular expression.
+	CPUProfile bool   `json:"cpuProfile"` // Capture a CPU profile, which can be viewed as a flame graph.
+	MemProfile bool   `json:"memProfile"` // Capture a memory profile.
+	Perf       bool   `json:"perf"`       // Report the hardware counters per operation, Linux only.
+}
+
+// LoadBenchConfig ⛏️ reads the config file and fills in the defaults.
+func LoadBenchConfig(path string) (cfg BenchConfig, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	if err = json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	// Fill in the defaults.
+	if cfg.Count <= 0 {
+		cfg.Count = 1
+	}
+	if cfg.BenchTime == "" {
+		cfg.BenchTime = "1s"
+	}
+	if cfg.ProfileDir == "" {
+		cfg.ProfileDir = "temp/algobench"
+	}
+
+	// Every target needs a package and a pattern.
+	for i, target := range cfg.Benchmarks {
+		if target.Package == "" || target.Pattern == "" {
+			return cfg, fmt.Errorf("benchmark %d: package and pattern are required", i)
+		}
+		if target.Name == "" {
+			cfg.Benchmarks[i].Name = fmt.Sprintf("bench%d", i)
+		}
+	}
+
+	return
+}
+
+// benchLine matches a result line, for example "Benchmark_BpTree_Search/Get-8   100   12345 ns/op   0 B/op".
+var benchLine = regexp.MustCompile(`^(Benchmark\S+)\s+\d+\s+([0-9.]+) ns/op`)
+
+// ParseResults ⛏️ collects the ns/op of every benchmark from the go test output.
+// A benchmark appears several times when it runs with -count, so all the runs are kept.
+func ParseResults(r io.Reader) (map[string][]float64, error) {
+	results := make(map[string][]float64)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		match := benchLine.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue
+		}
+		nsPerOp, err := strconv.ParseFloat(match[2], 64)
+		if err != nil {
+			return nil, err
+		}
+		results[match[1]] = append(results[match[1]], nsPerOp)
+	}
+	return results, scanner.Err()
+}
+
+// Comparison ⛏️ is the change of one benchmark between the baseline and the current run.
+type Comparison struct {
+	Name     string  // The benchmark name.
+	Baseline float64 // The mean ns/op of the baseline, 0 when the benchmark is new.
+	Current  float64 // The mean ns/op of the current run, 0 when the benchmark is gone.
+	Delta    float64 // The change in percent, positive means slower.
+}
+
+// Compare ⛏️ compares the mean ns/op of every benchmark, sorted by name.
+func Compare(baseline, current map[string][]float64) (comparisons []Comparison) {
+	// Collect the names of both sides.
+	names := make(map[string]struct{})
+	for name := range baseline {
+		names[name] = struct{}{}
+	}
+	for name := range current {
+		names[name] = struct{}{}
+	}
+
+	for name := range names {
+		c := Comparison{Name: name, Baseline: mean(baseline[name]), Current: mean(current[name])}
+		if c.Baseline > 0 && c.Current > 0 {
+			c.Delta = (c.Current - c.Baseline) / c.Baseline * 100
+		}
+		comparisons = append(comparisons, c)
+	}
+	sort.Slice(comparisons, func(i, j int) bool { return comparisons[i].Name < comparisons[j].Name })
+
+	return
+}
+
+// WriteComparison ⛏️ prints the comparisons as a table.
+func WriteComparison(w io.Writer, comparisons []Comparison) {
+	// Find the width of the name column.
+	width := len("name")
+	for _, c := range comparisons {
+		if len(c.Name) > width {
+			width = len(c.Name)
+		}
+	}
+
+	fmt.Fprintf(w, "%-*s  %14s  %14s  %8s\n", width, "name", "old ns/op", "new ns/op", "delta")
+	fmt.Fprintln(w, strings.Repeat("-", width+42))
+	for _, c := range comparisons {
+		switch {
+		case c.Baseline == 0:
+			fmt.Fprintf(w, "%-*s  %14s  %14.2f  %8s\n", width, c.Name, "-", c.Current, "new")
+		case c.Current == 0:
+			fmt.Fprintf(w, "%-*s  %14.2f  %14s  %8s\n", width, c.Name, c.Baseline, "-", "gone")
+		default:
+			fmt.Fprintf(w, "%-*s  %14.2f  %14.2f  %+7.2f%%\n", width, c.Name, c.Baseline, c.Current, c.Delta)
+		}
+	}
+}
+
+// mean ⛏️ returns the average of the values, or 0 for none.
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
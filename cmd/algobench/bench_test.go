@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_ParseResults tests that every run of every benchmark is collected from the go test output.
+func Test_ParseResults(t *testing.T) {
+	output := strings.Join([]string{
+		"goos: linux",
+		"pkg: github.com/panhongrainbow/go-algorithm/bptree",
+		"Benchmark_BpTree_Search/Get_loop-8   	     200	   2000 ns/op	       0 B/op	       0 allocs/op",
+		"Benchmark_BpTree_Search/Get_loop-8   	     200	   4000 ns/op	       0 B/op	       0 allocs/op",
+		"Benchmark_BpTree_Search/GetMany-8    	     200	   1500.5 ns/op",
+		"PASS",
+	}, "\n")
+
+	results, err := ParseResults(strings.NewReader(output))
+	require.NoError(t, err)
+	require.Equal(t, map[string][]float64{
+		"Benchmark_BpTree_Search/Get_loop-8": {2000, 4000},
+		"Benchmark_BpTree_Search/GetMany-8":  {1500.5},
+	}, results)
+}
+
+// Test_Compare tests the comparison table between the baseline and the current run.
+func Test_Compare(t *testing.T) {
+	baseline := map[string][]float64{"BenchmarkA": {100, 300}, "BenchmarkGone": {50}}
+	current := map[string][]float64{"BenchmarkA": {150, 150}, "BenchmarkNew": {10}}
+
+	comparisons := Compare(baseline, current)
+	require.Equal(t, []Comparison{
+		{Name: "BenchmarkA", Baseline: 200, Current: 150, Delta: -25},
+		{Name: "BenchmarkGone", Baseline: 50},
+		{Name: "BenchmarkNew", Current: 10},
+	}, comparisons)
+
+	var buf bytes.Buffer
+	WriteComparison(&buf, comparisons)
+	require.Contains(t, buf.String(), "-25.00%")
+	require.Contains(t, buf.String(), "gone")
+	require.Contains(t, buf.String(), "new")
+}
+
+// Test_LoadBenchConfig tests the defaults and the validation of the config file.
+func Test_LoadBenchConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	// The defaults are filled in.
+	path := filepath.Join(dir, "ok.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"benchmarks": [{"package": "./bptree", "pattern": "."}]}`), 0o644))
+	cfg, err := LoadBenchConfig(path)
+	require.NoError(t, err)
+	require.Equal(t, 1, cfg.Count)
+	require.Equal(t, "1s", cfg.BenchTime)
+	require.Equal(t, "bench0", cfg.Benchmarks[0].Name)
+
+	// A target without a pattern is rejected.
+	path = filepath.Join(dir, "bad.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"benchmarks": [{"package": "./bptree"}]}`), 0o644))
+	_, err = LoadBenchConfig(path)
+	require.Error(t, err)
+}
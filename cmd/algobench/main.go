@@ -0,0 +1,151 @@
+// Command algobench runs the benchmarks listed in a config file and compares them against a baseline.
+//
+// Usage:
+//
+//	go run ./cmd/algobench -config config/AlgoBench.json -out temp/algobench/new.txt
+//	go run ./cmd/algobench -config config/AlgoBench.json -save-baseline
+//
+// The result file has the same format as `go test -bench`, so `benchstat old.txt new.txt` works on it too.
+// The CPU profiles can be viewed as a flame graph with `go tool pprof -http=: <profile>`.
+// With "perf": true on Linux, the hardware counters such as cache-misses/op are added to the result lines.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/panhongrainbow/go-algorithm/lockhub"
+)
+
+// perfEnvName is the same as utilhub.PerfEnvName, which turns on the perf counters in the benchmarks.
+const perfEnvName = "ALGOBENCH_PERF"
+
+func main() {
+	configPath := flag.String("config", "config/AlgoBench.json", "the config file listing the benchmarks")
+	outPath := flag.String("out", "", "write the results to this file, in the go test -bench format")
+	baselinePath := flag.String("baseline", "", "compare with this result file instead of the one in the config")
+	saveBaseline := flag.Bool("save-baseline", false, "write the results as the new baseline")
+	flag.Parse()
+
+	if err := run(*configPath, *outPath, *baselinePath, *saveBaseline); err != nil {
+		fmt.Fprintln(os.Stderr, "algobench:", err)
+		os.Exit(1)
+	}
+}
+
+// run ⛏️ runs every benchmark target, writes the results and prints the comparison.
+func run(configPath, outPath, baselinePath string, saveBaseline bool) error {
+	cfg, err := LoadBenchConfig(configPath)
+	if err != nil {
+		return err
+	}
+	if baselinePath == "" {
+		baselinePath = cfg.Baseline
+	}
+	if err = os.MkdirAll(cfg.ProfileDir, 0o755); err != nil {
+		return err
+	}
+
+	// Keep another algobench or a test run from writing the profiles at the same time.
+	lock, err := lockhub.LockDir(cfg.ProfileDir, 0)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	// Run the targets one by one, showing the output while keeping a copy.
+	var results bytes.Buffer
+	for _, target := range cfg.Benchmarks {
+		if err = runTarget(cfg, target, io.MultiWriter(os.Stdout, &results)); err != nil {
+			return fmt.Errorf("%s: %w", target.Name, err)
+		}
+	}
+
+	// Write the results.
+	if outPath != "" {
+		if err = os.WriteFile(outPath, results.Bytes(), 0o644); err != nil {
+			return err
+		}
+	}
+	if saveBaseline {
+		if baselinePath == "" {
+			return fmt.Errorf("no baseline path in the config or the flags")
+		}
+		fmt.Printf("\nbaseline saved to %s\n", baselinePath)
+		return os.WriteFile(baselinePath, results.Bytes(), 0o644)
+	}
+
+	// Compare with the baseline when there is one.
+	if baselinePath == "" {
+		return nil
+	}
+	baselineFile, err := os.Open(baselinePath)
+	if os.IsNotExist(err) {
+		fmt.Printf("\nno baseline at %s, run with -save-baseline to create it\n", baselinePath)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer baselineFile.Close()
+
+	baseline, err := ParseResults(baselineFile)
+	if err != nil {
+		return err
+	}
+	current, err := ParseResults(&results)
+	if err != nil {
+		return err
+	}
+	fmt.Println()
+	WriteComparison(os.Stdout, Compare(baseline, current))
+
+	return nil
+}
+
+// runTarget ⛏️ runs the benchmarks of one target with go test and captures the requested profiles.
+func runTarget(cfg BenchConfig, target BenchTarget, out io.Writer) error {
+	args := []string{
+		"test", "-run", "^$",
+		"-bench", target.Pattern,
+		"-benchmem",
+		"-count", fmt.Sprint(cfg.Count),
+		"-benchtime", cfg.BenchTime,
+	}
+
+	// Profiling keeps the test binary, so it is placed next to the profiles.
+	if target.CPUProfile || target.MemProfile {
+		args = append(args, "-o", filepath.Join(cfg.ProfileDir, target.Name+".test"))
+	}
+	if target.CPUProfile {
+		profile, err := filepath.Abs(filepath.Join(cfg.ProfileDir, target.Name+".cpu.pprof"))
+		if err != nil {
+			return err
+		}
+		args = append(args, "-cpuprofile", profile)
+		defer fmt.Fprintf(os.Stderr, "flame graph: go tool pprof -http=: %s\n", profile)
+	}
+	if target.MemProfile {
+		profile, err := filepath.Abs(filepath.Join(cfg.ProfileDir, target.Name+".mem.pprof"))
+		if err != nil {
+			return err
+		}
+		args = append(args, "-memprofile", profile)
+	}
+	args = append(args, target.Package)
+
+	// Run go test and stream its output.
+	cmd := exec.Command("go", args...)
+	if target.Perf {
+		// The benchmarks check utilhub.PerfEnabled and report cache-misses/op and branch-misses/op.
+		cmd.Env = append(os.Environ(), perfEnvName+"=1")
+	}
+	cmd.Stdout = out
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
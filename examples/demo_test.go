@@ -0,0 +1,28 @@
+package examples
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Demos runs every demo with its config file, so the demos are smoke tests as well. (make demo)
+func Test_Demos(t *testing.T) {
+	// Every directory with a main.go and a config.json is a demo.
+	demos, err := filepath.Glob(filepath.Join("*", "main.go"))
+	require.NoError(t, err)
+	require.NotEmpty(t, demos)
+
+	for _, demo := range demos {
+		dir := filepath.Dir(demo)
+		t.Run(dir, func(t *testing.T) {
+			cmd := exec.Command("go", "run", "./"+dir, "-config", filepath.Join(dir, "config.json"))
+			cmd.Stdout = os.Stdout
+			cmd.Stderr = os.Stderr
+			require.NoError(t, cmd.Run())
+		})
+	}
+}
@@ -0,0 +1,95 @@
+// Command treeingest is a demo loading generated JSON lines into a B plus tree while a progress bar runs.
+//
+// Usage:
+//
+//	go run ./examples/treeingest -config examples/treeingest/config.json
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+
+	bpTree "github.com/panhongrainbow/go-algorithm/bptree"
+	"github.com/panhongrainbow/go-algorithm/utilhub"
+)
+
+// DemoConfig is the config file of the demo.
+type DemoConfig struct {
+	Width     int   `json:"width"`     // The width of the B plus tree.
+	Count     int   `json:"count"`     // How many rows are generated.
+	Seed      int64 `json:"seed"`      // The seed of the random keys.
+	BatchSize int   `json:"batchSize"` // How many rows are inserted under one lock.
+}
+
+func main() {
+	configPath := flag.String("config", "examples/treeingest/config.json", "the config file of the demo")
+	flag.Parse()
+
+	if err := run(*configPath); err != nil {
+		fmt.Fprintln(os.Stderr, "treeingest:", err)
+		os.Exit(1)
+	}
+}
+
+// run loads the generated rows into a new tree and checks the result.
+func run(configPath string) error {
+	// Read the config.
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return err
+	}
+	var cfg DemoConfig
+	if err = json.Unmarshal(data, &cfg); err != nil {
+		return fmt.Errorf("parse %s: %w", configPath, err)
+	}
+	if cfg.Count <= 0 {
+		return fmt.Errorf("count must be positive")
+	}
+
+	// Generate the JSON lines with random keys, such as {"id":42,"name":"row-42"}.
+	var rows bytes.Buffer
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	for i := 0; i < cfg.Count; i++ {
+		key := rng.Int63n(int64(cfg.Count) * 10)
+		fmt.Fprintf(&rows, "{\"id\":%d,\"name\":\"row-%d\"}\n", key, key)
+	}
+
+	// Start the progress bar, and use plain lines when the output is not a terminal.
+	bar, err := utilhub.NewProgressBar("Ingest", uint64(cfg.Count), 50, utilhub.WithETA(true), utilhub.WithLogMode())
+	if err != nil {
+		return err
+	}
+	bar.Start()
+
+	// Load the rows, the progress bar moves once for every row.
+	tree := bpTree.NewBpTree(cfg.Width)
+	loader := bpTree.NewLoader(tree, bpTree.WithLoadBatch(cfg.BatchSize), bpTree.WithLoadProgress(bar))
+	report, err := loader.LoadFromJSONLines(&rows, "id", "name")
+	bar.Complete()
+	<-bar.WaitForPrinterStop()
+	if err != nil {
+		return err
+	}
+	if err = bar.Report(32); err != nil {
+		return err
+	}
+
+	// Every loaded row is in the tree, in ascending key order.
+	count, previous := 0, int64(-1)
+	for it := tree.Iterator(); it.Next(); count++ {
+		if it.Key() < previous {
+			return fmt.Errorf("key %d comes after %d", it.Key(), previous)
+		}
+		previous = it.Key()
+	}
+	if count != report.Loaded {
+		return fmt.Errorf("loaded %d rows, but the tree has %d items", report.Loaded, count)
+	}
+	fmt.Printf("loaded %d rows, %d bad rows\n", report.Loaded, len(report.BadRows))
+
+	return nil
+}
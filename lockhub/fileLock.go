@@ -0,0 +1,106 @@
+// Package lockhub holds advisory file locks, so two test runs, or the CLI and a test run,
+// do not write into the same record directory at the same time.
+// It is a separate package without an init, so the commands can use it without the utilhub config.
+package lockhub
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// =====================================================================================================================
+//                  🛠️ File Lock (Tool)
+// File Lock uses flock on Unix and LockFileEx on Windows. The locks are advisory, so they only keep out
+// the processes which lock too, and they are released by the OS when the holding process dies.
+// =====================================================================================================================
+
+// ErrLocked ⛏️ is returned when another process holds the lock.
+var ErrLocked = errors.New("locked by another process")
+
+// errBusy ⛏️ is returned by tryLock when the lock is held, the platform errors differ.
+var errBusy = errors.New("lock is busy")
+
+// DirLockName ⛏️ is the name of the lock file which LockDir creates in the directory.
+const DirLockName = ".lock"
+
+// FileLock ⛏️ is an exclusive lock held on a file.
+type FileLock struct {
+	file *os.File // The open lock file, nil after Unlock.
+	path string   // The path of the lock file.
+}
+
+// Lock ⛏️ takes the exclusive lock of the file at path, creating it when it is missing.
+// It retries until the timeout when another process holds the lock, and 0 tries only once.
+// The error of a held lock wraps ErrLocked and names the process id of the holder when it can be read.
+func Lock(path string, timeout time.Duration) (*FileLock, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		err = tryLock(file)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, errBusy) || !time.Now().Before(deadline) {
+			_ = file.Close()
+			if errors.Is(err, errBusy) {
+				return nil, lockedError(path)
+			}
+			return nil, fmt.Errorf("lock %s: %w", path, err)
+		}
+		time.Sleep(min(100*time.Millisecond, time.Until(deadline)))
+	}
+
+	// Leave the process id in the file, so the next process knows who holds the lock.
+	if err = file.Truncate(0); err == nil {
+		_, err = file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+	if err != nil {
+		_ = unlock(file)
+		_ = file.Close()
+		return nil, fmt.Errorf("lock %s: %w", path, err)
+	}
+
+	return &FileLock{file: file, path: path}, nil
+}
+
+// LockDir ⛏️ takes the lock of a directory through the lock file named DirLockName inside it.
+func LockDir(dir string, timeout time.Duration) (*FileLock, error) {
+	return Lock(filepath.Join(dir, DirLockName), timeout)
+}
+
+// Path ⛏️ returns the path of the lock file.
+func (l *FileLock) Path() string {
+	return l.path
+}
+
+// Unlock ⛏️ releases the lock, and it is safe to call twice.
+// The lock file stays, because removing it would let two processes lock two different files.
+func (l *FileLock) Unlock() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	err := unlock(l.file)
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+	l.file = nil
+	return err
+}
+
+// lockedError ⛏️ describes a held lock, with the process id of the holder when it can be read.
+func lockedError(path string) error {
+	data, err := os.ReadFile(path)
+	if pid := strings.TrimSpace(string(data)); err == nil && pid != "" {
+		return fmt.Errorf("%s: %w (pid %s)", path, ErrLocked, pid)
+	}
+	return fmt.Errorf("%s: %w", path, ErrLocked)
+}
@@ -0,0 +1,15 @@
+//go:build !unix && !windows
+
+package lockhub
+
+import "os"
+
+// tryLock ⛏️ does nothing where the OS has no file locks, so the lock always succeeds.
+func tryLock(file *os.File) error {
+	return nil
+}
+
+// unlock ⛏️ does nothing where the OS has no file locks.
+func unlock(file *os.File) error {
+	return nil
+}
@@ -0,0 +1,50 @@
+package lockhub
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test_LockDir tests that a locked directory can not be locked again until it is unlocked.
+func Test_LockDir(t *testing.T) {
+	dir := t.TempDir()
+
+	// The lock file holds the process id.
+	lock, err := LockDir(dir, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, DirLockName), lock.Path())
+	data, err := os.ReadFile(lock.Path())
+	assert.NoError(t, err)
+	assert.Equal(t, strconv.Itoa(os.Getpid())+"\n", string(data))
+
+	// The second lock fails at once, and names the holder.
+	_, err = LockDir(dir, 0)
+	assert.True(t, errors.Is(err, ErrLocked))
+	assert.Contains(t, err.Error(), "pid "+strconv.Itoa(os.Getpid()))
+
+	// The second lock waits until the first is released.
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		assert.NoError(t, lock.Unlock())
+	}()
+	second, err := LockDir(dir, 5*time.Second)
+	assert.NoError(t, err)
+
+	// Unlocking twice is harmless, and the lock file stays.
+	assert.NoError(t, second.Unlock())
+	assert.NoError(t, second.Unlock())
+	assert.FileExists(t, filepath.Join(dir, DirLockName))
+}
+
+// Test_Lock_MissingDir tests that a lock in a missing directory is an ordinary error.
+func Test_Lock_MissingDir(t *testing.T) {
+	_, err := LockDir(filepath.Join(t.TempDir(), "missing"), 0)
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrLocked))
+}
@@ -0,0 +1,66 @@
+// Package metrichub exports the progress of the long algorithm runs and the shape of the trees as Prometheus metrics,
+// so the nightly benchmarks and endurance tests show up in Grafana.
+// It is a separate package, so utilhub and bptree do not depend on the Prometheus client.
+package metrichub
+
+import (
+	"github.com/panhongrainbow/go-algorithm/utilhub"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// =====================================================================================================================
+//                  🛠️ Progress Metrics (Tool)
+// Progress Metrics reads a progress bar on every scrape, so the bar itself does no extra work between scrapes.
+// Every bar is told apart by the "bar" label, which is the name of the progress bar.
+// =====================================================================================================================
+
+// ProgressCollector ⛏️ is a Prometheus collector reading the progress of one bar.
+type ProgressCollector struct {
+	bar     *utilhub.ProgressBar // The progress bar being exported.
+	done    *prometheus.Desc     // The completed steps, a gauge since Sub can take steps back.
+	total   *prometheus.Desc     // The total steps, a gauge since the total can change.
+	rate    *prometheus.Desc     // The completed steps per second.
+	elapsed *prometheus.Desc     // The elapsed seconds without the paused time.
+}
+
+// NewProgressCollector ⛏️ creates a collector for the progress bar, labelled with the given name.
+func NewProgressCollector(bar *utilhub.ProgressBar, name string) *ProgressCollector {
+	labels := prometheus.Labels{"bar": name}
+	return &ProgressCollector{
+		bar:     bar,
+		done:    prometheus.NewDesc("algorithm_progress_done_steps", "The steps completed by the progress bar.", nil, labels),
+		total:   prometheus.NewDesc("algorithm_progress_total_steps", "The total steps of the progress bar.", nil, labels),
+		rate:    prometheus.NewDesc("algorithm_progress_rate", "The completed steps per second, without the paused time.", nil, labels),
+		elapsed: prometheus.NewDesc("algorithm_progress_elapsed_seconds", "The time since the progress bar started, without the paused time.", nil, labels),
+	}
+}
+
+// Describe ⛏️ sends the descriptions of the metrics, it implements prometheus.Collector.
+func (c *ProgressCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.done
+	ch <- c.total
+	ch <- c.rate
+	ch <- c.elapsed
+}
+
+// Collect ⛏️ reads the progress bar and sends the metrics, it implements prometheus.Collector.
+func (c *ProgressCollector) Collect(ch chan<- prometheus.Metric) {
+	snapshot := c.bar.Snapshot()
+	ch <- prometheus.MustNewConstMetric(c.done, prometheus.GaugeValue, float64(snapshot.Completed))
+	ch <- prometheus.MustNewConstMetric(c.total, prometheus.GaugeValue, float64(snapshot.Total))
+	ch <- prometheus.MustNewConstMetric(c.rate, prometheus.GaugeValue, snapshot.Rate)
+	ch <- prometheus.MustNewConstMetric(c.elapsed, prometheus.GaugeValue, snapshot.Elapsed.Seconds())
+}
+
+// WithMetrics ⛏️ is a progress bar option which registers the bar to the Prometheus registry, it is opt-in.
+// The bar is labelled with its name, so the names of the bars in the same registry must be different.
+// A registration error, such as a duplicate name, is reported to onError when it is given, and the bar still works.
+func WithMetrics(registry prometheus.Registerer, onError ...func(error)) utilhub.BarOption {
+	return func(bar *utilhub.ProgressBar) {
+		if err := registry.Register(NewProgressCollector(bar, bar.Name())); err != nil {
+			for _, fn := range onError {
+				fn(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package metrichub
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/panhongrainbow/go-algorithm/utilhub"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+// gather collects the metrics of the registry by name, with the bar label of each.
+func gather(t *testing.T, registry *prometheus.Registry) map[string]float64 {
+	families, err := registry.Gather()
+	assert.NoError(t, err)
+
+	values := make(map[string]float64)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			name := family.GetName()
+			for _, label := range metric.GetLabel() {
+				name += "{" + label.GetName() + "=" + label.GetValue() + "}"
+			}
+			switch {
+			case metric.GetCounter() != nil:
+				values[name] = metric.GetCounter().GetValue()
+			case metric.GetGauge() != nil:
+				values[name] = metric.GetGauge().GetValue()
+			}
+		}
+	}
+	return values
+}
+
+// Test_ProgressMetrics tests exporting the progress of a bar to a Prometheus registry.
+func Test_ProgressMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	bar, err := utilhub.NewProgressBar("nightly", 100, 10, utilhub.WithTimeControl(0), utilhub.WithWriter(&bytes.Buffer{}),
+		utilhub.WithTimeZone("Etc/UTC"), WithMetrics(registry))
+	assert.NoError(t, err)
+	bar.Start()
+
+	// The metrics follow the bar on every scrape.
+	bar.AddSpecificTimes(40)
+	values := gather(t, registry)
+	assert.Equal(t, 40.0, values["algorithm_progress_done_steps{bar=nightly}"])
+
+	// The completed steps can go back, so they are a gauge and not a counter.
+	bar.Sub(10)
+	values = gather(t, registry)
+	assert.Equal(t, 30.0, values["algorithm_progress_done_steps{bar=nightly}"])
+	families, err := registry.Gather()
+	assert.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() == "algorithm_progress_done_steps" {
+			assert.Equal(t, "GAUGE", family.GetType().String())
+		}
+	}
+	bar.AddSpecificTimes(10)
+	assert.Equal(t, 100.0, values["algorithm_progress_total_steps{bar=nightly}"])
+	assert.Greater(t, values["algorithm_progress_rate{bar=nightly}"], 0.0)
+	assert.Greater(t, values["algorithm_progress_elapsed_seconds{bar=nightly}"], 0.0)
+
+	bar.Complete()
+	<-bar.WaitForPrinterStop()
+	values = gather(t, registry)
+	assert.Equal(t, 100.0, values["algorithm_progress_done_steps{bar=nightly}"])
+
+	// A second bar with the same name can not be registered, and the error is reported.
+	var registerErr error
+	_, err = utilhub.NewProgressBar("nightly", 10, 10, utilhub.WithTimeZone("Etc/UTC"),
+		WithMetrics(registry, func(err error) { registerErr = err }))
+	assert.NoError(t, err)
+	assert.Error(t, registerErr)
+}
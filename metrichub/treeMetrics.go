@@ -0,0 +1,56 @@
+package metrichub
+
+import (
+	"strconv"
+
+	bpTree "github.com/panhongrainbow/go-algorithm/bptree"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// =====================================================================================================================
+//                  🛠️ Tree Metrics (Tool)
+// Tree Metrics reads the fill factors of the nodes of a B plus tree on every scrape, and exports them as a histogram
+// for every level, so fragmentation building up in a long-lived index shows up before it hurts. (节点填充率监控)
+// Every tree is told apart by the "tree" label, and every level by the "level" label, 0 being the root.
+// =====================================================================================================================
+
+// TreeFillCollector ⛏️ is a Prometheus collector reading the fill factors of one tree.
+type TreeFillCollector struct {
+	tree *bpTree.BpTree   // The tree being exported.
+	fill *prometheus.Desc // The fill factors of the nodes, a histogram for every level.
+}
+
+// NewTreeFillCollector ⛏️ creates a collector for the tree, labelled with the given name.
+// Every scrape walks the whole tree under its lock, so the scrape interval should not be too short for a huge tree.
+func NewTreeFillCollector(tree *bpTree.BpTree, name string) *TreeFillCollector {
+	return &TreeFillCollector{
+		tree: tree,
+		fill: prometheus.NewDesc("algorithm_bptree_node_fill_ratio",
+			"The fill factors of the nodes by level, the children over the width or the items over width-1.",
+			[]string{"level", "kind"}, prometheus.Labels{"tree": name}),
+	}
+}
+
+// Describe ⛏️ sends the description of the metric, it implements prometheus.Collector.
+func (c *TreeFillCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.fill
+}
+
+// Collect ⛏️ reads the fill factors of the tree and sends a histogram for every level, it implements prometheus.Collector.
+func (c *TreeFillCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, level := range c.tree.FillStats() {
+		// The buckets of Prometheus are cumulative, and the nodes over the width only count in +Inf.
+		buckets := make(map[float64]uint64, len(bpTree.FillBuckets))
+		cumulative := uint64(0)
+		for i, bound := range bpTree.FillBuckets {
+			cumulative += level.Histogram[i]
+			buckets[bound] = cumulative
+		}
+
+		kind := "index"
+		if level.Data {
+			kind = "data"
+		}
+		ch <- prometheus.MustNewConstHistogram(c.fill, uint64(level.Nodes), level.Sum, buckets, strconv.Itoa(level.Level), kind)
+	}
+}
@@ -0,0 +1,56 @@
+package metrichub
+
+import (
+	"strconv"
+	"testing"
+
+	bpTree "github.com/panhongrainbow/go-algorithm/bptree"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test_TreeFillMetrics tests exporting the fill factors of a tree as a histogram for every level.
+func Test_TreeFillMetrics(t *testing.T) {
+	tree := bpTree.NewBpTree(5)
+	for key := int64(0); key < 500; key++ {
+		assert.NoError(t, tree.InsertValue(bpTree.BpItem{Key: key}))
+	}
+	registry := prometheus.NewRegistry()
+	assert.NoError(t, registry.Register(NewTreeFillCollector(tree, "orders")))
+
+	// Every level is a histogram, the data nodes are the deepest one.
+	families, err := registry.Gather()
+	assert.NoError(t, err)
+	assert.Len(t, families, 1)
+	assert.Equal(t, "algorithm_bptree_node_fill_ratio", families[0].GetName())
+	metrics := families[0].GetMetric()
+	assert.Len(t, metrics, tree.Height()+1)
+
+	levels := tree.FillStats()
+	for _, metric := range metrics {
+		labels := map[string]string{}
+		for _, label := range metric.GetLabel() {
+			labels[label.GetName()] = label.GetValue()
+		}
+		assert.Equal(t, "orders", labels["tree"])
+
+		// The metrics are sorted by their labels, so every one is matched to its level by the level label.
+		i, err := strconv.Atoi(labels["level"])
+		assert.NoError(t, err)
+		level := levels[i]
+		kind := "index"
+		if level.Data {
+			kind = "data"
+		}
+		assert.Equal(t, kind, labels["kind"])
+		histogram := metric.GetHistogram()
+		assert.Equal(t, uint64(level.Nodes), histogram.GetSampleCount())
+		assert.InDelta(t, level.Sum, histogram.GetSampleSum(), 1e-9)
+
+		// The buckets are cumulative, and the last one holds every node of the level at rest.
+		buckets := histogram.GetBucket()
+		assert.Len(t, buckets, len(bpTree.FillBuckets))
+		assert.Equal(t, 1.0, buckets[len(buckets)-1].GetUpperBound())
+		assert.Equal(t, uint64(level.Nodes), buckets[len(buckets)-1].GetCumulativeCount())
+	}
+}
@@ -0,0 +1,52 @@
+package orderedmap
+
+import (
+	"cmp"
+	"math/rand"
+	"testing"
+)
+
+// RunBenchmarks ⛏️ runs the benchmark suite with n keys, so the implementations can be compared with the same numbers.
+func RunBenchmarks[K cmp.Ordered, V any](b *testing.B, newMap func() OrderedMap[K, V], key func(i int) K, value func(i int) V, n int) {
+	// Prepare a full map and the keys in random order.
+	order := rand.New(rand.NewSource(1)).Perm(n)
+	full := newMap()
+	for _, i := range order {
+		full.Put(key(i), value(i))
+	}
+
+	b.Run("Put", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			m := newMap()
+			for _, j := range order {
+				m.Put(key(j), value(j))
+			}
+		}
+	})
+
+	b.Run("Get", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			full.Get(key(order[i%n]))
+		}
+	})
+
+	b.Run("Delete", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			m := newMap()
+			for _, j := range order {
+				m.Put(key(j), value(j))
+			}
+			b.StartTimer()
+			for _, j := range order {
+				m.Delete(key(j))
+			}
+		}
+	})
+
+	b.Run("Ascend", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			full.Ascend(func(K, V) bool { return true })
+		}
+	})
+}
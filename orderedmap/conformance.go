@@ -0,0 +1,152 @@
+package orderedmap
+
+import (
+	"cmp"
+	"math/rand"
+	"testing"
+)
+
+// =====================================================================================================================
+//                  🛠️ Conformance (Tool)
+// Conformance checks that an implementation behaves like an ordered map, by comparing it with a built-in map.
+// key(i) must increase strictly with i, so the suite knows the expected order, and value(i) can be anything.
+// A map with signed keys should also run it with CenteredInt64Keys, since Int64Keys never gives a negative key.
+// =====================================================================================================================
+
+// RunConformance ⛏️ runs the conformance suite, newMap must return an empty map every time.
+func RunConformance[K cmp.Ordered, V comparable](t *testing.T, newMap func() OrderedMap[K, V], key func(i int) K, value func(i int) V) {
+	t.Run("Empty", func(t *testing.T) {
+		m := newMap()
+		if m.Len() != 0 {
+			t.Fatalf("Len of an empty map is %d", m.Len())
+		}
+		if _, found := m.Get(key(1)); found {
+			t.Fatal("Get finds a key in an empty map")
+		}
+		if m.Delete(key(1)) {
+			t.Fatal("Delete removes a key from an empty map")
+		}
+		m.Ascend(func(K, V) bool {
+			t.Fatal("Ascend visits a key in an empty map")
+			return false
+		})
+	})
+
+	t.Run("Put and get", func(t *testing.T) {
+		m := newMap()
+		order := rand.New(rand.NewSource(1)).Perm(1000)
+		for _, i := range order {
+			m.Put(key(i), value(i))
+		}
+		if m.Len() != len(order) {
+			t.Fatalf("Len is %d, want %d", m.Len(), len(order))
+		}
+		for i := range order {
+			if got, found := m.Get(key(i)); !found || got != value(i) {
+				t.Fatalf("Get(%v) = %v, %v, want %v, true", key(i), got, found, value(i))
+			}
+		}
+		if _, found := m.Get(key(len(order))); found {
+			t.Fatalf("Get(%v) finds a key which has never been put", key(len(order)))
+		}
+	})
+
+	t.Run("Put replaces the value", func(t *testing.T) {
+		m := newMap()
+		m.Put(key(1), value(1))
+		m.Put(key(1), value(2))
+		if m.Len() != 1 {
+			t.Fatalf("Len is %d after replacing, want 1", m.Len())
+		}
+		if got, _ := m.Get(key(1)); got != value(2) {
+			t.Fatalf("Get(%v) = %v after replacing, want %v", key(1), got, value(2))
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		m := newMap()
+		order := rand.New(rand.NewSource(2)).Perm(1000)
+		for _, i := range order {
+			m.Put(key(i), value(i))
+		}
+
+		// Delete the even keys in random order, twice.
+		for _, i := range order {
+			if i%2 == 0 && !m.Delete(key(i)) {
+				t.Fatalf("Delete(%v) does not find the key", key(i))
+			}
+		}
+		for _, i := range order {
+			if i%2 == 0 && m.Delete(key(i)) {
+				t.Fatalf("Delete(%v) removes a key twice", key(i))
+			}
+		}
+		if m.Len() != len(order)/2 {
+			t.Fatalf("Len is %d, want %d", m.Len(), len(order)/2)
+		}
+		for i := range order {
+			if _, found := m.Get(key(i)); found != (i%2 == 1) {
+				t.Fatalf("Get(%v) found = %v after deleting the even keys", key(i), found)
+			}
+		}
+	})
+
+	t.Run("Ascend", func(t *testing.T) {
+		m := newMap()
+		for _, i := range rand.New(rand.NewSource(3)).Perm(500) {
+			m.Put(key(i), value(i))
+		}
+
+		// Every key is visited once in ascending order.
+		next := 0
+		m.Ascend(func(k K, v V) bool {
+			if k != key(next) || v != value(next) {
+				t.Fatalf("Ascend visits %v, %v, want %v, %v", k, v, key(next), value(next))
+			}
+			next++
+			return true
+		})
+		if next != 500 {
+			t.Fatalf("Ascend visits %d keys, want 500", next)
+		}
+
+		// Returning false stops the walk.
+		visited := 0
+		m.Ascend(func(K, V) bool {
+			visited++
+			return visited < 3
+		})
+		if visited != 3 {
+			t.Fatalf("Ascend visits %d keys after stopping at 3", visited)
+		}
+	})
+
+	t.Run("Random operations", func(t *testing.T) {
+		m := newMap()
+		model := make(map[int]int)
+		rng := rand.New(rand.NewSource(4))
+		for op := 0; op < 5000; op++ {
+			i, v := rng.Intn(300), rng.Intn(1000)
+			switch rng.Intn(3) {
+			case 0:
+				m.Put(key(i), value(v))
+				model[i] = v
+			case 1:
+				_, expected := model[i]
+				if m.Delete(key(i)) != expected {
+					t.Fatalf("operation %d: Delete(%v) does not match the model", op, key(i))
+				}
+				delete(model, i)
+			case 2:
+				got, found := m.Get(key(i))
+				expected, ok := model[i]
+				if found != ok || (found && got != value(expected)) {
+					t.Fatalf("operation %d: Get(%v) does not match the model", op, key(i))
+				}
+			}
+			if m.Len() != len(model) {
+				t.Fatalf("operation %d: Len is %d, want %d", op, m.Len(), len(model))
+			}
+		}
+	})
+}
@@ -0,0 +1,171 @@
+package orderedmap
+
+import (
+	"cmp"
+	"fmt"
+)
+
+// Mirror ⛏️ applies every write to two ordered maps and compares their reads on a sampling basis,
+// so a new implementation can run next to the old one until it is trusted, such as a generic tree next to the int64 tree.
+// The reads are served by the primary map, the secondary map only answers the sampled comparisons.
+// Like the maps it wraps, it is not safe for concurrent use.
+type Mirror[K cmp.Ordered, V comparable] struct {
+	primary   OrderedMap[K, V]       // The trusted map, which serves the reads.
+	secondary OrderedMap[K, V]       // The map under validation.
+	every     int                    // Compare one of every this many reads.
+	onDiff    func(diff Mismatch[K]) // Called with every mismatch, nil calls nothing.
+	stats     MirrorStats            // The reads and the comparisons so far.
+}
+
+// Mismatch ⛏️ is a difference between the primary and the secondary maps.
+type Mismatch[K cmp.Ordered] struct {
+	Op     string // The operation, such as Get, Delete, Len or Ascend.
+	Key    K      // The key of the operation, the zero key for Len.
+	Detail string // What each map returned.
+}
+
+// Error ⛏️ describes the mismatch, so it can be returned or logged as an error.
+func (m Mismatch[K]) Error() string {
+	return fmt.Sprintf("mirror %s %v: %s", m.Op, m.Key, m.Detail)
+}
+
+// MirrorStats ⛏️ counts the reads and the comparisons of a mirror.
+type MirrorStats struct {
+	Reads      uint64 // The reads served by the primary map.
+	Compared   uint64 // The reads and the deletes compared with the secondary map.
+	Mismatches uint64 // The comparisons which found a difference.
+}
+
+// MirrorOption ⛏️ configures a mirror.
+type MirrorOption[K cmp.Ordered, V comparable] func(*Mirror[K, V])
+
+// WithSampleEvery ⛏️ compares one of every n reads with the secondary map, 1 compares all of them, which is the default.
+func WithSampleEvery[K cmp.Ordered, V comparable](n int) MirrorOption[K, V] {
+	return func(m *Mirror[K, V]) {
+		m.every = max(n, 1)
+	}
+}
+
+// WithMismatchHandler ⛏️ sets the function called with every mismatch, such as one logging it or failing a test.
+func WithMismatchHandler[K cmp.Ordered, V comparable](fn func(diff Mismatch[K])) MirrorOption[K, V] {
+	return func(m *Mirror[K, V]) {
+		m.onDiff = fn
+	}
+}
+
+// NewMirror ⛏️ mirrors the writes of the primary map into the secondary map, both must hold the same keys to begin with.
+func NewMirror[K cmp.Ordered, V comparable](primary, secondary OrderedMap[K, V], opts ...MirrorOption[K, V]) *Mirror[K, V] {
+	m := &Mirror[K, V]{primary: primary, secondary: secondary, every: 1}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Put ⛏️ inserts the key into both maps, or replaces its value.
+func (m *Mirror[K, V]) Put(key K, value V) {
+	m.primary.Put(key, value)
+	m.secondary.Put(key, value)
+}
+
+// Get ⛏️ returns the value of the key in the primary map, and compares it with the secondary map when sampled.
+func (m *Mirror[K, V]) Get(key K) (value V, found bool) {
+	value, found = m.primary.Get(key)
+	if m.sampled() {
+		other, otherFound := m.secondary.Get(key)
+		m.compare(other != value || otherFound != found, "Get", key, "primary %v (found %t), secondary %v (found %t)", value, found, other, otherFound)
+	}
+	return
+}
+
+// Delete ⛏️ removes the key from both maps, and their answers are always compared since both run anyway.
+func (m *Mirror[K, V]) Delete(key K) bool {
+	deleted := m.primary.Delete(key)
+	other := m.secondary.Delete(key)
+	m.compare(deleted != other, "Delete", key, "primary deleted %t, secondary deleted %t", deleted, other)
+	return deleted
+}
+
+// Len ⛏️ returns the number of keys in the primary map, and compares it with the secondary map when sampled.
+func (m *Mirror[K, V]) Len() int {
+	n := m.primary.Len()
+	if m.sampled() {
+		other := m.secondary.Len()
+		var zero K
+		m.compare(n != other, "Len", zero, "primary %d, secondary %d", n, other)
+	}
+	return n
+}
+
+// Ascend ⛏️ calls fn for every key of the primary map in ascending order until fn returns false.
+// When sampled, the keys and the values fn has seen are compared with the secondary map, in the same order.
+func (m *Mirror[K, V]) Ascend(fn func(key K, value V) bool) {
+	if !m.sampled() {
+		m.primary.Ascend(fn)
+		return
+	}
+
+	// Collect the secondary pairs first, the maps can not be walked in step.
+	type pair struct {
+		key   K
+		value V
+	}
+	var others []pair
+	m.secondary.Ascend(func(key K, value V) bool {
+		others = append(others, pair{key, value})
+		return true
+	})
+
+	// Compare every pair fn sees, and report the first difference only.
+	i, differs, stopped := 0, false, false
+	m.primary.Ascend(func(key K, value V) bool {
+		if !differs {
+			switch {
+			case i >= len(others):
+				differs = true
+				m.compare(true, "Ascend", key, "primary has %v=%v at position %d, secondary has ended", key, value, i)
+			case others[i] != (pair{key, value}):
+				differs = true
+				m.compare(true, "Ascend", key, "primary has %v=%v at position %d, secondary has %v=%v", key, value, i, others[i].key, others[i].value)
+			}
+		}
+		i++
+		if !fn(key, value) {
+			stopped = true
+			return false
+		}
+		return true
+	})
+	if differs {
+		return
+	}
+	if !stopped && i < len(others) {
+		m.compare(true, "Ascend", others[i].key, "primary has ended at position %d, secondary has %v=%v", i, others[i].key, others[i].value)
+		return
+	}
+	var zero K
+	m.compare(false, "Ascend", zero, "")
+}
+
+// Stats ⛏️ returns the reads and the comparisons so far.
+func (m *Mirror[K, V]) Stats() MirrorStats {
+	return m.stats
+}
+
+// sampled ⛏️ counts a read and reports whether it is compared.
+func (m *Mirror[K, V]) sampled() bool {
+	m.stats.Reads++
+	return m.stats.Reads%uint64(m.every) == 0
+}
+
+// compare ⛏️ counts a comparison, and reports the mismatch when the maps differ.
+func (m *Mirror[K, V]) compare(differs bool, op string, key K, format string, args ...any) {
+	m.stats.Compared++
+	if !differs {
+		return
+	}
+	m.stats.Mismatches++
+	if m.onDiff != nil {
+		m.onDiff(Mismatch[K]{Op: op, Key: key, Detail: fmt.Sprintf(format, args...)})
+	}
+}
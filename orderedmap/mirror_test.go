@@ -0,0 +1,70 @@
+package orderedmap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// lossyMap is a sorted slice which silently drops the keys above a limit, a broken implementation to be caught.
+type lossyMap struct {
+	sliceMap
+	limit int64
+}
+
+func (m *lossyMap) Put(key, value int64) {
+	if key <= m.limit {
+		m.sliceMap.Put(key, value)
+	}
+}
+
+// Test_Mirror_Conformance runs the conformance suite on a mirror of two sorted slices.
+func Test_Mirror_Conformance(t *testing.T) {
+	RunConformance(t, func() OrderedMap[int64, int64] {
+		return NewMirror[int64, int64](&sliceMap{}, &sliceMap{}, WithMismatchHandler[int64, int64](func(diff Mismatch[int64]) { t.Error(diff) }))
+	}, Int64Keys, Int64Keys)
+}
+
+// Test_Mirror tests comparing the reads of a broken secondary map on a sampling basis.
+func Test_Mirror(t *testing.T) {
+	var diffs []Mismatch[int64]
+	mirror := NewMirror[int64, int64](&sliceMap{}, &lossyMap{limit: 5},
+		WithSampleEvery[int64, int64](2),
+		WithMismatchHandler[int64, int64](func(diff Mismatch[int64]) { diffs = append(diffs, diff) }))
+	for key := int64(1); key <= 10; key++ {
+		mirror.Put(key, key*10)
+	}
+
+	// The reads are served by the primary map, and only every second one is compared.
+	for key := int64(1); key <= 10; key++ {
+		value, found := mirror.Get(key)
+		assert.True(t, found)
+		assert.Equal(t, key*10, value)
+	}
+	assert.Equal(t, MirrorStats{Reads: 10, Compared: 5, Mismatches: 3}, mirror.Stats())
+	assert.Equal(t, []int64{6, 8, 10}, []int64{diffs[0].Key, diffs[1].Key, diffs[2].Key})
+	assert.Equal(t, "mirror Get 6: primary 60 (found true), secondary 0 (found false)", diffs[0].Error())
+
+	// The deletes are always compared.
+	diffs = nil
+	assert.True(t, mirror.Delete(7))
+	assert.Equal(t, "Delete", diffs[0].Op)
+
+	// Len and Ascend find the missing keys when sampled, and Ascend reports the first difference only.
+	diffs = nil
+	assert.Equal(t, 9, mirror.Len()) // The 11th read is not compared.
+	assert.Equal(t, 9, mirror.Len())
+	var keys []int64
+	mirror.Ascend(func(key, value int64) bool { keys = append(keys, key); return true }) // The 13th read is not compared.
+	mirror.Ascend(func(key, value int64) bool { return true })
+	assert.Equal(t, []int64{1, 2, 3, 4, 5, 6, 8, 9, 10}, keys)
+	assert.Len(t, diffs, 2)
+	assert.Equal(t, "mirror Len 0: primary 9, secondary 5", diffs[0].Error())
+	assert.Equal(t, "mirror Ascend 6: primary has 6=60 at position 5, secondary has ended", diffs[1].Error())
+
+	// An early stop compares only the keys fn has seen.
+	diffs = nil
+	mirror.Get(1)
+	mirror.Ascend(func(key, value int64) bool { return key < 3 })
+	assert.Empty(t, diffs)
+}
@@ -0,0 +1,33 @@
+// Package orderedmap defines the OrderedMap interface shared by the ordered data structures,
+// and a conformance suite and a benchmark suite which any implementation can run against itself.
+//
+//	func Test_MyMap(t *testing.T) {
+//		orderedmap.RunConformance(t, func() orderedmap.OrderedMap[int64, int64] { return NewMyMap() }, orderedmap.Int64Keys, orderedmap.Int64Keys)
+//	}
+package orderedmap
+
+import "cmp"
+
+// OrderedMap ⛏️ is a map which keeps its keys in ascending order.
+type OrderedMap[K cmp.Ordered, V any] interface {
+	// Put inserts the key, or replaces the value when the key is already there.
+	Put(key K, value V)
+	// Get returns the value of the key and whether it is found.
+	Get(key K) (value V, found bool)
+	// Delete removes the key and reports whether it was there.
+	Delete(key K) bool
+	// Len returns the number of keys.
+	Len() int
+	// Ascend calls fn for every key in ascending order until fn returns false.
+	Ascend(fn func(key K, value V) bool)
+}
+
+// Int64Keys ⛏️ maps i to int64(i), which can be used as both the key and the value generators of the suites.
+func Int64Keys(i int) int64 {
+	return int64(i)
+}
+
+// CenteredInt64Keys ⛏️ maps i to int64(i)-500, so the suites go through the negative keys and zero as well as the positive ones.
+func CenteredInt64Keys(i int) int64 {
+	return int64(i) - 500
+}
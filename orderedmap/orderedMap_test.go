@@ -0,0 +1,64 @@
+package orderedmap
+
+import (
+	"sort"
+	"testing"
+)
+
+// sliceMap is a sorted slice, the simplest ordered map, used to check the suites themselves.
+type sliceMap struct {
+	keys   []int64
+	values []int64
+}
+
+func (m *sliceMap) find(key int64) (int, bool) {
+	i := sort.Search(len(m.keys), func(i int) bool { return m.keys[i] >= key })
+	return i, i < len(m.keys) && m.keys[i] == key
+}
+
+func (m *sliceMap) Put(key, value int64) {
+	i, found := m.find(key)
+	if found {
+		m.values[i] = value
+		return
+	}
+	m.keys = append(m.keys[:i], append([]int64{key}, m.keys[i:]...)...)
+	m.values = append(m.values[:i], append([]int64{value}, m.values[i:]...)...)
+}
+
+func (m *sliceMap) Get(key int64) (int64, bool) {
+	if i, found := m.find(key); found {
+		return m.values[i], true
+	}
+	return 0, false
+}
+
+func (m *sliceMap) Delete(key int64) bool {
+	i, found := m.find(key)
+	if found {
+		m.keys = append(m.keys[:i], m.keys[i+1:]...)
+		m.values = append(m.values[:i], m.values[i+1:]...)
+	}
+	return found
+}
+
+func (m *sliceMap) Len() int { return len(m.keys) }
+
+func (m *sliceMap) Ascend(fn func(key, value int64) bool) {
+	for i := range m.keys {
+		if !fn(m.keys[i], m.values[i]) {
+			return
+		}
+	}
+}
+
+// Test_Conformance runs the conformance suite on the sorted slice.
+func Test_Conformance(t *testing.T) {
+	RunConformance(t, func() OrderedMap[int64, int64] { return &sliceMap{} }, Int64Keys, Int64Keys)
+	RunConformance(t, func() OrderedMap[int64, int64] { return &sliceMap{} }, CenteredInt64Keys, Int64Keys)
+}
+
+// Benchmark_OrderedMap runs the benchmark suite on the sorted slice.
+func Benchmark_OrderedMap(b *testing.B) {
+	RunBenchmarks(b, func() OrderedMap[int64, int64] { return &sliceMap{} }, Int64Keys, Int64Keys, 1000)
+}
@@ -1,8 +1,7 @@
 package randhub
 
 import (
-	"math/rand"
-	"time"
+	"github.com/panhongrainbow/go-algorithm/simhub"
 )
 
 // =====================================================================================================================
@@ -35,8 +34,8 @@ func (np *FastPool) GenerateUniqueInt64Numbers(min, max int64, count, withdraw i
 	// Create a slice to store the removed numbers with an initial capacity of 'withdraw'.
 	removedNumbers := make([]int64, 0, withdraw)
 
-	// Initialize a new random number generator with the current time as the seed.
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	// Initialize a new random number generator, seeded by the time or by the simulation.
+	r := simhub.NewRand()
 
 	// Keep generating numbers until the 'count' of unique numbers is reached.
 	for len(newNumbers) < count {
@@ -54,7 +53,7 @@ func (np *FastPool) GenerateUniqueInt64Numbers(min, max int64, count, withdraw i
 	// If fullRemove is true, all numbers in the pool will be removed.
 	if fullRemove {
 		// Iterate through the pool to remove all numbers.
-		for num := range np.pool {
+		for num := range poolOrder(np.pool, r) {
 			// Add each number to the removedNumbers slice.
 			removedNumbers = append(removedNumbers, num)
 		}
@@ -62,7 +61,7 @@ func (np *FastPool) GenerateUniqueInt64Numbers(min, max int64, count, withdraw i
 		np.pool = make(map[int64]struct{}) // Clear the pool.
 	} else {
 		// If fullRemove is false, only remove 'withdraw' number of items from the pool.
-		for num := range np.pool {
+		for num := range poolOrder(np.pool, r) {
 			// Remove the number from the pool.
 			delete(np.pool, num)
 			// Add the removed number to the removedNumbers slice.
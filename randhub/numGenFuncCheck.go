@@ -3,8 +3,8 @@ package randhub
 import (
 	"errors"
 	"fmt"
-	"math/rand"
-	"time"
+
+	"github.com/panhongrainbow/go-algorithm/simhub"
 )
 
 // =====================================================================================================================
@@ -34,8 +34,8 @@ func GenerateNumbers[T Number](count uint64, minNum, maxNum T) ([]T, error) {
 		return nil, errors.New("minNum must be less than or equal to maxNum")
 	}
 
-	// Create a new random number generator with a seed based on the current time.
-	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	// Create a new random number generator, seeded by the time or by the simulation.
+	rnd := simhub.NewRand()
 
 	// Result slice to store the generated numbers.
 	result := make([]T, 0, count)
@@ -72,8 +72,8 @@ func GenerateUniqueNumbers[T Number](count uint64, minNum, maxNum T) ([]T, error
 		// For float64 types, no range validation is performed, as floating-point numbers can represent an infinite range and may involve irrational numbers.
 	}
 
-	// Create a new random number generator with a seed based on the current time.
-	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	// Create a new random number generator, seeded by the time or by the simulation.
+	rnd := simhub.NewRand()
 
 	// Use a map to keep track of unique numbers.
 	numbers := make(map[T]struct{})
@@ -2,9 +2,13 @@ package randhub
 
 import (
 	"errors"
+	"iter"
+	"maps"
 	"math/rand"
+	"slices"
 	"sort"
-	"time"
+
+	"github.com/panhongrainbow/go-algorithm/simhub"
 )
 
 // =====================================================================================================================
@@ -137,8 +141,8 @@ func (np *NumberPool[T]) GenerateUniqueNumbers(minNum, maxNum T, opts ...NpOpt)
 	// Initialize a slice to store the numbers that will be removed from the pool.
 	removedNumbers := make([]T, 0, npset.withdraw)
 
-	// Create a new random number generator with a seed based on the current time.
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	// Create a new random number generator, seeded by the time or by the simulation.
+	r := simhub.NewRand()
 
 	// Generate new unique numbers within the range [minNum, maxNum].
 	for len(newNumbers) < npset.count {
@@ -156,13 +160,13 @@ func (np *NumberPool[T]) GenerateUniqueNumbers(minNum, maxNum T, opts ...NpOpt)
 	if npset.fullRemove {
 		// Convert the keys of the map to a slice.
 		keys := make([]T, 0, len(np.pool))
-		for num := range np.pool {
+		for num := range poolOrder(np.pool, r) {
 			keys = append(keys, num)
 		}
 
 		// Optionally shuffle the keys to randomize the order.
 		if npset.shuffle {
-			rand.Shuffle(len(keys), func(i, j int) {
+			r.Shuffle(len(keys), func(i, j int) {
 				keys[i], keys[j] = keys[j], keys[i]
 			})
 		}
@@ -176,13 +180,13 @@ func (np *NumberPool[T]) GenerateUniqueNumbers(minNum, maxNum T, opts ...NpOpt)
 	} else {
 		// Convert the keys of the map to a slice.
 		keys := make([]T, 0, len(np.pool))
-		for num := range np.pool {
+		for num := range poolOrder(np.pool, r) {
 			keys = append(keys, num)
 		}
 
 		// Optionally shuffle the keys to randomize the order.
 		if npset.shuffle {
-			rand.Shuffle(len(keys), func(i, j int) {
+			r.Shuffle(len(keys), func(i, j int) {
 				keys[i], keys[j] = keys[j], keys[i]
 			})
 		}
@@ -213,3 +217,16 @@ func generateRandomNumber[T Number](min, max T, r *rand.Rand) T {
 	return T(minFloat + (maxFloat-minFloat)*r.Float64())
 
 }
+
+// poolOrder 🧫 iterates the numbers of the pool in the map order, which is random but not by any seed,
+// so a simulation iterates them sorted and shuffled by r instead, the same order in every replay.
+func poolOrder[T Number](pool map[T]struct{}, r *rand.Rand) iter.Seq[T] {
+	if !simhub.Active() {
+		return maps.Keys(pool)
+	}
+	keys := slices.Sorted(maps.Keys(pool))
+	r.Shuffle(len(keys), func(i, j int) {
+		keys[i], keys[j] = keys[j], keys[i]
+	})
+	return slices.Values(keys)
+}
@@ -0,0 +1,124 @@
+package simhub
+
+import (
+	"time"
+)
+
+// =====================================================================================================================
+//                  🛠️ Fake Clock (Tool)
+// Fake Clock stands still in a simulation until Advance or Sleep moves it, and the tickers tick as it passes them,
+// so the times printed and the refreshes made are the same in every replay. (假时钟)
+// =====================================================================================================================
+
+// Now ⛏️ returns the time of the fake clock in a simulation, and time.Now otherwise.
+func Now() time.Time {
+	if s := active.Load(); s != nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.now
+	}
+	return time.Now()
+}
+
+// Since ⛏️ returns the time passed since t, by the fake clock in a simulation.
+func Since(t time.Time) time.Duration {
+	return Now().Sub(t)
+}
+
+// Sleep ⛏️ advances the fake clock by d in a simulation, and sleeps otherwise.
+func Sleep(d time.Duration) {
+	if Active() {
+		Advance(d)
+		return
+	}
+	time.Sleep(d)
+}
+
+// Advance ⛏️ moves the fake clock forward by d, and ticks the tickers it passes.
+// Like time.Ticker, a ticker holds a single tick, and the ticks nobody took are dropped.
+// Without a simulation, it does nothing.
+func Advance(d time.Duration) {
+	s := active.Load()
+	if s == nil || d <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = s.now.Add(d)
+	for _, t := range s.tickers {
+		if t.stopped || t.next.After(s.now) {
+			continue
+		}
+		select {
+		case t.c <- t.next:
+		default:
+		}
+		// Skip the ticks passed at once, the next one is the first after now.
+		for !t.next.After(s.now) {
+			t.next = t.next.Add(t.period)
+		}
+	}
+}
+
+// Ticker ⛏️ is a time.Ticker, ticking by the fake clock in a simulation.
+type Ticker struct {
+	C      <-chan time.Time // The channel of the ticks.
+	c      chan time.Time   // The same channel, sent to by Advance.
+	real   *time.Ticker     // The real ticker, nil in a simulation.
+	sim    *simulation      // The simulation whose clock ticks it, nil without a simulation.
+	period time.Duration    // The time between the ticks.
+	next   time.Time        // The time of the next tick by the fake clock.
+	// stopped is set by Stop and cleared by Reset.
+	stopped bool
+}
+
+// NewTicker ⛏️ returns a ticker ticking every d, which must be positive like time.NewTicker.
+func NewTicker(d time.Duration) *Ticker {
+	s := active.Load()
+	if s == nil {
+		real := time.NewTicker(d)
+		return &Ticker{C: real.C, real: real, period: d}
+	}
+	if d <= 0 {
+		panic("non-positive interval for simhub.NewTicker")
+	}
+	c := make(chan time.Time, 1)
+	t := &Ticker{C: c, c: c, sim: s, period: d}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t.next = s.now.Add(d)
+	s.tickers = append(s.tickers, t)
+	return t
+}
+
+// Stop ⛏️ stops the ticker and drops the tick waiting in it, so nothing is received until Reset.
+func (t *Ticker) Stop() {
+	if t.real != nil {
+		t.real.Stop()
+		return
+	}
+	t.sim.mu.Lock()
+	defer t.sim.mu.Unlock()
+	t.stopped = true
+	t.drain()
+}
+
+// Reset ⛏️ starts the ticker again with the period d, the next tick is d after now.
+func (t *Ticker) Reset(d time.Duration) {
+	if t.real != nil {
+		t.real.Reset(d)
+		return
+	}
+	t.sim.mu.Lock()
+	defer t.sim.mu.Unlock()
+	t.stopped, t.period, t.next = false, d, t.sim.now.Add(d)
+	t.drain()
+}
+
+// drain ⛏️ drops the tick waiting in the channel, the caller holds the lock of the simulation.
+func (t *Ticker) drain() {
+	select {
+	case <-t.c:
+	default:
+	}
+}
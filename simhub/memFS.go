@@ -0,0 +1,466 @@
+package simhub
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// =====================================================================================================================
+//                  🛠️ Memory FS (Tool)
+// Memory FS keeps the files in memory, so the persistence code can be unit-tested without touching the disk,
+// and a simulation never depends on what an earlier run left on the disk, and leaves nothing behind. (内存文件系统)
+// A path missing in memory is looked up in the lower filesystem, which is only read, so a simulation still finds
+// the configs and the fixtures on the disk.
+// =====================================================================================================================
+
+var (
+	errIsDir    = errors.New("is a directory")
+	errNotDir   = errors.New("not a directory")
+	errNotEmpty = errors.New("directory not empty")
+	errReadOnly = errors.New("not open for this access")
+)
+
+// MemFS ⛏️ is a VFS in memory, safe for concurrent use. The zero value is not usable, use NewMemFS.
+type MemFS struct {
+	mu    sync.Mutex
+	lower VFS                  // The filesystem read for the paths missing in memory, nil for memory only.
+	nodes map[string]*memEntry // The files and the directories, by cleaned path.
+}
+
+// memEntry ⛏️ is a file or a directory of a MemFS.
+type memEntry struct {
+	data    []byte
+	dir     bool
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+// NewMemFS ⛏️ returns an empty filesystem in memory over the lower filesystem, nil for memory only.
+// The root and the current directory always exist.
+func NewMemFS(lower VFS) *MemFS {
+	return &MemFS{lower: lower, nodes: make(map[string]*memEntry)}
+}
+
+// isDir ⛏️ reports whether the path is a directory, in memory or in the lower filesystem, the caller holds the lock.
+func (m *MemFS) isDir(path string) bool {
+	if e, ok := m.nodes[path]; ok {
+		return e.dir
+	}
+	if path == filepath.Dir(path) {
+		return true
+	}
+	if m.lower != nil {
+		if info, err := m.lower.Stat(path); err == nil {
+			return info.IsDir()
+		}
+	}
+	return false
+}
+
+// Open ⛏️ opens a file for reading.
+func (m *MemFS) Open(name string) (File, error) {
+	return m.OpenFile(name, os.O_RDONLY, 0)
+}
+
+// OpenFile ⛏️ opens a file with the flags of os.OpenFile.
+// A file of the lower filesystem opened for writing is copied into memory first, so the lower one is never written.
+func (m *MemFS) OpenFile(name string, flag int, perm fs.FileMode) (File, error) {
+	name = filepath.Clean(name)
+	writable := flag&(os.O_WRONLY|os.O_RDWR) != 0
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.nodes[name]
+	if !ok && m.lower != nil {
+		if info, err := m.lower.Stat(name); err == nil {
+			// A lower file which is only read is read from the lower filesystem.
+			if !writable {
+				return m.lower.OpenFile(name, flag, perm)
+			}
+			if info.IsDir() {
+				return nil, &fs.PathError{Op: "open", Path: name, Err: errIsDir}
+			}
+			if flag&(os.O_CREATE|os.O_EXCL) == os.O_CREATE|os.O_EXCL {
+				return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrExist}
+			}
+			e = &memEntry{mode: info.Mode(), modTime: Now()}
+			if flag&os.O_TRUNC == 0 {
+				if e.data, err = m.lower.ReadFile(name); err != nil {
+					return nil, err
+				}
+			}
+			m.nodes[name], ok = e, true
+		}
+	}
+
+	switch {
+	case !ok && flag&os.O_CREATE == 0:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	case !ok:
+		if !m.isDir(filepath.Dir(name)) {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+		}
+		e = &memEntry{mode: perm, modTime: Now()}
+		m.nodes[name] = e
+	case e.dir:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: errIsDir}
+	case flag&(os.O_CREATE|os.O_EXCL) == os.O_CREATE|os.O_EXCL:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrExist}
+	case writable && flag&os.O_TRUNC != 0:
+		e.data, e.modTime = nil, Now()
+	}
+	return &memFile{fs: m, name: name, entry: e, readable: flag&os.O_WRONLY == 0, writable: writable, append: flag&os.O_APPEND != 0}, nil
+}
+
+// ReadFile ⛏️ reads a whole file.
+func (m *MemFS) ReadFile(name string) ([]byte, error) {
+	name = filepath.Clean(name)
+	m.mu.Lock()
+	e, ok := m.nodes[name]
+	var data []byte
+	if ok && !e.dir {
+		data = slices.Clone(e.data)
+	}
+	m.mu.Unlock()
+
+	switch {
+	case ok && e.dir:
+		return nil, &fs.PathError{Op: "read", Path: name, Err: errIsDir}
+	case ok:
+		return data, nil
+	case m.lower != nil:
+		return m.lower.ReadFile(name)
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// WriteFile ⛏️ writes a whole file, creating it in an existing directory, or replacing it.
+func (m *MemFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	name = filepath.Clean(name)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.isDir(name) {
+		return &fs.PathError{Op: "open", Path: name, Err: errIsDir}
+	}
+	if !m.isDir(filepath.Dir(name)) {
+		return &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	if e, ok := m.nodes[name]; ok {
+		e.data, e.modTime = slices.Clone(data), Now()
+		return nil
+	}
+	m.nodes[name] = &memEntry{data: slices.Clone(data), mode: perm, modTime: Now()}
+	return nil
+}
+
+// Stat ⛏️ describes a file or a directory.
+func (m *MemFS) Stat(name string) (fs.FileInfo, error) {
+	name = filepath.Clean(name)
+	m.mu.Lock()
+	e, ok := m.nodes[name]
+	var info fs.FileInfo
+	if ok {
+		info = e.info(name)
+	}
+	m.mu.Unlock()
+
+	switch {
+	case ok:
+		return info, nil
+	case m.lower != nil:
+		return m.lower.Stat(name)
+	case name == filepath.Dir(name):
+		return (&memEntry{dir: true, mode: 0o755}).info(name), nil
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadDir ⛏️ lists a directory sorted by the name, the entries of memory over the ones of the lower filesystem.
+func (m *MemFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	name = filepath.Clean(name)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, inMemory := m.nodes[name]
+	if inMemory && !e.dir {
+		return nil, &fs.PathError{Op: "readdirent", Path: name, Err: errNotDir}
+	}
+	entries := make(map[string]fs.DirEntry)
+	var lowerErr error = &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	if m.lower != nil {
+		var lower []fs.DirEntry
+		if lower, lowerErr = m.lower.ReadDir(name); lowerErr == nil {
+			for _, entry := range lower {
+				entries[entry.Name()] = entry
+			}
+		}
+	}
+	if !inMemory && lowerErr != nil && name != filepath.Dir(name) {
+		return nil, lowerErr
+	}
+	for path, child := range m.nodes {
+		if path != name && filepath.Dir(path) == name {
+			entries[filepath.Base(path)] = fs.FileInfoToDirEntry(child.info(path))
+		}
+	}
+
+	list := make([]fs.DirEntry, 0, len(entries))
+	for _, entry := range entries {
+		list = append(list, entry)
+	}
+	slices.SortFunc(list, func(a, b fs.DirEntry) int { return strings.Compare(a.Name(), b.Name()) })
+	return list, nil
+}
+
+// MkdirAll ⛏️ creates the directory and the missing parents, an existing directory is not an error.
+func (m *MemFS) MkdirAll(path string, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var missing []string
+	for dir := filepath.Clean(path); !m.isDir(dir); dir = filepath.Dir(dir) {
+		if _, err := m.statLocked(dir); err == nil {
+			return &fs.PathError{Op: "mkdir", Path: dir, Err: errNotDir}
+		}
+		missing = append(missing, dir)
+	}
+	for _, dir := range missing {
+		m.nodes[dir] = &memEntry{dir: true, mode: perm, modTime: Now()}
+	}
+	return nil
+}
+
+// statLocked ⛏️ describes a path in memory or in the lower filesystem, the caller holds the lock.
+func (m *MemFS) statLocked(name string) (fs.FileInfo, error) {
+	if e, ok := m.nodes[name]; ok {
+		return e.info(name), nil
+	}
+	if m.lower != nil {
+		return m.lower.Stat(name)
+	}
+	return nil, fs.ErrNotExist
+}
+
+// Remove ⛏️ removes a file or an empty directory from memory, the lower filesystem is never changed.
+func (m *MemFS) Remove(name string) error {
+	name = filepath.Clean(name)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.nodes[name]
+	if !ok {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	if e.dir && m.hasChildren(name) {
+		return &fs.PathError{Op: "remove", Path: name, Err: errNotEmpty}
+	}
+	delete(m.nodes, name)
+	return nil
+}
+
+// RemoveAll ⛏️ removes a path and everything under it from memory, a missing path is not an error.
+func (m *MemFS) RemoveAll(path string) error {
+	path = filepath.Clean(path)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for name := range m.nodes {
+		if name == path || isUnder(name, path) {
+			delete(m.nodes, name)
+		}
+	}
+	return nil
+}
+
+// Rename ⛏️ moves a file or a directory of memory, replacing the file at the new path.
+func (m *MemFS) Rename(oldpath, newpath string) error {
+	oldpath, newpath = filepath.Clean(oldpath), filepath.Clean(newpath)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.nodes[oldpath]
+	switch {
+	case !ok:
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: fs.ErrNotExist}
+	case !m.isDir(filepath.Dir(newpath)):
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: fs.ErrNotExist}
+	case m.isDir(newpath) && oldpath != newpath:
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: errIsDir}
+	}
+	if e.dir {
+		for name, child := range m.nodes {
+			if isUnder(name, oldpath) {
+				delete(m.nodes, name)
+				m.nodes[newpath+name[len(oldpath):]] = child
+			}
+		}
+	}
+	delete(m.nodes, oldpath)
+	m.nodes[newpath] = e
+	return nil
+}
+
+// Files ⛏️ returns the paths of the files in memory, sorted.
+func (m *MemFS) Files() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var names []string
+	for name, e := range m.nodes {
+		if !e.dir {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// hasChildren ⛏️ reports whether anything in memory is under the directory, the caller holds the lock.
+func (m *MemFS) hasChildren(dir string) bool {
+	for name := range m.nodes {
+		if isUnder(name, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// isUnder ⛏️ reports whether the cleaned path is inside the cleaned directory.
+func isUnder(path, dir string) bool {
+	if dir == filepath.Dir(dir) {
+		return path != dir && strings.HasPrefix(path, dir)
+	}
+	return strings.HasPrefix(path, dir+string(filepath.Separator))
+}
+
+// info ⛏️ describes the entry as a file at the path.
+func (e *memEntry) info(path string) fs.FileInfo {
+	return memInfo{name: filepath.Base(path), size: int64(len(e.data)), dir: e.dir, mode: e.mode, modTime: e.modTime}
+}
+
+// memInfo ⛏️ is the fs.FileInfo of a MemFS entry.
+type memInfo struct {
+	name    string
+	size    int64
+	dir     bool
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (i memInfo) Name() string       { return i.name }
+func (i memInfo) Size() int64        { return i.size }
+func (i memInfo) ModTime() time.Time { return i.modTime }
+func (i memInfo) IsDir() bool        { return i.dir }
+func (i memInfo) Sys() any           { return nil }
+func (i memInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | i.mode.Perm()
+	}
+	return i.mode.Perm()
+}
+
+// memFile ⛏️ is a file of a MemFS opened by OpenFile, it keeps its data even when it is removed meanwhile, like on Unix.
+type memFile struct {
+	fs       *MemFS
+	name     string
+	entry    *memEntry
+	offset   int
+	readable bool
+	writable bool
+	append   bool
+	closed   bool
+}
+
+// Name ⛏️ returns the path the file was opened with.
+func (f *memFile) Name() string {
+	return f.name
+}
+
+// Read ⛏️ reads from the offset, and returns io.EOF at the end.
+func (f *memFile) Read(p []byte) (int, error) {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+	switch {
+	case f.closed:
+		return 0, &fs.PathError{Op: "read", Path: f.name, Err: fs.ErrClosed}
+	case !f.readable:
+		return 0, &fs.PathError{Op: "read", Path: f.name, Err: errReadOnly}
+	case f.offset >= len(f.entry.data):
+		return 0, io.EOF
+	}
+	n := copy(p, f.entry.data[f.offset:])
+	f.offset += n
+	return n, nil
+}
+
+// Write ⛏️ writes at the offset, or at the end when the file was opened with O_APPEND.
+func (f *memFile) Write(p []byte) (int, error) {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+	switch {
+	case f.closed:
+		return 0, &fs.PathError{Op: "write", Path: f.name, Err: fs.ErrClosed}
+	case !f.writable:
+		return 0, &fs.PathError{Op: "write", Path: f.name, Err: errReadOnly}
+	}
+	if f.append {
+		f.offset = len(f.entry.data)
+	}
+	if end := f.offset + len(p); end > len(f.entry.data) {
+		f.entry.data = append(f.entry.data, make([]byte, end-len(f.entry.data))...)
+	}
+	f.offset += copy(f.entry.data[f.offset:], p)
+	f.entry.modTime = Now()
+	return len(p), nil
+}
+
+// Sync ⛏️ does nothing, the data is in memory already.
+func (f *memFile) Sync() error {
+	return nil
+}
+
+// Close ⛏️ closes the file, a second close is an error like on *os.File.
+func (f *memFile) Close() error {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+	if f.closed {
+		return &fs.PathError{Op: "close", Path: f.name, Err: fs.ErrClosed}
+	}
+	f.closed = true
+	return nil
+}
+
+// ReadFile ⛏️ reads a file of FS.
+func ReadFile(name string) ([]byte, error) {
+	return FS().ReadFile(name)
+}
+
+// WriteFile ⛏️ writes a file of FS.
+func WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return FS().WriteFile(name, data, perm)
+}
+
+// OpenAppend ⛏️ opens a file of FS for appending, creating it when it is missing.
+func OpenAppend(name string, perm fs.FileMode) (File, error) {
+	return FS().OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, perm)
+}
+
+// MkdirAll ⛏️ creates the directories in FS.
+func MkdirAll(path string, perm fs.FileMode) error {
+	return FS().MkdirAll(path, perm)
+}
+
+// Remove ⛏️ removes a file of FS.
+func Remove(name string) error {
+	return FS().Remove(name)
+}
+
+// Files ⛏️ returns the paths of the files written into memory in the simulation, sorted, and nil without a simulation.
+func Files() []string {
+	if s := active.Load(); s != nil {
+		return s.fs.Files()
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package simhub
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test_MemFS tests the files and the directories in memory, with the same errors as the os package.
+func Test_MemFS(t *testing.T) {
+	memory := NewMemFS(nil)
+
+	// A file needs its directory, and MkdirAll creates the parents.
+	assert.ErrorIs(t, memory.WriteFile("/a/b/c.txt", []byte("x"), 0o644), fs.ErrNotExist)
+	assert.NoError(t, memory.MkdirAll("/a/b", 0o755))
+	assert.NoError(t, memory.MkdirAll("/a/b", 0o755))
+	assert.NoError(t, memory.WriteFile("/a/b/c.txt", []byte("hello"), 0o644))
+	assert.Error(t, memory.MkdirAll("/a/b/c.txt/d", 0o755))
+	info, err := memory.Stat("/a/b/c.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "c.txt", info.Name())
+	assert.Equal(t, int64(5), info.Size())
+	assert.False(t, info.IsDir())
+	info, err = memory.Stat("/a")
+	assert.NoError(t, err)
+	assert.True(t, info.IsDir())
+	_, err = memory.Stat("/missing")
+	assert.True(t, os.IsNotExist(err))
+
+	// The flags of OpenFile behave like on the disk.
+	_, err = memory.OpenFile("/a/b/c.txt", os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	assert.ErrorIs(t, err, fs.ErrExist)
+	file, err := memory.OpenFile("/a/b/c.txt", os.O_WRONLY|os.O_APPEND, 0)
+	assert.NoError(t, err)
+	_, err = file.Write([]byte(", world"))
+	assert.NoError(t, err)
+	_, err = file.Read(make([]byte, 1))
+	assert.Error(t, err)
+	assert.NoError(t, file.Close())
+	assert.ErrorIs(t, file.Close(), fs.ErrClosed)
+	file, err = memory.Open("/a/b/c.txt")
+	assert.NoError(t, err)
+	data, err := io.ReadAll(file)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello, world", string(data))
+	assert.NoError(t, file.Close())
+	file, err = memory.OpenFile("/a/b/c.txt", os.O_RDWR|os.O_TRUNC, 0)
+	assert.NoError(t, err)
+	_, err = file.Write([]byte("hi"))
+	assert.NoError(t, err)
+	assert.NoError(t, file.Close())
+	data, _ = memory.ReadFile("/a/b/c.txt")
+	assert.Equal(t, "hi", string(data))
+
+	// The listing is sorted, the renames move the children, and a directory with children is not removed.
+	assert.NoError(t, memory.WriteFile("/a/b/a.txt", nil, 0o644))
+	assert.NoError(t, memory.MkdirAll("/a/b/z", 0o755))
+	entries, err := memory.ReadDir("/a/b")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.txt", "c.txt", "z"}, entryNames(entries))
+	assert.True(t, entries[2].IsDir())
+	assert.NoError(t, memory.Rename("/a/b", "/a/moved"))
+	assert.Equal(t, []string{"/a/moved/a.txt", "/a/moved/c.txt"}, memory.Files())
+	assert.Error(t, memory.Remove("/a/moved"))
+	assert.NoError(t, memory.Remove("/a/moved/a.txt"))
+	assert.ErrorIs(t, memory.Remove("/a/moved/a.txt"), fs.ErrNotExist)
+	assert.NoError(t, memory.RemoveAll("/a"))
+	assert.NoError(t, memory.RemoveAll("/a"))
+	assert.Empty(t, memory.Files())
+	_, err = memory.ReadDir("/a")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+// Test_MemFS_Lower tests that the lower filesystem is read for the paths missing in memory, and never written.
+func Test_MemFS_Lower(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "config.json"), []byte("{}"), 0o644))
+	memory := NewMemFS(OSFS{})
+
+	// A lower file is read, and is copied into memory when it is opened for writing.
+	data, err := memory.ReadFile(filepath.Join(dir, "config.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, "{}", string(data))
+	file, err := memory.OpenFile(filepath.Join(dir, "config.json"), os.O_WRONLY|os.O_APPEND, 0)
+	assert.NoError(t, err)
+	_, err = file.Write([]byte("\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, file.Close())
+	data, _ = memory.ReadFile(filepath.Join(dir, "config.json"))
+	assert.Equal(t, "{}\n", string(data))
+	data, _ = os.ReadFile(filepath.Join(dir, "config.json"))
+	assert.Equal(t, "{}", string(data))
+
+	// The lower directories hold the new files, and the listing merges both.
+	assert.NoError(t, memory.WriteFile(filepath.Join(dir, "out.txt"), []byte("memory"), 0o644))
+	entries, err := memory.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"config.json", "out.txt"}, entryNames(entries))
+	assert.NoFileExists(t, filepath.Join(dir, "out.txt"))
+}
+
+// entryNames 🧫 returns the names of the directory entries.
+func entryNames(entries []fs.DirEntry) (names []string) {
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return
+}
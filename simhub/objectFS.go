@@ -0,0 +1,489 @@
+package simhub
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// =====================================================================================================================
+//                  🛠️ Object FS (Tool)
+// Object FS keeps the files in an S3-compatible object store, so a nightly endurance run on an ephemeral CI machine
+// can upload its snapshots, traces and reports before the machine is gone. (对象存储文件系统)
+// The paths become the keys under the prefix in the bucket, and a directory is only the common prefix of its keys:
+// it exists while something is under it, and MkdirAll creates nothing.
+// A file opened for writing is kept in memory, and uploaded as a whole object when it is synced or closed.
+// =====================================================================================================================
+
+// ObjectStore ⛏️ is where ObjectFS keeps the objects and how it signs in.
+type ObjectStore struct {
+	Endpoint  string       // The URL of the store, such as https://s3.us-east-1.amazonaws.com or http://localhost:9000.
+	Region    string       // The region the requests are signed for, such as us-east-1.
+	Bucket    string       // The bucket holding the objects, addressed in the path of the URL.
+	Prefix    string       // The key prefix all the paths are put under, such as nightly, empty for the bucket root.
+	AccessKey string       // The access key ID.
+	SecretKey string       // The secret access key.
+	Client    *http.Client // The client sending the requests, nil for http.DefaultClient.
+}
+
+// ObjectFS ⛏️ is a VFS in an S3-compatible object store, safe for concurrent use.
+type ObjectFS struct {
+	store ObjectStore
+}
+
+// NewObjectFS ⛏️ returns the filesystem in the object store, checking that the endpoint and the bucket are set.
+func NewObjectFS(store ObjectStore) (*ObjectFS, error) {
+	if store.Endpoint == "" || store.Bucket == "" {
+		return nil, errors.New("the object store needs an endpoint and a bucket")
+	}
+	if _, err := url.Parse(store.Endpoint); err != nil {
+		return nil, fmt.Errorf("the object store endpoint is not a URL: %w", err)
+	}
+	if store.Region == "" {
+		store.Region = "us-east-1"
+	}
+	if store.Client == nil {
+		store.Client = http.DefaultClient
+	}
+	store.Endpoint = strings.TrimRight(store.Endpoint, "/")
+	store.Prefix = strings.Trim(store.Prefix, "/")
+	return &ObjectFS{store: store}, nil
+}
+
+// key ⛏️ returns the key of the path, empty for the root of the prefix.
+func (o *ObjectFS) key(name string) string {
+	name = strings.TrimPrefix(filepath.ToSlash(filepath.Clean(name)), "/")
+	if name == "." {
+		name = ""
+	}
+	return strings.Trim(path.Join(o.store.Prefix, name), "/")
+}
+
+// dirPrefix ⛏️ returns the prefix of the keys inside the directory with the key.
+func dirPrefix(key string) string {
+	if key == "" {
+		return ""
+	}
+	return key + "/"
+}
+
+// do ⛏️ sends the signed request for the key, and returns the response of a success, or the error of a failure.
+// A missing object is fs.ErrNotExist, so the callers can wrap it in the errors of the os package.
+func (o *ObjectFS) do(method, key string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	target, err := url.Parse(o.store.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	target.Path += "/" + o.store.Bucket + "/" + key
+	target.RawPath = awsEscape(target.Path, true)
+	target.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequest(method, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	signRequest(req, o.store.AccessKey, o.store.SecretKey, o.store.Region, "s3", sha256Hex(body), time.Now())
+
+	resp, err := o.store.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 == 2 {
+		return resp, nil
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fs.ErrNotExist
+	}
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return nil, fmt.Errorf("object store %s %s: %s: %s", method, key, resp.Status, bytes.TrimSpace(message))
+}
+
+// get ⛏️ downloads the object with the key.
+func (o *ObjectFS) get(key string) ([]byte, error) {
+	resp, err := o.do(http.MethodGet, key, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	return io.ReadAll(resp.Body)
+}
+
+// put ⛏️ uploads the data as the object with the key, replacing it.
+func (o *ObjectFS) put(key string, data []byte) error {
+	resp, err := o.do(http.MethodPut, key, nil, nil, data)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// head ⛏️ describes the object with the key.
+func (o *ObjectFS) head(key string) (fs.FileInfo, error) {
+	resp, err := o.do(http.MethodHead, key, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	_ = resp.Body.Close()
+	modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return memInfo{name: path.Base(key), size: resp.ContentLength, mode: 0o644, modTime: modTime}, nil
+}
+
+// remove ⛏️ deletes the object with the key, a missing object is not an error, like for S3.
+func (o *ObjectFS) remove(key string) error {
+	resp, err := o.do(http.MethodDelete, key, nil, nil, nil)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// copy ⛏️ copies the object inside the bucket, without downloading it.
+func (o *ObjectFS) copy(from, to string) error {
+	header := http.Header{"X-Amz-Copy-Source": {awsEscape("/"+o.store.Bucket+"/"+from, true)}}
+	resp, err := o.do(http.MethodPut, to, nil, header, nil)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// listResult ⛏️ is a page of ListObjectsV2.
+type listResult struct {
+	IsTruncated bool `xml:"IsTruncated"`
+	Contents    []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	CommonPrefixes []struct {
+		Prefix string `xml:"Prefix"`
+	} `xml:"CommonPrefixes"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// list ⛏️ lists the objects under the prefix, page by page, and with the delimiter, the common prefixes as directories.
+// The limit stops the listing after that many objects and prefixes, 0 lists everything.
+func (o *ObjectFS) list(prefix, delimiter string, limit int) (objects []fs.FileInfo, dirs []string, err error) {
+	query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+	if delimiter != "" {
+		query.Set("delimiter", delimiter)
+	}
+	if limit > 0 {
+		query.Set("max-keys", strconv.Itoa(limit))
+	}
+	for {
+		resp, err := o.do(http.MethodGet, "", query, nil, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		var page listResult
+		err = xml.NewDecoder(resp.Body).Decode(&page)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, object := range page.Contents {
+			if object.Key == prefix {
+				continue // The marker some tools upload for an empty directory.
+			}
+			objects = append(objects, memInfo{name: object.Key, size: object.Size, mode: 0o644, modTime: object.LastModified})
+		}
+		for _, common := range page.CommonPrefixes {
+			dirs = append(dirs, common.Prefix)
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" || (limit > 0 && len(objects)+len(dirs) >= limit) {
+			return objects, dirs, nil
+		}
+		query.Set("continuation-token", page.NextContinuationToken)
+	}
+}
+
+// isDir ⛏️ reports whether anything is under the key, which makes it a directory.
+func (o *ObjectFS) isDir(key string) (bool, error) {
+	if key == "" {
+		return true, nil
+	}
+	objects, dirs, err := o.list(dirPrefix(key), "/", 1)
+	return len(objects)+len(dirs) > 0, err
+}
+
+// Open ⛏️ downloads a file for reading.
+func (o *ObjectFS) Open(name string) (File, error) {
+	return o.OpenFile(name, os.O_RDONLY, 0)
+}
+
+// OpenFile ⛏️ opens a file with the flags of os.OpenFile.
+// The object is downloaded unless it is truncated, and a file opened for writing is uploaded when it is synced or closed.
+func (o *ObjectFS) OpenFile(name string, flag int, perm fs.FileMode) (File, error) {
+	key := o.key(name)
+	writable := flag&(os.O_WRONLY|os.O_RDWR) != 0
+	truncate := writable && flag&os.O_TRUNC != 0
+
+	// A truncated object is only checked for, since its content is dropped anyway.
+	var data []byte
+	var err error
+	if truncate {
+		_, err = o.head(key)
+	} else {
+		data, err = o.get(key)
+	}
+	exists := err == nil
+	switch {
+	case err != nil && !errors.Is(err, fs.ErrNotExist):
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	case !exists && flag&os.O_CREATE == 0:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	case exists && flag&(os.O_CREATE|os.O_EXCL) == os.O_CREATE|os.O_EXCL:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrExist}
+	}
+
+	// A new or a truncated object is uploaded on close, even when nothing is written.
+	return &objectFile{fs: o, name: name, key: key, data: data, dirty: writable && (!exists || truncate),
+		readable: flag&os.O_WRONLY == 0, writable: writable, append: flag&os.O_APPEND != 0}, nil
+}
+
+// ReadFile ⛏️ downloads a whole file.
+func (o *ObjectFS) ReadFile(name string) ([]byte, error) {
+	data, err := o.get(o.key(name))
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return data, nil
+}
+
+// WriteFile ⛏️ uploads a whole file, replacing it, the permission is not kept by the store.
+func (o *ObjectFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if err := o.put(o.key(name), data); err != nil {
+		return &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return nil
+}
+
+// Stat ⛏️ describes an object, or a directory when objects are under the path.
+func (o *ObjectFS) Stat(name string) (fs.FileInfo, error) {
+	key := o.key(name)
+	info, err := o.head(key)
+	if err == nil {
+		return info, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: err}
+	}
+	if dir, err := o.isDir(key); err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: err}
+	} else if !dir {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	return memInfo{name: filepath.Base(name), dir: true, mode: 0o755}, nil
+}
+
+// ReadDir ⛏️ lists a directory sorted by the name, the common prefixes as the subdirectories.
+func (o *ObjectFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	key := o.key(name)
+	prefix := dirPrefix(key)
+	objects, dirs, err := o.list(prefix, "/", 0)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdirent", Path: name, Err: err}
+	}
+	if len(objects)+len(dirs) == 0 && key != "" {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	entries := make([]fs.DirEntry, 0, len(objects)+len(dirs))
+	for _, object := range objects {
+		info := object.(memInfo)
+		info.name = strings.TrimPrefix(info.name, prefix)
+		entries = append(entries, fs.FileInfoToDirEntry(info))
+	}
+	for _, dir := range dirs {
+		entries = append(entries, fs.FileInfoToDirEntry(memInfo{name: strings.TrimSuffix(strings.TrimPrefix(dir, prefix), "/"), dir: true, mode: 0o755}))
+	}
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int { return strings.Compare(a.Name(), b.Name()) })
+	return entries, nil
+}
+
+// MkdirAll ⛏️ does nothing, a directory appears with the first object written under it.
+func (o *ObjectFS) MkdirAll(path string, perm fs.FileMode) error {
+	return nil
+}
+
+// Remove ⛏️ removes an object, a directory with objects under it is not empty.
+func (o *ObjectFS) Remove(name string) error {
+	key := o.key(name)
+	_, err := o.head(key)
+	if errors.Is(err, fs.ErrNotExist) {
+		if dir, dirErr := o.isDir(key); dirErr != nil {
+			return &fs.PathError{Op: "remove", Path: name, Err: dirErr}
+		} else if dir {
+			return &fs.PathError{Op: "remove", Path: name, Err: errNotEmpty}
+		}
+	}
+	if err == nil {
+		err = o.remove(key)
+	}
+	if err != nil {
+		return &fs.PathError{Op: "remove", Path: name, Err: err}
+	}
+	return nil
+}
+
+// RemoveAll ⛏️ removes the object and every object under the path, a missing path is not an error.
+func (o *ObjectFS) RemoveAll(path string) error {
+	key := o.key(path)
+	objects, _, err := o.list(dirPrefix(key), "", 0)
+	if err == nil && key != "" {
+		err = o.remove(key)
+	}
+	for _, object := range objects {
+		if err != nil {
+			break
+		}
+		err = o.remove(object.Name())
+	}
+	if err != nil {
+		return &fs.PathError{Op: "removeall", Path: path, Err: err}
+	}
+	return nil
+}
+
+// Rename ⛏️ moves an object, or every object under a directory, by copying them inside the bucket and removing the old ones.
+// Unlike on the disk, it is not atomic, a failure leaves some objects copied.
+func (o *ObjectFS) Rename(oldpath, newpath string) error {
+	from, to := o.key(oldpath), o.key(newpath)
+	moves := [][2]string{{from, to}}
+	_, err := o.head(from)
+	if errors.Is(err, fs.ErrNotExist) {
+		var objects []fs.FileInfo
+		if objects, _, err = o.list(dirPrefix(from), "", 0); err == nil && len(objects) == 0 {
+			err = fs.ErrNotExist
+		}
+		moves = moves[:0]
+		for _, object := range objects {
+			moves = append(moves, [2]string{object.Name(), to + strings.TrimPrefix(object.Name(), from)})
+		}
+	}
+	for _, move := range moves {
+		if err != nil {
+			break
+		}
+		if err = o.copy(move[0], move[1]); err == nil {
+			err = o.remove(move[0])
+		}
+	}
+	if err != nil {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: err}
+	}
+	return nil
+}
+
+// objectFile ⛏️ is a file of an ObjectFS opened by OpenFile, its data is kept in memory until it is uploaded.
+type objectFile struct {
+	mu       sync.Mutex
+	fs       *ObjectFS
+	name     string
+	key      string
+	data     []byte
+	offset   int
+	dirty    bool // The data differs from the object, such as a new file which was never uploaded.
+	readable bool
+	writable bool
+	append   bool
+	closed   bool
+}
+
+// Name ⛏️ returns the path the file was opened with.
+func (f *objectFile) Name() string {
+	return f.name
+}
+
+// Read ⛏️ reads from the offset, and returns io.EOF at the end.
+func (f *objectFile) Read(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case f.closed:
+		return 0, &fs.PathError{Op: "read", Path: f.name, Err: fs.ErrClosed}
+	case !f.readable:
+		return 0, &fs.PathError{Op: "read", Path: f.name, Err: errReadOnly}
+	case f.offset >= len(f.data):
+		return 0, io.EOF
+	}
+	n := copy(p, f.data[f.offset:])
+	f.offset += n
+	return n, nil
+}
+
+// Write ⛏️ writes at the offset, or at the end when the file was opened with O_APPEND.
+func (f *objectFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case f.closed:
+		return 0, &fs.PathError{Op: "write", Path: f.name, Err: fs.ErrClosed}
+	case !f.writable:
+		return 0, &fs.PathError{Op: "write", Path: f.name, Err: errReadOnly}
+	}
+	if f.append {
+		f.offset = len(f.data)
+	}
+	if end := f.offset + len(p); end > len(f.data) {
+		f.data = append(f.data, make([]byte, end-len(f.data))...)
+	}
+	f.offset += copy(f.data[f.offset:], p)
+	f.dirty = true
+	return len(p), nil
+}
+
+// Sync ⛏️ uploads the data when it changed since the last upload.
+func (f *objectFile) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return &fs.PathError{Op: "sync", Path: f.name, Err: fs.ErrClosed}
+	}
+	return f.upload()
+}
+
+// Close ⛏️ uploads the changed data and closes the file.
+func (f *objectFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return &fs.PathError{Op: "close", Path: f.name, Err: fs.ErrClosed}
+	}
+	f.closed = true
+	return f.upload()
+}
+
+// upload ⛏️ uploads the changed data as the whole object, the caller holds the lock.
+func (f *objectFile) upload() error {
+	if !f.dirty {
+		return nil
+	}
+	if err := f.fs.put(f.key, f.data); err != nil {
+		return &fs.PathError{Op: "write", Path: f.name, Err: err}
+	}
+	f.dirty = false
+	return nil
+}
@@ -0,0 +1,106 @@
+// Package simhub holds the deterministic simulation mode, where all the randomness flows from one seed,
+// the clock is a fake one which only moves when it is advanced, and the files are written into memory.
+// It is a separate package without a config, so every package, the commands too, can use it.
+package simhub
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// =====================================================================================================================
+//                  🛠️ Simulation (Tool)
+// Simulation makes a run reproducible: the same seed gives the same random numbers, the same times and the same files,
+// so a failed run can be replayed exactly. (确定性模拟)
+// It is started by Start, or for a whole test binary or command by the environment variable, such as
+// ALGO_SIMULATION_SEED=42 go test ./bptree/...
+// Without a simulation, every function falls back to math/rand seeded by the time, the real clock and the real disk.
+// =====================================================================================================================
+
+// SeedEnvName ⛏️ is the environment variable holding the seed, the simulation starts at init when it is set.
+const SeedEnvName = "ALGO_SIMULATION_SEED"
+
+// Epoch ⛏️ is where the fake clock starts, the same for every seed.
+var Epoch = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// simulation ⛏️ is the state of a running simulation, guarded by its mutex.
+type simulation struct {
+	seed    int64
+	mu      sync.Mutex
+	rng     *rand.Rand // The source of every random number and every seed handed out.
+	now     time.Time  // The fake clock.
+	tickers []*Ticker  // The tickers of the fake clock.
+	fs      *MemFS     // The files written in the simulation, over the disk.
+}
+
+// active ⛏️ is the running simulation, nil when the run is real.
+var active atomic.Pointer[simulation]
+
+func init() {
+	value, ok := os.LookupEnv(SeedEnvName)
+	if !ok {
+		return
+	}
+	seed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		panic(fmt.Sprintf("%s must be an integer seed: %v", SeedEnvName, err))
+	}
+	Start(seed)
+}
+
+// Start ⛏️ starts a simulation with the seed, replacing the running one.
+// The random numbers start over, the clock is back at Epoch, and the in-memory filesystem is empty.
+func Start(seed int64) {
+	active.Store(&simulation{
+		seed: seed,
+		rng:  rand.New(rand.NewSource(seed)),
+		now:  Epoch,
+		fs:   NewMemFS(OSFS{}),
+	})
+}
+
+// Stop ⛏️ stops the simulation, the randomness, the clock and the files are real again.
+// The files written into memory are dropped, and the tickers of the fake clock never tick again.
+func Stop() {
+	active.Store(nil)
+}
+
+// Active ⛏️ reports whether a simulation is running.
+func Active() bool {
+	return active.Load() != nil
+}
+
+// Seed ⛏️ returns the seed of the running simulation, so a failed run can print it for the replay.
+func Seed() (seed int64, ok bool) {
+	if s := active.Load(); s != nil {
+		return s.seed, true
+	}
+	return 0, false
+}
+
+// NewRand ⛏️ returns a random number generator for a single goroutine.
+// In a simulation, it is seeded by the next number of the seed, so the generators are handed out in a fixed order;
+// otherwise, it is seeded by the time.
+func NewRand() *rand.Rand {
+	if s := active.Load(); s != nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return rand.New(rand.NewSource(s.rng.Int63()))
+	}
+	return rand.New(rand.NewSource(time.Now().UnixNano()))
+}
+
+// Int63n ⛏️ returns a random number in [0, n) like rand.Int63n, drawn from the seed in a simulation.
+func Int63n(n int64) int64 {
+	if s := active.Load(); s != nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.rng.Int63n(n)
+	}
+	return rand.Int63n(n)
+}
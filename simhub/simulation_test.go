@@ -0,0 +1,118 @@
+package simhub
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test_Simulation_Rand tests that the same seed hands out the same random numbers.
+func Test_Simulation_Rand(t *testing.T) {
+	draw := func() (numbers []int64) {
+		Start(42)
+		defer Stop()
+		first, second := NewRand(), NewRand()
+		for i := 0; i < 5; i++ {
+			numbers = append(numbers, first.Int63(), second.Int63(), Int63n(100))
+		}
+		return
+	}
+	numbers := draw()
+	assert.Equal(t, numbers, draw())
+
+	// Another seed draws other numbers, and the seed can be read back for the replay.
+	Start(43)
+	seed, ok := Seed()
+	assert.True(t, ok)
+	assert.Equal(t, int64(43), seed)
+	assert.NotEqual(t, numbers[0], NewRand().Int63())
+	Stop()
+	_, ok = Seed()
+	assert.False(t, ok)
+	assert.False(t, Active())
+}
+
+// Test_Simulation_Clock tests that the fake clock and its tickers only move when advanced.
+func Test_Simulation_Clock(t *testing.T) {
+	Start(1)
+	defer Stop()
+	assert.Equal(t, Epoch, Now())
+
+	ticker := NewTicker(10 * time.Millisecond)
+	Advance(9 * time.Millisecond)
+	assert.Len(t, ticker.C, 0)
+
+	// A tick is held until taken, and the ticks passed at once are dropped.
+	Sleep(25 * time.Millisecond)
+	assert.Equal(t, Epoch.Add(10*time.Millisecond), <-ticker.C)
+	assert.Equal(t, 34*time.Millisecond, Since(Epoch))
+	Advance(6 * time.Millisecond)
+	assert.Equal(t, Epoch.Add(40*time.Millisecond), <-ticker.C)
+
+	// A stopped ticker drops its waiting tick, and Reset counts from now.
+	Advance(10 * time.Millisecond)
+	ticker.Stop()
+	assert.Len(t, ticker.C, 0)
+	Advance(time.Second)
+	assert.Len(t, ticker.C, 0)
+	ticker.Reset(5 * time.Millisecond)
+	Advance(5 * time.Millisecond)
+	assert.Equal(t, Epoch.Add(1055*time.Millisecond), <-ticker.C)
+
+	// Starting over brings the clock back to the epoch.
+	Start(1)
+	assert.Equal(t, Epoch, Now())
+}
+
+// Test_Simulation_Files tests that the files of a simulation stay in memory.
+func Test_Simulation_Files(t *testing.T) {
+	dir := t.TempDir()
+	onDisk := filepath.Join(dir, "fixture.txt")
+	assert.NoError(t, os.WriteFile(onDisk, []byte("disk"), 0o644))
+
+	Start(7)
+	defer Stop()
+
+	// A file never written is read from the disk, and a written one shadows it without touching the disk.
+	data, err := ReadFile(onDisk)
+	assert.NoError(t, err)
+	assert.Equal(t, "disk", string(data))
+	assert.NoError(t, MkdirAll(filepath.Join(dir, "missing", "dir"), 0o755))
+	assert.NoError(t, WriteFile(filepath.Join(dir, "missing", "dir", "..", "out.txt"), []byte("memory"), 0o644))
+	assert.NoError(t, WriteFile(onDisk, []byte("memory"), 0o644))
+	data, err = ReadFile(onDisk)
+	assert.NoError(t, err)
+	assert.Equal(t, "memory", string(data))
+	data, _ = os.ReadFile(onDisk)
+	assert.Equal(t, "disk", string(data))
+	_, err = os.Stat(filepath.Join(dir, "missing"))
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+
+	// The appends add up.
+	log := filepath.Join(dir, "run.log")
+	for _, line := range []string{"a\n", "b\n"} {
+		file, err := OpenAppend(log, 0o644)
+		assert.NoError(t, err)
+		_, err = file.Write([]byte(line))
+		assert.NoError(t, err)
+		assert.NoError(t, file.Close())
+	}
+	data, err = ReadFile(log)
+	assert.NoError(t, err)
+	assert.Equal(t, "a\nb\n", string(data))
+	assert.Equal(t, []string{filepath.Join(dir, "fixture.txt"), filepath.Join(dir, "missing", "out.txt"), log}, Files())
+
+	// A removed file is gone from memory only, and the memory is dropped by Stop.
+	assert.NoError(t, Remove(log))
+	assert.Error(t, Remove(log))
+	Stop()
+	assert.Nil(t, Files())
+	data, err = ReadFile(onDisk)
+	assert.NoError(t, err)
+	assert.Equal(t, "disk", string(data))
+}
@@ -0,0 +1,97 @@
+package simhub
+
+import (
+	"io"
+	"io/fs"
+	"os"
+)
+
+// =====================================================================================================================
+//                  🛠️ VFS (Tool)
+// VFS is the filesystem the persistence code writes through, such as FileNode and the record writers,
+// so the same code runs on the disk by OSFS, or in memory by MemFS in the unit tests and in a simulation. (虚拟文件系统)
+// =====================================================================================================================
+
+// VFS ⛏️ is a filesystem with the same methods and errors as the os package.
+type VFS interface {
+	Open(name string) (File, error)
+	OpenFile(name string, flag int, perm fs.FileMode) (File, error)
+	ReadFile(name string) ([]byte, error)
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+	Stat(name string) (fs.FileInfo, error)
+	ReadDir(name string) ([]fs.DirEntry, error)
+	MkdirAll(path string, perm fs.FileMode) error
+	Remove(name string) error
+	RemoveAll(path string) error
+	Rename(oldpath, newpath string) error
+}
+
+// File ⛏️ is an open file of a VFS, *os.File is one.
+type File interface {
+	io.Reader
+	io.Writer
+	io.Closer
+	Name() string
+	Sync() error
+}
+
+// FS ⛏️ returns the filesystem of the running simulation, which writes into memory, and OSFS without a simulation.
+func FS() VFS {
+	if s := active.Load(); s != nil {
+		return s.fs
+	}
+	return OSFS{}
+}
+
+// OSFS ⛏️ is the disk, through the os package.
+type OSFS struct{}
+
+// Open ⛏️ opens a file for reading like os.Open.
+func (OSFS) Open(name string) (File, error) {
+	return os.Open(name)
+}
+
+// OpenFile ⛏️ opens a file like os.OpenFile.
+func (OSFS) OpenFile(name string, flag int, perm fs.FileMode) (File, error) {
+	return os.OpenFile(name, flag, perm)
+}
+
+// ReadFile ⛏️ reads a file like os.ReadFile.
+func (OSFS) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+// WriteFile ⛏️ writes a file like os.WriteFile.
+func (OSFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return os.WriteFile(name, data, perm)
+}
+
+// Stat ⛏️ describes a file like os.Stat.
+func (OSFS) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+// ReadDir ⛏️ lists a directory like os.ReadDir, sorted by the name.
+func (OSFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(name)
+}
+
+// MkdirAll ⛏️ creates the directories like os.MkdirAll.
+func (OSFS) MkdirAll(path string, perm fs.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
+
+// Remove ⛏️ removes a file or an empty directory like os.Remove.
+func (OSFS) Remove(name string) error {
+	return os.Remove(name)
+}
+
+// RemoveAll ⛏️ removes a path and everything under it like os.RemoveAll.
+func (OSFS) RemoveAll(path string) error {
+	return os.RemoveAll(path)
+}
+
+// Rename ⛏️ renames a file like os.Rename.
+func (OSFS) Rename(oldpath, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
@@ -1,8 +1,9 @@
 package testdata
 
 import (
-	"math/rand"
 	"strconv"
+
+	"github.com/panhongrainbow/go-algorithm/simhub"
 )
 
 // =====================================================================================================================
@@ -103,8 +104,8 @@ func (bPlan BpTreeProcess) RandomizedBoundary(minRemovals, maxRemovals, minDiffe
 	// Continue adding insertion and deletion patterns until reaching the target operation count.
 	for currentIncrement < bPlan.RandomTotalCount {
 		// Generate random values within the specified ranges for removals and insertions.
-		removals := minRemovals + rand.Int63n(maxRemovals-minRemovals)
-		difference := minDifference + rand.Int63n(maxDifference-minDifference)
+		removals := minRemovals + simhub.Int63n(maxRemovals-minRemovals)
+		difference := minDifference + simhub.Int63n(maxDifference-minDifference)
 
 		// Add a test stage with the generated insertion and deletion counts.
 		testStages = append(testStages, EachBpTestStage{
@@ -136,8 +137,8 @@ func (bPlan BpTreeProcess) GradualBoundary(minRemovals, maxRemovals, minDifferen
 	var currentIncrement int64 = 0
 
 	// Generate random values within the specified ranges for removals and insertions.
-	removals := minRemovals + rand.Int63n(maxRemovals-minRemovals)
-	difference := minDifference + rand.Int63n(maxDifference-minDifference)
+	removals := minRemovals + simhub.Int63n(maxRemovals-minRemovals)
+	difference := minDifference + simhub.Int63n(maxDifference-minDifference)
 
 	// Continue adding insertion and deletion patterns until reaching the target operation count.
 	for currentIncrement < bPlan.RandomTotalCount {
@@ -172,8 +173,8 @@ func (bPlan BpTreeProcess) RedundantOperation(minRemovals, maxRemovals, minDiffe
 	var currentIncrement int64 = 0
 
 	// Generate random values within the specified ranges for removals and insertions.
-	removals := minRemovals + rand.Int63n(maxRemovals-minRemovals)
-	difference := minDifference + rand.Int63n(maxDifference-minDifference)
+	removals := minRemovals + simhub.Int63n(maxRemovals-minRemovals)
+	difference := minDifference + simhub.Int63n(maxDifference-minDifference)
 
 	// Continue adding insertion and deletion patterns until reaching the target operation count.
 	for currentIncrement < bPlan.RandomTotalCount {
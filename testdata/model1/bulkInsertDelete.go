@@ -65,7 +65,7 @@ func (model1 *BpTestModel1) GenerateRandomSet(
 	// ▓▒░ Creating a progress bar with optional configurations.
 	progressBar, _ := utilhub.NewProgressBar(
 		"Mode 1: Bulk Insert/Delete - generate test data", // Progress bar title.
-		uint32(randomEvenCount),                           // Total number of operations.
+		uint64(randomEvenCount),                           // Total number of operations.
 		70,                                                // Progress bar width.
 		utilhub.WithTracking(5),                           // Update interval.
 		utilhub.WithTimeZone("Asia/Taipei"),               // Time zone.
@@ -74,9 +74,7 @@ func (model1 *BpTestModel1) GenerateRandomSet(
 	)
 
 	// ▓▒░ Start the progress bar printer in a separate goroutine.
-	go func() {
-		progressBar.ListenPrinter()
-	}()
+	progressBar.Start()
 
 	// Copying the generated random numbers, positive ones, to the dataset slice.
 	copy(dataSet, bulkAdd)
@@ -85,7 +83,7 @@ func (model1 *BpTestModel1) GenerateRandomSet(
 	utilhub.ShuffleSlice(bulkAdd)
 
 	// ▓▒░ Updating the progress bar.
-	progressBar.AddSpecificTimes(uint32(randomEvenCount / 2))
+	progressBar.AddSpecificTimes(uint64(randomEvenCount / 2))
 
 	// Calculating the length of the bulkAdd slice.
 	bulkAddLen := len(bulkAdd)
@@ -122,7 +120,7 @@ func (model1 *BpTestModel1) CheckRandomSet(dataSet []int64) error {
 	// ▓▒░ Creating a progress bar with optional configurations.
 	progressBar, _ := utilhub.NewProgressBar(
 		"Mode 1: Bulk Insert/Delete - check test data", // Progress bar title.
-		uint32(len(dataSet)/2*3),                       // Total number of operations.
+		uint64(len(dataSet)/2*3),                       // Total number of operations.
 		70,                                             // Progress bar width.
 		utilhub.WithTracking(5),                        // Update interval.
 		utilhub.WithTimeZone("Asia/Taipei"),            // Time zone.
@@ -131,9 +129,7 @@ func (model1 *BpTestModel1) CheckRandomSet(dataSet []int64) error {
 	)
 
 	// ▓▒░ Start the progress bar printer in a separate goroutine.
-	go func() {
-		progressBar.ListenPrinter()
-	}()
+	progressBar.Start()
 
 	// Iterate over the data set and separate the positive and negative numbers into the heaps.
 	for i := 0; i < len(dataSet); i++ {
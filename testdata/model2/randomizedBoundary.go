@@ -2,10 +2,9 @@ package model2
 
 import (
 	"errors"
-	"math/rand"
-	"time"
 
 	"github.com/panhongrainbow/go-algorithm/randhub"
+	"github.com/panhongrainbow/go-algorithm/simhub"
 	"github.com/panhongrainbow/go-algorithm/testdata/share"
 	"github.com/panhongrainbow/go-algorithm/utilhub"
 )
@@ -25,7 +24,7 @@ func (model2 *BpTestModel2) GenerateRandomSet() ([]int64, error) {
 
 	progressBar, _ := utilhub.NewProgressBar(
 		"Mode 2: Randomized Boundary - generate test data", // Progress bar title.
-		uint32(model2.TotalOps(testPlan)),                  // Total number of operations.
+		uint64(model2.TotalOps(testPlan)),                  // Total number of operations.
 		70,                                                 // Progress bar width.
 		utilhub.WithTracking(5),                            // Update interval.
 		utilhub.WithTimeZone("Asia/Taipei"),                // Time zone.
@@ -33,16 +32,13 @@ func (model2 *BpTestModel2) GenerateRandomSet() ([]int64, error) {
 		utilhub.WithDisplay(utilhub.BrightBlue),            // Display style.
 	)
 
-	go func() {
-		progressBar.ListenPrinter()
-	}()
+	progressBar.Start()
 
 	pool := randhub.NewDoublePool()
 
 	dataSet := make([]int64, 0)
 
-	source := rand.NewSource(time.Now().UnixNano())
-	random := rand.New(source)
+	random := simhub.NewRand()
 
 	for j := 0; j < len(testPlan); j++ {
 		batchInsert, batchRemove := pool.GenerateUniqueInt64Numbers(unitTestConfig.Parameters.RandomMin, unitTestConfig.Parameters.RandomMax, int(testPlan[j].op.insertAction), int(testPlan[j].op.deleteAction), false)
@@ -84,7 +80,7 @@ func (model2 *BpTestModel2) CheckRandomSet(dataSet []int64) error {
 	// ▓▒░ Create a progress bar with optional configurations.
 	progressBar, _ := utilhub.NewProgressBar(
 		"Mode 2: Randomized Boundary Test - check test data", // Progress bar title.
-		uint32(len(dataSet)),                     // Total number of operations.
+		uint64(len(dataSet)),                     // Total number of operations.
 		70,                                       // Progress bar width.
 		utilhub.WithTracking(5),                  // Update interval.
 		utilhub.WithTimeZone("Asia/Taipei"),      // Time zone.
@@ -96,9 +92,7 @@ func (model2 *BpTestModel2) CheckRandomSet(dataSet []int64) error {
 	checkPool := make(map[int64]struct{})
 
 	// ▓▒░ Start the progress bar printer in a separate goroutine.
-	go func() {
-		progressBar.ListenPrinter()
-	}()
+	progressBar.Start()
 
 	// Iterate through each element in the dataSet.
 	for i := 0; i < len(dataSet); i++ {
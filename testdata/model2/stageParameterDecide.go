@@ -1,8 +1,9 @@
 package model2
 
 import (
-	"math/rand"
 	"strconv"
+
+	"github.com/panhongrainbow/go-algorithm/simhub"
 )
 
 // stage 🧮 represents a single phase of the model2 test. (被切割成很多阶段)
@@ -68,9 +69,9 @@ func (model2 *BpTestModel2) StageParameters(
 	var keepInPool int64 = 0
 	for keepInPool < int64(limitTestScope) {
 		// removals randomly selects the number of deletions within the range [minRemovals, maxRemovals).
-		removals := minRemovals + rand.Int63n(maxRemovals-minRemovals)
+		removals := minRemovals + simhub.Int63n(maxRemovals-minRemovals)
 		// difference randomly selects the number of records to preserve in the pool within the range [minPreserveInPool, maxPreserveInPool).
-		difference := minPreserveInPool + rand.Int63n(maxPreserveInPool-minPreserveInPool)
+		difference := minPreserveInPool + simhub.Int63n(maxPreserveInPool-minPreserveInPool)
 
 		// This block constructs a stage that defines how many items will be inserted and deleted.
 		testStages = append(testStages, stage{
@@ -3,9 +3,9 @@ package share
 import (
 	"errors"
 	"math/rand"
-	"time"
 
 	"github.com/panhongrainbow/go-algorithm/randhub"
+	"github.com/panhongrainbow/go-algorithm/simhub"
 	"github.com/panhongrainbow/go-algorithm/utilhub"
 )
 
@@ -22,7 +22,7 @@ func (model *BpTestShare) ShareGenerateRandomSet(cyclicStressCount int64) ([]int
 
 	progressBar, _ := utilhub.NewProgressBar(
 		"Mode 3: CyclicStress Boundary - generate test data", // Progress bar title.
-		uint32(model._TotalOps(testPlan, cyclicStressCount)), // Total number of operations.
+		uint64(model._TotalOps(testPlan, cyclicStressCount)), // Total number of operations.
 		70,                                      // Progress bar width.
 		utilhub.WithTracking(5),                 // Update interval.
 		utilhub.WithTimeZone("Asia/Taipei"),     // Time zone.
@@ -30,9 +30,7 @@ func (model *BpTestShare) ShareGenerateRandomSet(cyclicStressCount int64) ([]int
 		utilhub.WithDisplay(utilhub.BrightBlue), // Display style.
 	)
 
-	go func() {
-		progressBar.ListenPrinter()
-	}()
+	progressBar.Start()
 
 	pool := randhub.NewDoublePool()
 
@@ -43,8 +41,7 @@ func (model *BpTestShare) ShareGenerateRandomSet(cyclicStressCount int64) ([]int
 
 		for cycle := 0; cycle < int(cyclicStressCount); cycle++ {
 
-			source := rand.NewSource(time.Now().UnixNano())
-			random := rand.New(source)
+			random := simhub.NewRand()
 
 			ShuffleSlice(batchInsert, random)
 			// shuffleSlice(batchRemove, random)
@@ -60,8 +57,7 @@ func (model *BpTestShare) ShareGenerateRandomSet(cyclicStressCount int64) ([]int
 			}
 		}
 
-		source := rand.NewSource(time.Now().UnixNano())
-		random := rand.New(source)
+		random := simhub.NewRand()
 
 		ShuffleSlice(batchInsert, random)
 		ShuffleSlice(batchRemove, random)
@@ -100,7 +96,7 @@ func (model *BpTestShare) CheckRandomSet(dataSet []int64) error {
 	// ▓▒░ Create a progress bar with optional configurations.
 	progressBar, _ := utilhub.NewProgressBar(
 		"Mode 3: CyclicStress Boundary Test - check test data", // Progress bar title.
-		uint32(len(dataSet)),                     // Total number of operations.
+		uint64(len(dataSet)),                     // Total number of operations.
 		70,                                       // Progress bar width.
 		utilhub.WithTracking(5),                  // Update interval.
 		utilhub.WithTimeZone("Asia/Taipei"),      // Time zone.
@@ -112,9 +108,7 @@ func (model *BpTestShare) CheckRandomSet(dataSet []int64) error {
 	checkPool := make(map[int64]struct{})
 
 	// ▓▒░ Start the progress bar printer in a separate goroutine.
-	go func() {
-		progressBar.ListenPrinter()
-	}()
+	progressBar.Start()
 
 	// Iterate through each element in the dataSet.
 	for i := 0; i < len(dataSet); i++ {
@@ -0,0 +1,119 @@
+package utilhub
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// =====================================================================================================================
+//                  🛠️ Alloc Profile (Tool)
+// Alloc Profile attributes the heap allocations to each operation type, such as Insert, Delete, Search and RangeScan,
+// and prints them as a table. It guides the arena and pooling work by showing which operation allocates the most.
+// =====================================================================================================================
+
+// AllocStat ⛏️ is the allocations of one operation type.
+type AllocStat struct {
+	Op     string  // The operation type, such as "Insert".
+	Runs   int     // How many times the operation has run.
+	Allocs float64 // The heap allocations per operation.
+	Bytes  float64 // The allocated bytes per operation.
+}
+
+// AllocProfile ⛏️ collects the allocation stats of every operation type, in the order they are added.
+type AllocProfile struct {
+	mu    sync.Mutex  // Protects the stats.
+	stats []AllocStat // The stats of every operation type.
+}
+
+// NewAllocProfile ⛏️ creates an empty allocation profile.
+func NewAllocProfile() *AllocProfile {
+	return &AllocProfile{}
+}
+
+// Measure ⛏️ runs the operation several times and samples the runtime memory stats before and after.
+// It works like testing.AllocsPerRun, which runs on one processor after a warm-up run, and it also records the bytes.
+// Measuring the same operation type again replaces the earlier stat.
+func (p *AllocProfile) Measure(op string, runs int, f func()) AllocStat {
+	if runs <= 0 {
+		runs = 1
+	}
+
+	// Use one processor, so the other goroutines do not add their allocations.
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(1))
+
+	// Warm up, so the one-time allocations are not counted.
+	f()
+
+	// Sample the memory stats around the runs.
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	for i := 0; i < runs; i++ {
+		f()
+	}
+	runtime.ReadMemStats(&after)
+
+	stat := AllocStat{
+		Op:     op,
+		Runs:   runs,
+		Allocs: float64(after.Mallocs-before.Mallocs) / float64(runs),
+		Bytes:  float64(after.TotalAlloc-before.TotalAlloc) / float64(runs),
+	}
+	p.Add(stat)
+
+	return stat
+}
+
+// Add ⛏️ records a stat measured elsewhere, for example by a testing.AllocsPerRun harness.
+// Adding the same operation type again replaces the earlier stat.
+func (p *AllocProfile) Add(stat AllocStat) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i := range p.stats {
+		if p.stats[i].Op == stat.Op {
+			p.stats[i] = stat
+			return
+		}
+	}
+	p.stats = append(p.stats, stat)
+}
+
+// Stats ⛏️ returns a copy of the collected stats.
+func (p *AllocProfile) Stats() []AllocStat {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]AllocStat(nil), p.stats...)
+}
+
+// Report ⛏️ prints the per-op allocation table.
+func (p *AllocProfile) Report(w io.Writer) {
+	stats := p.Stats()
+
+	// Find the width of the operation column.
+	opWidth := len("Operation")
+	for _, stat := range stats {
+		if len(stat.Op) > opWidth {
+			opWidth = len(stat.Op)
+		}
+	}
+	totalWidth := opWidth + 47
+	border := BrightYellow + strings.Repeat("=", totalWidth) + Reset
+	divider := BrightYellow + strings.Repeat("-", totalWidth) + Reset
+
+	// Print the title, the header and one row for each operation type.
+	title := "Allocation Report"
+	padding := (totalWidth - len(title)) / 2
+	fmt.Fprintln(w, border)
+	fmt.Fprintf(w, "%s|%s%s%s|%s\n", BrightMagenta, strings.Repeat(" ", padding), title, strings.Repeat(" ", totalWidth-len(title)-padding-2), Reset)
+	fmt.Fprintln(w, border)
+	fmt.Fprintf(w, "%s| %-*s | %10s | %12s | %12s |%s\n", BrightRed, opWidth, "Operation", "Runs", "allocs/op", "B/op", Reset)
+	fmt.Fprintln(w, divider)
+	for _, stat := range stats {
+		fmt.Fprintf(w, "%s| %-*s | %10d | %12.2f | %12.2f |%s\n", DarkYellow, opWidth, stat.Op, stat.Runs, stat.Allocs, stat.Bytes, Reset)
+	}
+	fmt.Fprintln(w, border)
+}
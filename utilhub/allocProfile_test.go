@@ -0,0 +1,43 @@
+package utilhub
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// allocSink keeps the allocations on the heap.
+var allocSink []byte
+
+// Test_AllocProfile tests measuring the allocations of each operation type and printing the table.
+func Test_AllocProfile(t *testing.T) {
+	profile := NewAllocProfile()
+
+	// One allocation of 1 KB for each run.
+	stat := profile.Measure("Alloc", 100, func() {
+		allocSink = make([]byte, 1024)
+	})
+	assert.Equal(t, 100, stat.Runs)
+	assert.InDelta(t, 1, stat.Allocs, 0.1)
+	assert.GreaterOrEqual(t, stat.Bytes, 1024.0)
+
+	// No allocation at all.
+	stat = profile.Measure("NoAlloc", 100, func() {})
+	assert.Zero(t, stat.Allocs)
+
+	// The stats measured elsewhere are added, and the same operation type is replaced.
+	profile.Add(AllocStat{Op: "Other", Runs: 1, Allocs: 3})
+	profile.Add(AllocStat{Op: "Other", Runs: 2, Allocs: 4})
+	stats := profile.Stats()
+	assert.Len(t, stats, 3)
+	assert.Equal(t, AllocStat{Op: "Other", Runs: 2, Allocs: 4}, stats[2])
+
+	// The report lists every operation type in order.
+	var buf bytes.Buffer
+	profile.Report(&buf)
+	out := buf.String()
+	assert.Contains(t, out, "Allocation Report")
+	assert.Contains(t, out, "allocs/op")
+	assert.Less(t, bytes.Index(buf.Bytes(), []byte("NoAlloc")), bytes.Index(buf.Bytes(), []byte("Other")))
+}
@@ -0,0 +1,10 @@
+//go:build !windows
+
+package utilhub
+
+import "io"
+
+// enableANSI ⛏️ reports whether the writer draws the ANSI codes, which every terminal outside Windows does.
+func enableANSI(w io.Writer) bool {
+	return true
+}
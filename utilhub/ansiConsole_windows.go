@@ -0,0 +1,31 @@
+//go:build windows
+
+package utilhub
+
+import (
+	"io"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// enableANSI ⛏️ turns on the virtual terminal processing of a Windows console, so the ANSI codes are drawn as colors.
+// It returns false for an older console which can not do it, such as cmd before Windows 10, and then the colors are off.
+// A writer which is not a console, such as a buffer, a file or a pipe, gets the ANSI codes like on the other platforms.
+func enableANSI(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return true
+	}
+
+	// A handle without a console mode is not a console.
+	handle := windows.Handle(file.Fd())
+	var mode uint32
+	if err := windows.GetConsoleMode(handle, &mode); err != nil {
+		return true
+	}
+	if mode&windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING != 0 {
+		return true
+	}
+	return windows.SetConsoleMode(handle, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING) == nil
+}
@@ -0,0 +1,131 @@
+package utilhub
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// =====================================================================================================================
+//                  🛠️ Bar Style (Tool)
+// Bar Style is the look of the bar, from the plain blocks to the eighth blocks, the braille cells and the shades,
+// which fill the cell at the edge part of the way, so a long run still moves on a short bar. (进度条样式)
+// =====================================================================================================================
+
+// BarStyle ⛏️ is a built-in look of the bar, selected by WithStyle.
+type BarStyle int
+
+// The styles of WithStyle.
+const (
+	BlocksStyle     BarStyle = iota // "███░░░", the default.
+	HalfBlocksStyle                 // "███▌  ", the edge cell is filled by eighths.
+	BrailleStyle                    // "⣿⣿⣿⣤⠀⠀", the edge cell is filled dot by dot.
+	DotsStyle                       // "●●●○○○", whole cells only.
+	ShadesStyle                     // "███▒  ", the edge cell is shaded darker as it fills.
+)
+
+// barRunes ⛏️ is the runes of a style.
+type barRunes struct {
+	filled  rune   // The rune of a completed cell.
+	empty   rune   // The rune of a remaining cell.
+	partial []rune // The runes of the edge cell from the least to the most filled, empty for whole cells only.
+}
+
+// barStyles ⛏️ is the runes of every style.
+var barStyles = map[BarStyle]barRunes{
+	BlocksStyle:     {filled: '█', empty: '░'},
+	HalfBlocksStyle: {filled: '█', empty: ' ', partial: []rune("▏▎▍▌▋▊▉")},
+	BrailleStyle:    {filled: '⣿', empty: '⠀', partial: []rune("⡀⣀⣄⣤⣦⣶⣷")},
+	DotsStyle:       {filled: '●', empty: '○'},
+	ShadesStyle:     {filled: '█', empty: ' ', partial: []rune("░▒▓")},
+}
+
+// BarStyleByName ⛏️ returns the style named "blocks", "halfblocks", "braille", "dots" or "shades", so a config can select it.
+// An empty name returns the default style.
+func BarStyleByName(name string) (BarStyle, error) {
+	switch strings.ToLower(name) {
+	case "", "blocks":
+		return BlocksStyle, nil
+	case "halfblocks":
+		return HalfBlocksStyle, nil
+	case "braille":
+		return BrailleStyle, nil
+	case "dots":
+		return DotsStyle, nil
+	case "shades":
+		return ShadesStyle, nil
+	}
+	return BlocksStyle, fmt.Errorf("unknown bar style %q, the styles are blocks, halfblocks, braille, dots and shades", name)
+}
+
+// drawBar ⛏️ draws the filled cells and the remaining cells, and the edge cell part of the way when the style can.
+// The fraction of the edge cell comes from the percentage, since the filled length counts the whole cells only.
+func (pb *ProgressBar) drawBar(filledLength, barLength int, percentage float64) string {
+	var bar strings.Builder
+	for i := 0; i < filledLength && i < barLength; i++ {
+		bar.WriteRune(pb.filledRune)
+	}
+
+	// Pick the partial rune by how much of the edge cell is filled, nothing is drawn below the first step.
+	rest := barLength - filledLength
+	if len(pb.partialRunes) > 0 && rest > 0 {
+		fraction := percentage/100*float64(barLength) - float64(filledLength)
+		if step := int(math.Floor(fraction * float64(len(pb.partialRunes)+1))); step > 0 {
+			bar.WriteRune(pb.partialRunes[min(step, len(pb.partialRunes))-1])
+			rest--
+		}
+	}
+
+	for i := 0; i < rest; i++ {
+		bar.WriteRune(pb.emptyRune)
+	}
+	return bar.String()
+}
+
+// checkRunes ⛏️ returns an error for a rune of the bar or its caps which does not take exactly one column.
+func (pb *ProgressBar) checkRunes() error {
+	runes := append([]rune{pb.filledRune, pb.emptyRune}, pb.partialRunes...)
+	runes = append(runes, []rune(pb.leftCap+pb.rightCap)...)
+	for _, r := range runes {
+		if !singleWidth(r) {
+			return fmt.Errorf("the bar rune %q (%U) does not take exactly one column", r, r)
+		}
+	}
+	return nil
+}
+
+// singleWidth ⛏️ reports whether the rune takes exactly one column on a terminal.
+// The control runes and the combining marks take none, while the wide East Asian runes and most emoji take two.
+func singleWidth(r rune) bool {
+	if r == ' ' {
+		return true
+	}
+	if !unicode.IsGraphic(r) || unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return false
+	}
+	for _, wide := range wideRanges {
+		if r >= wide[0] && r <= wide[1] {
+			return false
+		}
+	}
+	return true
+}
+
+// wideRanges ⛏️ is the main blocks of the runes shown two columns wide, such as CJK, Hangul, the fullwidth forms and emoji.
+var wideRanges = [][2]rune{
+	{0x1100, 0x115F},   // Hangul Jamo initials.
+	{0x2E80, 0x303E},   // CJK radicals and punctuation.
+	{0x3041, 0x33FF},   // Kana and CJK compatibility.
+	{0x3400, 0x4DBF},   // CJK extension A.
+	{0x4E00, 0x9FFF},   // CJK unified ideographs.
+	{0xA000, 0xA4CF},   // Yi.
+	{0xAC00, 0xD7A3},   // Hangul syllables.
+	{0xF900, 0xFAFF},   // CJK compatibility ideographs.
+	{0xFE30, 0xFE4F},   // CJK compatibility forms.
+	{0xFF00, 0xFF60},   // Fullwidth forms.
+	{0xFFE0, 0xFFE6},   // Fullwidth signs.
+	{0x1F300, 0x1F6FF}, // Pictographs, emoticons and transport symbols.
+	{0x1F900, 0x1F9FF}, // Supplemental pictographs.
+	{0x20000, 0x3FFFD}, // CJK extensions B and later.
+}
@@ -0,0 +1,187 @@
+package utilhub
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+// =====================================================================================================================
+//                  🛠️ Complexity Fit (Tool)
+// Complexity Fit runs an operation across geometrically increasing sizes, and fits the times to the usual growth curves,
+// so a complexity claim, such as an O(log n) search, can be confirmed by measurement for any structure. (复杂度实测)
+// Each curve t = a·f(n) is fitted on the relative errors, so the small sizes weigh as much as the large ones.
+// =====================================================================================================================
+
+// Complexity ⛏️ is a growth curve of the time with the size n.
+type Complexity int
+
+// The curves fitted by FitComplexity.
+const (
+	Constant     Complexity = iota // O(1)
+	Logarithmic                    // O(log n)
+	Linear                         // O(n)
+	Linearithmic                   // O(n log n)
+	Quadratic                      // O(n²)
+)
+
+// complexities ⛏️ is every curve in the order they are fitted.
+var complexities = []Complexity{Constant, Logarithmic, Linear, Linearithmic, Quadratic}
+
+// String ⛏️ returns the big O notation of the curve, such as "O(n log n)".
+func (c Complexity) String() string {
+	switch c {
+	case Constant:
+		return "O(1)"
+	case Logarithmic:
+		return "O(log n)"
+	case Linear:
+		return "O(n)"
+	case Linearithmic:
+		return "O(n log n)"
+	case Quadratic:
+		return "O(n²)"
+	}
+	return fmt.Sprintf("Complexity(%d)", int(c))
+}
+
+// grow ⛏️ returns f(n) of the curve.
+func (c Complexity) grow(n float64) float64 {
+	switch c {
+	case Logarithmic:
+		return math.Log2(n)
+	case Linear:
+		return n
+	case Linearithmic:
+		return n * math.Log2(n)
+	case Quadratic:
+		return n * n
+	}
+	return 1
+}
+
+// ComplexitySample ⛏️ is the time of the operation at one size.
+type ComplexitySample struct {
+	N       int           // The size.
+	Elapsed time.Duration // The time of the operation at this size, the fastest of the repeats.
+}
+
+// CurveFit ⛏️ is how well one curve explains the samples.
+type CurveFit struct {
+	Complexity Complexity // The curve.
+	Scale      float64    // The fitted a of t = a·f(n), in nanoseconds.
+	Error      float64    // The root mean square of the relative errors, 0.1 means the curve is off by about 10%.
+}
+
+// ComplexityReport ⛏️ is the best curve for the samples and how sure the fit is.
+type ComplexityReport struct {
+	Best       Complexity         // The curve with the smallest error.
+	Confidence float64            // The share of the best curve, from 0 to 1, by the inverse square errors of all curves.
+	Fits       []CurveFit         // Every curve, the best first.
+	Samples    []ComplexitySample // The samples fitted.
+}
+
+// String ⛏️ describes the best fit, such as "O(n log n) with 87% confidence".
+func (r ComplexityReport) String() string {
+	return fmt.Sprintf("%s with %.0f%% confidence", r.Best, r.Confidence*100)
+}
+
+// FitComplexity ⛏️ fits every curve to the samples, it needs at least 3 samples of different sizes above 1.
+func FitComplexity(samples []ComplexitySample) (ComplexityReport, error) {
+	sizes := make(map[int]struct{}, len(samples))
+	for _, sample := range samples {
+		if sample.N <= 1 || sample.Elapsed <= 0 {
+			return ComplexityReport{}, fmt.Errorf("the sample of size %d taking %s can not be fitted, the sizes must be above 1 and the times positive", sample.N, sample.Elapsed)
+		}
+		sizes[sample.N] = struct{}{}
+	}
+	if len(sizes) < 3 {
+		return ComplexityReport{}, errors.New("at least 3 different sizes are needed to tell the curves apart")
+	}
+
+	// Minimize the relative errors Σ(1 - a·f/t)², whose solution is a = Σ(f/t) / Σ(f/t)².
+	report := ComplexityReport{Samples: samples}
+	for _, c := range complexities {
+		var sum, sumSquares float64
+		for _, sample := range samples {
+			ratio := c.grow(float64(sample.N)) / float64(sample.Elapsed)
+			sum += ratio
+			sumSquares += ratio * ratio
+		}
+		scale := sum / sumSquares
+
+		var squares float64
+		for _, sample := range samples {
+			residual := 1 - scale*c.grow(float64(sample.N))/float64(sample.Elapsed)
+			squares += residual * residual
+		}
+		report.Fits = append(report.Fits, CurveFit{Complexity: c, Scale: scale, Error: math.Sqrt(squares / float64(len(samples)))})
+	}
+	slices.SortStableFunc(report.Fits, func(a, b CurveFit) int {
+		return cmp.Compare(a.Error, b.Error)
+	})
+	report.Best = report.Fits[0].Complexity
+
+	// A perfect fit takes all the confidence.
+	if report.Fits[0].Error == 0 {
+		report.Confidence = 1
+		return report, nil
+	}
+	var weights float64
+	for _, fit := range report.Fits {
+		weights += 1 / (fit.Error * fit.Error)
+	}
+	report.Confidence = 1 / (report.Fits[0].Error * report.Fits[0].Error) / weights
+	return report, nil
+}
+
+// ComplexityOption ⛏️ configures MeasureComplexity.
+type ComplexityOption func(*complexityConfig)
+
+// complexityConfig ⛏️ is the sizes and the repeats of a measurement.
+type complexityConfig struct {
+	start, factor, steps int // The sizes start, start·factor, start·factor², ... for steps sizes.
+	repeats              int // How many times each size is run, the fastest run is kept.
+}
+
+// WithSizes ⛏️ sets the sizes to start, start·factor, start·factor² and so on, steps sizes in all, 1000·2ⁱ for 8 sizes by default.
+func WithSizes(start, factor, steps int) ComplexityOption {
+	return func(config *complexityConfig) {
+		config.start, config.factor, config.steps = start, factor, steps
+	}
+}
+
+// WithRepeats ⛏️ sets how many times each size is run, 3 by default, the fastest run is kept to leave out the noise.
+func WithRepeats(repeats int) ComplexityOption {
+	return func(config *complexityConfig) {
+		config.repeats = repeats
+	}
+}
+
+// MeasureComplexity ⛏️ times the operation at every size and fits the curves to the times.
+// The setup prepares the work of size n, such as a tree of n keys, and returns the operation, only the operation is timed.
+func MeasureComplexity(setup func(n int) (run func()), opts ...ComplexityOption) (ComplexityReport, error) {
+	config := complexityConfig{start: 1000, factor: 2, steps: 8, repeats: 3}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if config.start <= 1 || config.factor < 2 || config.steps < 3 || config.repeats < 1 {
+		return ComplexityReport{}, errors.New("the sizes need a start above 1, a factor of at least 2 and 3 steps, and at least 1 repeat")
+	}
+
+	samples := make([]ComplexitySample, 0, config.steps)
+	for i, n := 0, config.start; i < config.steps; i, n = i+1, n*config.factor {
+		fastest := time.Duration(math.MaxInt64)
+		for r := 0; r < config.repeats; r++ {
+			run := setup(n)
+			start := time.Now()
+			run()
+			fastest = min(fastest, max(time.Since(start), 1))
+		}
+		samples = append(samples, ComplexitySample{N: n, Elapsed: fastest})
+	}
+	return FitComplexity(samples)
+}
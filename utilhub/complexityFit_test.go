@@ -0,0 +1,62 @@
+package utilhub
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test_ComplexityFit tests the fitted curves of synthetic samples and of a measured operation.
+func Test_ComplexityFit(t *testing.T) {
+	// Every curve is recognized from its own samples, with a few percent of noise.
+	noise := []float64{1.03, 0.97, 1.05, 0.98, 1.02, 0.96, 1.04, 1.0}
+	for _, c := range complexities {
+		var samples []ComplexitySample
+		for i, n := 0, 1000; i < len(noise); i, n = i+1, n*2 {
+			samples = append(samples, ComplexitySample{N: n, Elapsed: time.Duration(50 * c.grow(float64(n)) * noise[i])})
+		}
+		report, err := FitComplexity(samples)
+		assert.NoError(t, err)
+		assert.Equal(t, c, report.Best, "%s fitted as %s", c, report)
+		assert.Greater(t, report.Confidence, 0.5, "%s", report)
+		assert.Len(t, report.Fits, len(complexities))
+		assert.Less(t, report.Fits[0].Error, 0.05)
+	}
+
+	// An exact curve takes all the confidence.
+	report, err := FitComplexity([]ComplexitySample{{N: 10, Elapsed: 100}, {N: 20, Elapsed: 200}, {N: 40, Elapsed: 400}})
+	assert.NoError(t, err)
+	assert.Equal(t, Linear, report.Best)
+	assert.Equal(t, 1.0, report.Confidence)
+	assert.InDelta(t, 10.0, report.Fits[0].Scale, 1e-9)
+	assert.Equal(t, "O(n) with 100% confidence", report.String())
+
+	// Too few sizes or a bad sample can not be fitted.
+	_, err = FitComplexity([]ComplexitySample{{N: 10, Elapsed: 100}, {N: 10, Elapsed: 110}, {N: 20, Elapsed: 200}})
+	assert.Error(t, err)
+	_, err = FitComplexity([]ComplexitySample{{N: 1, Elapsed: 100}, {N: 10, Elapsed: 110}, {N: 20, Elapsed: 200}})
+	assert.Error(t, err)
+
+	// A nested loop is measured as quadratic, the setup is not timed.
+	report, err = MeasureComplexity(func(n int) func() {
+		time.Sleep(time.Millisecond) // Not timed.
+		return func() {
+			sum := 0.0
+			for i := 0; i < n; i++ {
+				for j := 0; j < n; j++ {
+					sum += float64(i ^ j)
+				}
+			}
+			_ = math.Sqrt(sum)
+		}
+	}, WithSizes(256, 2, 5), WithRepeats(3))
+	assert.NoError(t, err)
+	assert.Equal(t, Quadratic, report.Best, "%s %+v", report, report.Fits)
+	assert.Len(t, report.Samples, 5)
+	assert.Equal(t, 4096, report.Samples[4].N)
+
+	_, err = MeasureComplexity(func(n int) func() { return func() {} }, WithSizes(1000, 1, 5))
+	assert.Error(t, err)
+}
@@ -1,6 +1,7 @@
 package utilhub
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -8,8 +9,10 @@ import (
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // =====================================================================================================================
@@ -19,50 +22,79 @@ import (
 // =====================================================================================================================
 
 // ParseDefault ⛏️ loads the default configuration from struct tags and applies it to the provided struct.
-func ParseDefault(cfg DefaultConfig) error {
-	// Prepare the variable outside of the closure function.
-	var err error
-	var projectPath, file string
+// The file is config/<name>.json, named after the struct unless the config implements ConfigFileNamer,
+// or config/<name>.yaml, .yml or .toml, the first one found unless WithFormat picks the format.
+// WithWriteBack writes the file merged with the defaults back to it, so the new fields of the struct show up.
+// Then the environment variables override the file and the tags, see applyEnv, the validate tags are checked,
+// see validateConfig, and a config implementing AfterLoader finishes itself at the end.
+// Every call reads the file again, so the caller decides whether the config is loaded once.
+func ParseDefault[T DefaultConfig](cfg *T, opts ...ParseOption) error {
+	var settings parseSettings
+	for _, opt := range opts {
+		opt(&settings)
+	}
 
-	// Use Golang's sync.Once to prevent the setting from being overwritten.
-	_ones.Do(func() {
-		// Get the default configuration directory.
-		projectPath, err = GetProjectDir(filepath.Join(ProjectName))
-		if err != nil {
-			return
-		}
+	// Get the default configuration directory.
+	projectPath, err := GetProjectDir(filepath.Join(ProjectName))
+	if err != nil {
+		return err
+	}
 
-		// Get the struct name to use as the filename.
-		file, err = GetDefaultStructName(&cfg)
-		if err != nil {
-			return
-		}
+	// Get the file name of the config.
+	file, err := configFileName(cfg)
+	if err != nil {
+		return err
+	}
 
-		// Return the result of _parseDefault.
-		err = _parseDefault(filepath.Join(projectPath, "config", file+".json"), cfg)
-		if err != nil {
-			return
-		}
+	// Load the file in its format and apply the defaults of the tags.
+	filePath, format := configFilePath(filepath.Join(projectPath, "config"), file, settings.format)
+	if err = _parseDefaultAs(filePath, format, cfg); err != nil {
+		return err
+	}
 
-		// If the record is configured to be inside the project directory,
-		// prepend the project path to the test record path
-		if cfg.(*BptreeUnitTestConfig).Record.IsInsideProject == true {
-			cfg.(*BptreeUnitTestConfig).Record.TestRecordPath = filepath.Join(projectPath, cfg.(*BptreeUnitTestConfig).Record.TestRecordPath)
+	// Write the file and the tags back, before the environment and AfterLoad change the values in memory only.
+	if settings.writeBack {
+		if err = writeBackConfig(cfg, filePath, format); err != nil {
+			return err
 		}
+	}
 
-		// Below is the test code.
-		cfg.(*BptreeUnitTestConfig).Parameters.BpWidth = []int{3, 6, 7, 8, 12}
+	// Override the file and the tags with the environment variables, such as in a CI job.
+	if err = applyEnv(cfg); err != nil {
+		return err
+	}
+
+	// Check the final values, so a typo in the file or in a variable stops the run with the path of the field.
+	if err = validateConfig(cfg); err != nil {
+		return err
+	}
 
-	})
+	// Let the config finish itself, such as turning the relative paths into absolute ones.
+	if loader, ok := any(cfg).(AfterLoader); ok {
+		return loader.AfterLoad(projectPath)
+	}
 
 	// Return nil to indicate the operation completed successfully.
-	return err
+	return nil
+}
 
+// configFileName ⛏️ returns the file name of the config without the extension, from ConfigFileNamer or the struct name.
+func configFileName(cfg DefaultConfig) (string, error) {
+	if namer, ok := cfg.(ConfigFileNamer); ok {
+		return namer.ConfigFileName(), nil
+	}
+	return GetDefaultStructName(cfg)
 }
 
 // _parseDefault ⛏️ loads the default configuration from struct tags and applies it to the provided struct.
 // Configuration from the file, if the file exists, and applies and overwrites the struct. (以文件的配置为主,结构体配置为次)
+// The format of the file comes from its extension, see _parseDefaultAs.
 func _parseDefault(filePath string, cfg DefaultConfig) error {
+	return _parseDefaultAs(filePath, formatOf(filePath), cfg)
+}
+
+// _parseDefaultAs ⛏️ is _parseDefault reading the file in the given format.
+func _parseDefaultAs(filePath string, format ConfigFormat, cfg DefaultConfig) error {
 	// Check if the config is a pointer to a struct.
 	if reflect.ValueOf(cfg).Kind() != reflect.Ptr {
 		return errors.New("config must be a pointer to a struct")
@@ -77,8 +109,8 @@ func _parseDefault(filePath string, cfg DefaultConfig) error {
 		return err
 	}
 
-	// Unmarshal the JSON data into the provided config and overwrite the default values.
-	if err := json.Unmarshal(file, cfg); err != nil {
+	// Unmarshal the data into the provided config and overwrite the default values.
+	if err := unmarshalConfig(file, format, cfg); err != nil {
 		return err
 	}
 
@@ -93,20 +125,46 @@ func _parseDefault(filePath string, cfg DefaultConfig) error {
 
 // applyDefaults ⛏️ applies the default values from struct tags to the provided config.
 func applyDefaults(cfg interface{}) error {
+	return applyDefaultsWithin(cfg, nil)
+}
+
+// applyDefaultsWithin ⛏️ applies the defaults to the struct, which is nested in the structs of the given types.
+// The types stop a recursive type, such as a Next *Node field, from allocating its nil pointers forever. (防止无限递归)
+func applyDefaultsWithin(cfg interface{}, within []reflect.Type) error {
 	// Get the reflect.Value of the passed-in struct and dereference it.
 	v := reflect.ValueOf(cfg).Elem()
 
 	// Get the type information of the struct.
 	t := v.Type()
+	within = append(within, t)
 
 	// Iterate through all fields in the struct.
 	for i := 0; i < v.NumField(); i++ {
 		field := v.Field(i)     // This will be used later to get the actual value of the field. (在这里获取实际值)
 		fieldType := t.Field(i) // This will be used later to get the default tag value. (在这里获取预设值)
 
-		// If the field is a struct, recursively apply defaults to it.
-		if field.Kind() == reflect.Struct {
-			if err := applyDefaults(field.Addr().Interface()); err != nil { // (这里是递归)
+		// If the field is a struct, recursively apply defaults to it, except time.Time, which takes its default as a value.
+		if field.Kind() == reflect.Struct && field.Type() != timeType {
+			if err := applyDefaultsWithin(field.Addr().Interface(), within); err != nil { // (这里是递归)
+				return err
+			}
+			continue
+		}
+
+		// If the field points to a struct, even through several pointers, allocate the missing ones and recurse,
+		// so an optional section left out of the config file still gets its defaults. (指针指向的结构体)
+		// A nil pointer of a recursive type is left nil, while the loaded ones are still filled.
+		if elem := pointedStruct(field.Type()); elem != nil && field.CanSet() {
+			for field.Kind() == reflect.Pointer && (!field.IsNil() || !slices.Contains(within, elem)) {
+				if field.IsNil() {
+					field.Set(reflect.New(field.Type().Elem()))
+				}
+				field = field.Elem()
+			}
+			if field.Kind() == reflect.Pointer {
+				continue
+			}
+			if err := applyDefaultsWithin(field.Addr().Interface(), within); err != nil { // (这里是递归)
 				return err
 			}
 			continue
@@ -124,6 +182,14 @@ func applyDefaults(cfg interface{}) error {
 			continue
 		}
 
+		// A time is parsed with the layout tag, or with the common layouts without it.
+		if field.Type() == timeType {
+			if err := setTimeValue(field, defaultTag, fieldType.Tag.Get("layout")); err != nil {
+				return fmt.Errorf("field %s: %v", fieldType.Name, err)
+			}
+			continue
+		}
+
 		// Set the field to the default value from the tag.
 		if err := setFieldValue(field, defaultTag); err != nil {
 			return fmt.Errorf("field %s: %v", fieldType.Name, err)
@@ -134,6 +200,47 @@ func applyDefaults(cfg interface{}) error {
 	return nil
 }
 
+// timeType and durationType are the types whose defaults are parsed by the time package instead of by their kind.
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// timeLayouts ⛏️ are tried in order for a time.Time default without a layout tag.
+var timeLayouts = []string{time.RFC3339, time.DateTime, time.DateOnly}
+
+// setTimeValue ⛏️ parses the value with the layout into a time.Time field, such as layout:"2006-01-02" for a date.
+// Without a layout, RFC 3339, the date and time, and the date alone are tried. The time is in UTC unless it has a zone.
+func setTimeValue(field reflect.Value, value, layout string) error {
+	layouts := timeLayouts
+	if layout != "" {
+		layouts = []string{layout}
+	}
+	var err error
+	for _, layout := range layouts {
+		var parsed time.Time
+		if parsed, err = time.Parse(layout, value); err == nil {
+			field.Set(reflect.ValueOf(parsed))
+			return nil
+		}
+	}
+	return err
+}
+
+// pointedStruct ⛏️ returns the struct type the pointer type points to, through any number of pointers, or nil.
+func pointedStruct(t reflect.Type) reflect.Type {
+	if t.Kind() != reflect.Pointer {
+		return nil
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
+
 // setFieldValue ⛏️ sets the value of a field based on its type.
 func setFieldValue(field reflect.Value, value string) error {
 	// Return an error if the field cannot be set.
@@ -153,7 +260,22 @@ func setFieldValue(field reflect.Value, value string) error {
 			return err
 		}
 		field.SetBool(boolVal)
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+	case reflect.Int64:
+		// A duration is written like 500ms or 1m30s, instead of the raw nanoseconds.
+		if field.Type() == durationType {
+			duration, err := time.ParseDuration(value)
+			if err != nil {
+				return err
+			}
+			field.SetInt(int64(duration))
+			return nil
+		}
+		intVal, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(intVal)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
 		// Parse and set an integer value.
 		intVal, err := strconv.ParseInt(value, 10, 64)
 		if err != nil {
@@ -233,15 +355,15 @@ func setFieldValue(field reflect.Value, value string) error {
 }
 
 // defaultConfig2file ⛏️ saves the default configuration to a JSON file.
-func defaultConfig2file(cfg DefaultConfig, overwrite bool) error {
+func defaultConfig2file[T DefaultConfig](cfg *T, overwrite bool) error {
 	// Get the default configuration directory.
 	path, err := GetProjectDir("go-algorithm/config")
 	if err != nil {
 		return err
 	}
 
-	// Get the struct name to use as the filename.
-	file, err := GetDefaultStructName(&cfg)
+	// Get the file name of the config.
+	file, err := configFileName(cfg)
 	if err != nil {
 		return err
 	}
@@ -280,6 +402,85 @@ func _defaultConfig2file(cfg DefaultConfig, filePath string, overwrite bool) err
 	return nil
 }
 
+// writeBackConfig ⛏️ writes the config, loaded from the file with the defaults of the tags, back to the JSON file.
+// The file is left alone when nothing changes, and only JSON is written back, as YAML and TOML would lose their comments.
+// A file holding keys the config does not know is refused, so a config reading a part of a shared file,
+// such as displayConfig, does not erase the rest of it. (只写回 JSON 文件)
+func writeBackConfig(cfg DefaultConfig, filePath string, format ConfigFormat) error {
+	if format != FormatJSON {
+		return fmt.Errorf("can not write back %s, only JSON config files are written back", filePath)
+	}
+
+	// Marshal the config into indented JSON format, like _defaultConfig2file.
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Compare the file with the config, and find the keys the config would drop.
+	file, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(bytes.TrimSpace(file), data) {
+		return nil
+	}
+	var before, after interface{}
+	if err = json.Unmarshal(file, &before); err != nil {
+		return err
+	}
+	if err = json.Unmarshal(data, &after); err != nil {
+		return err
+	}
+	if unknown := unknownKeys(before, after, ""); len(unknown) > 0 {
+		return fmt.Errorf("can not write back %s, the config does not know %s", filePath, strings.Join(unknown, ", "))
+	}
+
+	// Write to a temporary file in the same directory with the permissions of the file, so the rename is atomic
+	// and a crash leaves either the old config or the new one.
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), filepath.Base(filePath)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Chmod(info.Mode().Perm())
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filePath)
+}
+
+// unknownKeys ⛏️ returns the paths of the keys in the objects of the file which are missing in the config, sorted.
+func unknownKeys(file, config interface{}, path string) (unknown []string) {
+	fileObject, ok := file.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	configObject, _ := config.(map[string]interface{})
+	for key, value := range fileObject {
+		keyPath := key
+		if path != "" {
+			keyPath = path + "." + key
+		}
+		if configValue, found := configObject[key]; found {
+			unknown = append(unknown, unknownKeys(value, configValue, keyPath)...) // (这里是递归)
+		} else {
+			unknown = append(unknown, keyPath)
+		}
+	}
+	slices.Sort(unknown)
+	return
+}
+
 // GetDefaultStructName ⛏️ retrieves the name of the struct.
 func GetDefaultStructName(cfg DefaultConfig) (string, error) {
 	// Check if the config is a pointer to a struct.
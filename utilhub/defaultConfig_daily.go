@@ -1,12 +1,14 @@
 package utilhub
 
-import "sync"
+import (
+	"fmt"
+	"sort"
+)
 
 var (
 	// 🧪 Create a config instance for B plus tree unit testing and parse default values.
 	_unitTestConfig = BptreeUnitTestConfig{}
 	_configParseErr = ParseDefault(&_unitTestConfig)
-	_ones           sync.Once // Prevent configuration from being overwritten.
 )
 
 // 🧪 Initialize default test parameters.
@@ -32,3 +34,35 @@ func SetRandomTotalCount(value int64) {
 func GetRandomTotalCount() int64 {
 	return _unitTestConfig.Parameters.RandomTotalCount
 }
+
+// UsePreset ⛏️ replaces the test size parameters with the named preset from the config, such as "small" or "xlarge".
+// RandomMax is calculated again, so it still matches the new RandomTotalCount.
+func UsePreset(name string) error {
+	preset, ok := _unitTestConfig.Presets[name]
+	if !ok {
+		return fmt.Errorf("unknown test preset %q, the presets are %v", name, PresetNames())
+	}
+	if preset.RandomTotalCount <= 0 || len(preset.BpWidth) == 0 {
+		return fmt.Errorf("test preset %q needs a positive randomTotalCount and at least one bpWidth", name)
+	}
+
+	parameters := &_unitTestConfig.Parameters
+	parameters.RandomTotalCount = preset.RandomTotalCount
+	parameters.BpWidth = append([]int(nil), preset.BpWidth...)
+	parameters.VerifyEvery = preset.VerifyEvery
+	if parameters.RandomHitCollisionPercentage > 0 {
+		// randomTotalCount/randomHitCollisionPercentage*100 + randomMin = randomMax
+		parameters.RandomMax = parameters.RandomTotalCount*100/parameters.RandomHitCollisionPercentage + parameters.RandomMin
+	}
+
+	return nil
+}
+
+// PresetNames ⛏️ lists the names of the presets in the config in alphabetical order.
+func PresetNames() (names []string) {
+	for name := range _unitTestConfig.Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
@@ -0,0 +1,30 @@
+package utilhub
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test_UsePreset tests replacing the test size parameters with a preset from the config.
+func Test_UsePreset(t *testing.T) {
+	// Restore the config after the test, because it is shared by the whole package.
+	saved := _unitTestConfig
+	saved.Parameters.BpWidth = append([]int(nil), saved.Parameters.BpWidth...)
+	defer func() { _unitTestConfig = saved }()
+
+	// The config file lists the presets from small to xlarge.
+	assert.Equal(t, []string{"large", "medium", "small", "xlarge"}, PresetNames())
+
+	// The small preset finishes quickly and checks the tree often.
+	assert.NoError(t, UsePreset("small"))
+	cfg := GetDefaultConfig()
+	assert.Equal(t, int64(20000), cfg.Parameters.RandomTotalCount)
+	assert.Equal(t, []int{3, 4, 5}, cfg.Parameters.BpWidth)
+	assert.Equal(t, int64(1000), cfg.Parameters.VerifyEvery)
+	assert.Equal(t, 20000*100/cfg.Parameters.RandomHitCollisionPercentage+cfg.Parameters.RandomMin, cfg.Parameters.RandomMax)
+
+	// An unknown preset changes nothing.
+	assert.Error(t, UsePreset("tiny"))
+	assert.Equal(t, int64(20000), GetRandomTotalCount())
+}
@@ -0,0 +1,111 @@
+package utilhub
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// =====================================================================================================================
+//                  🛠️ Default Config Env (Tool)
+// Default Config Env overrides the fields of a config with environment variables, after the file and the default tags,
+// so a CI job changes a parameter such as RandomTotalCount without editing the JSON file. (环境变量覆盖配置)
+// The precedence, from the highest: the environment variable, the config file, the default tag.
+// =====================================================================================================================
+
+// applyEnv ⛏️ overrides the fields of the config with the environment variables set for them.
+// A field is read from the variable in its env tag, or else from its path in UPPER_SNAKE case,
+// built from the json names, such as PARAMETERS_RANDOM_TOTAL_COUNT for parameters.randomTotalCount.
+// An env tag on a section replaces the start of the names of its fields, and env:"-" leaves a field or a section out.
+// An empty variable is ignored, and a value which can not be parsed is an error naming the variable.
+func applyEnv(cfg interface{}) error {
+	return applyEnvWithin(reflect.ValueOf(cfg).Elem(), "")
+}
+
+// applyEnvWithin ⛏️ overrides the fields of the struct, whose variables start with the prefix.
+func applyEnvWithin(v reflect.Value, prefix string) error {
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		field, fieldType := v.Field(i), t.Field(i)
+		if !fieldType.IsExported() {
+			continue
+		}
+		name, ok := envName(prefix, fieldType)
+		if !ok {
+			continue
+		}
+
+		// Follow the pointers allocated by the file or by the defaults, a nil one has nothing to override.
+		for field.Kind() == reflect.Pointer && !field.IsNil() {
+			field = field.Elem()
+		}
+		if field.Kind() == reflect.Pointer {
+			continue
+		}
+
+		// A section passes its name on to its fields. (这里是递归)
+		if field.Kind() == reflect.Struct && field.Type() != timeType {
+			if err := applyEnvWithin(field, name); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// The variable wins over the file and the default tag.
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		var err error
+		if field.Type() == timeType {
+			err = setTimeValue(field, value, fieldType.Tag.Get("layout"))
+		} else {
+			err = setFieldValue(field, value)
+		}
+		if err != nil {
+			return fmt.Errorf("environment variable %s: %v", name, err)
+		}
+	}
+
+	// No error occurred, return nil.
+	return nil
+}
+
+// envName ⛏️ returns the name of the variable of the field, or false when the field is left out by env:"-".
+func envName(prefix string, fieldType reflect.StructField) (string, bool) {
+	if tag := fieldType.Tag.Get("env"); tag != "" {
+		return tag, tag != "-"
+	}
+	if fieldType.Anonymous {
+		return prefix, true // An embedded struct adds nothing to the names of its fields.
+	}
+	name, _, _ := strings.Cut(fieldType.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		name = fieldType.Name
+	}
+	if prefix != "" {
+		return prefix + "_" + upperSnake(name), true
+	}
+	return upperSnake(name), true
+}
+
+// upperSnake ⛏️ turns a camel case name into UPPER_SNAKE case, such as minFreeMB into MIN_FREE_MB.
+func upperSnake(name string) string {
+	runes := []rune(name)
+	var sb strings.Builder
+	for i, r := range runes {
+		// A word starts at an upper case letter after a lower case one or a digit,
+		// or at the last upper case letter of an acronym followed by a lower case one, such as the S of HTTPServer.
+		if i > 0 && unicode.IsUpper(r) {
+			previous := runes[i-1]
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) ||
+				(unicode.IsUpper(previous) && i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				sb.WriteByte('_')
+			}
+		}
+		sb.WriteRune(unicode.ToUpper(r))
+	}
+	return sb.String()
+}
@@ -0,0 +1,80 @@
+package utilhub
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_UpperSnake tests turning the json names into the names of the environment variables.
+func Test_UpperSnake(t *testing.T) {
+	for name, expected := range map[string]string{
+		"randomTotalCount": "RANDOM_TOTAL_COUNT",
+		"minFreeMB":        "MIN_FREE_MB",
+		"HTTPServer":       "HTTP_SERVER",
+		"bpWidth":          "BP_WIDTH",
+		"mode1Count":       "MODE1_COUNT",
+		"Features":         "FEATURES",
+	} {
+		require.Equal(t, expected, upperSnake(name), name)
+	}
+}
+
+// Test_ApplyEnv tests overriding the file and the default tags with the environment variables.
+func Test_ApplyEnv(t *testing.T) {
+	// The variables are named after the json names, and the tags and the file are overridden.
+	var cfg testConfig
+	require.NoError(t, json.Unmarshal([]byte(`{"server": {"host": "example.com"}}`), &cfg))
+	require.NoError(t, applyDefaults(&cfg))
+	t.Setenv("SERVER_HOST", "ci.local")
+	t.Setenv("DATABASE_POOL_SIZE", "3")
+	t.Setenv("FEATURES", "a, b")
+	t.Setenv("DATABASE_USERNAME", "") // An empty variable is ignored.
+	require.NoError(t, applyEnv(&cfg))
+	require.Equal(t, "ci.local", cfg.Server.Host)
+	require.Equal(t, 8080, cfg.Server.Port)
+	require.Equal(t, 3, cfg.Database.PoolSize)
+	require.Equal(t, "admin", cfg.Database.Username)
+	require.Equal(t, []string{"a", "b"}, cfg.Features)
+
+	// A value which can not be parsed names its variable.
+	t.Setenv("SERVER_PORT", "eighty")
+	err := applyEnv(&cfg)
+	require.ErrorContains(t, err, "SERVER_PORT")
+
+	// The env tags rename a field or a section, or leave them out, and the times and the pointers are followed.
+	var tagged struct {
+		Timeout time.Duration `json:"timeout" env:"CI_TIMEOUT"`
+		Since   time.Time     `json:"since" layout:"01/02/2006"`
+		Cache   *struct {
+			Size int `json:"size"`
+		} `json:"cache" env:"CI_CACHE"`
+		Secret string `json:"secret" env:"-"`
+	}
+	tagged.Cache = &struct {
+		Size int `json:"size"`
+	}{}
+	t.Setenv("CI_TIMEOUT", "2s")
+	t.Setenv("SINCE", "03/01/2024")
+	t.Setenv("CI_CACHE_SIZE", "16")
+	t.Setenv("SECRET", "leaked")
+	require.NoError(t, applyEnv(&tagged))
+	require.Equal(t, 2*time.Second, tagged.Timeout)
+	require.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), tagged.Since)
+	require.Equal(t, 16, tagged.Cache.Size)
+	require.Empty(t, tagged.Secret)
+}
+
+// Test_ApplyEnv_RandomTotalCount tests that a CI job changes the size of the accuracy tests without editing the file.
+func Test_ApplyEnv_RandomTotalCount(t *testing.T) {
+	// Restore the config after the test, because it is shared by the whole package.
+	saved := _unitTestConfig
+	defer func() { _unitTestConfig, _configParseErr = saved, nil }()
+
+	t.Setenv("PARAMETERS_RANDOM_TOTAL_COUNT", "1234")
+	ForceReloadConfig()
+	require.NoError(t, _configParseErr)
+	require.Equal(t, int64(1234), GetRandomTotalCount())
+}
@@ -0,0 +1,127 @@
+package utilhub
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// =====================================================================================================================
+//                  🛠️ Default Config Format (Tool)
+// Default Config Format reads the config file as JSON, YAML or TOML, by its extension or by WithFormat. (配置文件格式)
+// The field names still come from the json tags, so the same struct reads every format without more tags.
+// =====================================================================================================================
+
+// ConfigFormat ⛏️ is the format of a config file.
+type ConfigFormat string
+
+// The formats of the config files.
+const (
+	FormatJSON ConfigFormat = "json"
+	FormatYAML ConfigFormat = "yaml"
+	FormatTOML ConfigFormat = "toml"
+)
+
+// configExtensions ⛏️ are the extensions of every format, looked for in this order when no format is given.
+var configExtensions = []struct {
+	ext    string
+	format ConfigFormat
+}{
+	{".json", FormatJSON},
+	{".yaml", FormatYAML},
+	{".yml", FormatYAML},
+	{".toml", FormatTOML},
+}
+
+// parseSettings ⛏️ holds the settings of ParseDefault.
+type parseSettings struct {
+	format    ConfigFormat // The format of the config file, empty to find it by the extension.
+	writeBack bool         // Whether the file and the defaults of the tags are written back to the file.
+}
+
+// ParseOption ⛏️ defines a function type for configuring ParseDefault.
+type ParseOption func(*parseSettings)
+
+// WithFormat ⛏️ reads the config file only in the format, such as config/<name>.yaml for FormatYAML.
+func WithFormat(format ConfigFormat) ParseOption {
+	return func(s *parseSettings) {
+		s.format = format
+	}
+}
+
+// WithWriteBack ⛏️ writes the config back to its JSON file after the defaults of the tags are applied,
+// so the fields added to the struct show up in the existing file, see writeBackConfig.
+func WithWriteBack() ParseOption {
+	return func(s *parseSettings) {
+		s.writeBack = true
+	}
+}
+
+// configFilePath ⛏️ returns the path of the config file of the name in the directory, and its format.
+// Without a format, the first existing file of the extensions is taken, and the JSON file when there is none,
+// so a missing config is still reported as the missing JSON file.
+func configFilePath(dir, name string, format ConfigFormat) (string, ConfigFormat) {
+	first := ""
+	for _, candidate := range configExtensions {
+		if format != "" && candidate.format != format {
+			continue
+		}
+		path := filepath.Join(dir, name+candidate.ext)
+		if first == "" {
+			first = path
+		}
+		if _, err := os.Stat(path); err == nil {
+			return path, candidate.format
+		}
+	}
+	if format == "" {
+		format = FormatJSON
+	}
+	if first == "" {
+		first = filepath.Join(dir, name+"."+string(format)) // An unknown format is reported when the file is read.
+	}
+	return first, format
+}
+
+// formatOf ⛏️ returns the format of the file by its extension, JSON for an unknown one as before.
+func formatOf(filePath string) ConfigFormat {
+	ext := strings.ToLower(filepath.Ext(filePath))
+	for _, candidate := range configExtensions {
+		if candidate.ext == ext {
+			return candidate.format
+		}
+	}
+	return FormatJSON
+}
+
+// unmarshalConfig ⛏️ decodes the data of the format into the config.
+// YAML and TOML are decoded into a document first, and the document goes through JSON into the config,
+// so the json tags name the fields in every format.
+func unmarshalConfig(data []byte, format ConfigFormat, cfg DefaultConfig) error {
+	var document map[string]interface{}
+	switch format {
+	case FormatJSON:
+		return json.Unmarshal(data, cfg)
+	case FormatYAML:
+		if err := yaml.Unmarshal(data, &document); err != nil {
+			return err
+		}
+	case FormatTOML:
+		if err := toml.Unmarshal(data, &document); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown config format %q", format)
+	}
+
+	converted, err := json.Marshal(document)
+	if err != nil {
+		return fmt.Errorf("the %s config does not fit into JSON: %w", format, err)
+	}
+	return json.Unmarshal(converted, cfg)
+}
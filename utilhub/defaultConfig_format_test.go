@@ -0,0 +1,124 @@
+package utilhub
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_ParseDefault_Format tests reading the same config from JSON, YAML and TOML, with the defaults of the tags.
+func Test_ParseDefault_Format(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.json": `{"server": {"host": "example.com"}, "database": {"pool_size": 3}, "features": ["a", "b"]}`,
+		"config.yaml": "server:\n  host: example.com\ndatabase:\n  pool_size: 3\nfeatures:\n  - a\n  - b\n",
+		"config.yml":  "server: {host: example.com}\ndatabase: {pool_size: 3}\nfeatures: [a, b]\n",
+		"config.toml": "features = [\"a\", \"b\"]\n\n[server]\nhost = \"example.com\"\n\n[database]\npool_size = 3\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+		// The json tags name the fields in every format, and the missing fields get their defaults.
+		var cfg testConfig
+		require.NoError(t, _parseDefault(path, &cfg), name)
+		require.Equal(t, "example.com", cfg.Server.Host, name)
+		require.Equal(t, 8080, cfg.Server.Port, name)
+		require.Equal(t, 3, cfg.Database.PoolSize, name)
+		require.Equal(t, "admin", cfg.Database.Username, name)
+		require.Equal(t, []string{"a", "b"}, cfg.Features, name)
+	}
+
+	// A broken file and an unknown format are errors.
+	broken := filepath.Join(dir, "broken.toml")
+	require.NoError(t, os.WriteFile(broken, []byte("[server\n"), 0o600))
+	require.Error(t, _parseDefault(broken, &testConfig{}))
+	require.Error(t, _parseDefaultAs(filepath.Join(dir, "config.json"), "ini", &testConfig{}))
+}
+
+// Test_ConfigFilePath tests finding the config file by its extension, or by the format given with WithFormat.
+func Test_ConfigFilePath(t *testing.T) {
+	dir := t.TempDir()
+
+	// Without any file, the missing JSON file is reported.
+	path, format := configFilePath(dir, "app", "")
+	require.Equal(t, filepath.Join(dir, "app.json"), path)
+	require.Equal(t, FormatJSON, format)
+
+	// The only file is found, and JSON comes first among several files.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app.toml"), nil, 0o600))
+	path, format = configFilePath(dir, "app", "")
+	require.Equal(t, filepath.Join(dir, "app.toml"), path)
+	require.Equal(t, FormatTOML, format)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app.yml"), nil, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app.json"), nil, 0o600))
+	path, _ = configFilePath(dir, "app", "")
+	require.Equal(t, filepath.Join(dir, "app.json"), path)
+
+	// WithFormat picks the format, both extensions of YAML are accepted.
+	var settings parseSettings
+	WithFormat(FormatYAML)(&settings)
+	path, format = configFilePath(dir, "app", settings.format)
+	require.Equal(t, filepath.Join(dir, "app.yml"), path)
+	require.Equal(t, FormatYAML, format)
+}
+
+// Test_WriteBackConfig tests writing the file merged with the defaults of the tags back to the JSON file.
+func Test_WriteBackConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	// WithWriteBack turns the write-back on.
+	var settings parseSettings
+	WithWriteBack()(&settings)
+	require.True(t, settings.writeBack)
+
+	// The fields missing in the file are written back with their defaults, and the values of the file are kept.
+	path := filepath.Join(dir, "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"server": {"port": 9090}}`), 0o600))
+	var cfg testConfig
+	require.NoError(t, _parseDefault(path, &cfg))
+	require.NoError(t, writeBackConfig(&cfg, path, FormatJSON))
+	var written testConfig
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &written))
+	require.Equal(t, cfg, written)
+	require.Equal(t, 9090, written.Server.Port)
+	require.Equal(t, "admin", written.Database.Username)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// Writing back again changes nothing.
+	require.NoError(t, writeBackConfig(&cfg, path, FormatJSON))
+	again, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, data, again)
+
+	// A file with keys the config does not know is refused, and left alone.
+	shared := filepath.Join(dir, "shared.json")
+	content := []byte(`{"server": {"host": "example.com", "tls": true}, "display": {"palette": "default"}}`)
+	require.NoError(t, os.WriteFile(shared, content, 0o600))
+	cfg = testConfig{}
+	require.NoError(t, _parseDefault(shared, &cfg))
+	err = writeBackConfig(&cfg, shared, FormatJSON)
+	require.ErrorContains(t, err, "display, server.tls")
+	unchanged, err := os.ReadFile(shared)
+	require.NoError(t, err)
+	require.Equal(t, content, unchanged)
+
+	// YAML and TOML are not written back.
+	require.Error(t, writeBackConfig(&cfg, filepath.Join(dir, "config.yaml"), FormatYAML))
+
+	// The file is replaced by a rename, so no temporary file is left in the directory.
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	require.Equal(t, []string{"config.json", "shared.json"}, names)
+}
@@ -1,9 +1,11 @@
 package utilhub
 
 import (
+	"encoding/json"
 	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -252,6 +254,83 @@ func Test_DefaultConfig(t *testing.T) {
 	require.Equal(t, []string(nil), fileList)
 }
 
+// Test_ParseDefault tests loading configs of different structs, named by ConfigFileNamer and finished by AfterLoader.
+func Test_ParseDefault(t *testing.T) {
+	// The B plus tree config keeps its file, and its record path is resolved inside the project.
+	var unitTest BptreeUnitTestConfig
+	require.NoError(t, ParseDefault(&unitTest))
+	require.True(t, filepath.IsAbs(unitTest.Record.TestRecordPath))
+	require.Equal(t, _unitTestConfig.Record.TestRecordPath, unitTest.Record.TestRecordPath)
+	require.NotEmpty(t, unitTest.Parameters.BpWidth)
+
+	// Another struct reads the same file, the tags fill the fields missing in it, and every call loads it again.
+	var display displayConfig
+	require.NoError(t, ParseDefault(&display))
+	require.Equal(t, unitTest.Display.Palette, display.Display.Palette)
+	require.Equal(t, "dark", display.Display.Theme)
+	require.NotEmpty(t, display.projectPath)
+	display.projectPath = ""
+	require.NoError(t, ParseDefault(&display))
+	require.NotEmpty(t, display.projectPath)
+
+	// A struct without a file of its name is an error.
+	require.Error(t, ParseDefault(&testConfig{}))
+}
+
+// Test_ApplyDefaults_Pointer tests the defaults of the optional sections behind pointers.
+func Test_ApplyDefaults_Pointer(t *testing.T) {
+	// The missing sections are allocated, even through two pointers, and get their defaults.
+	var cfg optionalConfig
+	require.NoError(t, applyDefaults(&cfg))
+	require.NotNil(t, cfg.Cache)
+	require.Equal(t, 64, cfg.Cache.Size)
+	require.Equal(t, "lru", cfg.Cache.Mode)
+	require.NotNil(t, cfg.Mirror)
+	require.Equal(t, "http://localhost", (*cfg.Mirror).URL)
+
+	// A recursive type is filled once, and its pointer to the same type is left nil.
+	require.Equal(t, "first", cfg.Chain.Name)
+	require.Nil(t, cfg.Chain.Next)
+
+	// The values from the file are kept, and only the missing fields are filled, in every loaded link of a chain too.
+	cfg = optionalConfig{}
+	require.NoError(t, json.Unmarshal([]byte(`{"cache": {"size": 8}, "chain": {"next": {"next": {}}}}`), &cfg))
+	require.NoError(t, applyDefaults(&cfg))
+	require.Equal(t, 8, cfg.Cache.Size)
+	require.Equal(t, "lru", cfg.Cache.Mode)
+	require.Equal(t, "first", cfg.Chain.Next.Next.Name)
+	require.Nil(t, cfg.Chain.Next.Next.Next)
+}
+
+// Test_ApplyDefaults_Time tests the defaults of the durations and the times.
+func Test_ApplyDefaults_Time(t *testing.T) {
+	var cfg timeConfig
+	require.NoError(t, applyDefaults(&cfg))
+	require.Equal(t, 500*time.Millisecond, cfg.Timeout)
+	require.Equal(t, []time.Duration{time.Second, 90 * time.Second}, cfg.Backoffs)
+
+	// Without a layout tag, a date or RFC 3339 is read, and with it, the layout is used.
+	require.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), cfg.Since)
+	require.Equal(t, time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), cfg.Until)
+	require.True(t, cfg.Scheduled.Equal(time.Date(2024, 3, 1, 0, 30, 0, 0, time.UTC)))
+
+	// A time from the file is kept.
+	cfg = timeConfig{}
+	require.NoError(t, json.Unmarshal([]byte(`{"since": "2020-01-01T00:00:00Z"}`), &cfg))
+	require.NoError(t, applyDefaults(&cfg))
+	require.Equal(t, 2020, cfg.Since.Year())
+
+	// A default not matching its layout is an error.
+	var bad struct {
+		Until time.Time `default:"2025-01-02" layout:"01/02/2006"`
+	}
+	require.Error(t, applyDefaults(&bad))
+	var badDuration struct {
+		Timeout time.Duration `default:"500"`
+	}
+	require.Error(t, applyDefaults(&badDuration))
+}
+
 // Test_RestoreDefaultConfig demonstrates saving the default configuration to a JSON file.
 // The second parameter (overwrite) is set to 'true', which means:
 // - When true: The configuration will actually be written to the physical file
@@ -1,5 +1,10 @@
 package utilhub
 
+import (
+	"path/filepath"
+	"time"
+)
+
 // =====================================================================================================================
 //                  🛠️ Default Config Type (Tool)
 // Default Config Type contains types for DefaultConfig, bptreeUnitTestConfig etc. (这里收集了 DefaultConfig 等类型)
@@ -8,21 +13,42 @@ package utilhub
 // DefaultConfig ⛏️ is a type constraint that allows struct types to store default configuration values. (预设配置)
 type DefaultConfig interface{}
 
+// ConfigFileNamer ⛏️ is implemented by a config whose file under the config directory is not named after its struct.
+type ConfigFileNamer interface {
+	ConfigFileName() string // The file name without the extension, such as DefaultConfig for config/DefaultConfig.json.
+}
+
+// AfterLoader ⛏️ is implemented by a config which finishes itself after ParseDefault loads it, such as resolving its paths.
+type AfterLoader interface {
+	AfterLoad(projectPath string) error // The project path is the root of the repository.
+}
+
 // BptreeUnitTestConfig ⛏️ is a struct for BpTree unit test configuration.
 type BptreeUnitTestConfig struct {
 	Record struct { // 🧪 Record contains configurations related to test record storage.
-		TestRecordPath  string `json:"testRecordPath" default:"/temp/test_record"` // 🧪 TestRecordPath specifies the directory path where test records will be saved.
-		IsInsideProject bool   `json:"isInsideProject" default:"true"`             // 🧪 IsInsideProject indicates whether the test records are stored inside the project directory.
+		TestRecordPath  string `json:"testRecordPath" default:"/temp/test_record"`                         // 🧪 TestRecordPath specifies the directory path where test records will be saved.
+		IsInsideProject bool   `json:"isInsideProject" default:"true"`                                     // 🧪 IsInsideProject indicates whether the test records are stored inside the project directory.
+		MinFreeMB       int64  `json:"minFreeMB" default:"512"`                                            // 🧪 MinFreeMB is the free space in MiB which must stay on the record filesystem, a negative value checks nothing.
+		LowSpaceWait    int64  `json:"lowSpaceWait" default:"0"`                                           // 🧪 LowSpaceWait pauses up to this many seconds for space to be freed, 0 aborts at once.
+		LogLevel        string `json:"logLevel" default:"info" validate:"oneof=debug|info|warn|error|off"` // 🧪 LogLevel is debug, info, warn, error or off, for the log file of every mode, such as mode1.log.
 	} `json:"record"`
 	Parameters struct { // Parameters contains configurations for test execution parameters.
-		RandomTotalCount             int64 `json:"randomTotalCount" default:"7500000"`        // 🧪 RandomTotalCount represents the number of elements to be generated for random testing.
-		RandomMin                    int64 `json:"randomMin" default:"10"`                    // 🧪 RandomMin represents the minimum value for generating random numbers.
-		RandomHitCollisionPercentage int64 `json:"randomHitCollisionPercentage" default:"70"` // 🧪 Random number hit collision percentage.
+		RandomTotalCount             int64 `json:"randomTotalCount" default:"7500000" validate:"min=1"`                // 🧪 RandomTotalCount represents the number of elements to be generated for random testing.
+		RandomMin                    int64 `json:"randomMin" default:"10"`                                             // 🧪 RandomMin represents the minimum value for generating random numbers.
+		RandomHitCollisionPercentage int64 `json:"randomHitCollisionPercentage" default:"70" validate:"min=1,max=100"` // 🧪 Random number hit collision percentage.
 		// Calculate the maximum random value.
 		// randomTotalCount/randomHitCollisionPercentage*100 + randomMin = randomMax
 		// 7500000 / 70 * 100 + 10 = 10714295
-		RandomMax int64 `json:"randomMax" default:"10714295"` // 🧪 RandomMax represents the maximum value for generating random numbers.
-		BpWidth   []int `json:"bpWidth" default:"3,4,5,6,7"`
+		RandomMax   int64 `json:"randomMax" default:"10714295"` // 🧪 RandomMax represents the maximum value for generating random numbers.
+		BpWidth     []int `json:"bpWidth" default:"3,4,5,6,7" validate:"required,min=3"`
+		VerifyEvery int64 `json:"verifyEvery" default:"0" validate:"min=0"` // 🧪 VerifyEvery checks the whole tree after every this many operations, 0 checks nothing in between.
+		SlowestOps  int64 `json:"slowestOps" default:"0" validate:"min=0"`  // 🧪 SlowestOps times every operation and reports this many of the slowest ones, 0 times nothing.
+		// 🧪 ReleaseMemory collects the garbage left by the previous phase before every run: off, gc, or os, which also returns it to the OS.
+		ReleaseMemory string `json:"releaseMemory" default:"gc" validate:"oneof=off|gc|os"`
+		// 🧪 LightPreset is the preset of the accuracy test built without the heavy tag, so a plain go test ./... finishes quickly.
+		LightPreset string `json:"lightPreset" default:"small"`
+		// 🧪 Suites lists the accuracy suites to run, such as mode1,mode3, empty runs every suite.
+		Suites string `json:"suites"`
 	} `json:"parameters"`
 	PoolStage struct { // This is primarily used to test boundary conditions.
 		MinRemovals       int64 `json:"minRemovals" default:"5"`        // 🧪 Lower bound of items to remove in this stage.
@@ -31,13 +57,48 @@ type BptreeUnitTestConfig struct {
 		MaxPreserveInPool int64 `json:"maxPreserveInPool" default:"20"` // 🧪 Upper bound of items to remain in the pool after this stage.
 	} `json:"poolStage"`
 	CyclicStress struct { // metal fatigue style endurance test.
-		CyclicStressCount int `json:"cyclicStressCount" default:"10"` // 🧪 Number of fatigue test cycles.
+		CyclicStressCount int `json:"cyclicStressCount" default:"10" validate:"min=1"` // 🧪 Number of fatigue test cycles.
 	} `json:"cyclicStress"`
+	Display struct { // Display contains the look of the progress bars and the reports.
+		Palette      string `json:"palette" default:"default" validate:"oneof=default|colorblind|monochrome"`         // 🧪 Palette is default, colorblind or monochrome, NO_COLOR still turns the colors off.
+		NumberLocale string `json:"numberLocale" default:"en"`                                                        // 🧪 NumberLocale groups the digits of the counts, such as en for 12,500,000, or none for raw integers.
+		BarStyle     string `json:"barStyle" default:"blocks" validate:"oneof=blocks|halfblocks|braille|dots|shades"` // 🧪 BarStyle is blocks, halfblocks, braille, dots or shades.
+	} `json:"display"`
+	ObjectStore struct { // ObjectStore uploads the records of a run into an S3-compatible object store, such as a nightly CI run. (对象存储)
+		Endpoint     string `json:"endpoint"`                                     // 🧪 Endpoint is the URL of the store, such as http://localhost:9000, empty uploads nothing.
+		Region       string `json:"region" default:"us-east-1"`                   // 🧪 Region is the region the requests are signed for.
+		Bucket       string `json:"bucket"`                                       // 🧪 Bucket holds the uploaded records.
+		Prefix       string `json:"prefix" default:"test_record"`                 // 🧪 Prefix is the key prefix the dated record directories go under.
+		AccessKeyEnv string `json:"accessKeyEnv" default:"AWS_ACCESS_KEY_ID"`     // 🧪 AccessKeyEnv names the environment variable holding the access key ID.
+		SecretKeyEnv string `json:"secretKeyEnv" default:"AWS_SECRET_ACCESS_KEY"` // 🧪 SecretKeyEnv names the environment variable holding the secret key.
+		Upload       string `json:"upload" default:"*.report.json,*.log"`         // 🧪 Upload lists the patterns of the record files uploaded at the end of a run.
+	} `json:"objectStore"`
 	ManualTest struct { // 使用手动测试，重现之前的错误
 		EnableBulkInsertDelete   bool `json:"enableBulkInsertDelete" default:"false"`
 		EnableRandomizedBoundary bool `json:"enableRandomizedBoundary" default:"false"`
 		EnableNodeEnduranceTest  bool `json:"enableNodeEnduranceTest" default:"false"`
 	} `json:"manualTest"`
+	Presets map[string]TestPreset `json:"presets"` // Named workload sizes, such as small for contributors and xlarge for CI. (测试规模预设)
+}
+
+// ConfigFileName ⛏️ keeps the B plus tree config in config/DefaultConfig.json, where it has always been.
+func (cfg *BptreeUnitTestConfig) ConfigFileName() string {
+	return "DefaultConfig"
+}
+
+// AfterLoad ⛏️ prepends the project path to the test record path, if the records are configured to be inside the project.
+func (cfg *BptreeUnitTestConfig) AfterLoad(projectPath string) error {
+	if cfg.Record.IsInsideProject {
+		cfg.Record.TestRecordPath = filepath.Join(projectPath, cfg.Record.TestRecordPath)
+	}
+	return nil
+}
+
+// TestPreset ⛏️ bundles the parameters which decide how long the accuracy tests run.
+type TestPreset struct {
+	RandomTotalCount int64 `json:"randomTotalCount"`         // 🧪 The number of elements to be generated for random testing.
+	BpWidth          []int `json:"bpWidth" validate:"min=3"` // 🧪 The widths of the B plus trees under test.
+	VerifyEvery      int64 `json:"verifyEvery"`              // 🧪 Check the whole tree after every this many operations, 0 checks nothing in between.
 }
 
 // types for testing is as bellows: (以下是测试用的类型) ===== ===== ===== ===== ===== ===== ===== ===== =====
@@ -56,3 +117,62 @@ type testConfig struct {
 	} `json:"database"`
 	Features []string `json:"features" default:"feature1,feature2,feature3"`
 }
+
+// displayConfig ⛏️ is a test struct reading only the display section of the B plus tree config file. (只读显示设置)
+type displayConfig struct {
+	Display struct {
+		Palette string `json:"palette" default:"default"`
+		Theme   string `json:"theme" default:"dark"`
+	} `json:"display"`
+	projectPath string // The project path handed to AfterLoad.
+}
+
+// ConfigFileName ⛏️ shares config/DefaultConfig.json with BptreeUnitTestConfig.
+func (cfg *displayConfig) ConfigFileName() string {
+	return "DefaultConfig"
+}
+
+// AfterLoad ⛏️ records the project path.
+func (cfg *displayConfig) AfterLoad(projectPath string) error {
+	cfg.projectPath = projectPath
+	return nil
+}
+
+// optionalConfig ⛏️ is a test struct with optional sections behind pointers. (指针可选配置)
+type optionalConfig struct {
+	Cache *struct {
+		Size int    `json:"size" default:"64"`
+		Mode string `json:"mode" default:"lru"`
+	} `json:"cache"`
+	Mirror **struct {
+		URL string `json:"url" default:"http://localhost"`
+	} `json:"mirror"`
+	Chain *chainConfig `json:"chain"`
+}
+
+// chainConfig ⛏️ is a test struct pointing to its own type, which must not be allocated forever.
+type chainConfig struct {
+	Name string       `json:"name" default:"first"`
+	Next *chainConfig `json:"next"`
+}
+
+// timeConfig ⛏️ is a test struct with the durations and the times parsed from their defaults. (时间配置)
+type timeConfig struct {
+	Timeout   time.Duration   `json:"timeout" default:"500ms"`
+	Backoffs  []time.Duration `json:"backoffs" default:"1s,1m30s"`
+	Since     time.Time       `json:"since" default:"2024-03-01"`
+	Until     time.Time       `json:"until" default:"01/02/2025" layout:"01/02/2006"`
+	Scheduled time.Time       `json:"scheduled" default:"2024-03-01T08:30:00+08:00"`
+}
+
+// validatedConfig ⛏️ is a test struct with the validate tags on every kind of field. (校验配置)
+type validatedConfig struct {
+	Name   string  `json:"name" validate:"required,max=8"`
+	Mode   string  `json:"mode" default:"fast" validate:"oneof=fast|safe"`
+	Widths []int   `json:"widths" default:"3,4" validate:"required,min=3,max=64"`
+	Ratio  float64 `json:"ratio" default:"0.5" validate:"min=0,max=1"`
+	Cache  *struct {
+		Size int `json:"size" default:"64" validate:"min=1"`
+	} `json:"cache"`
+	Presets map[string]TestPreset `json:"presets"`
+}
@@ -0,0 +1,143 @@
+package utilhub
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// =====================================================================================================================
+//                  🛠️ Default Config Validate (Tool)
+// Default Config Validate checks the validate tags after the file, the defaults and the environment are applied,
+// so a typo such as a width of 0 stops the run with the path of the field, instead of breaking it silently. (配置校验)
+// =====================================================================================================================
+
+// validateConfig ⛏️ checks the fields of the config against their validate tags, such as validate:"required,min=3",
+// and returns one error listing every offending field by its path of json names, such as parameters.bpWidth[0].
+// The rules are separated by commas:
+//
+//	required        the field is not zero, a slice or a map is not empty, and a pointer is not nil
+//	min=N, max=N    a number is within the bound, and a string is at least or at most N bytes long
+//	oneof=a|b|c     the value, written as text, is one of the choices
+//
+// On a slice or an array, required checks the slice itself, and the other rules check every element.
+// The sections behind the pointers, and the structs in the maps and the slices, are checked too.
+func validateConfig(cfg interface{}) error {
+	var problems []string
+	validateValue(reflect.ValueOf(cfg).Elem(), "", "", &problems)
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid config: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// validateValue ⛏️ checks the value at the path against the rules, and goes into its fields and elements.
+func validateValue(v reflect.Value, path, rules string, problems *[]string) {
+	// The required rule looks at the value itself, before the pointers are followed.
+	rest := make([]string, 0, 4)
+	for _, rule := range strings.Split(rules, ",") {
+		if rule = strings.TrimSpace(rule); rule == "" {
+			continue
+		}
+		if rule == "required" {
+			if v.IsZero() || ((v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.Len() == 0) {
+				*problems = append(*problems, fmt.Sprintf("%s is required", path))
+				return
+			}
+			continue
+		}
+		rest = append(rest, rule)
+	}
+
+	// Follow the pointers, a nil one has nothing more to check.
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	switch {
+	case v.Kind() == reflect.Struct && v.Type() != timeType:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			if !t.Field(i).IsExported() {
+				continue
+			}
+			validateValue(v.Field(i), joinPath(path, t.Field(i)), t.Field(i).Tag.Get("validate"), problems) // (这里是递归)
+		}
+	case v.Kind() == reflect.Slice || v.Kind() == reflect.Array:
+		// The rules left over check every element.
+		for i := 0; i < v.Len(); i++ {
+			validateValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i), strings.Join(rest, ","), problems)
+		}
+	case v.Kind() == reflect.Map:
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return strings.Compare(fmt.Sprint(a.Interface()), fmt.Sprint(b.Interface()))
+		})
+		for _, key := range keys {
+			validateValue(v.MapIndex(key), fmt.Sprintf("%s[%v]", path, key.Interface()), "", problems)
+		}
+	default:
+		for _, rule := range rest {
+			if problem := checkRule(v, rule); problem != "" {
+				*problems = append(*problems, fmt.Sprintf("%s %s", path, problem))
+			}
+		}
+	}
+}
+
+// joinPath ⛏️ appends the json name of the field to the path, or its Go name without one.
+func joinPath(path string, field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		name = field.Name
+	}
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// checkRule ⛏️ checks the value against one rule, and returns what is wrong, empty when nothing is.
+func checkRule(v reflect.Value, rule string) string {
+	name, arg, _ := strings.Cut(rule, "=")
+	switch name {
+	case "min", "max":
+		bound, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return fmt.Sprintf("has a bad rule %q", rule)
+		}
+		var value float64
+		var what string
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			value, what = float64(v.Int()), fmt.Sprint(v.Interface())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			value, what = float64(v.Uint()), fmt.Sprint(v.Interface())
+		case reflect.Float32, reflect.Float64:
+			value, what = v.Float(), fmt.Sprint(v.Interface())
+		case reflect.String:
+			value, what = float64(v.Len()), fmt.Sprintf("%q has %d bytes, which", v.String(), v.Len())
+		default:
+			return fmt.Sprintf("can not be checked by %q", rule)
+		}
+		if name == "min" && value < bound {
+			return fmt.Sprintf("= %s is less than the minimum %s", what, arg)
+		}
+		if name == "max" && value > bound {
+			return fmt.Sprintf("= %s is more than the maximum %s", what, arg)
+		}
+	case "oneof":
+		value := fmt.Sprint(v.Interface())
+		if choices := strings.Split(arg, "|"); !slices.Contains(choices, value) {
+			return fmt.Sprintf("= %q is not one of %s", value, strings.Join(choices, ", "))
+		}
+	default:
+		return fmt.Sprintf("has an unknown rule %q", rule)
+	}
+	return ""
+}
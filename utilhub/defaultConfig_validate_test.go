@@ -0,0 +1,48 @@
+package utilhub
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_ValidateConfig tests checking the validate tags after the file and the defaults are applied.
+func Test_ValidateConfig(t *testing.T) {
+	// load 🧫 applies the file and the defaults, and checks the result.
+	load := func(file string) error {
+		var cfg validatedConfig
+		require.NoError(t, json.Unmarshal([]byte(file), &cfg))
+		require.NoError(t, applyDefaults(&cfg))
+		return validateConfig(&cfg)
+	}
+
+	// The defaults and a good file pass.
+	require.NoError(t, load(`{"name": "bench", "presets": {"small": {"bpWidth": [3, 4]}}}`))
+
+	// Every offending field is listed by its path of json names.
+	err := load(`{"widths": [3, 0, 100], "mode": "slow", "ratio": 2, "cache": {"size": -1},
+		"presets": {"small": {"bpWidth": [2]}}}`)
+	require.EqualError(t, err, "invalid config: "+
+		"name is required; "+
+		`mode = "slow" is not one of fast, safe; `+
+		"widths[1] = 0 is less than the minimum 3; "+
+		"widths[2] = 100 is more than the maximum 64; "+
+		"ratio = 2 is more than the maximum 1; "+
+		"cache.size = -1 is less than the minimum 1; "+
+		"presets[small].bpWidth[0] = 2 is less than the minimum 3")
+
+	// A string is measured in bytes, and a bad rule is reported instead of being ignored.
+	err = load(`{"name": "a very long name"}`)
+	require.ErrorContains(t, err, `name = "a very long name" has 16 bytes, which is more than the maximum 8`)
+	var typo struct {
+		Width int `json:"width" validate:"minimum=3"`
+	}
+	require.ErrorContains(t, validateConfig(&typo), `width has an unknown rule "minimum=3"`)
+
+	// The config of the B plus tree tests passes as it is loaded, and a width of 0 is caught.
+	cfg := GetDefaultConfig()
+	require.NoError(t, validateConfig(&cfg))
+	cfg.Parameters.BpWidth = []int{3, 0}
+	require.ErrorContains(t, validateConfig(&cfg), "parameters.bpWidth[1] = 0 is less than the minimum 3")
+}
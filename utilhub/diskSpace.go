@@ -0,0 +1,110 @@
+package utilhub
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// =====================================================================================================================
+//                  🛠️ Disk Space Guard (Tool)
+// Disk Space Guard checks the free space of the record directory before and during the record-heavy runs,
+// and pauses or aborts with a LowDiskSpaceError below a threshold, instead of failing mid-write with ENOSPC.
+// =====================================================================================================================
+
+// ErrDiskSpaceUnsupported ⛏️ is returned by FreeDiskSpace where the free space can not be read.
+var ErrDiskSpaceUnsupported = errors.New("free disk space is not supported on this OS")
+
+// LowDiskSpaceError ⛏️ reports that the filesystem of the path has less free space than the guard requires.
+type LowDiskSpaceError struct {
+	Path    string // The guarded path.
+	Free    uint64 // The free bytes of its filesystem.
+	Need    uint64 // The bytes about to be written.
+	MinFree uint64 // The bytes which must stay free after the write.
+}
+
+// Error ⛏️ describes the shortage in MiB.
+func (e *LowDiskSpaceError) Error() string {
+	return fmt.Sprintf("low disk space on %s: %d MiB free, %d MiB needed and %d MiB must stay free",
+		e.Path, e.Free>>20, e.Need>>20, e.MinFree>>20)
+}
+
+// DiskGuard ⛏️ checks the free space of the filesystem holding a path.
+type DiskGuard struct {
+	path      string                            // The guarded path, such as the record directory.
+	minFree   uint64                            // The bytes which must stay free.
+	wait      time.Duration                     // How long to pause for space, 0 aborts at once.
+	interval  time.Duration                     // How often the space is checked while paused.
+	freeSpace func(path string) (uint64, error) // Reads the free space, replaced in tests.
+}
+
+// DiskGuardOption ⛏️ defines a function type for configuring the DiskGuard.
+type DiskGuardOption func(*DiskGuard)
+
+// WithLowSpaceWait ⛏️ pauses up to wait for space to be freed, checking every interval, before aborting.
+func WithLowSpaceWait(wait, interval time.Duration) DiskGuardOption {
+	return func(g *DiskGuard) {
+		g.wait = wait
+		if interval > 0 {
+			g.interval = interval
+		}
+	}
+}
+
+// NewDiskGuard ⛏️ creates a guard keeping minFree bytes free on the filesystem of the path.
+func NewDiskGuard(path string, minFree uint64, opts ...DiskGuardOption) *DiskGuard {
+	g := &DiskGuard{
+		path:      path,
+		minFree:   minFree,
+		interval:  5 * time.Second,
+		freeSpace: FreeDiskSpace,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// Check ⛏️ returns a *LowDiskSpaceError when writing need bytes would leave less than the minimum free.
+// A nil guard checks nothing, and neither does an OS where the free space can not be read.
+func (g *DiskGuard) Check(need uint64) error {
+	if g == nil {
+		return nil
+	}
+	free, err := g.freeSpace(g.path)
+	if errors.Is(err, ErrDiskSpaceUnsupported) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("check disk space on %s: %w", g.path, err)
+	}
+	if free < need || free-need < g.minFree {
+		return &LowDiskSpaceError{Path: g.path, Free: free, Need: need, MinFree: g.minFree}
+	}
+	return nil
+}
+
+// Ensure ⛏️ is Check which pauses for space when the guard waits, and the progress bar, if any, is paused meanwhile.
+// It returns the last *LowDiskSpaceError when the space is still low after the wait.
+func (g *DiskGuard) Ensure(need uint64, bar *ProgressBar) error {
+	err := g.Check(need)
+	var low *LowDiskSpaceError
+	if !errors.As(err, &low) || g.wait <= 0 {
+		return err
+	}
+
+	// Pause the bar, so the time waiting for space is not counted.
+	if bar != nil {
+		bar.Pause()
+		defer bar.Resume()
+	}
+
+	deadline := time.Now().Add(g.wait)
+	for time.Now().Before(deadline) {
+		time.Sleep(min(g.interval, time.Until(deadline)))
+		if err = g.Check(need); !errors.As(err, &low) {
+			return err
+		}
+	}
+	return err
+}
@@ -0,0 +1,14 @@
+//go:build linux
+
+package utilhub
+
+import "golang.org/x/sys/unix"
+
+// FreeDiskSpace ⛏️ returns the bytes available to an unprivileged user on the filesystem of the path.
+func FreeDiskSpace(path string) (uint64, error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	return stat.Bavail * uint64(stat.Bsize), nil
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package utilhub
+
+// FreeDiskSpace ⛏️ is not available outside Linux, so the disk space guard checks nothing there.
+func FreeDiskSpace(path string) (uint64, error) {
+	return 0, ErrDiskSpaceUnsupported
+}
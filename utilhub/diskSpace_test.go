@@ -0,0 +1,65 @@
+package utilhub
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test_FreeDiskSpace tests reading the free space of the temporary directory.
+func Test_FreeDiskSpace(t *testing.T) {
+	free, err := FreeDiskSpace(os.TempDir())
+	if errors.Is(err, ErrDiskSpaceUnsupported) {
+		t.Skip(err)
+	}
+	assert.NoError(t, err)
+	assert.Greater(t, free, uint64(0))
+}
+
+// Test_DiskGuard tests aborting and pausing when the free space is low.
+func Test_DiskGuard(t *testing.T) {
+	var free atomic.Uint64
+	free.Store(100 << 20)
+	fake := func(string) (uint64, error) { return free.Load(), nil }
+
+	// Aborting at once returns the typed error.
+	guard := NewDiskGuard("/records", 64<<20)
+	guard.freeSpace = fake
+	assert.NoError(t, guard.Check(30<<20))
+	err := guard.Ensure(40<<20, nil)
+	var low *LowDiskSpaceError
+	assert.True(t, errors.As(err, &low))
+	assert.Equal(t, LowDiskSpaceError{Path: "/records", Free: 100 << 20, Need: 40 << 20, MinFree: 64 << 20}, *low)
+	assert.Equal(t, "low disk space on /records: 100 MiB free, 40 MiB needed and 64 MiB must stay free", err.Error())
+
+	// A nil guard checks nothing, and an OS without the free space neither.
+	assert.NoError(t, (*DiskGuard)(nil).Check(1<<40))
+	guard.freeSpace = func(string) (uint64, error) { return 0, ErrDiskSpaceUnsupported }
+	assert.NoError(t, guard.Check(1<<40))
+
+	// Pausing waits until the space is freed, and the bar is paused meanwhile.
+	guard = NewDiskGuard("/records", 64<<20, WithLowSpaceWait(time.Second, time.Millisecond))
+	guard.freeSpace = fake
+	progressBar, err := NewProgressBar("Write", 10, 10, WithTimeControl(0), WithWriter(&bytes.Buffer{}))
+	assert.NoError(t, err)
+	progressBar.Start()
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		free.Store(200 << 20)
+	}()
+	assert.NoError(t, guard.Ensure(40<<20, progressBar))
+	assert.Greater(t, progressBar.Snapshot().Paused, time.Duration(0))
+
+	// Pausing still aborts when no space is freed in time.
+	guard = NewDiskGuard("/records", 64<<20, WithLowSpaceWait(10*time.Millisecond, time.Millisecond))
+	guard.freeSpace = fake
+	free.Store(10 << 20)
+	assert.True(t, errors.As(guard.Ensure(0, progressBar), &low))
+	progressBar.Complete()
+	<-progressBar.WaitForPrinterStop()
+}
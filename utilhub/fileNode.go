@@ -5,7 +5,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
+
+	"github.com/panhongrainbow/go-algorithm/lockhub"
+	"github.com/panhongrainbow/go-algorithm/simhub"
 )
 
 const (
@@ -13,12 +17,48 @@ const (
 	filePermission = 0644
 )
 
+// pathLocks holds a mutex for every path a FileNode creates, writes or removes, so the parallel subtests
+// creating the same dated directory or writing the same record file take turns. (同一路径串行)
+// The mutexes are kept until the process ends, since a test run only touches a few paths.
+var pathLocks sync.Map // map[string]*sync.Mutex
+
+// lockPath serializes the operations on the same path within the process, and returns the function releasing it.
+// It does not keep other processes out, which is what Lock is for.
+func lockPath(path string) (unlock func()) {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	value, _ := pathLocks.LoadOrStore(path, &sync.Mutex{})
+	mu := value.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
 // FileNode represents a file manager that can create directories and track errors.
+// A FileNode is a value, so every goroutine holds its own copy, and the operations on the same path are serialized,
+// which makes the methods safe to call from parallel subtests.
+// It works on the disk, or in memory in a simulation, unless UseFS gives it another filesystem.
 type FileNode struct {
 	// transfer stores the current directory path being transferred.
 	transfer string
 	// err stores any errors that occur during file operations.
 	err error
+	// vfs is the filesystem set by UseFS, nil for simhub.FS.
+	vfs simhub.VFS
+}
+
+// UseFS sets the filesystem of the FileNode and of the FileNodes derived from it, such as a simhub.MemFS in a unit test.
+func (fn FileNode) UseFS(vfs simhub.VFS) FileNode {
+	fn.vfs = vfs
+	return fn
+}
+
+// fs returns the filesystem set by UseFS, or the one of the simulation, which is the disk without a simulation.
+func (fn FileNode) fs() simhub.VFS {
+	if fn.vfs != nil {
+		return fn.vfs
+	}
+	return simhub.FS()
 }
 
 // Error returns the error state of the FileNode instance.
@@ -28,6 +68,7 @@ func (fn FileNode) Error() error {
 }
 
 // MkDir creates a new directory at the specified path.
+// It is idempotent, so an existing directory is not an error, even when another goroutine has just created it.
 func (fn FileNode) MkDir(path string) FileNode {
 	// Check if a previous error has occurred and return it if so.
 	if fn.err != nil {
@@ -38,14 +79,18 @@ func (fn FileNode) MkDir(path string) FileNode {
 	// Update the transfer path to include the newly created directory.
 	fn.transfer = filepath.Join(fn.transfer, path)
 
+	// Take turns with the other goroutines creating the same directory.
+	unlock := lockPath(fn.transfer)
+	defer unlock()
+
 	// Check if the directory already exists.
-	if _, err := os.Stat(fn.transfer); err == nil {
+	if _, err := fn.fs().Stat(fn.transfer); err == nil {
 		// Directory already exists, return immediately without error.
 		return fn
 	}
 
 	// Attempt to create the directory with the specified permissions.
-	if err := os.MkdirAll(fn.transfer, dirPermission); err != nil {
+	if err := fn.fs().MkdirAll(fn.transfer, dirPermission); err != nil {
 		// Return an error if directory creation fails.
 		fn.err = fmt.Errorf("failed to create directory %s: %v", path, err)
 		return fn
@@ -64,8 +109,8 @@ func (fn FileNode) Goto(path string) FileNode {
 	}
 
 	// Check if the directory exists.
-	// os.Stat returns a FileInfo describing the file, or an error if the file does not exist.
-	if _, err := os.Stat(path); os.IsNotExist(err) {
+	// Stat returns a FileInfo describing the file, or an error if the file does not exist.
+	if _, err := fn.fs().Stat(path); os.IsNotExist(err) {
 		// If the directory does not exist, return an error with a descriptive message.
 		fn.err = fmt.Errorf("directory does not exist: %s", path)
 		return fn
@@ -103,9 +148,9 @@ func (fn FileNode) Jump(paths ...string) FileNode {
 	// Join the provided paths to the current transfer path using filepath.Join.
 	fn.transfer = filepath.Join(fn.transfer, filepath.Join(paths...))
 
-	// Check if the resulting directory exists using os.Stat.
-	// If the directory does not exist, os.Stat returns an error.
-	if _, err := os.Stat(fn.transfer); os.IsNotExist(err) {
+	// Check if the resulting directory exists using Stat.
+	// If the directory does not exist, Stat returns an error.
+	if _, err := fn.fs().Stat(fn.transfer); os.IsNotExist(err) {
 		// If the directory does not exist, return an error with a descriptive message.
 		fn.err = fmt.Errorf("directory does not exist: %s", fn.transfer)
 		return fn
@@ -115,8 +160,26 @@ func (fn FileNode) Jump(paths ...string) FileNode {
 	return fn
 }
 
+// Lock takes the advisory lock of the current directory, so two test runs do not write into it at the same time.
+// It retries until the timeout while another process holds the lock, and 0 tries only once.
+func (fn FileNode) Lock(timeout time.Duration) (*lockhub.FileLock, error) {
+	// Check if a previous error has occurred and return it if so.
+	if fn.err != nil {
+		return nil, fn.err
+	}
+
+	// A filesystem in memory is not shared with other processes, so it needs no lock, and a zero lock unlocks nothing.
+	if _, onDisk := fn.fs().(simhub.OSFS); !onDisk {
+		return &lockhub.FileLock{}, nil
+	}
+
+	// Lock the directory through the lock file inside it.
+	return lockhub.LockDir(fn.transfer, timeout)
+}
+
 // Touch creates a new empty file or truncates an existing file to zero length.
 // If the file does not exist, it is created. If the file exists, its contents are cleared.
+// It is idempotent, calling it again leaves the same empty file.
 func (fn FileNode) Touch(filename string) error {
 	// Check if a previous error has occurred and return it immediately.
 	if fn.err != nil {
@@ -136,16 +199,21 @@ func (fn FileNode) Touch(filename string) error {
 		return fmt.Errorf("filename cannot be empty")
 	}
 
+	// Take turns with the other goroutines writing the same file.
+	unlock := lockPath(fn.transfer)
+	defer unlock()
+
 	// Check if the file exists.
-	if _, err := os.Stat(fn.transfer); os.IsNotExist(err) {
+	if _, err := fn.fs().Stat(fn.transfer); os.IsNotExist(err) {
 		// File does not exist, create a new empty file.
-		_, err := os.Create(fn.transfer)
+		file, err := fn.fs().OpenFile(fn.transfer, os.O_RDWR|os.O_CREATE|os.O_TRUNC, filePermission)
 		if err != nil {
 			return fmt.Errorf("failed to create file %s: %v", fn.transfer, err)
 		}
+		_ = file.Close()
 	} else {
 		// File exists, truncate its contents to zero length.
-		err := os.WriteFile(fn.transfer, []byte(""), filePermission)
+		err := fn.fs().WriteFile(fn.transfer, []byte(""), filePermission)
 		if err != nil {
 			return fmt.Errorf("failed to empty file %s: %v", fn.transfer, err)
 		}
@@ -154,6 +222,28 @@ func (fn FileNode) Touch(filename string) error {
 	return nil
 }
 
+// Create creates a file in the directory for writing, or truncates it when it exists, on the filesystem of the FileNode,
+// so the files written into a record directory follow UseFS and the simulation like the logs and the records.
+func (fn FileNode) Create(filename string) (simhub.File, error) {
+	// Check if a previous error has occurred and return it immediately.
+	if fn.err != nil {
+		return nil, fn.err
+	}
+
+	// filename cannot be empty.
+	if filename == "" {
+		return nil, fmt.Errorf("filename cannot be empty")
+	}
+
+	// Open the file for writing, creating it when it is missing.
+	path := filepath.Join(fn.transfer, filename)
+	file, err := fn.fs().OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, filePermission)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file %s: %v", path, err)
+	}
+	return file, nil
+}
+
 // FileTag represents a set of flags for selecting a date and time format.
 type FileTag struct {
 	// yearMonth indicates whether to include the year and month in the date format.
@@ -172,7 +262,7 @@ type FileTag struct {
 // It returns an error if no valid time format is selected.
 func DateTimeTag(ft FileTag) (string, error) {
 	// Get the current time.
-	now := time.Now()
+	now := simhub.Now()
 
 	// Initialize the format string.
 	var format string
@@ -220,7 +310,7 @@ func (fn FileNode) List() (dir []string, file []string, err error) {
 
 	// Check if the given path exists and is a directory.
 	var info os.FileInfo
-	if info, err = os.Stat(fn.transfer); err != nil {
+	if info, err = fn.fs().Stat(fn.transfer); err != nil {
 		return // Return empty slices and the error.
 	}
 
@@ -230,16 +320,9 @@ func (fn FileNode) List() (dir []string, file []string, err error) {
 		return
 	}
 
-	// Open the directory.
-	var dirHandle *os.File
-	if dirHandle, err = os.Open(fn.transfer); err != nil {
-		return // Return empty slices and the error.
-	}
-	defer func() { _ = dirHandle.Close() }() // Ensure the directory is closed.
-
 	// Read directory contents.
-	var entries []os.FileInfo
-	if entries, err = dirHandle.Readdir(-1); err != nil {
+	var entries []os.DirEntry
+	if entries, err = fn.fs().ReadDir(fn.transfer); err != nil {
 		return // Return empty slices and the error.
 	}
 
@@ -284,8 +367,12 @@ func (fn FileNode) RemoveFile(paths ...string) error {
 		return err
 	}
 
+	// Take turns with the other goroutines using the same file.
+	unlock := lockPath(absPath)
+	defer unlock()
+
 	// Get information about the file.
-	info, err := os.Stat(absPath)
+	info, err := fn.fs().Stat(absPath)
 	if err != nil {
 		// If the file does not exist, return an error.
 		if os.IsNotExist(err) {
@@ -302,7 +389,7 @@ func (fn FileNode) RemoveFile(paths ...string) error {
 	}
 
 	// Attempt to remove the file.
-	if err := os.Remove(absPath); err != nil {
+	if err := fn.fs().Remove(absPath); err != nil {
 		// Return an error if the removal operation fails.
 		return fmt.Errorf("failed to remove file %s: %v", absPath, err)
 	}
@@ -319,8 +406,12 @@ func (fn FileNode) RemoveDir(paths ...string) error {
 		return err
 	}
 
+	// Take turns with the other goroutines using the same directory.
+	unlock := lockPath(absPath)
+	defer unlock()
+
 	// Check if the directory exists.
-	info, err := os.Stat(absPath)
+	info, err := fn.fs().Stat(absPath)
 	if os.IsNotExist(err) {
 		return fmt.Errorf("directory does not exist: %s", absPath)
 	}
@@ -331,7 +422,7 @@ func (fn FileNode) RemoveDir(paths ...string) error {
 	}
 
 	// Attempt to remove the directory.
-	if err := os.RemoveAll(absPath); err != nil {
+	if err := fn.fs().RemoveAll(absPath); err != nil {
 		return fmt.Errorf("failed to remove directory %s: %v", absPath, err)
 	}
 
@@ -3,11 +3,17 @@ package utilhub
 import (
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
 	"github.com/google/uuid"
+	"github.com/panhongrainbow/go-algorithm/simhub"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"testing"
-	"time"
 )
 
 // Test_FileNode_MkDir tests the MkDir method of the FileNode struct.
@@ -36,6 +42,94 @@ func Test_FileNode_MkDir(t *testing.T) {
 	assert.EqualError(t, fm.err, "previous error")
 }
 
+// Test_FileNode_Parallel tests creating the same dated directory and touching the same file from parallel subtests.
+func Test_FileNode_Parallel(t *testing.T) {
+	root := t.TempDir()
+	t.Run("group", func(t *testing.T) {
+		for i := 0; i < 8; i++ {
+			t.Run(fmt.Sprintf("subtest %d", i), func(t *testing.T) {
+				t.Parallel()
+				dated := FileNode{}.Goto(root).MkDir("2025-01-02").MkDir("mode1")
+				require.NoError(t, dated.Error())
+				require.NoError(t, dated.Touch("mode1.do_not_open"))
+			})
+		}
+	})
+
+	// Every subtest found the same directory and file, and nothing else was created.
+	dirs, files, err := FileNode{}.Goto(root).Jump("2025-01-02").List()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"mode1"}, dirs)
+	assert.Empty(t, files)
+	_, files, err = FileNode{}.Goto(root).Jump("2025-01-02", "mode1").List()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"mode1.do_not_open"}, files)
+}
+
+// Test_FileNode_MemFS tests the FileNode and the record writers on a filesystem in memory, which leaves nothing on the disk.
+func Test_FileNode_MemFS(t *testing.T) {
+	memory := simhub.NewMemFS(nil)
+	root := "/tmp/" + uuid.New().String()
+	dated := FileNode{}.UseFS(memory).MkDir(root).MkDir("2025-01-02")
+	require.NoError(t, dated.Error())
+	require.NoError(t, dated.Touch("mode1.do_not_open"))
+	require.NoError(t, dated.Touch("mode1.record"))
+	lock, err := dated.Lock(0)
+	require.NoError(t, err)
+	require.NoError(t, lock.Unlock())
+
+	// The log appends, and the stream writes are read back in chunks.
+	log, err := dated.OpenLog("mode1", slog.LevelInfo)
+	require.NoError(t, err)
+	log.Info("run started", "width", 3)
+	require.NoError(t, log.Close())
+	data, err := memory.ReadFile(filepath.Join(dated.Path(), "mode1.log"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `level=INFO msg="run started" width=3`)
+
+	dataChan, finishChan, err := dated.LinuxSpliceStreamWrite("mode1.record", os.O_WRONLY|os.O_APPEND, filePermission)
+	require.NoError(t, err)
+	dataChan <- [][]byte{[]byte("1234"), []byte("56")}
+	dataChan <- [][]byte{[]byte("789")}
+	close(dataChan)
+	<-finishChan
+	chunks, errChan := dated.ReadBytesInChunks("mode1.record", 4)
+	var read []string
+	for chunk := range chunks {
+		read = append(read, string(chunk))
+	}
+	assert.Equal(t, []string{"1234", "5678", "9"}, read)
+	assert.ErrorIs(t, <-errChan, io.EOF)
+
+	// A created file is truncated when it is created again.
+	for _, content := range []string{"{\"mode\": 1}", "{}"} {
+		file, err := dated.Create("mode1.report.json")
+		require.NoError(t, err)
+		_, err = file.Write([]byte(content))
+		require.NoError(t, err)
+		require.NoError(t, file.Close())
+	}
+	data, err = memory.ReadFile(filepath.Join(dated.Path(), "mode1.report.json"))
+	require.NoError(t, err)
+	assert.Equal(t, "{}", string(data))
+	_, err = dated.Create("")
+	assert.Error(t, err)
+
+	// The listing and the removals see the memory only.
+	dirs, files, err := FileNode{}.UseFS(memory).Goto(root).List()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2025-01-02"}, dirs)
+	assert.Empty(t, files)
+	_, files, err = dated.List()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"mode1.do_not_open", "mode1.log", "mode1.record", "mode1.report.json"}, files)
+	assert.NoError(t, dated.RemoveFile(dated.Path(), "mode1.log"))
+	assert.Error(t, dated.RemoveFile(dated.Path(), "mode1.log"))
+	assert.NoError(t, dated.RemoveDir(root))
+	assert.Empty(t, memory.Files())
+	assert.NoDirExists(t, root)
+}
+
 // Test_FileNode_Jump tests the Jump method of the FileNode struct.
 func Test_FileNode_Jump(t *testing.T) {
 	// Create an empty FileNode instance.
@@ -0,0 +1,34 @@
+//go:build !linux
+
+package utilhub
+
+import (
+	"fmt"
+	"os"
+)
+
+// LinuxSpliceBulkWrite ⛏️ writes multiple chunks of data to a file with plain writes, since Splice is not available outside Linux.
+func LinuxSpliceBulkWrite(filename string, data [][]byte, fileFlag int, filePerm os.FileMode) error {
+	file, err := os.OpenFile(filename, fileFlag, filePerm)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	for _, chunk := range data {
+		if _, err := file.Write(chunk); err != nil {
+			return fmt.Errorf("failed to write data: %w", err)
+		}
+	}
+	return nil
+}
+
+// LinuxSpliceStreamWrite ⛏️ returns a channel to send data to be written to the file with plain writes outside Linux.
+func LinuxSpliceStreamWrite(filename string, fileFlag int, filePerm os.FileMode) (dataChan chan [][]byte, finishChan chan struct{}, err error) {
+	file, err := os.OpenFile(filename, fileFlag, filePerm)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	dataChan, finishChan = streamWrite(file)
+	return dataChan, finishChan, nil
+}
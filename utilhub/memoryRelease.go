@@ -0,0 +1,95 @@
+package utilhub
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// =====================================================================================================================
+//                  🛠️ Memory Release (Tool)
+// Memory Release collects the garbage at the boundary between two phases, such as after a prepare phase which leaves
+// a multi-GB heap behind, so the next phase is not measured together with the garbage of the previous one. (阶段间回收内存)
+// The heap before and after is kept, so the report shows how much each release has freed.
+// =====================================================================================================================
+
+// ReleaseMode ⛏️ is how much memory is released at a phase boundary.
+type ReleaseMode int
+
+// The modes of ReleaseMemory.
+const (
+	ReleaseOff ReleaseMode = iota // Nothing is released.
+	ReleaseGC                     // runtime.GC collects the garbage, the freed memory stays with the process.
+	ReleaseOS                     // debug.FreeOSMemory also returns the freed memory to the operating system, which is slower.
+)
+
+// ParseReleaseMode ⛏️ returns the mode named "off", "gc" or "os", such as from a config.
+func ParseReleaseMode(name string) (ReleaseMode, error) {
+	switch strings.ToLower(name) {
+	case "off":
+		return ReleaseOff, nil
+	case "gc":
+		return ReleaseGC, nil
+	case "os":
+		return ReleaseOS, nil
+	}
+	return ReleaseOff, fmt.Errorf("unknown memory release mode %q, the modes are off, gc and os", name)
+}
+
+// MemoryRelease ⛏️ is the memory before and after one release.
+type MemoryRelease struct {
+	Phase      string        // The phase boundary, such as "after prepare".
+	HeapBefore uint64        // The bytes of the allocated heap objects before the release.
+	HeapAfter  uint64        // The bytes of the allocated heap objects after the release.
+	SysBefore  uint64        // The heap bytes held from the operating system before the release.
+	SysAfter   uint64        // The heap bytes held from the operating system after the release.
+	Duration   time.Duration // How long the release took.
+}
+
+// Freed ⛏️ returns the heap bytes freed by the release, 0 when the heap has grown.
+func (r MemoryRelease) Freed() uint64 {
+	if r.HeapAfter > r.HeapBefore {
+		return 0
+	}
+	return r.HeapBefore - r.HeapAfter
+}
+
+// ReleaseMemory ⛏️ releases the memory in the mode, and returns the heap before and after.
+// The second result is false when the mode is ReleaseOff, and then nothing is measured.
+func ReleaseMemory(phase string, mode ReleaseMode) (MemoryRelease, bool) {
+	if mode == ReleaseOff {
+		return MemoryRelease{}, false
+	}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	if mode == ReleaseOS {
+		debug.FreeOSMemory() // It runs a garbage collection too.
+	} else {
+		runtime.GC()
+	}
+	duration := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	return MemoryRelease{
+		Phase:      phase,
+		HeapBefore: before.HeapAlloc,
+		HeapAfter:  after.HeapAlloc,
+		SysBefore:  before.HeapSys - before.HeapReleased,
+		SysAfter:   after.HeapSys - after.HeapReleased,
+		Duration:   duration,
+	}, true
+}
+
+// String ⛏️ describes the release, such as "heap 3.1 GiB -> 120.0 MiB, freed 3.0 GiB in 410ms".
+func (r MemoryRelease) String() string {
+	return r.format(RawNumbers)
+}
+
+// format ⛏️ describes the release with the decimal separator of the number format.
+func (r MemoryRelease) format(f NumberFormat) string {
+	return fmt.Sprintf("heap %s -> %s, freed %s in %s", f.Bytes(r.HeapBefore), f.Bytes(r.HeapAfter), f.Bytes(r.Freed()), r.Duration.Round(time.Millisecond))
+}
@@ -0,0 +1,151 @@
+package utilhub
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// =====================================================================================================================
+//                  🛠️ Multi Bar Manager (Tool)
+// Multi Bar Manager renders several progress bars at the same time, one row for each bar.
+// It is used when parallel workers track their own progress, so the bars do not fight over the same terminal line.
+// =====================================================================================================================
+
+// MultiBarManager ⛏️ owns several ProgressBars and repaints them together.
+type MultiBarManager struct {
+	writer  io.Writer      // Destination of the rendered rows, stdout by default.
+	bars    []*ProgressBar // The progress bars, in the order of their rows.
+	lines   []string       // The latest rendered line of every bar.
+	painted bool           // Indicates whether the rows have been painted once, so the cursor must move back up.
+	started bool           // Indicates whether Start has been called.
+	updates chan barUpdate // Fan-in channel of the progress messages of all bars.
+	finish  chan struct{}  // Closed after the final repaint.
+	mu      sync.Mutex     // Protects bars and started.
+}
+
+// barUpdate ⛏️ is a progress message of the bar at the given row.
+type barUpdate struct {
+	row int        // The row of the bar.
+	msg barMessage // The progress message.
+}
+
+// NewMultiBarManager ⛏️ creates a manager rendering to the writer, nil means stdout.
+func NewMultiBarManager(writer io.Writer) *MultiBarManager {
+	if writer == nil {
+		writer = os.Stdout
+	}
+	return &MultiBarManager{
+		writer:  writer,
+		updates: make(chan barUpdate),
+		finish:  make(chan struct{}),
+	}
+}
+
+// AddBar ⛏️ creates a progress bar owned by the manager, which gets the next row on the screen.
+// The bars must be added before Start. Do not call their own Start, ListenPrinter or WaitForPrinterStop; use Wait instead.
+func (m *MultiBarManager) AddBar(name string, total uint64, barLength int, opts ...BarOption) (*ProgressBar, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// The rows are fixed once the painting starts.
+	if m.started {
+		return nil, errors.New("bars cannot be added after the manager has started")
+	}
+
+	// Create the bar with the same options as a single one.
+	pb, err := NewProgressBar(name, total, barLength, opts...)
+	if err != nil {
+		return nil, err
+	}
+	m.bars = append(m.bars, pb)
+	m.lines = append(m.lines, pb.render(barMessage{}))
+
+	return pb, nil
+}
+
+// Start ⛏️ paints all rows and starts listening to the progress of every bar.
+func (m *MultiBarManager) Start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Prevent the listeners from being started twice.
+	if m.started {
+		return
+	}
+	m.started = true
+
+	// Forward the messages of every bar to the fan-in channel.
+	var wg sync.WaitGroup
+	for row, pb := range m.bars {
+		wg.Add(1)
+		go func(row int, pb *ProgressBar) {
+			defer wg.Done()
+			for msg := range pb.printChannel {
+				m.updates <- barUpdate{row: row, msg: msg}
+			}
+		}(row, pb)
+	}
+
+	// Close the fan-in channel after every bar is completed.
+	go func() {
+		wg.Wait()
+		close(m.updates)
+	}()
+
+	// Repaint the rows whenever any bar moves.
+	go func() {
+		m.repaint()
+		for update := range m.updates {
+			m.lines[update.row] = m.bars[update.row].render(update.msg)
+
+			// Take all pending messages first, so one repaint covers them. (合并更新，减少闪烁)
+			m.drain()
+			m.repaint()
+		}
+		close(m.finish)
+	}()
+}
+
+// Wait ⛏️ waits until every bar is completed and the final rows are painted.
+func (m *MultiBarManager) Wait() {
+	<-m.finish
+}
+
+// drain ⛏️ applies the pending messages without blocking.
+func (m *MultiBarManager) drain() {
+	for {
+		select {
+		case update, ok := <-m.updates:
+			if !ok {
+				return
+			}
+			m.lines[update.row] = m.bars[update.row].render(update.msg)
+		default:
+			return
+		}
+	}
+}
+
+// repaint ⛏️ writes all rows in a single write, moving the cursor back to the first row when they were painted before.
+func (m *MultiBarManager) repaint() {
+	var frame strings.Builder
+
+	// Move the cursor up to the first row.
+	if m.painted && len(m.lines) > 0 {
+		frame.WriteString(fmt.Sprintf("\033[%dA", len(m.lines)))
+	}
+
+	// Clear every row and write its latest line.
+	for _, line := range m.lines {
+		frame.WriteString("\r\033[2K")
+		frame.WriteString(line)
+		frame.WriteString("\n")
+	}
+
+	_, _ = io.WriteString(m.writer, frame.String())
+	m.painted = true
+}
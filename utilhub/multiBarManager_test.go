@@ -0,0 +1,60 @@
+package utilhub
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test_MultiBarManager tests that several progress bars are painted on their own rows.
+func Test_MultiBarManager(t *testing.T) {
+	// Create a manager writing into a buffer.
+	var buf bytes.Buffer
+	manager := NewMultiBarManager(&buf)
+
+	// Add three bars, one for each worker.
+	names := []string{"Worker-1", "Worker-2", "Worker-3"}
+	var bars []*ProgressBar
+	for _, name := range names {
+		pb, err := manager.AddBar(name, 20, 10, WithTimeControl(1), WithTimeZone("Etc/UTC"))
+		assert.NoError(t, err)
+		bars = append(bars, pb)
+	}
+	manager.Start()
+
+	// No bar can be added after the start.
+	_, err := manager.AddBar("Late", 1, 10)
+	assert.Error(t, err)
+
+	// The workers update their bars concurrently.
+	var wg sync.WaitGroup
+	for _, pb := range bars {
+		wg.Add(1)
+		go func(pb *ProgressBar) {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				pb.UpdateBar()
+			}
+			pb.Complete()
+		}(pb)
+	}
+	wg.Wait()
+	manager.Wait()
+
+	// The first frame has no cursor movement, and every later frame moves back to the first row.
+	output := buf.String()
+	frames := strings.Split(output, "\033[3A")
+	assert.Greater(t, len(frames), 1)
+	assert.Equal(t, 3, strings.Count(frames[0], "\n"))
+
+	// The last frame shows every bar completed on its own row.
+	last := strings.Split(strings.TrimSuffix(frames[len(frames)-1], "\n"), "\n")
+	assert.Len(t, last, 3)
+	for i, name := range names {
+		assert.True(t, strings.HasPrefix(last[i], "\r\033[2K"+name+": "))
+		assert.True(t, strings.Contains(last[i], "100.00%"))
+	}
+}
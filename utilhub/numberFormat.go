@@ -0,0 +1,111 @@
+package utilhub
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// =====================================================================================================================
+//                  🛠️ Number Format (Tool)
+// Number Format groups the digits of large counts, such as "12,500,000" instead of "12500000",
+// because the raw nine-digit integers in the reports are hard to read.
+// =====================================================================================================================
+
+// NumberFormat ⛏️ is how the numbers are written in a locale.
+type NumberFormat struct {
+	Group   string // The thousands separator, empty for no grouping.
+	Decimal string // The decimal separator.
+}
+
+// The number formats which can be selected by locale in a config.
+var (
+	// RawNumbers ⛏️ writes the numbers as Go does, without grouping.
+	RawNumbers = NumberFormat{Decimal: "."}
+
+	// numberLocales ⛏️ maps the locales to their number formats. (千分位格式)
+	numberLocales = map[string]NumberFormat{
+		"none": RawNumbers,
+		"en":   {Group: ",", Decimal: "."}, // 12,500,000.5
+		"de":   {Group: ".", Decimal: ","}, // 12.500.000,5
+		"fr":   {Group: " ", Decimal: ","}, // 12 500 000,5
+		"ch":   {Group: "'", Decimal: "."}, // 12'500'000.5
+		"zh":   {Group: ",", Decimal: "."}, // 12,500,000.5
+	}
+)
+
+// NumberFormatByLocale ⛏️ returns the number format of a locale, such as "en", "de", "fr", "ch", "zh" or "none".
+// The region and the encoding are ignored, so "de_DE.UTF-8" is the same as "de". An empty locale returns RawNumbers.
+func NumberFormatByLocale(locale string) (NumberFormat, error) {
+	if locale == "" {
+		return RawNumbers, nil
+	}
+	parts := strings.FieldsFunc(locale, func(r rune) bool { return r == '_' || r == '-' || r == '.' })
+	if len(parts) == 0 {
+		return RawNumbers, fmt.Errorf("unknown number locale %q", locale)
+	}
+	format, ok := numberLocales[strings.ToLower(parts[0])]
+	if !ok {
+		return RawNumbers, fmt.Errorf("unknown number locale %q", locale)
+	}
+	return format, nil
+}
+
+// Uint ⛏️ writes an unsigned integer with the digits grouped by three.
+func (f NumberFormat) Uint(n uint64) string {
+	return f.group(strconv.FormatUint(n, 10))
+}
+
+// Float ⛏️ writes a float with the given decimals, grouping the integer part.
+func (f NumberFormat) Float(v float64, decimals int) string {
+	text := strconv.FormatFloat(v, 'f', decimals, 64)
+	integer, fraction, found := strings.Cut(text, ".")
+	sign := ""
+	if strings.HasPrefix(integer, "-") {
+		sign, integer = "-", integer[1:]
+	}
+	text = sign + f.group(integer)
+	if found {
+		decimal := f.Decimal
+		if decimal == "" {
+			decimal = "." // The zero format still writes a decimal point.
+		}
+		text += decimal + fraction
+	}
+	return text
+}
+
+// group ⛏️ inserts the thousands separator into a string of digits.
+func (f NumberFormat) group(digits string) string {
+	if f.Group == "" || len(digits) <= 3 {
+		return digits
+	}
+
+	var b strings.Builder
+	head := len(digits) % 3
+	if head == 0 {
+		head = 3
+	}
+	b.WriteString(digits[:head])
+	for i := head; i < len(digits); i += 3 {
+		b.WriteString(f.Group)
+		b.WriteString(digits[i : i+3])
+	}
+	return b.String()
+}
+
+// byteUnits ⛏️ are the binary units of Bytes, each 1024 times the one before.
+var byteUnits = [...]string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+
+// Bytes ⛏️ writes a size with a binary unit and one decimal, such as "1.2 GiB", and the bytes below 1 KiB as "512 B".
+func (f NumberFormat) Bytes(n uint64) string {
+	if n < 1024 {
+		return strconv.FormatUint(n, 10) + " " + byteUnits[0]
+	}
+	value, unit := float64(n), 0
+	for value >= 1024 && unit < len(byteUnits)-1 {
+		value /= 1024
+		unit++
+	}
+	return f.Float(value, 1) + " " + byteUnits[unit]
+}
@@ -0,0 +1,47 @@
+package utilhub
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test_NumberFormat tests grouping the digits in several locales.
+func Test_NumberFormat(t *testing.T) {
+	// The locale picks the separators, and the region and the encoding are ignored.
+	for locale, expected := range map[string]string{
+		"":            "12500000.50",
+		"none":        "12500000.50",
+		"en":          "12,500,000.50",
+		"en_US.UTF-8": "12,500,000.50",
+		"de-DE":       "12.500.000,50",
+		"fr":          "12 500 000,50",
+		"ch":          "12'500'000.50",
+	} {
+		format, err := NumberFormatByLocale(locale)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, format.Float(12500000.5, 2), "locale %q", locale)
+	}
+	_, err := NumberFormatByLocale("xx")
+	assert.Error(t, err)
+	_, err = NumberFormatByLocale(".")
+	assert.Error(t, err)
+
+	// The digits are grouped by three from the right, and the short numbers are kept.
+	en, _ := NumberFormatByLocale("en")
+	for n, expected := range map[uint64]string{0: "0", 999: "999", 1000: "1,000", 123456: "123,456", 1234567: "1,234,567", 18446744073709551615: "18,446,744,073,709,551,615"} {
+		assert.Equal(t, expected, en.Uint(n))
+	}
+	assert.Equal(t, "-1,234.6", en.Float(-1234.56, 1))
+	assert.Equal(t, "1,235", en.Float(1234.56, 0))
+
+	// The zero format writes the raw numbers.
+	assert.Equal(t, "1234.5", NumberFormat{}.Float(1234.5, 1))
+
+	// The sizes are written with binary units and the decimal separator of the locale.
+	de, _ := NumberFormatByLocale("de")
+	for n, expected := range map[uint64]string{0: "0 B", 1023: "1023 B", 1024: "1.0 KiB", 1288490189: "1.2 GiB", 4 << 30: "4.0 GiB", 18446744073709551615: "16.0 EiB"} {
+		assert.Equal(t, expected, en.Bytes(n))
+	}
+	assert.Equal(t, "1,5 MiB", de.Bytes(3<<19))
+}
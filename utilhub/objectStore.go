@@ -0,0 +1,86 @@
+package utilhub
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/panhongrainbow/go-algorithm/simhub"
+)
+
+// =====================================================================================================================
+//                  🛠️ Object Store (Tool)
+// Object Store uploads the records of a run, such as the reports and the logs, into the object store set in the config,
+// so a nightly endurance run on an ephemeral CI machine keeps them after the machine is gone. (上传测试记录)
+// The credentials come from the environment variables named in the config, so they never land in the config file.
+// =====================================================================================================================
+
+// ConfigObjectFS ⛏️ returns the object store set in the config, nil when no endpoint is set.
+func ConfigObjectFS() (simhub.VFS, error) {
+	store := _unitTestConfig.ObjectStore
+	if store.Endpoint == "" {
+		return nil, nil
+	}
+	accessKey, secretKey := os.Getenv(store.AccessKeyEnv), os.Getenv(store.SecretKeyEnv)
+	if accessKey == "" || secretKey == "" {
+		return nil, fmt.Errorf("the object store needs the credentials in %s and %s", store.AccessKeyEnv, store.SecretKeyEnv)
+	}
+	return simhub.NewObjectFS(simhub.ObjectStore{
+		Endpoint:  store.Endpoint,
+		Region:    store.Region,
+		Bucket:    store.Bucket,
+		Prefix:    store.Prefix,
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+	})
+}
+
+// UploadPatterns ⛏️ returns the patterns of the record files uploaded at the end of a run, set in the config.
+func UploadPatterns() (patterns []string) {
+	for _, pattern := range strings.Split(_unitTestConfig.ObjectStore.Upload, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return
+}
+
+// Upload ⛏️ copies the files of the directory whose names match any of the patterns into the directory of another filesystem,
+// such as the reports of a run into an object store, and returns the names of the copied files.
+// The subdirectories are not copied, and a bad pattern is an error before anything is copied.
+func (fn FileNode) Upload(dst simhub.VFS, dir string, patterns ...string) (uploaded []string, err error) {
+	if fn.err != nil {
+		return nil, fn.err
+	}
+	for _, pattern := range patterns {
+		if _, err = filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("bad upload pattern %q: %w", pattern, err)
+		}
+	}
+	_, files, err := fn.List()
+	if err != nil {
+		return nil, err
+	}
+	if err = dst.MkdirAll(dir, dirPermission); err != nil {
+		return nil, err
+	}
+
+	for _, name := range files {
+		for _, pattern := range patterns {
+			if matched, _ := filepath.Match(pattern, name); !matched {
+				continue
+			}
+			data, err := fn.fs().ReadFile(filepath.Join(fn.Path(), name))
+			if err != nil {
+				return uploaded, err
+			}
+			if err = dst.WriteFile(filepath.Join(dir, name), data, filePermission); err != nil {
+				return uploaded, err
+			}
+			uploaded = append(uploaded, name)
+			break
+		}
+	}
+	return uploaded, nil
+}
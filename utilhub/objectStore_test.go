@@ -0,0 +1,37 @@
+package utilhub
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/panhongrainbow/go-algorithm/simhub"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test_FileNode_Upload tests copying the matching record files into another filesystem.
+func Test_FileNode_Upload(t *testing.T) {
+	records, store := simhub.NewMemFS(nil), simhub.NewMemFS(nil)
+	dated := FileNode{}.UseFS(records).MkDir("/records").MkDir("2025-01-02")
+	assert.NoError(t, dated.Error())
+	for _, name := range []string{"mode1.log", "mode1.report.json", "mode1.do_not_open"} {
+		assert.NoError(t, records.WriteFile(filepath.Join(dated.Path(), name), []byte(name), 0o644))
+	}
+	assert.NoError(t, dated.MkDir("mode1").Touch("trace.log"))
+
+	// Only the matching files of the directory itself are copied.
+	uploaded, err := dated.Upload(store, "/nightly/2025-01-02", "*.report.json", "*.log")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"mode1.log", "mode1.report.json"}, uploaded)
+	assert.Equal(t, []string{"/nightly/2025-01-02/mode1.log", "/nightly/2025-01-02/mode1.report.json"}, store.Files())
+	data, err := store.ReadFile("/nightly/2025-01-02/mode1.log")
+	assert.NoError(t, err)
+	assert.Equal(t, "mode1.log", string(data))
+
+	// A bad pattern copies nothing, and the config without an endpoint uploads nothing.
+	_, err = dated.Upload(store, "/other", "[")
+	assert.Error(t, err)
+	objects, err := ConfigObjectFS()
+	assert.NoError(t, err)
+	assert.Nil(t, objects)
+	assert.Equal(t, []string{"*.report.json", "*.log"}, UploadPatterns())
+}
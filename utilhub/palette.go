@@ -0,0 +1,57 @@
+package utilhub
+
+import (
+	"fmt"
+	"os"
+)
+
+// =====================================================================================================================
+//                  🛠️ Palette (Tool)
+// Palette groups the colors of the progress bar and its report, so the red/yellow/magenta scheme can be swapped
+// for a colorblind-friendly one, or for no color at all when NO_COLOR or CLICOLOR=0 asks for it.
+// =====================================================================================================================
+
+// Palette ⛏️ is the colors of a progress bar and its report.
+type Palette struct {
+	Bar     string // The bar color, empty keeps the WithDisplay color.
+	Title   string // The report title.
+	Border  string // The report borders and dividers.
+	Header  string // The report header.
+	Row     string // The report rows.
+	NoColor bool   // Prints no ANSI codes at all, not even the bar colors.
+}
+
+// The palettes which can be selected by name in a config.
+var (
+	// DefaultPalette ⛏️ is the original scheme, which keeps the bar color set by WithDisplay.
+	DefaultPalette = Palette{Title: BrightMagenta, Border: BrightYellow, Header: BrightRed, Row: DarkYellow}
+
+	// ColorblindPalette ⛏️ uses blue and yellow, which stay apart for the common red-green color blindness.
+	ColorblindPalette = Palette{Bar: BrightBlue, Title: BrightWhite, Border: BrightBlue, Header: BrightYellow, Row: BrightWhite}
+
+	// MonochromePalette ⛏️ prints plain text, for the terminal themes where colors are unreadable and for log files.
+	MonochromePalette = Palette{NoColor: true}
+)
+
+// PaletteByName ⛏️ returns the palette named "default", "colorblind" or "monochrome", so a config can select it.
+// An empty name returns the default palette.
+func PaletteByName(name string) (Palette, error) {
+	switch name {
+	case "", "default":
+		return DefaultPalette, nil
+	case "colorblind":
+		return ColorblindPalette, nil
+	case "monochrome":
+		return MonochromePalette, nil
+	}
+	return Palette{}, fmt.Errorf("unknown palette %q, the palettes are default, colorblind and monochrome", name)
+}
+
+// colorDisabled ⛏️ reports whether the environment asks for no color.
+// NO_COLOR with any value or CLICOLOR=0 turns the colors off, and CLICOLOR_FORCE other than 0 turns them on again.
+func colorDisabled() bool {
+	if force := os.Getenv("CLICOLOR_FORCE"); force != "" && force != "0" {
+		return false
+	}
+	return os.Getenv("NO_COLOR") != "" || os.Getenv("CLICOLOR") == "0"
+}
@@ -0,0 +1,68 @@
+package utilhub
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// =====================================================================================================================
+//                  🛠️ Perf Counter (Tool)
+// Perf Counter reads the hardware counters of the CPU, such as cache misses and branch misses, with perf_event_open.
+// Node layout optimizations of the B plus tree need hardware evidence, and these counters can be reported
+// next to ns/op in the benchmark output. It only works on Linux, and it is optional.
+// =====================================================================================================================
+
+// ErrPerfUnsupported is returned when the hardware counters are not available on this system.
+var ErrPerfUnsupported = errors.New("hardware perf counters are not supported")
+
+// PerfEnvName is the environment variable that turns on the perf counters in the benchmarks, set by algobench.
+const PerfEnvName = "ALGOBENCH_PERF"
+
+// PerfEvent ⛏️ is a hardware event to be counted.
+type PerfEvent int
+
+const (
+	PerfCacheMisses     PerfEvent = iota + 1 // Last level cache misses.
+	PerfCacheReferences                      // Last level cache accesses.
+	PerfBranchMisses                         // Mispredicted branches.
+	PerfInstructions                         // Retired instructions.
+)
+
+// String returns the unit name used in the benchmark output.
+func (event PerfEvent) String() string {
+	switch event {
+	case PerfCacheMisses:
+		return "cache-misses"
+	case PerfCacheReferences:
+		return "cache-references"
+	case PerfBranchMisses:
+		return "branch-misses"
+	case PerfInstructions:
+		return "instructions"
+	}
+	return fmt.Sprintf("perf-event-%d", int(event))
+}
+
+// PerfResult ⛏️ holds the counted value of every event.
+type PerfResult map[PerfEvent]uint64
+
+// MetricReporter ⛏️ is satisfied by *testing.B, so this file does not import the testing package.
+type MetricReporter interface {
+	ReportMetric(n float64, unit string)
+}
+
+// ReportPerOp ⛏️ reports every counter divided by the number of operations, such as "cache-misses/op".
+func (result PerfResult) ReportPerOp(reporter MetricReporter, ops int) {
+	if ops <= 0 {
+		return
+	}
+	for event, value := range result {
+		reporter.ReportMetric(float64(value)/float64(ops), event.String()+"/op")
+	}
+}
+
+// PerfEnabled ⛏️ reports whether the benchmarks should collect the perf counters.
+func PerfEnabled() bool {
+	return os.Getenv(PerfEnvName) == "1"
+}
@@ -0,0 +1,122 @@
+//go:build linux
+
+package utilhub
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"strconv"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// perfConfigs ⛏️ maps the hardware events to their perf_event_open configs.
+var perfConfigs = map[PerfEvent]uint64{
+	PerfCacheMisses:     unix.PERF_COUNT_HW_CACHE_MISSES,
+	PerfCacheReferences: unix.PERF_COUNT_HW_CACHE_REFERENCES,
+	PerfBranchMisses:    unix.PERF_COUNT_HW_BRANCH_MISSES,
+	PerfInstructions:    unix.PERF_COUNT_HW_INSTRUCTIONS,
+}
+
+// PerfCounters ⛏️ holds the opened hardware counters of every thread of the process.
+type PerfCounters struct {
+	events []PerfEvent // The counted events.
+	fds    [][]int     // The file descriptors, fds[i] belongs to events[i], one for each thread.
+}
+
+// StartPerfCounters ⛏️ opens and enables the hardware counters for every thread of the current process.
+// The threads created later are counted as well, because the counters are inherited.
+// Only the user space is counted, so it works with the default perf_event_paranoid setting.
+// It returns ErrPerfUnsupported when the kernel or the container does not allow hardware counters.
+func StartPerfCounters(events ...PerfEvent) (*PerfCounters, error) {
+	// Count cache misses and branch misses by default.
+	if len(events) == 0 {
+		events = []PerfEvent{PerfCacheMisses, PerfBranchMisses}
+	}
+
+	// The Go runtime runs goroutines on several threads, so every thread needs its own counter.
+	threads, err := os.ReadDir("/proc/self/task")
+	if err != nil {
+		return nil, err
+	}
+
+	pc := &PerfCounters{events: events, fds: make([][]int, len(events))}
+	for i, event := range events {
+		config, ok := perfConfigs[event]
+		if !ok {
+			pc.close()
+			return nil, errors.New("unknown perf event: " + event.String())
+		}
+		attr := unix.PerfEventAttr{
+			Type:   unix.PERF_TYPE_HARDWARE,
+			Config: config,
+			Bits:   unix.PerfBitDisabled | unix.PerfBitInherit | unix.PerfBitExcludeKernel | unix.PerfBitExcludeHv,
+		}
+		attr.Size = uint32(unsafe.Sizeof(attr))
+		for _, thread := range threads {
+			tid, convErr := strconv.Atoi(thread.Name())
+			if convErr != nil {
+				continue
+			}
+			fd, openErr := unix.PerfEventOpen(&attr, tid, -1, -1, unix.PERF_FLAG_FD_CLOEXEC)
+			if errors.Is(openErr, unix.ESRCH) {
+				continue // The thread has just exited.
+			}
+			if openErr != nil {
+				pc.close()
+				return nil, errors.Join(ErrPerfUnsupported, openErr)
+			}
+			pc.fds[i] = append(pc.fds[i], fd)
+		}
+	}
+
+	// Enable all counters at the same time, as close as possible.
+	for _, fds := range pc.fds {
+		for _, fd := range fds {
+			if err = unix.IoctlSetInt(fd, unix.PERF_EVENT_IOC_ENABLE, 0); err != nil {
+				pc.close()
+				return nil, err
+			}
+		}
+	}
+
+	return pc, nil
+}
+
+// Stop ⛏️ disables the counters, closes them and returns the sums of all threads.
+func (pc *PerfCounters) Stop() (PerfResult, error) {
+	defer pc.close()
+
+	// Disable first, so the reading does not count itself.
+	for _, fds := range pc.fds {
+		for _, fd := range fds {
+			_ = unix.IoctlSetInt(fd, unix.PERF_EVENT_IOC_DISABLE, 0)
+		}
+	}
+
+	// Sum the counters of every thread.
+	result := make(PerfResult, len(pc.events))
+	buf := make([]byte, 8)
+	for i, fds := range pc.fds {
+		for _, fd := range fds {
+			if _, err := unix.Read(fd, buf); err != nil {
+				return nil, err
+			}
+			result[pc.events[i]] += binary.NativeEndian.Uint64(buf)
+		}
+	}
+
+	return result, nil
+}
+
+// close ⛏️ closes all opened file descriptors.
+func (pc *PerfCounters) close() {
+	for i, fds := range pc.fds {
+		for _, fd := range fds {
+			_ = unix.Close(fd)
+		}
+		pc.fds[i] = nil
+	}
+}
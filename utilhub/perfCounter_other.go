@@ -0,0 +1,16 @@
+//go:build !linux
+
+package utilhub
+
+// PerfCounters ⛏️ is not available outside Linux.
+type PerfCounters struct{}
+
+// StartPerfCounters ⛏️ always returns ErrPerfUnsupported outside Linux.
+func StartPerfCounters(events ...PerfEvent) (*PerfCounters, error) {
+	return nil, ErrPerfUnsupported
+}
+
+// Stop ⛏️ always returns ErrPerfUnsupported outside Linux.
+func (pc *PerfCounters) Stop() (PerfResult, error) {
+	return nil, ErrPerfUnsupported
+}
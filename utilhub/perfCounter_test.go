@@ -0,0 +1,39 @@
+package utilhub
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// metricRecorder collects the reported metrics like *testing.B does.
+type metricRecorder map[string]float64
+
+func (r metricRecorder) ReportMetric(n float64, unit string) { r[unit] = n }
+
+// Test_PerfCounters tests counting the hardware events around some work.
+func Test_PerfCounters(t *testing.T) {
+	counters, err := StartPerfCounters(PerfInstructions, PerfBranchMisses)
+	if errors.Is(err, ErrPerfUnsupported) {
+		t.Skip("hardware perf counters are not available:", err)
+	}
+	assert.NoError(t, err)
+
+	// Do some work that runs many instructions.
+	sum := 0
+	for i := 0; i < 1_000_000; i++ {
+		sum += i % 7
+	}
+	assert.Greater(t, sum, 0)
+
+	result, err := counters.Stop()
+	assert.NoError(t, err)
+	assert.Greater(t, result[PerfInstructions], uint64(1_000_000))
+
+	// The counters are reported per operation.
+	recorder := metricRecorder{}
+	result.ReportPerOp(recorder, 1_000_000)
+	assert.Contains(t, recorder, "instructions/op")
+	assert.Contains(t, recorder, "branch-misses/op")
+}
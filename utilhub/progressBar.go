@@ -3,11 +3,18 @@ package utilhub
 import (
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
-	"unsafe"
+	"unicode/utf8"
+
+	"github.com/panhongrainbow/go-algorithm/simhub"
 )
 
 // =====================================================================================================================
@@ -21,44 +28,157 @@ import (
 type ProgressBar struct {
 	// Basic properties
 	name      string // Name of the progress bar.
-	total     uint32 // Total number of steps or units to track.
+	total     uint64 // Total number of steps or units to track.
 	barLength int    // Visual length of the progress bar.
 
 	// Tracking progress
 	precision        int    // Number of decimal places for displaying the progress percentage.
-	currentProcess   uint32 // Current progress value.
-	lastFilledLength int    // Tracks the last filled position to avoid redundant updates.
+	currentProcess   uint64 // Current progress value.
+	lastFilledLength int64  // Tracks the last filled position to avoid redundant updates, accessed atomically.
 
 	// Timezone configuration
 	timezone string         // Timezone for displaying the start time of the progress bar.
 	location *time.Location // Time.Location object for the specified timezone.
 
 	// Timing information
-	startTime time.Time // Start time of the progress tracking.
-	endTime   time.Time // End time, set when progress is complete.
-	complete  bool      // Indicates whether the progress has been completed.
+	startTime   time.Time   // Start time of the progress tracking.
+	endTime     time.Time   // End time, set when progress is complete.
+	complete    bool        // Indicates whether the progress has been completed.
+	interrupted bool        // Indicates whether the progress bar was ended by Interrupt instead of Complete.
+	failure     error       // The error given to Fail, nil unless the progress bar failed.
+	stopped     atomic.Bool // Set by Interrupt and Fail, so the later updates are ignored without the mutex.
+
+	// Pause control
+	paused     bool          // Indicates whether the progress bar is paused.
+	pausedAt   time.Time     // When the current pause began.
+	pausedTime time.Duration // Total paused time, excluded from the elapsed time.
+
+	// Rate smoothing
+	smoothing    float64   // The weight of the newest sample in the moving average of the rate, 0 uses the average since the start.
+	smoothedRate float64   // The exponentially weighted moving average of the rate, 0 until the first sample.
+	sampleAt     time.Time // When the last rate sample was taken, the paused time is skipped.
+	sampleDone   uint64    // The progress at the last rate sample.
+
+	// Stage
+	stage string // The short status message shown after the percentage, such as the running phase, empty for none.
+
+	// Progress group
+	parent *ProgressBar // The parent bar of a progress group, which moves together with this bar.
+
+	// Phases
+	phases  []PhaseStat     // The steps and the time of every phase fed by Checkpoint, in the order they first appear.
+	slowOps *SlowOps        // The slowest operations of an instrumented run, nil when the operations are not timed.
+	memory  []MemoryRelease // The memory released at the phase boundaries.
+
+	// Hooks
+	onUpdate   func(done, total uint64)    // Called after every update with the current progress.
+	onComplete func(report ProgressReport) // Called once when the progress bar is completed.
+
+	// Milestones
+	milestones     []float64                                 // The percentages crossed once each, sorted, empty for none.
+	nextMilestone  atomic.Int32                              // The index of the next milestone to cross.
+	onMilestone    func(percent float64, done, total uint64) // Called once for every milestone crossed.
+	milestoneMu    sync.Mutex                                // Guards milestoneLines.
+	milestoneLines []string                                  // The milestone lines of log mode waiting for the printer.
 
 	// Time control and synchronization
-	updateInterval int // Time interval between each update (in milliseconds).
-	// ticker         *time.Ticker // Controls the frequency of updates (regular refreshes).
-	ticker <-chan time.Time // Channel to control the frequency of updates (regular refreshes).
+	updateInterval int            // Time interval between each update (in milliseconds).
+	ticker         *simhub.Ticker // Ticks once every update interval while running, nil without an update interval.
+	refreshDue     atomic.Bool    // Set when a tick is taken, and cleared by the refresh which sends the progress.
 
 	// Display properties
-	barColor     string          // ANSI color code for the progress bar display.
-	resetColor   string          // ANSI reset code to revert colors after rendering the progress bar.
-	printChannel chan barMessage // Channel for displaying progress messages, added for testing purposes.
-	finishBar    chan struct{}   // Channel to wait for all messages to finish displaying.
+	barColor     string           // ANSI color code for the progress bar display.
+	filledRune   rune             // The rune of the completed portion, 0 until it is chosen by the charset.
+	emptyRune    rune             // The rune of the remaining portion, 0 until it is chosen by the charset.
+	partialRunes []rune           // The runes of a partly filled edge cell set by the style, empty for whole cells only.
+	leftCap      string           // Drawn before the bar, "[" by default.
+	rightCap     string           // Drawn after the bar, "]" by default.
+	thresholds   []colorThreshold // The colors switched by the percentage, sorted by the percentage, empty for one color.
+	showETA      bool             // Indicates whether the estimated time remaining is displayed after the percentage.
+	showRate     bool             // Indicates whether the operations per second are displayed after the percentage.
+	showElapsed  bool             // Indicates whether the running elapsed time is displayed right after the percentage.
+	autoWidth    bool             // Indicates whether the bar length follows the terminal width.
+	columns      atomic.Int32     // The terminal width watched by the printer, 0 when the writer is not a terminal.
+	template     string           // The layout of the rendered line, empty for the default layout.
+	logMode      bool             // Indicates whether plain lines are printed when the writer is not a terminal.
+	resetColor   string           // ANSI reset code to revert colors after rendering the progress bar.
+	palette      Palette          // The colors of the bar and the report.
+	numbers      NumberFormat     // How the counts, the rates and the percentage are written.
+	units        Units            // What the steps count, such as Bytes, which are written with binary units.
+	writer       io.Writer        // Destination of the rendered progress bar and report, stdout by default.
+	printChannel chan barMessage  // Channel for displaying progress messages, added for testing purposes.
+	finishBar    chan struct{}    // Channel to wait for all messages to finish displaying.
+	printers     sync.WaitGroup   // The running printers, started by Start or ListenPrinter, waited for by Close.
+	closeOnce    sync.Once        // Close tears the bar down once.
 
 	// Using atomic operations can reduce the dependence on mutexes, thereby improving the performance and concurrency of the program.
 	mu sync.Mutex
 }
 
+// ProgressReport ⛏️ is the summary of a completed progress bar, the same as the table printed by Report.
+type ProgressReport struct {
+	Name        string          // The name of the progress bar.
+	StartTime   time.Time       // When the progress bar started.
+	EndTime     time.Time       // When the progress bar was completed.
+	Elapsed     time.Duration   // The time between the start and the end, without the paused time.
+	Paused      time.Duration   // The total paused time.
+	Total       uint64          // The total number of steps.
+	Completed   uint64          // The number of completed steps.
+	Rate        float64         // The completed steps per second, without the paused time.
+	Smoothed    float64         // The moving average of the rate over the latest refreshes, 0 when it is turned off.
+	Stage       string          // The stage set by SetStage, empty when none is set.
+	Phases      []PhaseStat     // The throughput of every phase fed by Checkpoint, such as the insert and the delete phases.
+	Slowest     []SlowOp        // The slowest operations kept by WithSlowOps, the slowest first.
+	Memory      []MemoryRelease // The memory released at the phase boundaries, added by AddMemoryRelease.
+	Interrupted bool            // Indicates the bar was ended by Interrupt, so Completed may be less than Total.
+	Failure     error           // The error given to Fail, nil unless the bar failed.
+}
+
+// PhaseStat ⛏️ is the steps and the time spent in one phase, such as the inserts of a test mode.
+type PhaseStat struct {
+	Name    string        // The name of the phase.
+	Steps   uint64        // The steps done in the phase.
+	Elapsed time.Duration // The time spent in the phase.
+}
+
+// Rate ⛏️ returns the steps per second of the phase, or 0 when no time has been recorded.
+func (stat PhaseStat) Rate() float64 {
+	if stat.Elapsed <= 0 {
+		return 0
+	}
+	return float64(stat.Steps) / stat.Elapsed.Seconds()
+}
+
+// colorThreshold ⛏️ is the bar color used from a percentage on.
+type colorThreshold struct {
+	percentage float64 // The percentage from which the color is used (0 to 100).
+	color      string  // ANSI color code of the bar.
+}
+
 // barMessage ⛏️ is used for passing progress updates through channels.
 type barMessage struct {
-	filledLength int     // The number of units filled in the progress bar.
-	percentage   float64 // The current progress percentage (0 to 100).
+	filledLength int           // The number of units filled in the progress bar.
+	percentage   float64       // The current progress percentage (0 to 100).
+	eta          time.Duration // The estimated time remaining, negative when it is still unknown.
+	rate         float64       // The smoothed steps done per second.
+	done         uint64        // The steps done so far.
+	total        uint64        // The total number of steps.
+	interrupted  bool          // Indicates the final message of an interrupted bar.
+	failure      error         // The error of the final message of a failed bar.
+	stage        string        // The stage set by SetStage, empty when none is set.
+	counter      bool          // Indicates a bar without a total, which shows the count instead of the bar and the percentage.
+	elapsed      time.Duration // The time since the start, without the paused time.
 }
 
+// Units ⛏️ is what the steps of a progress bar count.
+type Units int
+
+// The units of WithUnits.
+const (
+	Counts Units = iota // Plain counts, such as operations, written with the number format.
+	Bytes               // Bytes, written with binary units, such as "1.2 GiB / 4.0 GiB".
+)
+
 // BarOption ⛏️ defines a function type for configuring the ProgressBar.
 type BarOption func(*ProgressBar)
 
@@ -76,7 +196,7 @@ func WithTimeZone(timeZoneName string) BarOption {
 	}
 }
 
-// WithTimeControl sets the ticker for controlling the progress bar update frequency, in milliseconds.
+// WithTimeControl sets the timer for controlling the progress bar update frequency, in milliseconds.
 func WithTimeControl(updateInterval int) BarOption {
 	return func(pb *ProgressBar) {
 		pb.updateInterval = updateInterval
@@ -90,8 +210,193 @@ func WithDisplay(color string) BarOption {
 	}
 }
 
+// WithPalette sets the colors of the bar and the report, such as ColorblindPalette or MonochromePalette.
+// Without it, the default palette is used. NO_COLOR or CLICOLOR=0 turns the colors off with any palette.
+func WithPalette(palette Palette) BarOption {
+	return func(pb *ProgressBar) {
+		pb.palette = palette
+	}
+}
+
+// WithSmoothing sets the weight of the newest sample in the moving average of the rate, between 0 and 1.
+// A higher weight follows the changes faster, and 0 shows the average since the start. The default is 0.3.
+func WithSmoothing(weight float64) BarOption {
+	return func(pb *ProgressBar) {
+		pb.smoothing = min(max(weight, 0), 1)
+	}
+}
+
+// WithSlowOps sets the slowest operations shown by the report, the operations are fed to them by the caller.
+func WithSlowOps(slowOps *SlowOps) BarOption {
+	return func(pb *ProgressBar) {
+		pb.slowOps = slowOps
+	}
+}
+
+// WithUnits sets what the steps count. With Bytes, the done and total steps are written with binary units,
+// and the default layout shows them after the percentage, such as "1.2 GiB / 4.0 GiB".
+func WithUnits(units Units) BarOption {
+	return func(pb *ProgressBar) {
+		pb.units = units
+	}
+}
+
+// WithNumberFormat sets how the counts, the rates and the percentage are written, such as "12,500,000".
+// Use NumberFormatByLocale to pick the format of a locale, the numbers are not grouped without it.
+func WithNumberFormat(format NumberFormat) BarOption {
+	return func(pb *ProgressBar) {
+		pb.numbers = format
+	}
+}
+
+// WithColorThresholds sets the bar colors by the percentage, each color is used from its percentage (0 to 100) on,
+// such as {0: BrightRed, 50: BrightYellow, 90: BrightGreen}. Below the lowest percentage, the WithDisplay color is used.
+// A phase which stalls keeps its color for long, so it can be spotted at a glance.
+func WithColorThresholds(thresholds map[float64]string) BarOption {
+	return func(pb *ProgressBar) {
+		pb.thresholds = pb.thresholds[:0]
+		for percentage, color := range thresholds {
+			pb.thresholds = append(pb.thresholds, colorThreshold{percentage: percentage, color: color})
+		}
+		sort.Slice(pb.thresholds, func(i, j int) bool { return pb.thresholds[i].percentage < pb.thresholds[j].percentage })
+	}
+}
+
+// WithCharset sets the runes of the completed and the remaining portions, such as '#' and '-' or '=' and ' '.
+// Without it, "█" and "░" are used, and '#' and '-' are used when the terminal does not seem to support Unicode.
+func WithCharset(filled, empty rune) BarOption {
+	return func(pb *ProgressBar) {
+		pb.filledRune, pb.emptyRune = filled, empty
+	}
+}
+
+// WithRunes sets the runes of the completed and the remaining cells and the caps around the bar,
+// such as '=', ' ', '<' and '>' for "<===   >", so a house style is matched without a whole style.
+// A cap of 0 draws nothing, and NewProgressBar returns an error for a rune which does not take exactly one column.
+func WithRunes(fill, empty, leftCap, rightCap rune) BarOption {
+	return func(pb *ProgressBar) {
+		pb.filledRune, pb.emptyRune, pb.partialRunes = fill, empty, nil
+		pb.leftCap, pb.rightCap = "", ""
+		if leftCap != 0 {
+			pb.leftCap = string(leftCap)
+		}
+		if rightCap != 0 {
+			pb.rightCap = string(rightCap)
+		}
+	}
+}
+
+// WithStyle sets the look of the bar, such as HalfBlocksStyle, whose edge cell is filled by eighths.
+// WithCharset after it replaces the runes of the whole cells and keeps the edge cell.
+func WithStyle(style BarStyle) BarOption {
+	return func(pb *ProgressBar) {
+		runes := barStyles[style]
+		if runes.filled == 0 {
+			runes = barStyles[BlocksStyle]
+		}
+		pb.filledRune, pb.emptyRune, pb.partialRunes = runes.filled, runes.empty, runes.partial
+	}
+}
+
+// WithElapsed toggles the running elapsed time right after the percentage, such as "26:03:10" on a day-long run,
+// so how long the run has taken is known before the report. The paused time is not counted.
+func WithElapsed(show bool) BarOption {
+	return func(pb *ProgressBar) {
+		pb.showElapsed = show
+	}
+}
+
+// WithRate toggles the operations per second after the percentage, such as "12500.0/s", so a slowdown
+// shows up while the percentage alone still looks fine, such as when the widths are compared in Mode 3.
+func WithRate(show bool) BarOption {
+	return func(pb *ProgressBar) {
+		pb.showRate = show
+	}
+}
+
+// WithETA toggles the estimated time remaining after the percentage.
+func WithETA(show bool) BarOption {
+	return func(pb *ProgressBar) {
+		pb.showETA = show
+	}
+}
+
+// WithAutoWidth toggles fitting the bar into the terminal width, which is checked again on every refresh.
+// The fixed bar length is used when the width can not be detected.
+func WithAutoWidth(auto bool) BarOption {
+	return func(pb *ProgressBar) {
+		pb.autoWidth = auto
+	}
+}
+
+// WithTemplate sets the layout of the rendered line, so the components can be reordered or dropped.
+// The components are {name}, {bar}, {percent}, {eta}, {rate}, {count}, {stage} and {elapsed}, such as "{name} {bar} {percent} {eta} {rate}".
+// {count} is the done steps and the total, such as "1,250,000/12,500,000" with WithNumberFormat, and {elapsed} is hh:mm:ss.
+func WithTemplate(template string) BarOption {
+	return func(pb *ProgressBar) {
+		pb.template = template
+	}
+}
+
+// WithLogMode prints a timestamped plain line at each refresh when the writer is not a terminal,
+// such as a CI log or a pipe, where rewriting the line with \r produces garbage.
+// The progress bar is still redrawn in place on a terminal.
+func WithLogMode() BarOption {
+	return func(pb *ProgressBar) {
+		pb.logMode = true
+	}
+}
+
+// WithOnUpdate sets a hook called after every update with the done steps and the total,
+// such as pushing the progress into a metrics system. It runs on the updating goroutine, so it should be quick.
+func WithOnUpdate(fn func(done, total uint64)) BarOption {
+	return func(pb *ProgressBar) {
+		pb.onUpdate = fn
+	}
+}
+
+// WithOnComplete sets a hook called once with the report when the progress bar is completed.
+func WithOnComplete(fn func(report ProgressReport)) BarOption {
+	return func(pb *ProgressBar) {
+		pb.onComplete = fn
+	}
+}
+
+// WithMilestones sets the percentages, such as 25, 50, 75 and 90, which are reported once each when the progress crosses them,
+// by the WithOnMilestone hook and by a timestamped line in log mode. A long unattended run can raise an alert on them.
+// The percentages outside (0, 100] are ignored, and a progress taken back by Sub does not cross a milestone again.
+func WithMilestones(percents ...float64) BarOption {
+	return func(pb *ProgressBar) {
+		pb.milestones = pb.milestones[:0]
+		for _, percent := range percents {
+			if percent > 0 && percent <= 100 {
+				pb.milestones = append(pb.milestones, percent)
+			}
+		}
+		sort.Float64s(pb.milestones)
+		pb.milestones = slices.Compact(pb.milestones)
+	}
+}
+
+// WithOnMilestone sets a hook called once for every milestone set by WithMilestones, with the milestone and the progress
+// which crossed it. It runs on the updating goroutine, so it should be quick.
+func WithOnMilestone(fn func(percent float64, done, total uint64)) BarOption {
+	return func(pb *ProgressBar) {
+		pb.onMilestone = fn
+	}
+}
+
+// WithWriter sets the destination of the progress bar and the report, such as stderr, a buffer or a log file.
+func WithWriter(w io.Writer) BarOption {
+	return func(pb *ProgressBar) {
+		pb.writer = w
+	}
+}
+
 // NewProgressBar ⛏️ initializes and returns a ProgressBar with optional configurations.
-func NewProgressBar(name string, total uint32, barLength int, opts ...BarOption) (*ProgressBar, error) {
+// A total of 0 makes a counter-only bar for work of unknown size, which shows the steps done without a bar or a percentage,
+// until SetTotal gives it a total.
+func NewProgressBar(name string, total uint64, barLength int, opts ...BarOption) (*ProgressBar, error) {
 	// Create a default ProgressBar with the required parameters.
 	pb := &ProgressBar{
 		// Basic properties
@@ -115,17 +420,54 @@ func NewProgressBar(name string, total uint32, barLength int, opts ...BarOption)
 
 		// Time control and synchronization
 		updateInterval: 1000, // Default update interval in milliseconds.
-		// ticker: will be updated (4)
+		// ticker: will be started (4)
+
+		// Rate smoothing
+		smoothing: 0.3, // The newest sample weighs 30% in the moving average of the rate.
+		// sampleAt: will be updated (2)
 
 		// Display properties
-		barColor:   BrightCyan, // Default color for the progress bar.
-		resetColor: Reset,      // Reset color to avoid affecting subsequent terminal output.
+		barColor:   BrightCyan,     // Default color for the progress bar.
+		leftCap:    "[",            // Default cap before the bar.
+		rightCap:   "]",            // Default cap after the bar.
+		resetColor: Reset,          // Reset color to avoid affecting subsequent terminal output.
+		palette:    DefaultPalette, // Default colors of the report.
+		numbers:    RawNumbers,     // Numbers are not grouped by default.
+		writer:     os.Stdout,      // Print to the terminal by default.
 	}
 
 	// Apply any optional configurations to the default ProgressBar.
 	for _, opt := range opts {
 		opt(pb)
 	}
+	if pb.writer == nil {
+		pb.writer = os.Stdout
+	}
+
+	// NO_COLOR and CLICOLOR win over any palette, and so does an older Windows console which would print the ANSI codes as text.
+	// The palette overrides the bar colors.
+	if colorDisabled() || !enableANSI(pb.writer) {
+		pb.palette = MonochromePalette
+	}
+	if pb.palette.Bar != "" {
+		pb.barColor = pb.palette.Bar
+	}
+	if pb.palette.NoColor {
+		pb.barColor, pb.resetColor, pb.thresholds = "", "", nil
+	}
+
+	// Fall back to ASCII when no charset is set and the block runes would show as mojibake.
+	if pb.filledRune == 0 && pb.emptyRune == 0 {
+		pb.filledRune, pb.emptyRune = barStyles[BlocksStyle].filled, barStyles[BlocksStyle].empty
+		if !supportsUnicode() {
+			pb.filledRune, pb.emptyRune = '#', '-'
+		}
+	}
+
+	// Every rune of the bar must take one column, or the bar would not keep its length.
+	if err := pb.checkRunes(); err != nil {
+		return nil, err
+	}
 
 	// Set the start/end time using the specified timezone.
 	loc, err := time.LoadLocation(pb.timezone)
@@ -135,52 +477,320 @@ func NewProgressBar(name string, total uint32, barLength int, opts ...BarOption)
 	pb.location = loc // Updated location based on the timezone (1)
 
 	// Set the start time using the specified timezone.
-	pb.startTime = time.Now().In(loc) // Start time is set after loading the location (2)
+	pb.startTime = simhub.Now().In(loc) // Start time is set after loading the location (2)
+	pb.sampleAt = pb.startTime
 
-	// If an update interval is provided, initialize the ticker for updates.
+	// If an update interval is provided, start the ticker for the refreshes. (4)
+	// It is the only ticker of the bar, stopped by Pause and by the end of the bar, and reset by Resume.
 	if pb.updateInterval > 0 {
-		pb.ticker = time.After(time.Duration(pb.updateInterval) * time.Millisecond)
-		// pb.ticker = time.NewTicker(time.Duration(pb.updateInterval) * time.Millisecond) // Initialize the ticker (4)
+		pb.ticker = simhub.NewTicker(time.Duration(pb.updateInterval) * time.Millisecond)
 	}
 
 	// printChannel is used to send messages for displaying updates on the progress bar.
-	pb.printChannel = make(chan barMessage)
+	// It holds one message, and a newer message replaces it, so a slow writer never holds back the updates.
+	pb.printChannel = make(chan barMessage, 1)
 
 	// finishBar is used to notify when the Progress Bar has completed, triggering the generation of a progress report.
-	pb.finishBar = make(chan struct{})
+	// It holds the signal of the printer, so the printer returns even when WaitForPrinterStop is never called.
+	pb.finishBar = make(chan struct{}, 1)
 
 	return pb, nil
 }
 
+// Start ⛏️ runs the printer in its own goroutine, counted before the goroutine starts,
+// so a Close called right after it still waits for the printer to return.
+func (pb *ProgressBar) Start() {
+	pb.printers.Add(1)
+	go pb.listen()
+}
+
 // ListenPrinter ⛏️ listens to the print channel and outputs progress messages.
+// It returns once the progress bar is finished, or closed by Close.
+// It blocks, so use Start instead of running it with go, which Close could miss.
 func (pb *ProgressBar) ListenPrinter() {
-	for msg := range pb.printChannel {
+	pb.printers.Add(1)
+	pb.listen()
+}
 
-		// Format the percentage string using the specified precision.
-		format := fmt.Sprintf("%%.%df", pb.precision) // `%%` will be interpreted as a literal percent sign character.
-		percentageStr := fmt.Sprintf(format, msg.percentage)
+// listen ⛏️ prints the progress messages until the print channel is closed, and marks the printer done.
+func (pb *ProgressBar) listen() {
+	defer pb.printers.Done()
+
+	plain := pb.plainLines()
+
+	// On a terminal, watch its width, so a resized window gets the bar fitted and repainted at once.
+	var resized chan os.Signal
+	if !plain && isTerminal(pb.writer) {
+		resized = make(chan os.Signal, 1)
+		notifyResize(resized)
+		defer stopResize(resized)
+		pb.columns.Store(int32(terminalWidth(pb.writer)))
+	}
 
-		// Use "█" to represent the completed portion and "░" for the remaining portion.
-		bar := ""
-		for i := 0; i < msg.filledLength; i++ {
-			bar += "█" // Append filled segment.
+	var last *barMessage // The message shown, repainted on a resize.
+	var shown int        // The columns of the line shown.
+Printing:
+	for {
+		select {
+		case msg, ok := <-pb.printChannel:
+			if !ok {
+				break Printing
+			}
+
+			// In log mode, print one plain line for each refresh, without the color codes.
+			if plain {
+				pb.printMilestones()
+				line := ansiEscape.ReplaceAllString(pb.render(msg), "")
+				fmt.Fprintf(pb.writer, "%s %s\n", simhub.Now().In(pb.location).Format(time.DateTime), line)
+				continue
+			}
+
+			// Print the progress bar, starting from the beginning of the line.
+			line := pb.render(msg)
+			fmt.Fprintf(pb.writer, "\r%s", line)
+			last, shown = &msg, visibleLength(line)
+		case <-resized:
+			pb.columns.Store(int32(terminalWidth(pb.writer)))
+			if last != nil {
+				shown = pb.repaint(*last, shown)
+			}
 		}
-		for i := msg.filledLength; i < pb.barLength; i++ {
-			bar += "░" // Append unfilled segment.
+	}
+	if plain {
+		pb.printMilestones()
+	}
+
+	// Signal that the progress bar has finished by sending an empty struct.
+	pb.finishBar <- struct{}{}
+}
+
+// repaint ⛏️ draws the message again after the terminal was resized, and returns the columns of the new line.
+// The shown line of the given columns has wrapped onto several rows when the window got narrower, and \r only returns
+// to the last of them, so the cursor goes up to the first row and everything below it is cleared before the redraw.
+func (pb *ProgressBar) repaint(msg barMessage, shown int) int {
+	var b strings.Builder
+	if columns := int(pb.columns.Load()); columns > 0 && shown > columns {
+		fmt.Fprintf(&b, "\033[%dA", (shown-1)/columns)
+	}
+	line := pb.render(msg)
+	b.WriteString("\r\033[J")
+	b.WriteString(line)
+	fmt.Fprint(pb.writer, b.String())
+	return visibleLength(line)
+}
+
+// plainLines ⛏️ reports whether the progress is printed as plain lines instead of being redrawn in place.
+func (pb *ProgressBar) plainLines() bool {
+	return pb.logMode && !isTerminal(pb.writer)
+}
+
+// render ⛏️ formats a progress message into one line of the progress bar without a line break.
+func (pb *ProgressBar) render(msg barMessage) string {
+	// Format the percentage string using the specified precision and the decimal separator of the number format.
+	percentageStr := pb.numbers.Float(msg.percentage, pb.precision)
+
+	// The rate follows the percentage, when it is shown.
+	rate := ""
+	if pb.showRate {
+		rate = " " + pb.formatRate(msg.rate)
+	}
+
+	// Format the estimated time remaining, rounded to seconds.
+	etaStr := "--"
+	if msg.eta >= 0 {
+		etaStr = msg.eta.Round(time.Second).String()
+	}
+
+	// If a name is provided, include it in the output.
+	label := "Progress"
+	if pb.name != "" {
+		label = pb.name
+	}
+
+	// Pick the color of the highest threshold which the percentage has crossed.
+	barColor := pb.barColor
+	for _, threshold := range pb.thresholds {
+		if msg.percentage < threshold.percentage {
+			break
+		}
+		barColor = threshold.color
+	}
+
+	// An interrupted or failed bar is marked at the end of its line, so it is not mistaken for a finished one.
+	// A failed bar is also painted red, unless the colors are off.
+	status := ""
+	switch {
+	case msg.failure != nil:
+		status = " (failed: " + msg.failure.Error() + ")"
+		if pb.resetColor != "" {
+			barColor = BrightRed
+		}
+	case msg.interrupted:
+		status = " (interrupted)"
+	}
+
+	// The elapsed time follows the percentage right away, when it is shown.
+	elapsed := ""
+	if pb.showElapsed {
+		elapsed = " " + formatClock(msg.elapsed)
+	}
+
+	// The stage follows the percentage, separated by a space.
+	stage := ""
+	if msg.stage != "" {
+		stage = " " + msg.stage
+	}
+
+	// The bytes are worth showing even in the default layout, unlike the raw counts.
+	count := ""
+	if pb.units == Bytes {
+		count = " " + pb.formatCount(msg.done, msg.total)
+	}
+
+	// Without a total there is no percentage to show, so the bar only counts the steps, such as "Scan: 1,234 steps".
+	if msg.counter {
+		return pb.renderCounter(msg, label, barColor, elapsed+rate+stage, status)
+	}
+
+	// compose puts the bar and the other components into the layout.
+	compose := func(bar string) string {
+		if pb.template == "" {
+			// The default layout colors the bar and everything after it, and the ETA is optional.
+			eta := ""
+			if pb.showETA {
+				eta = " ETA " + etaStr
+			}
+			return fmt.Sprintf("%s: %s%s%s%s %s%%%s%s%s%s%s%s%s", label, barColor, pb.leftCap, bar, pb.rightCap, percentageStr, elapsed, rate, count, stage, eta, pb.resetColor, status)
+		}
+		return strings.NewReplacer(
+			"{name}", label,
+			"{bar}", barColor+pb.leftCap+bar+pb.rightCap+pb.resetColor,
+			"{percent}", percentageStr+"%",
+			"{eta}", "ETA "+etaStr,
+			"{rate}", pb.formatRate(msg.rate),
+			"{count}", pb.formatCount(msg.done, msg.total),
+			"{stage}", msg.stage,
+			"{elapsed}", formatClock(msg.elapsed),
+		).Replace(pb.template) + status
+	}
+
+	// Fit the bar into the terminal width, leaving the last column empty, so the line never wraps and \r still works.
+	// A bar of fixed length only shrinks, when the watched terminal is too narrow for it.
+	barLength, filledLength := pb.barLength, msg.filledLength
+	if pb.autoWidth {
+		if width := terminalWidth(pb.writer); width > 0 {
+			barLength = width - visibleLength(compose("")) - 1
+			if barLength < 1 {
+				barLength = 1
+			}
+			filledLength = int(msg.percentage / 100 * float64(barLength))
+		}
+	} else if width := int(pb.columns.Load()); width > 0 {
+		if fit := max(width-visibleLength(compose(""))-1, 1); fit < barLength {
+			barLength = fit
+			filledLength = int(msg.percentage / 100 * float64(barLength))
 		}
+	}
+
+	// Render the progress bar with color, along with the percentage.
+	return compose(pb.drawBar(filledLength, barLength, msg.percentage))
+}
+
+// renderCounter ⛏️ formats a progress message of a bar without a total, which has the count instead of the bar and the percentage.
+// The suffix is the elapsed time, the rate and the stage of the default layout.
+func (pb *ProgressBar) renderCounter(msg barMessage, label, barColor, suffix, status string) string {
+	count := pb.formatSteps(msg.done)
+	if pb.units == Counts {
+		count += " steps"
+	}
+	if pb.template == "" {
+		return fmt.Sprintf("%s: %s%s%s%s%s", label, barColor, count, suffix, pb.resetColor, status)
+	}
+	return strings.NewReplacer(
+		"{name}", label,
+		"{bar}", barColor+count+pb.resetColor,
+		"{percent}", "--%",
+		"{eta}", "ETA --",
+		"{rate}", pb.formatRate(msg.rate),
+		"{count}", count,
+		"{stage}", msg.stage,
+		"{elapsed}", formatClock(msg.elapsed),
+	).Replace(pb.template) + status
+}
+
+// formatClock ⛏️ writes a duration as hh:mm:ss, and the hours go on past 24, such as "26:03:10".
+func formatClock(d time.Duration) string {
+	seconds := int64(max(d, 0) / time.Second)
+	return fmt.Sprintf("%02d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
+
+// ansiEscape matches the ANSI color codes, which take no column on the terminal.
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// visibleLength ⛏️ counts the columns of a line without the color codes.
+func visibleLength(line string) int {
+	return utf8.RuneCountInString(ansiEscape.ReplaceAllString(line, ""))
+}
+
+// estimate ⛏️ calculates the time remaining from the throughput since the start.
+// It returns a negative duration when nothing has been done yet, because the throughput is still unknown.
+func (pb *ProgressBar) estimate(progress float64) time.Duration {
+	if progress <= 0 {
+		return -1
+	}
+	if progress >= 1 {
+		return 0
+	}
+
+	// Use the smoothed rate when there is one, since the cost of a step changes with the depth of the tree.
+	if pb.smoothedRate > 0 {
+		remaining := (1 - progress) * float64(atomic.LoadUint64(&pb.total))
+		return time.Duration(remaining / pb.smoothedRate * float64(time.Second))
+	}
+
+	// Assume the remaining work goes at the same rate as the work done so far. (按目前的速度推估)
+	elapsed := pb.activeElapsed(simhub.Now())
+	return time.Duration(float64(elapsed) * (1 - progress) / progress)
+}
+
+// throughput ⛏️ returns the smoothed steps per second, or the average so far before the first sample.
+// The mutex must be held by the caller.
+func (pb *ProgressBar) throughput() float64 {
+	if pb.smoothedRate > 0 {
+		return pb.smoothedRate
+	}
+	return pb.throughputAt(simhub.Now())
+}
+
+// sampleRate ⛏️ adds the rate since the last sample into the moving average, the mutex must be held by the caller.
+func (pb *ProgressBar) sampleRate(now time.Time, current uint64) {
+	if pb.smoothing <= 0 {
+		return
+	}
+	elapsed := now.Sub(pb.sampleAt).Seconds()
+	if elapsed <= 0 {
+		return
+	}
 
-		// Print the progress bar with color, along with the percentage.
-		if pb.name != "" {
-			// If a name is provided, include it in the output.
-			fmt.Printf("\r%s: %s[%s] %s%%%s", pb.name, pb.barColor, bar, percentageStr, pb.resetColor)
+	// The first sample starts the average, and a rollback by Sub only moves the baseline.
+	if current >= pb.sampleDone {
+		rate := float64(current-pb.sampleDone) / elapsed
+		if pb.smoothedRate == 0 {
+			pb.smoothedRate = rate
 		} else {
-			// Default output if no name is provided.
-			fmt.Printf("\rProgress: %s[%s] %s%%%s", pb.barColor, bar, percentageStr, pb.resetColor)
+			pb.smoothedRate = pb.smoothing*rate + (1-pb.smoothing)*pb.smoothedRate
 		}
 	}
+	pb.sampleAt, pb.sampleDone = now, current
+}
 
-	// Signal that the progress bar has finished by sending an empty struct.
-	pb.finishBar <- struct{}{}
+// throughputAt ⛏️ calculates the steps done per second until the given time, the mutex must be held by the caller.
+func (pb *ProgressBar) throughputAt(now time.Time) float64 {
+	elapsed := pb.activeElapsed(now)
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(atomic.LoadUint64(&pb.currentProcess)) / elapsed.Seconds()
 }
 
 // WaitForPrinterStop ⛏️ waits for the printer to stop and returns a channel to signal completion.
@@ -192,8 +802,10 @@ func (pb *ProgressBar) WaitForPrinterStop() chan struct{} {
 		<-pb.finishBar
 		close(pb.finishBar)
 
-		// Print a newline to signify that the progress bar is complete.
-		fmt.Printf("\n")
+		// Print a newline to signify that the progress bar is complete, the plain lines have ended already.
+		if !pb.plainLines() {
+			fmt.Fprintf(pb.writer, "\n")
+		}
 
 		// Signal that the printing has finished.
 		close(finish)
@@ -202,28 +814,70 @@ func (pb *ProgressBar) WaitForPrinterStop() chan struct{} {
 	return finish // Return the channel for external use.
 }
 
+// UpdateBar ⛏️ adds one step to the progress bar.
 func (pb *ProgressBar) UpdateBar() {
-	// Return immediately if the progress has already reached completion.
-	if atomic.LoadUint32(&pb.currentProcess) == pb.total {
+	pb.advance(1)
+}
+
+// advance ⛏️ adds the steps with a single atomic add, which is the hot path of tight loops.
+// The mutex is only taken when a refresh is due and the bar has really moved, at most once every update interval.
+func (pb *ProgressBar) advance(steps uint64) {
+	// An interrupted or failed bar stays where it stopped.
+	if pb.stopped.Load() {
 		return
 	}
 
-	// If the current process exceeds the total value, cap it to the total.
-	if atomic.LoadUint32(&pb.currentProcess) > pb.total {
-		atomic.StoreUint32(&pb.currentProcess, pb.total) // Limit currentProcess to total.
-		return
+	total := atomic.LoadUint64(&pb.total)
+	current := atomic.AddUint64(&pb.currentProcess, steps)
+
+	// Give back the steps over the total, so the progress never passes it. A zero total only counts the steps.
+	if total > 0 && current > total {
+		over := min(current-total, steps)
+		atomic.AddUint64(&pb.currentProcess, ^(over - 1)) // Subtract over.
+		current -= over
+		steps -= over
+		if steps == 0 {
+			return
+		}
 	}
 
-	// Lock the mutex to ensure thread safety during updates.
+	// Move the parent bar by the steps which are really added, and call the update and the milestone hooks.
+	pb.advanceParent(steps)
+	pb.notifyUpdate()
+	pb.crossMilestones(current, total)
+
+	// Refresh the bar only when it is due and the filled length has changed, or the count has when there is no total.
+	if pb.due() && (total == 0 || pb.filledLength(current, total) != atomic.LoadInt64(&pb.lastFilledLength)) {
+		pb.refresh()
+	}
+}
+
+// filledLength ⛏️ calculates how many units of the bar are filled, with integers only.
+func (pb *ProgressBar) filledLength(current, total uint64) int64 {
+	if total == 0 {
+		return 0
+	}
+	return int64(current * uint64(pb.barLength) / total)
+}
+
+// refresh ⛏️ sends the current progress to the printer, once for every tick taken.
+func (pb *ProgressBar) refresh() {
 	pb.mu.Lock()
+	defer pb.mu.Unlock()
 
-	// Increment the current process by one step.
-	atomic.AddUint32(&pb.currentProcess, 1)
-	// pb.currentProcess++
+	// Another update may have taken this refresh already, and nothing is sent while paused or after completion.
+	if pb.paused || pb.complete || !pb.refreshDue.CompareAndSwap(true, false) {
+		return
+	}
 
-	// Calculate the current progress percentage.
-	progress := float64(pb.currentProcess) / float64(pb.total)
-	filledLength := int(progress * float64(pb.barLength))
+	// Calculate the current progress percentage, which stays at 0 without a total.
+	current, total := atomic.LoadUint64(&pb.currentProcess), atomic.LoadUint64(&pb.total)
+	progress := 0.0
+	if total > 0 {
+		progress = float64(current) / float64(total)
+	}
+	filledLength := pb.filledLength(current, total)
+	pb.sampleRate(simhub.Now(), current)
 
 	// Format the progress percentage, ensuring it does not exceed 100%.
 	percentage := progress * 100
@@ -231,116 +885,461 @@ func (pb *ProgressBar) UpdateBar() {
 		percentage = 100 // Cap percentage at 100.
 	}
 
-	// Update the progress bar if the filled length has changed.
-	if filledLength != pb.lastFilledLength {
-	LOOP:
-		select {
-		case <-pb.ticker:
-			// Send the progress update to the print channel.
-			pb.printChannel <- barMessage{filledLength, percentage}
+	// Send the progress update to the print channel.
+	pb.send(barMessage{filledLength: int(filledLength), percentage: percentage, eta: pb.estimate(progress), rate: pb.throughput(), done: current, total: total, stage: pb.stage, counter: total == 0, elapsed: pb.activeElapsed(simhub.Now())})
 
-			// Update the last filled length to prevent redundant updates.
-			pb.lastFilledLength = filledLength
+	// Update the last filled length to prevent redundant updates.
+	atomic.StoreInt64(&pb.lastFilledLength, filledLength)
+}
 
-			// Reset the ticker for the next update interval.
-			pb.ticker = time.After(time.Duration(pb.updateInterval) * time.Millisecond)
+// send ⛏️ puts the message into the print channel without blocking, and drops the older message still waiting in it.
+// Only the latest message matters to the printer, so dropping the older one loses nothing shown.
+// The senders are serialized by the mutex, or by the completion, so the loop ends after at most one drop.
+func (pb *ProgressBar) send(msg barMessage) {
+	for {
+		select {
+		case pb.printChannel <- msg:
+			return
 		default:
-			// Exit the loop if no ticker event occurs.
-			break LOOP
 		}
+
+		// The printer is behind, so drop the oldest message, unless the printer has just taken it.
+		select {
+		case <-pb.printChannel:
+		default:
+		}
+	}
+}
+
+// due ⛏️ reports whether a refresh is due, and takes the tick waiting in the ticker without blocking.
+// No goroutine is needed for the ticks, so a bar which is never completed holds nothing but its ticker.
+func (pb *ProgressBar) due() bool {
+	if pb.refreshDue.Load() {
+		return true
+	}
+	if pb.ticker == nil {
+		return false
 	}
+	select {
+	case <-pb.ticker.C:
+		pb.refreshDue.Store(true)
+		return true
+	default:
+		return false
+	}
+}
 
-	// Unlock the mutex after completing the update.
-	pb.mu.Unlock()
+// startRefresh ⛏️ restarts the ticker, so the next refresh is due a full update interval later.
+func (pb *ProgressBar) startRefresh() {
+	if pb.ticker != nil {
+		pb.ticker.Reset(time.Duration(pb.updateInterval) * time.Millisecond)
+	}
+}
 
-	// If progress is complete, stop the ticker.
-	if atomic.LoadUint32(&pb.currentProcess) == pb.total {
-		// Set ticker to nil to indicate completion.
-		// pb.ticker = nil // Originally could be written this way, but to prevent bugs, we change it to atomic.
-		atomic.StorePointer((*unsafe.Pointer)(unsafe.Pointer(&pb.ticker)), nil)
+// stopRefresh ⛏️ stops the ticker, so no refresh is due until it is started again.
+// A stopped ticker sends no more ticks, not even one which was already waiting, so nothing is due afterwards.
+func (pb *ProgressBar) stopRefresh() {
+	if pb.ticker != nil {
+		pb.ticker.Stop()
 	}
+	pb.refreshDue.Store(false)
 }
 
-// Complete ⛏️ marks the progress bar as complete.
-func (pb *ProgressBar) Complete() {
-	// Check if the progress bar is already complete.
-	if pb.complete == false {
-		// Ensure the current progress is less than or equal to the total.
-		if atomic.LoadUint32(&pb.currentProcess) <= pb.total {
-			// Set the end time to the current time in the specified location.
-			pb.endTime = time.Now().In(pb.location)
-
-			// Set the current process to the total to mark it as fully completed.
-			atomic.StoreUint32(&pb.currentProcess, pb.total)
-
-			// Send a final update to the print channel, indicating completion.
-			pb.printChannel <- barMessage{pb.barLength, 100.0}
-
-			// Mark the progress bar as complete.
-			pb.complete = true
+// advanceParent ⛏️ moves the parent bar of a progress group by the steps this bar has moved.
+func (pb *ProgressBar) advanceParent(steps uint64) {
+	if pb.parent != nil && steps > 0 {
+		pb.parent.AddSpecificTimes(steps)
+	}
+}
+
+// notifyUpdate ⛏️ calls the update hook with the current progress.
+func (pb *ProgressBar) notifyUpdate() {
+	if pb.onUpdate != nil {
+		pb.onUpdate(atomic.LoadUint64(&pb.currentProcess), atomic.LoadUint64(&pb.total))
+	}
+}
+
+// crossMilestones ⛏️ reports the milestones up to the progress, each once, even when the updates race.
+// Without milestones, it costs a single length check on the hot path.
+func (pb *ProgressBar) crossMilestones(current, total uint64) {
+	if len(pb.milestones) == 0 || total == 0 {
+		return
+	}
+	for {
+		next := pb.nextMilestone.Load()
+		if int(next) >= len(pb.milestones) || float64(current)*100 < pb.milestones[next]*float64(total) {
+			return
 		}
+		// The update which moves the index reports the milestone, the others try the next one.
+		if !pb.nextMilestone.CompareAndSwap(next, next+1) {
+			continue
+		}
+		percent := pb.milestones[next]
+		if pb.plainLines() {
+			line := fmt.Sprintf("%s %s: milestone %s%% reached, %s", simhub.Now().In(pb.location).Format(time.DateTime), pb.name, pb.numbers.Float(percent, pb.precision), pb.formatCount(current, total))
+			pb.milestoneMu.Lock()
+			pb.milestoneLines = append(pb.milestoneLines, line)
+			pb.milestoneMu.Unlock()
+		}
+		if pb.onMilestone != nil {
+			pb.onMilestone(percent, current, total)
+		}
+	}
+}
 
-		// Set the ticker to nil as no further updates are required.
-		pb.ticker = nil
+// printMilestones ⛏️ prints the milestone lines waiting for the printer, so they are written by the printer only,
+// before the refresh which follows them.
+func (pb *ProgressBar) printMilestones() {
+	pb.milestoneMu.Lock()
+	lines := pb.milestoneLines
+	pb.milestoneLines = nil
+	pb.milestoneMu.Unlock()
+	for _, line := range lines {
+		fmt.Fprintln(pb.writer, line)
+	}
+}
+
+// Name ⛏️ returns the name of the progress bar.
+func (pb *ProgressBar) Name() string {
+	return pb.name
+}
+
+// Snapshot ⛏️ returns the progress so far, such as for a metrics exporter polling a running bar.
+// While the bar is running, EndTime is zero and the elapsed time and the rate are counted until now.
+func (pb *ProgressBar) Snapshot() ProgressReport {
+	return pb.report()
+}
 
-		// Close the print channel since no more messages will be sent, allowing the listener to terminate.
-		close(pb.printChannel)
+// report ⛏️ collects the summary of the progress bar.
+func (pb *ProgressBar) report() ProgressReport {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	// A running bar is measured until now.
+	end := pb.endTime
+	if end.IsZero() {
+		end = simhub.Now()
+	}
+
+	return ProgressReport{
+		Name:        pb.name,
+		StartTime:   pb.startTime,
+		EndTime:     pb.endTime,
+		Elapsed:     pb.activeElapsed(end),
+		Paused:      pb.pausedTime,
+		Total:       atomic.LoadUint64(&pb.total),
+		Completed:   atomic.LoadUint64(&pb.currentProcess),
+		Rate:        pb.throughputAt(end),
+		Smoothed:    pb.smoothedRate,
+		Stage:       pb.stage,
+		Phases:      append([]PhaseStat(nil), pb.phases...),
+		Slowest:     pb.slowOps.Slowest(),
+		Memory:      append([]MemoryRelease(nil), pb.memory...),
+		Interrupted: pb.interrupted,
+		Failure:     pb.failure,
 	}
 }
 
-// AddSpecificTimes ⛏️ adds the progress bar by a specific times.
-func (pb *ProgressBar) AddSpecificTimes(steps uint32) {
-	// Return immediately if the progress has already reached completion.
-	if atomic.LoadUint32(&pb.currentProcess) >= pb.total {
-		atomic.StoreUint32(&pb.currentProcess, pb.total) // Limit currentProcess to total.
+// Checkpoint ⛏️ adds the steps done in a phase and the time they took, so the report shows the throughput of each phase.
+// The aggregate elapsed time hides the asymmetry between phases, such as fast inserts and slow deletes.
+// It does not move the bar, which is still moved by UpdateBar and AddSpecificTimes.
+func (pb *ProgressBar) Checkpoint(phase string, steps uint64, elapsed time.Duration) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	for i := range pb.phases {
+		if pb.phases[i].Name == phase {
+			pb.phases[i].Steps += steps
+			pb.phases[i].Elapsed += elapsed
+			return
+		}
+	}
+	pb.phases = append(pb.phases, PhaseStat{Name: phase, Steps: steps, Elapsed: elapsed})
+}
+
+// formatSteps ⛏️ writes a number of steps in the units of the progress bar.
+func (pb *ProgressBar) formatSteps(n uint64) string {
+	if pb.units == Bytes {
+		return pb.numbers.Bytes(n)
+	}
+	return pb.numbers.Uint(n)
+}
+
+// formatCount ⛏️ writes the done and the total steps, such as "1,250/12,500" or "1.2 GiB / 4.0 GiB".
+func (pb *ProgressBar) formatCount(done, total uint64) string {
+	if pb.units == Bytes {
+		return pb.formatSteps(done) + " / " + pb.formatSteps(total)
+	}
+	return pb.formatSteps(done) + "/" + pb.formatSteps(total)
+}
+
+// formatRate ⛏️ writes the steps per second in the units of the progress bar.
+func (pb *ProgressBar) formatRate(rate float64) string {
+	if pb.units == Bytes {
+		return pb.numbers.Bytes(uint64(max(rate, 0))) + "/s"
+	}
+	return pb.numbers.Float(rate, 1) + "/s"
+}
+
+// AddMemoryRelease ⛏️ adds the memory released at a phase boundary, so the report shows how much the release has freed.
+func (pb *ProgressBar) AddMemoryRelease(release MemoryRelease) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	pb.memory = append(pb.memory, release)
+}
+
+// truncateField ⛏️ cuts a field name to the width of the field column, so the table stays aligned.
+func truncateField(field string, width int) string {
+	if utf8.RuneCountInString(field) <= width {
+		return field
+	}
+	return string([]rune(field)[:width])
+}
+
+// SetTotal ⛏️ changes the total while the progress bar is running, so the work found later can be added.
+// The percentage is recalculated on the next refresh, and the progress is capped when the total shrinks below it.
+func (pb *ProgressBar) SetTotal(total uint64) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	// The total of a completed progress bar can not be changed.
+	if pb.complete {
 		return
 	}
+	previous := atomic.SwapUint64(&pb.total, total)
+
+	// The total of the parent bar is the sum of its children, so it changes by the same amount.
+	if pb.parent != nil {
+		pb.parent.SetTotal(atomic.LoadUint64(&pb.parent.total) + total - previous)
+	}
+	if total > 0 && atomic.LoadUint64(&pb.currentProcess) > total {
+		atomic.StoreUint64(&pb.currentProcess, total)
+	}
+
+	// Force the next refresh, even if the filled length stays the same.
+	atomic.StoreInt64(&pb.lastFilledLength, -1)
+}
 
-	// Lock the mutex to ensure thread safety during updates.
+// SetStage ⛏️ sets a short status message shown after the percentage, such as "rebalancing leaves", empty removes it.
+// It is shown on the next refresh, and at once when a refresh is due, so changing it often costs no more output.
+// The completed bar drops it, while an interrupted or failed bar keeps it, so the line shows where the run stopped.
+func (pb *ProgressBar) SetStage(stage string) {
 	pb.mu.Lock()
+	if pb.complete || pb.stage == stage {
+		pb.mu.Unlock()
+		return
+	}
+	pb.stage = stage
 
-	// Adding the progress by a specific steps.
-	atomic.AddUint32(&pb.currentProcess, steps)
-	// pb.currentProcess += steps
+	// Force the next refresh, even if the filled length stays the same.
+	atomic.StoreInt64(&pb.lastFilledLength, -1)
+	pb.mu.Unlock()
+
+	// A stage without any update, such as a verification, is still shown when the refresh is due already.
+	if pb.due() {
+		pb.refresh()
+	}
+}
 
-	// Calculate the current progress percentage.
-	// progress := float64(pb.currentProcess) / float64(pb.total)
-	progress := float64(pb.currentProcess / pb.total)
-	filledLength := int(progress * float64(pb.barLength))
+// Pause ⛏️ stops refreshing the progress bar, and the time until Resume is excluded from the elapsed time.
+// The progress can still be updated while paused, it is shown again after Resume.
+func (pb *ProgressBar) Pause() {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
 
-	// Format the progress percentage, ensuring it does not exceed 100%.
-	percentage := progress * 100
-	if percentage > 100 {
-		percentage = 100 // Cap percentage at 100.
+	// Pausing twice or after completion does nothing.
+	if pb.paused || pb.complete {
+		return
 	}
+	pb.paused = true
+	pb.pausedAt = simhub.Now()
 
-	// Update the progress bar if the filled length has changed.
-	if filledLength != pb.lastFilledLength {
-	LOOP:
-		select {
-		case <-pb.ticker:
-			// Send the progress update to the print channel.
-			pb.printChannel <- barMessage{filledLength, percentage}
+	// Stop the ticker, so no message is sent while paused.
+	pb.stopRefresh()
+}
 
-			// Update the last filled length to prevent redundant updates.
-			pb.lastFilledLength = filledLength
+// Resume ⛏️ restarts refreshing the progress bar after Pause.
+func (pb *ProgressBar) Resume() {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
 
-			// Reset the ticker for the next update interval.
-			pb.ticker = time.After(time.Duration(pb.updateInterval) * time.Millisecond)
-		default:
-			// Exit the loop if no ticker event occurs.
-			break LOOP
+	// Only a paused progress bar can be resumed.
+	if !pb.paused {
+		return
+	}
+	pb.paused = false
+	paused := simhub.Since(pb.pausedAt)
+	pb.pausedTime += paused
+	pb.sampleAt = pb.sampleAt.Add(paused) // The paused time is not a sample.
+
+	// Restart the ticker for the next update interval.
+	pb.startRefresh()
+}
+
+// activeElapsed ⛏️ returns the time since the start without the paused time, the mutex must be held by the caller.
+func (pb *ProgressBar) activeElapsed(now time.Time) time.Duration {
+	elapsed := now.Sub(pb.startTime) - pb.pausedTime
+	if pb.paused {
+		elapsed -= now.Sub(pb.pausedAt)
+	}
+	return elapsed
+}
+
+// ErrBarFinished ⛏️ is returned by Complete when the progress bar is already completed, interrupted or failed.
+var ErrBarFinished = errors.New("progress bar is already finished")
+
+// Complete ⛏️ marks the progress bar as complete.
+// It never blocks, even before the printer is started, since the printer only needs the latest message.
+// A second call changes nothing and returns ErrBarFinished.
+func (pb *ProgressBar) Complete() error {
+	return pb.finish(false, nil)
+}
+
+// Interrupt ⛏️ ends the progress bar where it is, such as when the run is stopped by a signal.
+// The bar is not filled up, its line is marked as interrupted, and the report shows the interrupted status.
+func (pb *ProgressBar) Interrupt() {
+	_ = pb.finish(true, nil)
+}
+
+// Fail ⛏️ ends the progress bar where it is because of the error, such as a failed test.
+// The bar is painted red with the error at the end of its line, and the report shows a "Failed" status with the error.
+func (pb *ProgressBar) Fail(err error) {
+	if err == nil {
+		err = errors.New("unknown error")
+	}
+	_ = pb.finish(false, err)
+}
+
+// Close ⛏️ tears the progress bar down, so nothing of it is left running, even when it was never completed,
+// such as when a test stops early on a failed require. It is meant to be deferred right after the bar is created.
+// An unfinished bar is ended like Interrupt, its ticker is stopped, and Close waits for the printer started by Start or ListenPrinter to return.
+// Closing a finished bar changes nothing, and so does a second call.
+func (pb *ProgressBar) Close() error {
+	pb.closeOnce.Do(func() {
+		_ = pb.finish(true, nil)
+		pb.printers.Wait()
+	})
+	return nil
+}
+
+// finish ⛏️ ends the progress bar once, either completed, interrupted or failed, and closes the print channel.
+// It returns ErrBarFinished when the progress bar is already finished.
+func (pb *ProgressBar) finish(interrupted bool, failure error) error {
+	// Check if the progress bar is already finished, under the mutex so Complete, Interrupt and Fail can race safely.
+	pb.mu.Lock()
+	if pb.complete {
+		pb.mu.Unlock()
+		return ErrBarFinished
+	}
+
+	// Set the end time to the current time in the specified location.
+	pb.endTime = simhub.Now().In(pb.location)
+
+	// A pause still going on ends here.
+	if pb.paused {
+		pb.paused = false
+		pb.pausedTime += pb.endTime.Sub(pb.pausedAt)
+	}
+
+	// Prepare the final update. A completed bar jumps to the total, and an interrupted or failed bar stays where it is.
+	total, current := atomic.LoadUint64(&pb.total), atomic.LoadUint64(&pb.currentProcess)
+	elapsed := pb.activeElapsed(pb.endTime)
+	var final *barMessage
+	var remaining uint64
+	switch {
+	case interrupted || failure != nil:
+		percentage := 0.0
+		if total > 0 {
+			percentage = min(float64(current)/float64(total)*100, 100)
+		}
+		final = &barMessage{filledLength: int(pb.filledLength(min(current, total), total)), percentage: percentage, eta: -1, rate: pb.throughput(), done: current, total: total, interrupted: interrupted, failure: failure, stage: pb.stage, counter: total == 0, elapsed: elapsed}
+	case total == 0:
+		// A counter-only bar ends with the steps it has counted.
+		final = &barMessage{rate: pb.throughput(), done: current, counter: true, elapsed: elapsed}
+	case current <= total:
+		// Set the current process to the total to mark it as fully completed.
+		if before := atomic.SwapUint64(&pb.currentProcess, total); before < total {
+			remaining = total - before
 		}
+		final = &barMessage{filledLength: pb.barLength, percentage: 100.0, rate: pb.throughput(), done: total, total: total, elapsed: elapsed}
 	}
 
-	// Unlock the mutex after completing the update.
+	// Mark the progress bar as finished under the mutex, so no refresh sends after the final update.
+	pb.complete = true
+	pb.interrupted = interrupted
+	pb.failure = failure
+	pb.stopped.Store(interrupted || failure != nil)
+	pb.stopRefresh()
 	pb.mu.Unlock()
 
-	// If progress is complete, stop the ticker.
-	if atomic.LoadUint32(&pb.currentProcess) == pb.total {
-		// Set ticker to nil to indicate completion.
-		// pb.ticker = nil // Originally could be written this way, but to prevent bugs, we change it to atomic.
-		atomic.StorePointer((*unsafe.Pointer)(unsafe.Pointer(&pb.ticker)), nil)
+	if final != nil {
+		// A completed bar crosses the milestones left, before the final update, so their lines are printed before it.
+		if !interrupted && failure == nil {
+			pb.crossMilestones(final.done, total)
+		}
+
+		// Send a final update to the print channel, it replaces any message the printer has not taken yet.
+		pb.send(*final)
+
+		// The remaining steps of this bar are done, so the parent bar moves by them.
+		pb.advanceParent(remaining)
+
+		// Call the hooks with the final progress.
+		pb.notifyUpdate()
+		if pb.onComplete != nil {
+			pb.onComplete(pb.report())
+		}
+	}
+
+	// Close the print channel since no more messages will be sent, allowing the listener to terminate.
+	close(pb.printChannel)
+	return nil
+}
+
+// AddSpecificTimes ⛏️ adds the progress bar by a specific times.
+func (pb *ProgressBar) AddSpecificTimes(steps uint64) {
+	pb.advance(steps)
+}
+
+// Sub ⛏️ takes steps back from the progress bar, such as when a failed batch is retried, and stops at zero.
+// The parent bar moves back by the same steps, and a shorter bar is shown at once.
+func (pb *ProgressBar) Sub(steps uint64) {
+	pb.mu.Lock()
+
+	// The progress of a completed progress bar can not be changed.
+	if pb.complete {
+		pb.mu.Unlock()
+		return
+	}
+
+	// Subtract with compare-and-swap, so the progress is clamped at zero even when the updates race.
+	var current, removed uint64
+	for {
+		before := atomic.LoadUint64(&pb.currentProcess)
+		removed = min(steps, before)
+		current = before - removed
+		if removed == 0 || atomic.CompareAndSwapUint64(&pb.currentProcess, before, current) {
+			break
+		}
+	}
+
+	// Show a shorter bar without waiting for the ticker, rollbacks are rare enough.
+	shorter := pb.filledLength(current, atomic.LoadUint64(&pb.total)) != atomic.LoadInt64(&pb.lastFilledLength)
+	if !pb.paused && removed > 0 && shorter {
+		pb.refreshDue.Store(true)
+	}
+	pb.mu.Unlock()
+	if removed == 0 {
+		return
+	}
+
+	// Move the parent bar back, and call the update hook.
+	if pb.parent != nil {
+		pb.parent.Sub(removed)
+	}
+	pb.notifyUpdate()
+	if shorter {
+		pb.refresh()
 	}
 }
 
@@ -352,8 +1351,8 @@ func (pb *ProgressBar) Report(valueWidth int) error {
 		return errors.New("progress is not yet complete")
 	}
 
-	// Calculate the total time that has elapsed between the start and the end.
-	elapsed := pb.endTime.Sub(pb.startTime)
+	// Collect the summary, the elapsed time is without the paused time.
+	report := pb.report()
 
 	// Define fixed widths for the table's fields and values to ensure proper alignment.
 	fieldWidth := 20
@@ -362,31 +1361,71 @@ func (pb *ProgressBar) Report(valueWidth int) error {
 	}
 	totalWidth := fieldWidth + valueWidth + 7
 	// Create a border for the table using a repeated pattern for visual clarity.
-	border := BrightYellow + strings.Repeat("=", totalWidth) + Reset
-	divider := BrightYellow + strings.Repeat("-", totalWidth) + Reset
+	palette, reset := pb.palette, pb.resetColor
+	border := palette.Border + strings.Repeat("=", totalWidth) + reset
+	divider := palette.Border + strings.Repeat("-", totalWidth) + reset
 
 	// Print the report title centered within the table, using padding to adjust its position.
 	title := "Progress Bar Report"
 	titleWidth := len(title)
 	padding := (totalWidth - titleWidth) / 2
-	fmt.Println(BrightMagenta + border + Reset)
-	fmt.Printf("%s|%s%s%s|%s\n", BrightMagenta, strings.Repeat(" ", padding), title, strings.Repeat(" ", padding-1), Reset)
-	fmt.Println(BrightMagenta + border + Reset)
+	fmt.Fprintln(pb.writer, border)
+	fmt.Fprintf(pb.writer, "%s|%s%s%s|%s\n", palette.Title, strings.Repeat(" ", padding), title, strings.Repeat(" ", padding-1), reset)
+	fmt.Fprintln(pb.writer, border)
 
 	// Print the table header, highlighting the column titles for "Field" and "Value".
-	fmt.Printf("%s| %-*s | %-*s |%s\n", BrightRed, fieldWidth, "Field", valueWidth, "Value", Reset)
-	fmt.Println(BrightRed + divider + Reset)
+	fmt.Fprintf(pb.writer, "%s| %-*s | %-*s |%s\n", palette.Header, fieldWidth, "Field", valueWidth, "Value", reset)
+	fmt.Fprintln(pb.writer, divider)
 
 	// Print each row of the table with the task's details, formatted to align fields and values.
-	fmt.Printf("%s| %-*s | %-*s |%s\n", DarkYellow, fieldWidth, "Task Name", valueWidth, pb.name, Reset) // %-*s ensures left alignment.
-	fmt.Printf("%s| %-*s | %-*s |%s\n", DarkYellow, fieldWidth, "Start Time", valueWidth, pb.startTime.Format(time.RFC1123), Reset)
-	fmt.Printf("%s| %-*s | %-*s |%s\n", DarkYellow, fieldWidth, "End Time", valueWidth, pb.endTime.Format(time.RFC1123), Reset)
-	fmt.Printf("%s| %-*s | %-*s |%s\n", DarkYellow, fieldWidth, "Elapsed Time", valueWidth, elapsed.String(), Reset)
-	fmt.Printf("%s| %-*s | %-*d |%s\n", DarkYellow, fieldWidth, "Total Tasks", valueWidth, pb.total, Reset)
-	fmt.Printf("%s| %-*s | %-*d |%s\n", DarkYellow, fieldWidth, "Completed Tasks", valueWidth, pb.currentProcess, Reset)
+	fmt.Fprintf(pb.writer, "%s| %-*s | %-*s |%s\n", palette.Row, fieldWidth, "Task Name", valueWidth, report.Name, reset) // %-*s ensures left alignment.
+	fmt.Fprintf(pb.writer, "%s| %-*s | %-*s |%s\n", palette.Row, fieldWidth, "Start Time", valueWidth, report.StartTime.Format(time.RFC1123), reset)
+	fmt.Fprintf(pb.writer, "%s| %-*s | %-*s |%s\n", palette.Row, fieldWidth, "End Time", valueWidth, report.EndTime.Format(time.RFC1123), reset)
+	fmt.Fprintf(pb.writer, "%s| %-*s | %-*s |%s\n", palette.Row, fieldWidth, "Elapsed Time", valueWidth, report.Elapsed.String(), reset)
+	fmt.Fprintf(pb.writer, "%s| %-*s | %-*s |%s\n", palette.Row, fieldWidth, "Paused Time", valueWidth, report.Paused.String(), reset)
+	fmt.Fprintf(pb.writer, "%s| %-*s | %-*s |%s\n", palette.Row, fieldWidth, "Total Tasks", valueWidth, pb.formatSteps(report.Total), reset)
+	fmt.Fprintf(pb.writer, "%s| %-*s | %-*s |%s\n", palette.Row, fieldWidth, "Completed Tasks", valueWidth, pb.formatSteps(report.Completed), reset)
+
+	// The rate is the moving average of the latest refreshes, or the average when no refresh has been sampled.
+	rate := report.Smoothed
+	if rate == 0 {
+		rate = report.Rate
+	}
+	fmt.Fprintf(pb.writer, "%s| %-*s | %-*s |%s\n", palette.Row, fieldWidth, "Rate", valueWidth, pb.formatRate(rate), reset)
+	switch {
+	case report.Failure != nil:
+		fmt.Fprintf(pb.writer, "%s| %-*s | %-*s |%s\n", palette.Row, fieldWidth, "Status", valueWidth, "Failed: "+report.Failure.Error(), reset)
+	case report.Interrupted:
+		fmt.Fprintf(pb.writer, "%s| %-*s | %-*s |%s\n", palette.Row, fieldWidth, "Status", valueWidth, "interrupted", reset)
+	}
+
+	// Print the throughput of every phase, when the phases are fed by Checkpoint.
+	if len(report.Phases) > 0 {
+		fmt.Fprintln(pb.writer, divider)
+		for _, phase := range report.Phases {
+			value := fmt.Sprintf("%s ops/s (%s in %s)", pb.numbers.Float(phase.Rate(), 1), pb.numbers.Uint(phase.Steps), phase.Elapsed.Round(time.Millisecond))
+			fmt.Fprintf(pb.writer, "%s| %-*s | %-*s |%s\n", palette.Row, fieldWidth, truncateField(phase.Name+" Rate", fieldWidth), valueWidth, value, reset)
+		}
+	}
+
+	// Print the memory released at the phase boundaries.
+	if len(report.Memory) > 0 {
+		fmt.Fprintln(pb.writer, divider)
+		for _, release := range report.Memory {
+			fmt.Fprintf(pb.writer, "%s| %-*s | %-*s |%s\n", palette.Row, fieldWidth, truncateField("GC "+release.Phase, fieldWidth), valueWidth, release.format(pb.numbers), reset)
+		}
+	}
+
+	// Print the slowest operations, when the operations are timed.
+	if len(report.Slowest) > 0 {
+		fmt.Fprintln(pb.writer, divider)
+		for i, op := range report.Slowest {
+			fmt.Fprintf(pb.writer, "%s| %-*s | %-*s |%s\n", palette.Row, fieldWidth, truncateField(fmt.Sprintf("Slowest #%d", i+1), fieldWidth), valueWidth, op.String(), reset)
+		}
+	}
 
 	// Print a closing border to signal the end of the report.
-	fmt.Println(BrightMagenta + border + Reset)
+	fmt.Fprintln(pb.writer, border)
 
 	return nil
 }
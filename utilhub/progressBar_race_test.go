@@ -26,7 +26,7 @@ func Test_Race_ProgressBar(t *testing.T) {
 		var collected []testBarMessage
 
 		// Set total steps and progress bar length.
-		var totalSteps uint32 = 10000
+		var totalSteps uint64 = 10000
 		barLength := 50
 
 		// Create a new progress bar.
@@ -79,15 +79,15 @@ func Test_Race_ProgressBar(t *testing.T) {
 	t.Run("ListenPrinter", func(t *testing.T) {
 
 		// Set total steps and progress bar length.
-		var totalSteps uint32 = 10000000
+		var totalSteps uint64 = 10000000
 		barLength := 50
 
 		// Create a new progress bar.
 		progressBar, err := NewProgressBar("Test Progress", totalSteps, barLength)
 		assert.NoError(t, err)
 
-		// Run the ListenPrinter function concurrently to collect progress bar messages.
-		go progressBar.ListenPrinter()
+		// Start the printer in its own goroutine to collect progress bar messages.
+		progressBar.Start()
 
 		// Use WaitGroup to wait for all goroutines to complete.
 		var wg sync.WaitGroup
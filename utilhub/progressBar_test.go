@@ -1,10 +1,19 @@
 package utilhub
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"github.com/panhongrainbow/go-algorithm/simhub"
 	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
+	"unicode/utf8"
 )
 
 // testBarMessage holds the details of each progress bar message.
@@ -117,8 +126,11 @@ func Test_ProcessBar(t *testing.T) {
 		)
 
 		// Run the ListPrint function concurrently to collect progress bar messages.
+		// The printer stops before ListPrint returns, so wait for the collected messages too.
+		listed := make(chan struct{})
 		go func() {
 			collected = progressBar.ListPrint(T{})
+			close(listed)
 		}()
 
 		// Simulate progress by updating the bar 10 times, with a 150-millisecond pause between updates.
@@ -132,6 +144,7 @@ func Test_ProcessBar(t *testing.T) {
 
 		// Wait for the progress bar's printer to stop.
 		<-progressBar.WaitForPrinterStop()
+		<-listed
 
 		// Validate the collected messages using the validateCollectedMessages function.
 		errs := validateCollectedMessages(T{}, collected, 15) // 15 倍的人类眼速
@@ -157,8 +170,11 @@ func Test_ProcessBar(t *testing.T) {
 		)
 
 		// Run the ListPrint function concurrently to collect progress bar messages.
+		// The printer stops before ListPrint returns, so wait for the collected messages too.
+		listed := make(chan struct{})
 		go func() {
 			collected = progressBar.ListPrint(T{})
+			close(listed)
 		}()
 
 		// Simulate progress by updating the bar 10 times, with a 150-millisecond pause between updates.
@@ -172,6 +188,7 @@ func Test_ProcessBar(t *testing.T) {
 
 		// Wait for the progress bar's printer to stop.
 		<-progressBar.WaitForPrinterStop()
+		<-listed
 
 		// Validate the collected messages using the validateCollectedMessages function.
 		errs := validateCollectedMessages(T{}, collected, 200) // 200 倍的人类眼速
@@ -185,3 +202,1124 @@ func Test_ProcessBar(t *testing.T) {
 		assert.Equal(t, 1, len(collected), "Expected 10 collected messages, but got %d", len(collected))
 	})
 }
+
+// Test_ProcessBar_Writer tests that the progress bar and the report are written to the given writer.
+func Test_ProcessBar_Writer(t *testing.T) {
+	// Create a ProgressBar writing into a buffer.
+	var buf bytes.Buffer
+	progressBar, err := NewProgressBar("Upload", 5, 10,
+		WithTracking(0),         // Show the percentage without decimals.
+		WithTimeControl(0),      // Only the completion message is printed.
+		WithWriter(&buf),        // Capture the output.
+		WithTimeZone("Etc/UTC"), // Use a time zone available everywhere.
+	)
+	assert.NoError(t, err)
+
+	// Print the progress bar in the background.
+	progressBar.Start()
+
+	// Simulate progress and complete the bar.
+	for i := 0; i < 5; i++ {
+		progressBar.UpdateBar()
+	}
+	progressBar.Complete()
+	<-progressBar.WaitForPrinterStop()
+
+	// The completed bar is followed by a newline.
+	assert.Equal(t, "\rUpload: "+BrightCyan+"[██████████] 100%"+Reset+"\n", buf.String())
+
+	// The report goes to the same writer.
+	buf.Reset()
+	assert.NoError(t, progressBar.Report(32))
+	assert.True(t, strings.Contains(buf.String(), "Progress Bar Report"))
+	assert.True(t, strings.Contains(buf.String(), "Upload"))
+}
+
+// Test_ProcessBar_ETA tests the estimated time remaining calculated from the throughput.
+func Test_ProcessBar_ETA(t *testing.T) {
+	// Create a ProgressBar displaying the ETA.
+	progressBar, err := NewProgressBar("Endurance", 100, 10, WithTracking(0), WithETA(true), WithTimeZone("Etc/UTC"))
+	assert.NoError(t, err)
+
+	// Pretend the progress bar started 10 seconds ago, so a quarter done leaves about 30 seconds.
+	progressBar.startTime = time.Now().Add(-10 * time.Second)
+	eta := progressBar.estimate(0.25)
+	assert.InDelta(t, 30*time.Second, eta, float64(time.Second))
+
+	// The ETA is rendered after the percentage, and it is unknown before anything is done.
+	assert.Equal(t, "Endurance: "+BrightCyan+"[██░░░░░░░░] 25% ETA 30s"+Reset,
+		progressBar.render(barMessage{filledLength: 2, percentage: 25, eta: 30 * time.Second}))
+	assert.Equal(t, "Endurance: "+BrightCyan+"[░░░░░░░░░░] 0% ETA --"+Reset,
+		progressBar.render(barMessage{eta: progressBar.estimate(0)}))
+	assert.Equal(t, time.Duration(0), progressBar.estimate(1))
+}
+
+// Test_ProcessBar_Rate tests showing the operations per second after the percentage.
+func Test_ProcessBar_Rate(t *testing.T) {
+	msg := barMessage{filledLength: 2, percentage: 50, rate: 12500}
+
+	// The rate follows the percentage, only when it is turned on.
+	progressBar, err := NewProgressBar("Mode 3", 10, 4, WithTracking(0), WithTimeZone("Etc/UTC"), WithRate(true))
+	assert.NoError(t, err)
+	assert.Contains(t, progressBar.render(msg), "50% 12500.0/s")
+	progressBar, err = NewProgressBar("Mode 3", 10, 4, WithTracking(0), WithTimeZone("Etc/UTC"))
+	assert.NoError(t, err)
+	assert.NotContains(t, progressBar.render(msg), "/s")
+
+	// The rate is the steps done per second, so five steps in ten seconds go at half a step per second.
+	progressBar, err = NewProgressBar("Mode 3", 10, 4, WithTracking(0), WithTimeControl(60000), WithTimeZone("Etc/UTC"), WithWriter(&bytes.Buffer{}))
+	assert.NoError(t, err)
+	progressBar.AddSpecificTimes(5)
+	progressBar.startTime = time.Now().Add(-10 * time.Second)
+	assert.InDelta(t, 0.5, progressBar.throughput(), 0.01)
+}
+
+// Test_ProcessBar_Pause tests that nothing is printed while paused and that the paused time is excluded from the elapsed time.
+func Test_ProcessBar_Pause(t *testing.T) {
+	// Create a ProgressBar which refreshes every millisecond.
+	var buf bytes.Buffer
+	progressBar, err := NewProgressBar("Phase", 10, 10, WithTimeControl(1), WithWriter(&buf), WithTimeZone("Etc/UTC"))
+	assert.NoError(t, err)
+	progressBar.Start()
+
+	// Updates while paused are counted, but not printed.
+	progressBar.Pause()
+	progressBar.Pause() // Pausing twice does nothing.
+	time.Sleep(200 * time.Millisecond)
+	for i := 0; i < 5; i++ {
+		progressBar.UpdateBar()
+	}
+	assert.Equal(t, uint64(5), progressBar.currentProcess)
+	progressBar.Resume()
+
+	// Complete the bar, and only the completion message is printed.
+	progressBar.Complete()
+	<-progressBar.WaitForPrinterStop()
+	assert.Equal(t, 1, strings.Count(buf.String(), "\r"))
+
+	// The paused time is recorded and excluded from the elapsed time.
+	assert.GreaterOrEqual(t, progressBar.pausedTime, 200*time.Millisecond)
+	assert.Less(t, progressBar.activeElapsed(progressBar.endTime), 200*time.Millisecond)
+
+	buf.Reset()
+	assert.NoError(t, progressBar.Report(32))
+	assert.True(t, strings.Contains(buf.String(), "Paused Time"))
+}
+
+// Test_ProcessBar_LargeTotal tests that the counters do not overflow with more than 4 billion steps.
+func Test_ProcessBar_LargeTotal(t *testing.T) {
+	// Create a ProgressBar for 10 billion steps.
+	var buf bytes.Buffer
+	progressBar, err := NewProgressBar("Soak", 10_000_000_000, 10, WithTimeControl(0), WithWriter(&buf), WithTimeZone("Etc/UTC"))
+	assert.NoError(t, err)
+	progressBar.Start()
+
+	// Go past the 32-bit limit.
+	progressBar.AddSpecificTimes(5_000_000_000)
+	progressBar.UpdateBar()
+	assert.Equal(t, uint64(5_000_000_001), progressBar.currentProcess)
+
+	// Going over the total is limited to the total.
+	progressBar.AddSpecificTimes(6_000_000_000)
+	assert.Equal(t, uint64(10_000_000_000), progressBar.currentProcess)
+
+	progressBar.Complete()
+	<-progressBar.WaitForPrinterStop()
+}
+
+// Test_ProcessBar_SetTotal tests changing the total while the progress bar is running.
+func Test_ProcessBar_SetTotal(t *testing.T) {
+	// Create a ProgressBar which refreshes every millisecond.
+	var buf bytes.Buffer
+	progressBar, err := NewProgressBar("Generate", 10, 10, WithTimeControl(1), WithWriter(&buf), WithTimeZone("Etc/UTC"))
+	assert.NoError(t, err)
+	progressBar.Start()
+
+	// Finish the first 10 steps, which stops the refreshing.
+	progressBar.AddSpecificTimes(10)
+	assert.Equal(t, uint64(10), progressBar.currentProcess)
+
+	// More work is found, so the total grows and the percentage is recalculated on the next refresh.
+	progressBar.SetTotal(40)
+	time.Sleep(5 * time.Millisecond)
+	progressBar.UpdateBar()
+	assert.Equal(t, uint64(11), progressBar.currentProcess)
+
+	// The total shrinks below the progress, so the progress is capped.
+	progressBar.SetTotal(8)
+	assert.Equal(t, uint64(8), progressBar.currentProcess)
+
+	progressBar.Complete()
+	<-progressBar.WaitForPrinterStop()
+	assert.Contains(t, buf.String(), "27.50%")
+
+	// The total can not be changed after completion.
+	progressBar.SetTotal(100)
+	assert.Equal(t, uint64(8), progressBar.total)
+}
+
+// Test_ProcessBar_AutoWidth tests fitting the progress bar into the terminal width.
+func Test_ProcessBar_AutoWidth(t *testing.T) {
+	// The buffer is not a terminal, so the width comes from COLUMNS.
+	t.Setenv("COLUMNS", "30")
+	var buf bytes.Buffer
+	progressBar, err := NewProgressBar("Load", 100, 50, WithTracking(0), WithAutoWidth(true), WithWriter(&buf), WithTimeZone("Etc/UTC"))
+	assert.NoError(t, err)
+
+	// "Load: [] 50%" takes 12 columns and the last column is left empty, so the bar has 17 segments.
+	line := progressBar.render(barMessage{filledLength: 25, percentage: 50})
+	assert.Equal(t, "Load: "+BrightCyan+"["+strings.Repeat("█", 8)+strings.Repeat("░", 9)+"] 50%"+Reset, line)
+
+	// The width is checked again on every refresh.
+	t.Setenv("COLUMNS", "20")
+	line = progressBar.render(barMessage{filledLength: 25, percentage: 50})
+	assert.Equal(t, "Load: "+BrightCyan+"["+strings.Repeat("█", 3)+strings.Repeat("░", 4)+"] 50%"+Reset, line)
+
+	// The fixed bar length is used when the width is unknown.
+	t.Setenv("COLUMNS", "")
+	line = progressBar.render(barMessage{filledLength: 25, percentage: 50})
+	assert.Equal(t, 50, utf8.RuneCountInString(line)-utf8.RuneCountInString("Load: [] 50%")-len(BrightCyan)-len(Reset))
+}
+
+// Test_ProcessBar_Resize tests fitting a bar of fixed length into a narrower terminal, and repainting it after a resize.
+func Test_ProcessBar_Resize(t *testing.T) {
+	var buf bytes.Buffer
+	progressBar, err := NewProgressBar("Load", 100, 20, WithTracking(0), WithWriter(&buf), WithTimeZone("Etc/UTC"))
+	assert.NoError(t, err)
+
+	// The bar keeps its length while it fits into the terminal.
+	progressBar.columns.Store(80)
+	line := progressBar.render(barMessage{filledLength: 10, percentage: 50})
+	assert.Equal(t, 32, visibleLength(line))
+
+	// "Load: [] 50%" takes 12 columns and the last column is left empty, so the bar shrinks to 7 segments.
+	progressBar.columns.Store(20)
+	line = progressBar.render(barMessage{filledLength: 10, percentage: 50})
+	assert.Equal(t, "Load: "+BrightCyan+"["+strings.Repeat("█", 3)+strings.Repeat("░", 4)+"] 50%"+Reset, line)
+
+	// The old line of 32 columns wrapped onto 2 rows, so the cursor goes up 1 row before clearing and redrawing.
+	shown := progressBar.repaint(barMessage{filledLength: 10, percentage: 50}, 32)
+	assert.Equal(t, "\033[1A\r\033[J"+line, buf.String())
+	assert.Equal(t, 19, shown)
+
+	// A line which did not wrap is only cleared and redrawn.
+	buf.Reset()
+	progressBar.columns.Store(80)
+	progressBar.repaint(barMessage{filledLength: 10, percentage: 50}, shown)
+	assert.True(t, strings.HasPrefix(buf.String(), "\r\033[J"))
+	assert.Equal(t, 32, visibleLength(strings.TrimPrefix(buf.String(), "\r\033[J")))
+}
+
+// Test_ProcessBar_Template tests rendering the progress bar with a template.
+func Test_ProcessBar_Template(t *testing.T) {
+	// Reorder the components and drop the name.
+	progressBar, err := NewProgressBar("Index", 100, 4, WithTracking(1), WithTemplate("{percent} {bar} {eta} {rate}"), WithTimeZone("Etc/UTC"))
+	assert.NoError(t, err)
+	line := progressBar.render(barMessage{filledLength: 1, percentage: 25, eta: 3 * time.Second, rate: 12.5})
+	assert.Equal(t, "25.0% "+BrightCyan+"[█░░░]"+Reset+" ETA 3s 12.5/s", line)
+
+	// Components can be dropped, and the text around them is kept.
+	progressBar, err = NewProgressBar("Index", 100, 4, WithTracking(0), WithTemplate("{name} | {percent}"), WithTimeZone("Etc/UTC"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Index | 50%", progressBar.render(barMessage{filledLength: 2, percentage: 50}))
+
+	// The bar takes the rest of the terminal width, without counting the color codes.
+	t.Setenv("COLUMNS", "20")
+	progressBar, err = NewProgressBar("Index", 100, 4, WithTracking(0), WithTemplate("{bar} {percent}"), WithAutoWidth(true), WithTimeZone("Etc/UTC"))
+	assert.NoError(t, err)
+	line = progressBar.render(barMessage{percentage: 50})
+	assert.Equal(t, 19, visibleLength(line))
+
+	// The rate is sent with the progress.
+	progressBar.startTime = time.Now().Add(-2 * time.Second)
+	progressBar.currentProcess = 10
+	assert.InDelta(t, 5, progressBar.throughput(), 0.1)
+}
+
+// Test_ProcessBar_ColorThresholds tests switching the bar color as the percentage crosses the thresholds.
+func Test_ProcessBar_ColorThresholds(t *testing.T) {
+	progressBar, err := NewProgressBar("Build", 100, 4, WithTracking(0), WithTimeZone("Etc/UTC"), WithDisplay(BrightBlue),
+		WithColorThresholds(map[float64]string{90: BrightGreen, 10: BrightRed, 50: BrightYellow}))
+	assert.NoError(t, err)
+
+	// Below the lowest threshold, the display color is used, and each threshold takes over from its percentage.
+	for _, tc := range []struct {
+		percentage float64
+		color      string
+	}{
+		{5, BrightBlue},
+		{10, BrightRed},
+		{49.9, BrightRed},
+		{50, BrightYellow},
+		{89, BrightYellow},
+		{100, BrightGreen},
+	} {
+		line := progressBar.render(barMessage{filledLength: int(tc.percentage / 25), percentage: tc.percentage})
+		assert.True(t, strings.HasPrefix(line, "Build: "+tc.color+"["), "%v%%: %q", tc.percentage, line)
+	}
+}
+
+// Test_ProcessBar_Charset tests the user supplied runes and the ASCII fallback.
+func Test_ProcessBar_Charset(t *testing.T) {
+	// The runes set by the option are always used.
+	progressBar, err := NewProgressBar("Sync", 100, 4, WithTracking(0), WithTimeZone("Etc/UTC"), WithCharset('=', ' '))
+	assert.NoError(t, err)
+	assert.Equal(t, "Sync: "+BrightCyan+"[==  ] 50%"+Reset, progressBar.render(barMessage{filledLength: 2, percentage: 50}))
+
+	// The locale decides between the block runes and ASCII, and the first set variable wins.
+	for _, tc := range []struct {
+		lcAll, lang, term string
+		bar               string
+	}{
+		{"", "", "xterm", "[██░░]"},
+		{"", "en_US.UTF-8", "xterm", "[██░░]"},
+		{"", "C.utf8", "xterm", "[██░░]"},
+		{"", "C", "xterm", "[##--]"},
+		{"POSIX", "en_US.UTF-8", "xterm", "[##--]"},
+		{"", "en_US.UTF-8", "vt220", "[##--]"},
+	} {
+		t.Setenv("LC_ALL", tc.lcAll)
+		t.Setenv("LC_CTYPE", "")
+		t.Setenv("LANG", tc.lang)
+		t.Setenv("TERM", tc.term)
+		progressBar, err = NewProgressBar("Sync", 100, 4, WithTracking(0), WithTimeZone("Etc/UTC"))
+		assert.NoError(t, err)
+		line := progressBar.render(barMessage{filledLength: 2, percentage: 50})
+		assert.Equal(t, "Sync: "+BrightCyan+tc.bar+" 50%"+Reset, line, "LC_ALL=%q LANG=%q TERM=%q", tc.lcAll, tc.lang, tc.term)
+	}
+}
+
+// Test_ProcessBar_Palette tests the colorblind and monochrome palettes, and the NO_COLOR and CLICOLOR detection.
+func Test_ProcessBar_Palette(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("CLICOLOR", "")
+	t.Setenv("CLICOLOR_FORCE", "")
+	msg := barMessage{filledLength: 2, percentage: 50}
+
+	// The palettes are selected by name.
+	for name, expected := range map[string]Palette{"": DefaultPalette, "default": DefaultPalette, "colorblind": ColorblindPalette, "monochrome": MonochromePalette} {
+		palette, err := PaletteByName(name)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, palette)
+	}
+	_, err := PaletteByName("neon")
+	assert.Error(t, err)
+
+	// The default palette keeps the WithDisplay color, and the colorblind one replaces it.
+	progressBar, err := NewProgressBar("Load", 100, 4, WithTracking(0), WithTimeZone("Etc/UTC"), WithDisplay(BrightGreen))
+	assert.NoError(t, err)
+	assert.Equal(t, "Load: "+BrightGreen+"[██░░] 50%"+Reset, progressBar.render(msg))
+	progressBar, err = NewProgressBar("Load", 100, 4, WithTracking(0), WithTimeZone("Etc/UTC"), WithDisplay(BrightGreen), WithPalette(ColorblindPalette))
+	assert.NoError(t, err)
+	assert.Equal(t, "Load: "+BrightBlue+"[██░░] 50%"+Reset, progressBar.render(msg))
+
+	// The monochrome palette prints no ANSI codes in the bar or the report.
+	var buf bytes.Buffer
+	progressBar, err = NewProgressBar("Load", 10, 4, WithTracking(0), WithTimeZone("Etc/UTC"), WithTimeControl(0), WithWriter(&buf),
+		WithPalette(MonochromePalette), WithColorThresholds(map[float64]string{0: BrightRed}))
+	assert.NoError(t, err)
+	progressBar.Start()
+	progressBar.Complete()
+	<-progressBar.WaitForPrinterStop()
+	assert.NoError(t, progressBar.Report(32))
+	assert.NotContains(t, buf.String(), "\033[")
+	assert.Contains(t, buf.String(), "\rLoad: [████] 100%\n")
+
+	// NO_COLOR and CLICOLOR=0 turn the colors off with any palette, and CLICOLOR_FORCE turns them on again.
+	for _, tc := range []struct {
+		noColor, cliColor, force string
+		plain                    bool
+	}{
+		{"1", "", "", true},
+		{"", "0", "", true},
+		{"1", "", "1", false},
+		{"", "1", "", false},
+	} {
+		t.Setenv("NO_COLOR", tc.noColor)
+		t.Setenv("CLICOLOR", tc.cliColor)
+		t.Setenv("CLICOLOR_FORCE", tc.force)
+		progressBar, err = NewProgressBar("Load", 100, 4, WithTracking(0), WithTimeZone("Etc/UTC"), WithPalette(ColorblindPalette))
+		assert.NoError(t, err)
+		assert.Equal(t, tc.plain, progressBar.render(msg) == "Load: [██░░] 50%", "%+v", tc)
+	}
+
+	// A writer which is not a console, such as a buffer, draws the ANSI codes on every platform.
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("CLICOLOR", "")
+	t.Setenv("CLICOLOR_FORCE", "")
+	assert.True(t, enableANSI(&buf))
+	progressBar, err = NewProgressBar("Load", 100, 4, WithTracking(0), WithTimeZone("Etc/UTC"), WithWriter(&buf), WithDisplay(BrightGreen))
+	assert.NoError(t, err)
+	assert.Equal(t, "Load: "+BrightGreen+"[██░░] 50%"+Reset, progressBar.render(msg))
+}
+
+// Test_ProcessBar_NumberFormat tests the grouped counts in the bar and the report.
+func Test_ProcessBar_NumberFormat(t *testing.T) {
+	de, err := NumberFormatByLocale("de")
+	assert.NoError(t, err)
+
+	// The percentage, the rate and the count follow the locale.
+	progressBar, err := NewProgressBar("Load", 12500000, 4, WithTracking(1), WithTimeZone("Etc/UTC"), WithNumberFormat(de),
+		WithTemplate("{percent} {count} {rate}"))
+	assert.NoError(t, err)
+	line := progressBar.render(barMessage{filledLength: 1, percentage: 27.5, rate: 1234.5, done: 3437500, total: 12500000})
+	assert.Equal(t, "27,5% 3.437.500/12.500.000 1.234,5/s", line)
+
+	// The report groups the counts.
+	var buf bytes.Buffer
+	en, _ := NumberFormatByLocale("en")
+	progressBar, err = NewProgressBar("Load", 12500000, 4, WithTimeControl(0), WithTimeZone("Etc/UTC"), WithWriter(&buf), WithNumberFormat(en))
+	assert.NoError(t, err)
+	progressBar.Start()
+	progressBar.Checkpoint("Insert", 6250000, time.Second)
+	progressBar.Complete()
+	<-progressBar.WaitForPrinterStop()
+	assert.NoError(t, progressBar.Report(32))
+	assert.Contains(t, buf.String(), "| Total Tasks          | 12,500,000 ")
+	assert.Contains(t, buf.String(), "| Completed Tasks      | 12,500,000 ")
+	assert.Contains(t, buf.String(), "| Insert Rate          | 6,250,000.0 ops/s (6,250,000 in 1s)")
+}
+
+// Test_ProcessBar_LogMode tests printing plain lines when the writer is not a terminal.
+func Test_ProcessBar_LogMode(t *testing.T) {
+	// A buffer is not a terminal, so every refresh becomes a plain line.
+	var buf bytes.Buffer
+	progressBar, err := NewProgressBar("CI", 4, 4, WithTracking(0), WithTimeControl(1), WithLogMode(), WithWriter(&buf), WithTimeZone("Etc/UTC"))
+	assert.NoError(t, err)
+	progressBar.Start()
+
+	// Wait for the ticker and for the printer to take the last line before each update, so every update is printed.
+	for i := 0; i < 2; i++ {
+		for !progressBar.due() || len(progressBar.printChannel) > 0 {
+			time.Sleep(time.Millisecond)
+		}
+		progressBar.UpdateBar()
+	}
+	progressBar.Complete()
+	<-progressBar.WaitForPrinterStop()
+
+	// No carriage return and no color code, and every line starts with a timestamp.
+	out := buf.String()
+	assert.NotContains(t, out, "\r")
+	assert.NotContains(t, out, "\033[")
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	assert.Equal(t, 3, len(lines))
+	for _, line := range lines {
+		_, err = time.Parse(time.DateTime, line[:len(time.DateTime)])
+		assert.NoError(t, err)
+	}
+	assert.True(t, strings.HasSuffix(lines[0], " CI: [█░░░] 25%"))
+	assert.True(t, strings.HasSuffix(lines[2], " CI: [████] 100%"))
+}
+
+// Test_ProcessBar_Simulation tests that a progress bar prints the same lines in every replay of a simulation.
+func Test_ProcessBar_Simulation(t *testing.T) {
+	replay := func() string {
+		simhub.Start(42)
+		defer simhub.Stop()
+		var buf bytes.Buffer
+		progressBar, err := NewProgressBar("Sim", 4, 4, WithTracking(0), WithTimeControl(100), WithLogMode(), WithWriter(&buf), WithTimeZone("Etc/UTC"))
+		assert.NoError(t, err)
+		progressBar.Start()
+
+		// The fake clock only moves when advanced, so the refreshes come at the same steps.
+		// Wait for the printer to take each line, so no line is dropped for a slow printer.
+		for i := 0; i < 4; i++ {
+			simhub.Advance(60 * time.Millisecond)
+			progressBar.UpdateBar()
+			for len(progressBar.printChannel) > 0 {
+				time.Sleep(time.Millisecond)
+			}
+		}
+		assert.NoError(t, progressBar.Complete())
+		<-progressBar.WaitForPrinterStop()
+		return buf.String()
+	}
+	out := replay()
+	assert.Equal(t, out, replay())
+	assert.True(t, strings.HasPrefix(out, "2000-01-01 00:00:00 Sim: [██░░] 50%\n"), out)
+	assert.Contains(t, out, "2000-01-01 00:00:00 Sim: [████] 100%\n")
+}
+
+// Test_ProcessBar_Milestones tests that every milestone is reported once, by the hook and by a line in log mode.
+func Test_ProcessBar_Milestones(t *testing.T) {
+	type crossed struct {
+		percent     float64
+		done, total uint64
+	}
+	var buf bytes.Buffer
+	var hooked []crossed
+	progressBar, err := NewProgressBar("Run", 20, 4, WithTracking(0), WithTimeControl(0), WithLogMode(), WithWriter(&buf), WithTimeZone("Etc/UTC"),
+		WithMilestones(90, 25, 50, 75, 50, 0, 150),
+		WithOnMilestone(func(percent float64, done, total uint64) {
+			hooked = append(hooked, crossed{percent, done, total})
+		}),
+	)
+	assert.NoError(t, err)
+	progressBar.Start()
+
+	// One update can cross several milestones, and a progress taken back does not cross them again.
+	progressBar.AddSpecificTimes(5)
+	progressBar.AddSpecificTimes(12)
+	progressBar.Sub(10)
+	progressBar.AddSpecificTimes(10)
+	assert.Equal(t, []crossed{{25, 5, 20}, {50, 17, 20}, {75, 17, 20}}, hooked)
+
+	// The completion crosses the milestones left, and their lines come before the final line.
+	assert.NoError(t, progressBar.Complete())
+	<-progressBar.WaitForPrinterStop()
+	assert.Equal(t, crossed{90, 20, 20}, hooked[3])
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Len(t, lines, 5)
+	for i, want := range []string{"Run: milestone 25% reached, 5/20", "Run: milestone 50% reached, 17/20", "Run: milestone 75% reached, 17/20", "Run: milestone 90% reached, 20/20"} {
+		assert.True(t, strings.HasSuffix(lines[i], want), lines[i])
+	}
+	assert.True(t, strings.HasSuffix(lines[4], "Run: [████] 100%"), lines[4])
+
+	// The racing updates report every milestone once, and an interrupted bar does not cross the milestones left.
+	var count atomic.Int32
+	progressBar, err = NewProgressBar("Run", 800, 4, WithTracking(0), WithTimeControl(0), WithWriter(&bytes.Buffer{}), WithTimeZone("Etc/UTC"),
+		WithMilestones(10, 20, 30, 40, 50, 60, 70, 80, 90, 100),
+		WithOnMilestone(func(percent float64, done, total uint64) {
+			count.Add(1)
+			assert.GreaterOrEqual(t, float64(done)*100, percent*float64(total))
+		}),
+	)
+	assert.NoError(t, err)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 90; i++ {
+				progressBar.UpdateBar()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(9), count.Load())
+	progressBar.Interrupt()
+	assert.Equal(t, int32(9), count.Load())
+}
+
+// Test_ProgressGroup tests a parent bar moving together with its child bars.
+func Test_ProgressGroup(t *testing.T) {
+	// Every bar prints into its own buffer.
+	group, err := NewProgressGroup("Suite", 10, WithTimeControl(0), WithWriter(&bytes.Buffer{}), WithTimeZone("Etc/UTC"))
+	assert.NoError(t, err)
+	parent := group.Parent()
+	parent.Start()
+
+	mode1, err := group.AddChild("Mode 1", 10, 10, WithTimeControl(0), WithWriter(&bytes.Buffer{}), WithTimeZone("Etc/UTC"))
+	assert.NoError(t, err)
+	mode1.Start()
+	mode2, err := group.AddChild("Mode 2", 30, 10, WithTimeControl(0), WithWriter(&bytes.Buffer{}), WithTimeZone("Etc/UTC"))
+	assert.NoError(t, err)
+	mode2.Start()
+	assert.Equal(t, uint64(40), parent.total)
+
+	// Every step of a child moves the parent.
+	for i := 0; i < 5; i++ {
+		mode1.UpdateBar()
+	}
+	assert.Equal(t, uint64(5), parent.currentProcess)
+
+	// Completing a child moves the parent by its remaining steps.
+	mode1.Complete()
+	<-mode1.WaitForPrinterStop()
+	assert.Equal(t, uint64(10), parent.currentProcess)
+
+	// The steps over the total of a child do not move the parent.
+	mode2.AddSpecificTimes(10)
+	mode2.SetTotal(15)
+	assert.Equal(t, uint64(25), parent.total)
+	mode2.AddSpecificTimes(100)
+	assert.Equal(t, uint64(25), parent.currentProcess)
+
+	mode2.Complete()
+	<-mode2.WaitForPrinterStop()
+	assert.Equal(t, parent.total, parent.currentProcess)
+
+	parent.Complete()
+	<-parent.WaitForPrinterStop()
+}
+
+// Test_ProcessBar_Hooks tests the update and completion hooks.
+func Test_ProcessBar_Hooks(t *testing.T) {
+	// Record every update and the final report.
+	var updates [][2]uint64
+	var reports []ProgressReport
+	progressBar, err := NewProgressBar("Metrics", 10, 10, WithTimeControl(0), WithWriter(&bytes.Buffer{}), WithTimeZone("Etc/UTC"),
+		WithOnUpdate(func(done, total uint64) { updates = append(updates, [2]uint64{done, total}) }),
+		WithOnComplete(func(report ProgressReport) { reports = append(reports, report) }))
+	assert.NoError(t, err)
+	progressBar.Start()
+
+	progressBar.UpdateBar()
+	progressBar.AddSpecificTimes(3)
+	progressBar.Complete()
+	<-progressBar.WaitForPrinterStop()
+	progressBar.Complete() // Completing twice does not call the hook again.
+
+	assert.Equal(t, [][2]uint64{{1, 10}, {4, 10}, {10, 10}}, updates)
+	assert.Len(t, reports, 1)
+	assert.Equal(t, "Metrics", reports[0].Name)
+	assert.Equal(t, uint64(10), reports[0].Total)
+	assert.Equal(t, uint64(10), reports[0].Completed)
+	assert.False(t, reports[0].EndTime.Before(reports[0].StartTime))
+}
+
+// Test_ProcessBar_ConcurrentUpdates tests that the lock-free updates from many goroutines never go over the total.
+func Test_ProcessBar_ConcurrentUpdates(t *testing.T) {
+	progressBar, err := NewProgressBar("Workers", 10000, 20, WithTimeControl(1), WithWriter(&bytes.Buffer{}), WithTimeZone("Etc/UTC"))
+	assert.NoError(t, err)
+	progressBar.Start()
+
+	// 8 goroutines add 2000 steps each, which is more than the total.
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				progressBar.UpdateBar()
+				progressBar.AddSpecificTimes(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The extra steps are given back, so the progress stops exactly at the total.
+	assert.Equal(t, uint64(10000), atomic.LoadUint64(&progressBar.currentProcess))
+	progressBar.Complete()
+	<-progressBar.WaitForPrinterStop()
+}
+
+// Benchmark_ProcessBar_UpdateBar measures UpdateBar in a tight loop, which only takes the mutex when a refresh is due.
+func Benchmark_ProcessBar_UpdateBar(b *testing.B) {
+	progressBar, err := NewProgressBar("Bench", uint64(b.N), 20, WithTimeControl(100), WithWriter(&bytes.Buffer{}), WithTimeZone("Etc/UTC"))
+	assert.NoError(b, err)
+	progressBar.Start()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			progressBar.UpdateBar()
+		}
+	})
+	b.StopTimer()
+
+	progressBar.Complete()
+	<-progressBar.WaitForPrinterStop()
+}
+
+// Test_ProcessBar_SaveState tests continuing a progress bar from a saved state, as a restarted process does.
+func Test_ProcessBar_SaveState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "endurance.json")
+
+	// The first process does 40 of 100 steps, and is paused when the state is saved.
+	progressBar, err := NewProgressBar("Endurance", 100, 10, WithTimeControl(0), WithWriter(&bytes.Buffer{}), WithTimeZone("Etc/UTC"))
+	assert.NoError(t, err)
+	progressBar.Start()
+	progressBar.AddSpecificTimes(40)
+	progressBar.Pause()
+	time.Sleep(5 * time.Millisecond)
+	assert.NoError(t, progressBar.SaveState(path))
+	startTime := progressBar.startTime
+	progressBar.Complete()
+	<-progressBar.WaitForPrinterStop()
+
+	// The second process continues from 40%, and the paused time and the downtime are not counted as elapsed.
+	time.Sleep(5 * time.Millisecond)
+	resumed, err := ResumeProgressBar(path, WithTimeControl(0), WithWriter(&bytes.Buffer{}), WithTimeZone("Etc/UTC"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Endurance", resumed.name)
+	assert.Equal(t, 10, resumed.barLength)
+	assert.Equal(t, uint64(40), atomic.LoadUint64(&resumed.currentProcess))
+	assert.True(t, resumed.startTime.Equal(startTime))
+	assert.GreaterOrEqual(t, resumed.pausedTime, 10*time.Millisecond)
+
+	resumed.Start()
+	resumed.AddSpecificTimes(60)
+	resumed.Complete()
+	<-resumed.WaitForPrinterStop()
+	report := resumed.report()
+	assert.Equal(t, uint64(100), report.Completed)
+	assert.GreaterOrEqual(t, report.Paused, 10*time.Millisecond)
+	assert.Equal(t, report.EndTime.Sub(report.StartTime), report.Elapsed+report.Paused)
+
+	// A missing or broken state can not be resumed.
+	_, err = ResumeProgressBar(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+	assert.NoError(t, os.WriteFile(path, []byte(`{"total": 10, "current": 20}`), 0o644))
+	_, err = ResumeProgressBar(path)
+	assert.Error(t, err)
+}
+
+// Test_ProcessBar_Checkpoint tests the throughput of each phase in the report.
+func Test_ProcessBar_Checkpoint(t *testing.T) {
+	var buf bytes.Buffer
+	progressBar, err := NewProgressBar("Mode 1", 30, 10, WithTimeControl(0), WithWriter(&buf), WithTimeZone("Etc/UTC"))
+	assert.NoError(t, err)
+	progressBar.Start()
+
+	// The checkpoints of the same phase add up, and the phases keep the order they first appear.
+	progressBar.Checkpoint("Insert", 10, time.Second)
+	progressBar.Checkpoint("Delete", 5, 2*time.Second)
+	progressBar.Checkpoint("Insert", 10, time.Second)
+	progressBar.Checkpoint("Delete", 5, 2*time.Second)
+	progressBar.AddSpecificTimes(30)
+	progressBar.Complete()
+	<-progressBar.WaitForPrinterStop()
+
+	report := progressBar.report()
+	assert.Equal(t, []PhaseStat{{"Insert", 20, 2 * time.Second}, {"Delete", 10, 4 * time.Second}}, report.Phases)
+	assert.Equal(t, 10.0, report.Phases[0].Rate())
+	assert.Equal(t, 2.5, report.Phases[1].Rate())
+	assert.Equal(t, 0.0, PhaseStat{Steps: 1}.Rate())
+
+	// The report prints one row for each phase.
+	buf.Reset()
+	assert.NoError(t, progressBar.Report(32))
+	assert.Contains(t, buf.String(), "| Insert Rate          | 10.0 ops/s (20 in 2s)")
+	assert.Contains(t, buf.String(), "| Delete Rate          | 2.5 ops/s (10 in 4s)")
+}
+
+// Test_ProcessBar_Interrupt tests ending a progress bar where it is.
+func Test_ProcessBar_Interrupt(t *testing.T) {
+	var buf bytes.Buffer
+	var final ProgressReport
+	progressBar, err := NewProgressBar("Run", 10, 10, WithTracking(0), WithTimeControl(0), WithTimeZone("Etc/UTC"), WithWriter(&buf),
+		WithOnComplete(func(report ProgressReport) { final = report }))
+	assert.NoError(t, err)
+	progressBar.Start()
+	progressBar.AddSpecificTimes(4)
+
+	// The bar stays at 40% and its line is marked.
+	progressBar.Interrupt()
+	<-progressBar.WaitForPrinterStop()
+	assert.True(t, final.Interrupted)
+	assert.Equal(t, uint64(4), final.Completed)
+	assert.Contains(t, buf.String(), "[████░░░░░░] 40%")
+	assert.Contains(t, buf.String(), "(interrupted)")
+
+	// Completing an interrupted bar does nothing, and the report shows the status.
+	progressBar.Complete()
+	assert.NoError(t, progressBar.Report(32))
+	assert.Contains(t, buf.String(), "| Status               | interrupted ")
+	assert.Equal(t, uint64(4), progressBar.Snapshot().Completed)
+}
+
+// Test_ProcessBar_Close tests tearing a progress bar down with nothing left running.
+func Test_ProcessBar_Close(t *testing.T) {
+	// An unfinished bar is interrupted, and its printer has returned when Close returns.
+	var buf bytes.Buffer
+	progressBar, err := NewProgressBar("Run", 10, 10, WithTracking(0), WithTimeControl(10), WithTimeZone("Etc/UTC"), WithWriter(&buf))
+	assert.NoError(t, err)
+	// Close right after Start still waits for the printer, which prints the interrupted bar before it returns.
+	progressBar.Start()
+	progressBar.AddSpecificTimes(4)
+	assert.NoError(t, progressBar.Close())
+	assert.True(t, progressBar.Snapshot().Interrupted)
+	assert.Contains(t, buf.String(), "(interrupted)")
+
+	// The ticker is stopped, and a second Close changes nothing.
+	time.Sleep(30 * time.Millisecond)
+	assert.False(t, progressBar.due())
+	assert.NoError(t, progressBar.Close())
+
+	// A completed bar keeps its status, and its printer returns even without WaitForPrinterStop.
+	progressBar, err = NewProgressBar("Run", 10, 10, WithTracking(0), WithTimeControl(0), WithTimeZone("Etc/UTC"), WithWriter(&bytes.Buffer{}))
+	assert.NoError(t, err)
+	progressBar.Start()
+	assert.NoError(t, progressBar.Complete())
+	assert.NoError(t, progressBar.Close())
+	assert.False(t, progressBar.Snapshot().Interrupted)
+	assert.Equal(t, uint64(10), progressBar.Snapshot().Completed)
+
+	// A bar without a printer is closed at once.
+	progressBar, err = NewProgressBar("Run", 10, 10, WithTracking(0), WithTimeZone("Etc/UTC"), WithWriter(&bytes.Buffer{}))
+	assert.NoError(t, err)
+	assert.NoError(t, progressBar.Close())
+}
+
+// Test_ProcessBar_Sub tests taking steps back from a progress bar.
+func Test_ProcessBar_Sub(t *testing.T) {
+	var buf bytes.Buffer
+	var updates []uint64
+	group, err := NewProgressGroup("Suite", 10, WithTimeControl(0), WithWriter(&bytes.Buffer{}))
+	assert.NoError(t, err)
+	parent := group.Parent()
+	parent.Start()
+	progressBar, err := group.AddChild("Retry", 10, 10, WithTracking(0), WithTimeControl(0), WithWriter(&buf),
+		WithOnUpdate(func(done, total uint64) { updates = append(updates, done) }))
+	assert.NoError(t, err)
+
+	// A rollback shows the shorter bar at once, and the parent moves back too.
+	// The printer is not started yet, so the print channel holds the latest message.
+	progressBar.AddSpecificTimes(6)
+	progressBar.Sub(4)
+	assert.Equal(t, uint64(2), progressBar.Snapshot().Completed)
+	assert.Equal(t, uint64(2), parent.Snapshot().Completed)
+	msg := <-progressBar.printChannel
+	assert.Equal(t, uint64(2), msg.done)
+	assert.Equal(t, 2, msg.filledLength)
+
+	// The progress is clamped at zero.
+	progressBar.Sub(5)
+	assert.Equal(t, uint64(0), progressBar.Snapshot().Completed)
+	assert.Equal(t, uint64(0), parent.Snapshot().Completed)
+	assert.Equal(t, []uint64{6, 2, 0}, updates)
+	msg = <-progressBar.printChannel
+	assert.Equal(t, uint64(0), msg.done)
+	assert.Equal(t, 0, msg.filledLength)
+
+	// Nothing can be taken back after completion.
+	progressBar.Start()
+	progressBar.Complete()
+	<-progressBar.WaitForPrinterStop()
+	progressBar.Sub(3)
+	assert.Equal(t, uint64(10), progressBar.Snapshot().Completed)
+	assert.Contains(t, buf.String(), "[██████████] 100%")
+	parent.Complete()
+	<-parent.WaitForPrinterStop()
+}
+
+// Test_ProcessBar_SlowWriter tests that a blocked writer does not hold back the updates, and only the latest message waits.
+func Test_ProcessBar_SlowWriter(t *testing.T) {
+	writer := &blockingWriter{release: make(chan struct{})}
+	progressBar, err := NewProgressBar("Slow", 100, 10, WithTracking(0), WithTimeControl(0), WithTimeZone("Etc/UTC"), WithWriter(writer))
+	assert.NoError(t, err)
+	progressBar.Start()
+
+	// Every update refreshes at once, while the printer is stuck in the first write.
+	updated := make(chan struct{})
+	go func() {
+		for i := 0; i < 99; i++ {
+			progressBar.UpdateBar()
+		}
+		close(updated)
+	}()
+	select {
+	case <-updated:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the updates are held back by the blocked writer")
+	}
+
+	// The final message replaces the waiting one, and it is still printed after the writer is released.
+	progressBar.Complete()
+	close(writer.release)
+	<-progressBar.WaitForPrinterStop()
+	output := writer.String()
+	assert.LessOrEqual(t, strings.Count(output, "\r"), 2)
+	assert.Contains(t, output, "[██████████] 100%")
+}
+
+// blockingWriter 🧫 blocks every write until release is closed, like a stuck terminal.
+type blockingWriter struct {
+	release chan struct{}
+	mu      sync.Mutex
+	buf     bytes.Buffer
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	<-w.release
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *blockingWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+// Test_ProcessBar_Stage tests showing the stage after the percentage.
+func Test_ProcessBar_Stage(t *testing.T) {
+	// The stage follows the percentage, and the template places it anywhere.
+	progressBar, err := NewProgressBar("Mode 1", 10, 4, WithTracking(0), WithTimeZone("Etc/UTC"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Mode 1: "+BrightCyan+"[██░░] 50% verify"+Reset, progressBar.render(barMessage{filledLength: 2, percentage: 50, stage: "verify"}))
+	progressBar, err = NewProgressBar("Mode 1", 10, 4, WithTracking(0), WithTemplate("{percent} [{stage}]"), WithTimeZone("Etc/UTC"))
+	assert.NoError(t, err)
+	assert.Equal(t, "50% [insert]", progressBar.render(barMessage{filledLength: 2, percentage: 50, stage: "insert"}))
+
+	// A new stage is sent at once when a refresh is due, even without any update.
+	var buf bytes.Buffer
+	progressBar, err = NewProgressBar("Mode 1", 10, 10, WithTracking(0), WithTimeControl(1), WithTimeZone("Etc/UTC"), WithWriter(&buf))
+	assert.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+	progressBar.SetStage("rebalancing leaves")
+	msg := <-progressBar.printChannel
+	assert.Equal(t, "rebalancing leaves", msg.stage)
+	assert.Equal(t, "rebalancing leaves", progressBar.Snapshot().Stage)
+
+	// Setting the same stage again sends nothing.
+	time.Sleep(10 * time.Millisecond)
+	progressBar.SetStage("rebalancing leaves")
+	select {
+	case msg = <-progressBar.printChannel:
+		t.Fatalf("unexpected message %+v", msg)
+	default:
+	}
+
+	// The completed bar drops the stage.
+	progressBar.Start()
+	progressBar.Complete()
+	<-progressBar.WaitForPrinterStop()
+	assert.Equal(t, "\rMode 1: "+BrightCyan+"[██████████] 100%"+Reset+"\n", buf.String())
+}
+
+// Test_ProcessBar_CompleteTwice tests completing a progress bar twice, and before its printer is started.
+func Test_ProcessBar_CompleteTwice(t *testing.T) {
+	var buf bytes.Buffer
+	progressBar, err := NewProgressBar("Run", 10, 10, WithTracking(0), WithTimeControl(0), WithTimeZone("Etc/UTC"), WithWriter(&buf))
+	assert.NoError(t, err)
+	progressBar.AddSpecificTimes(3)
+
+	// Nothing blocks without a printer, and the second call is refused.
+	done := make(chan error, 2)
+	go func() {
+		done <- progressBar.Complete()
+		done <- progressBar.Complete()
+	}()
+	for i, want := range []error{nil, ErrBarFinished} {
+		select {
+		case err = <-done:
+			assert.ErrorIs(t, err, want, "call %d", i+1)
+		case <-time.After(5 * time.Second):
+			t.Fatal("Complete blocks without a printer")
+		}
+	}
+
+	// The printer started later still shows the final bar, and the others are ignored too.
+	progressBar.Interrupt()
+	progressBar.Fail(errors.New("late"))
+	progressBar.Start()
+	<-progressBar.WaitForPrinterStop()
+	assert.Equal(t, "\rRun: "+BrightCyan+"[██████████] 100%"+Reset+"\n", buf.String())
+	assert.Equal(t, "completed", progressBar.Snapshot().Status())
+}
+
+// Test_ProcessBar_Units tests writing the steps of a progress bar as bytes.
+func Test_ProcessBar_Units(t *testing.T) {
+	// The default layout shows the bytes after the percentage, and the template shows them with the rate.
+	progressBar, err := NewProgressBar("Backup", 4<<30, 4, WithTracking(0), WithUnits(Bytes), WithTimeZone("Etc/UTC"))
+	assert.NoError(t, err)
+	msg := barMessage{filledLength: 1, percentage: 30, done: 1288490189, total: 4 << 30, rate: 50 << 20}
+	assert.Equal(t, "Backup: "+BrightCyan+"[█░░░] 30% 1.2 GiB / 4.0 GiB"+Reset, progressBar.render(msg))
+	progressBar, err = NewProgressBar("Backup", 4<<30, 4, WithTracking(0), WithUnits(Bytes), WithTemplate("{count} {rate}"), WithTimeZone("Etc/UTC"))
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2 GiB / 4.0 GiB 50.0 MiB/s", progressBar.render(msg))
+
+	// The report writes the totals as bytes too.
+	var buf bytes.Buffer
+	progressBar, err = NewProgressBar("Backup", 3<<20, 4, WithTimeControl(0), WithUnits(Bytes), WithTimeZone("Etc/UTC"), WithWriter(&buf))
+	assert.NoError(t, err)
+	progressBar.Start()
+	assert.NoError(t, progressBar.Complete())
+	<-progressBar.WaitForPrinterStop()
+	assert.NoError(t, progressBar.Report(20))
+	assert.Contains(t, buf.String(), "Total Tasks          | 3.0 MiB")
+
+	// The counts stay as they are without the units.
+	progressBar, err = NewProgressBar("Index", 12500, 4, WithTracking(0), WithTemplate("{count}"), WithTimeZone("Etc/UTC"))
+	assert.NoError(t, err)
+	assert.Equal(t, "1250/12500", progressBar.render(barMessage{done: 1250, total: 12500}))
+}
+
+// Test_ProcessBar_Fail tests ending a progress bar with an error.
+func Test_ProcessBar_Fail(t *testing.T) {
+	var buf bytes.Buffer
+	progressBar, err := NewProgressBar("Run", 10, 10, WithTracking(0), WithTimeControl(0), WithTimeZone("Etc/UTC"), WithWriter(&buf), WithPalette(DefaultPalette))
+	assert.NoError(t, err)
+	progressBar.Start()
+	progressBar.AddSpecificTimes(3)
+
+	// The failure unblocks the printer, and the later updates are ignored.
+	progressBar.Fail(errors.New("key 42 is missing"))
+	<-progressBar.WaitForPrinterStop()
+	progressBar.UpdateBar()
+	progressBar.Complete()
+	report := progressBar.Snapshot()
+	assert.EqualError(t, report.Failure, "key 42 is missing")
+	assert.False(t, report.Interrupted)
+	assert.Equal(t, uint64(3), report.Completed)
+
+	// The bar is red with the failure marker, unless NO_COLOR is set.
+	if !colorDisabled() {
+		assert.Contains(t, buf.String(), BrightRed+"[███░░░░░░░] 30%")
+	}
+	assert.Contains(t, buf.String(), "(failed: key 42 is missing)")
+
+	// The report works after a failure and shows the status.
+	assert.NoError(t, progressBar.Report(32))
+	assert.Contains(t, buf.String(), "| Status               | Failed: key 42 is missing ")
+}
+
+// Test_ProcessBar_SmoothedRate tests the moving average of the rate and the ETA taken from it.
+func Test_ProcessBar_SmoothedRate(t *testing.T) {
+	var buf bytes.Buffer
+	progressBar, err := NewProgressBar("Insert", 1000, 10, WithTimeControl(0), WithTimeZone("Etc/UTC"), WithWriter(&buf))
+	assert.NoError(t, err)
+	start := progressBar.sampleAt
+
+	// The first sample starts the average, and the next ones weigh 30%.
+	progressBar.sampleRate(start.Add(time.Second), 100)
+	assert.Equal(t, 100.0, progressBar.smoothedRate)
+	progressBar.sampleRate(start.Add(2*time.Second), 300)
+	assert.InDelta(t, 130.0, progressBar.smoothedRate, 1e-9)
+
+	// A rollback only moves the baseline.
+	progressBar.sampleRate(start.Add(3*time.Second), 250)
+	assert.InDelta(t, 130.0, progressBar.smoothedRate, 1e-9)
+	assert.Equal(t, uint64(250), progressBar.sampleDone)
+
+	// The ETA divides the remaining steps by the smoothed rate.
+	assert.InDelta(t, float64(700*time.Second/130), float64(progressBar.estimate(0.3)), float64(time.Millisecond))
+
+	// The report shows the smoothed rate.
+	progressBar.Start()
+	progressBar.Complete()
+	<-progressBar.WaitForPrinterStop()
+	assert.InDelta(t, 130.0, progressBar.Snapshot().Smoothed, 1e-9)
+	assert.NoError(t, progressBar.Report(32))
+	assert.Contains(t, buf.String(), "| Rate                 | 130.0/s ")
+
+	// Without smoothing, the average since the start is used.
+	progressBar, err = NewProgressBar("Insert", 1000, 10, WithSmoothing(0), WithTimeZone("Etc/UTC"))
+	assert.NoError(t, err)
+	progressBar.sampleRate(progressBar.sampleAt.Add(time.Second), 100)
+	assert.Equal(t, 0.0, progressBar.smoothedRate)
+}
+
+// Test_ProcessBar_ZeroTotal tests a progress bar without a total, which counts the steps instead of showing a percentage.
+func Test_ProcessBar_ZeroTotal(t *testing.T) {
+	// The steps are counted, and every refresh prints the count.
+	var buf bytes.Buffer
+	progressBar, err := NewProgressBar("Scan", 0, 4, WithTimeControl(0), WithTimeZone("Etc/UTC"), WithWriter(&buf), WithPalette(MonochromePalette))
+	assert.NoError(t, err)
+	progressBar.Start()
+	progressBar.AddSpecificTimes(1200)
+	progressBar.UpdateBar()
+	assert.Equal(t, uint64(1201), progressBar.Snapshot().Completed)
+	assert.Equal(t, "Scan: 1201 steps stage", progressBar.render(barMessage{done: 1201, stage: "stage", counter: true}))
+
+	// Completing keeps the count, and no percentage is written.
+	assert.NoError(t, progressBar.Complete())
+	<-progressBar.WaitForPrinterStop()
+	assert.Equal(t, "Scan: 1201 steps\n", buf.String()[strings.LastIndex(buf.String(), "\r")+1:])
+	assert.NotContains(t, buf.String(), "NaN")
+	assert.NotContains(t, buf.String(), "%")
+
+	// The bytes have no steps suffix, and the template shows no percentage.
+	progressBar, err = NewProgressBar("Dump", 0, 4, WithTracking(0), WithUnits(Bytes), WithTemplate("{name} {bar} {percent} {count}"), WithTimeZone("Etc/UTC"), WithPalette(MonochromePalette))
+	assert.NoError(t, err)
+	assert.Equal(t, "Dump 1.5 KiB --% 1.5 KiB", progressBar.render(barMessage{done: 1536, counter: true}))
+
+	// A total set later turns it into a normal bar, and the count over the total is capped.
+	progressBar, err = NewProgressBar("Scan", 0, 4, WithTracking(0), WithTimeControl(0), WithTimeZone("Etc/UTC"), WithWriter(&bytes.Buffer{}))
+	assert.NoError(t, err)
+	progressBar.Start()
+	progressBar.AddSpecificTimes(30)
+	progressBar.SetTotal(20)
+	progressBar.UpdateBar()
+	assert.Equal(t, uint64(20), progressBar.Snapshot().Completed)
+	assert.NoError(t, progressBar.Complete())
+	<-progressBar.WaitForPrinterStop()
+}
+
+// Test_ProcessBar_Style tests the built-in bar styles, whose edge cell is drawn part of the way.
+func Test_ProcessBar_Style(t *testing.T) {
+	// 45% of 4 cells is 1.8 cells, so one whole cell and the edge cell 80% filled.
+	msg := barMessage{filledLength: 1, percentage: 45}
+	for _, tc := range []struct {
+		style BarStyle
+		bar   string
+	}{
+		{BlocksStyle, "█░░░"},
+		{HalfBlocksStyle, "█▊  "},
+		{BrailleStyle, "⣿⣶⠀⠀"},
+		{DotsStyle, "●○○○"},
+		{ShadesStyle, "█▓  "},
+	} {
+		progressBar, err := NewProgressBar("Load", 100, 4, WithTracking(0), WithTimeZone("Etc/UTC"), WithStyle(tc.style), WithTemplate("{bar}"), WithPalette(MonochromePalette))
+		assert.NoError(t, err)
+		assert.Equal(t, "["+tc.bar+"]", progressBar.render(msg), "style %d", tc.style)
+	}
+
+	// The edge cell stays empty below the first step, and a full bar has no edge cell.
+	progressBar, err := NewProgressBar("Load", 100, 4, WithTracking(0), WithTimeZone("Etc/UTC"), WithStyle(ShadesStyle), WithTemplate("{bar}"), WithPalette(MonochromePalette))
+	assert.NoError(t, err)
+	assert.Equal(t, "[█   ]", progressBar.render(barMessage{filledLength: 1, percentage: 27}))
+	assert.Equal(t, "[████]", progressBar.render(barMessage{filledLength: 4, percentage: 100}))
+
+	// The charset replaces the whole cells and keeps the edge cell.
+	progressBar, err = NewProgressBar("Load", 100, 4, WithTracking(0), WithTimeZone("Etc/UTC"), WithStyle(HalfBlocksStyle), WithCharset('#', '.'), WithTemplate("{bar}"), WithPalette(MonochromePalette))
+	assert.NoError(t, err)
+	assert.Equal(t, "[#▊..]", progressBar.render(msg))
+
+	// The styles can be selected by name.
+	style, err := BarStyleByName("Braille")
+	assert.NoError(t, err)
+	assert.Equal(t, BrailleStyle, style)
+	_, err = BarStyleByName("stars")
+	assert.Error(t, err)
+}
+
+// Test_ProcessBar_Runes tests the custom runes of the cells and the caps, and rejecting the runes which are not one column wide.
+func Test_ProcessBar_Runes(t *testing.T) {
+	progressBar, err := NewProgressBar("Sync", 100, 8, WithTracking(0), WithTimeZone("Etc/UTC"), WithRunes('=', ' ', '<', '>'), WithPalette(MonochromePalette))
+	assert.NoError(t, err)
+	assert.Equal(t, "Sync: <===     > 40%", progressBar.render(barMessage{filledLength: 3, percentage: 40}))
+
+	// A cap of 0 draws nothing, also in the template.
+	progressBar, err = NewProgressBar("Sync", 100, 4, WithTracking(0), WithTimeZone("Etc/UTC"), WithRunes('#', '.', 0, '|'), WithTemplate("{bar}"), WithPalette(MonochromePalette))
+	assert.NoError(t, err)
+	assert.Equal(t, "##..|", progressBar.render(barMessage{filledLength: 2, percentage: 50}))
+
+	// The wide, the combining and the control runes would break the length of the bar.
+	for _, r := range []rune{'界', '🚀', '́', '\t', 'Ａ'} {
+		_, err = NewProgressBar("Sync", 100, 4, WithTimeZone("Etc/UTC"), WithRunes(r, ' ', '[', ']'))
+		assert.Error(t, err, "rune %q", r)
+	}
+	_, err = NewProgressBar("Sync", 100, 4, WithTimeZone("Etc/UTC"), WithRunes('=', ' ', '[', '界'))
+	assert.ErrorContains(t, err, "does not take exactly one column")
+
+	// The built-in styles all pass.
+	for style := range barStyles {
+		_, err = NewProgressBar("Sync", 100, 4, WithTimeZone("Etc/UTC"), WithStyle(style))
+		assert.NoError(t, err)
+	}
+}
+
+// Test_ProcessBar_Elapsed tests showing the running elapsed time right after the percentage.
+func Test_ProcessBar_Elapsed(t *testing.T) {
+	// The hours go on past a day.
+	assert.Equal(t, "00:00:00", formatClock(-time.Second))
+	assert.Equal(t, "00:01:05", formatClock(65*time.Second+400*time.Millisecond))
+	assert.Equal(t, "26:03:10", formatClock(26*time.Hour+3*time.Minute+10*time.Second))
+
+	// The elapsed time follows the percentage, and the template places it anywhere.
+	progressBar, err := NewProgressBar("Mode 1", 10, 4, WithTracking(0), WithTimeZone("Etc/UTC"), WithElapsed(true), WithPalette(MonochromePalette))
+	assert.NoError(t, err)
+	msg := barMessage{filledLength: 2, percentage: 50, stage: "insert", elapsed: 90 * time.Minute}
+	assert.Equal(t, "Mode 1: [██░░] 50% 01:30:00 insert", progressBar.render(msg))
+	progressBar, err = NewProgressBar("Mode 1", 10, 4, WithTracking(0), WithTimeZone("Etc/UTC"), WithTemplate("{elapsed} {percent}"))
+	assert.NoError(t, err)
+	assert.Equal(t, "01:30:00 50%", progressBar.render(msg))
+
+	// Every refresh and the final message carry the time since the start.
+	progressBar, err = NewProgressBar("Mode 1", 10, 4, WithTracking(0), WithTimeControl(1), WithTimeZone("Etc/UTC"), WithWriter(&bytes.Buffer{}))
+	assert.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+	progressBar.AddSpecificTimes(5)
+	msg = <-progressBar.printChannel
+	assert.GreaterOrEqual(t, msg.elapsed, 10*time.Millisecond)
+	assert.NoError(t, progressBar.Complete())
+	final := <-progressBar.printChannel
+	assert.GreaterOrEqual(t, final.elapsed, msg.elapsed)
+	assert.Equal(t, progressBar.Snapshot().Elapsed, final.elapsed)
+}
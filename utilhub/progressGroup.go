@@ -0,0 +1,47 @@
+package utilhub
+
+import "sync"
+
+// =====================================================================================================================
+//                  🛠️ Progress Group (Tool)
+// Progress Group derives the progress of a parent bar from its child bars, such as a whole test suite with one child
+// for each mode. The total of the parent is the sum of the child totals, and every step of a child moves the parent,
+// so a completed child has advanced the parent by its whole total.
+// =====================================================================================================================
+
+// ProgressGroup ⛏️ holds a parent bar which moves together with its child bars.
+type ProgressGroup struct {
+	mu     sync.Mutex   // Serializes adding the children.
+	parent *ProgressBar // The parent bar.
+}
+
+// NewProgressGroup ⛏️ creates a group with a parent bar, whose total starts at zero and grows with every child.
+func NewProgressGroup(name string, barLength int, opts ...BarOption) (*ProgressGroup, error) {
+	parent, err := NewProgressBar(name, 0, barLength, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &ProgressGroup{parent: parent}, nil
+}
+
+// Parent ⛏️ returns the parent bar, its printer is started and it is completed by the caller like any other bar.
+func (g *ProgressGroup) Parent() *ProgressBar {
+	return g.parent
+}
+
+// AddChild ⛏️ creates a child bar, and its total is added to the parent.
+func (g *ProgressGroup) AddChild(name string, total uint64, barLength int, opts ...BarOption) (*ProgressBar, error) {
+	child, err := NewProgressBar(name, 0, barLength, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// Link the child first, so setting its total also grows the parent.
+	child.parent = g.parent
+	child.SetTotal(total)
+
+	return child, nil
+}
@@ -0,0 +1,50 @@
+package utilhub
+
+import "io"
+
+// =====================================================================================================================
+//                  🛠️ Progress IO (Tool)
+// Progress IO wraps a reader or a writer, and advances a progress bar by the bytes passing through, (进度读写)
+// so a copy, a record writer or a dataset loader gets its progress without counting by itself.
+// The bar is usually created with WithUnits(Bytes) and the size of the data as its total, or a zero total to only count.
+// =====================================================================================================================
+
+// progressReader ⛏️ advances the bar by the bytes read.
+type progressReader struct {
+	r  io.Reader
+	pb *ProgressBar
+}
+
+// ProgressReader ⛏️ returns a reader which reads from r and advances the bar by every byte read.
+func ProgressReader(r io.Reader, pb *ProgressBar) io.Reader {
+	return &progressReader{r: r, pb: pb}
+}
+
+// Read ⛏️ reads from the wrapped reader, the bytes read are counted even when an error comes with them.
+func (pr *progressReader) Read(p []byte) (int, error) {
+	n, err := pr.r.Read(p)
+	if n > 0 {
+		pr.pb.advance(uint64(n))
+	}
+	return n, err
+}
+
+// progressWriter ⛏️ advances the bar by the bytes written.
+type progressWriter struct {
+	w  io.Writer
+	pb *ProgressBar
+}
+
+// ProgressWriter ⛏️ returns a writer which writes to w and advances the bar by every byte written.
+func ProgressWriter(w io.Writer, pb *ProgressBar) io.Writer {
+	return &progressWriter{w: w, pb: pb}
+}
+
+// Write ⛏️ writes to the wrapped writer, a short write only counts the bytes which are really written.
+func (pw *progressWriter) Write(p []byte) (int, error) {
+	n, err := pw.w.Write(p)
+	if n > 0 {
+		pw.pb.advance(uint64(n))
+	}
+	return n, err
+}
@@ -0,0 +1,62 @@
+package utilhub
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// shortWriter writes at most limit bytes, and then fails.
+type shortWriter struct {
+	limit int
+	buf   bytes.Buffer
+}
+
+func (sw *shortWriter) Write(p []byte) (int, error) {
+	if len(p) > sw.limit {
+		n, _ := sw.buf.Write(p[:sw.limit])
+		sw.limit = 0
+		return n, io.ErrShortWrite
+	}
+	sw.limit -= len(p)
+	return sw.buf.Write(p)
+}
+
+// Test_ProgressIO tests the reader and the writer advancing the bar by the bytes passing through.
+func Test_ProgressIO(t *testing.T) {
+	data := strings.Repeat("0123456789", 100)
+
+	// Copying through both wrappers moves both bars to the end.
+	var buf bytes.Buffer
+	readBar, err := NewProgressBar("Read", uint64(len(data)), 10, WithTracking(0), WithTimeZone("Etc/UTC"), WithUnits(Bytes), WithWriter(io.Discard))
+	assert.NoError(t, err)
+	writeBar, err := NewProgressBar("Write", 0, 10, WithTracking(0), WithTimeZone("Etc/UTC"), WithUnits(Bytes), WithWriter(io.Discard))
+	assert.NoError(t, err)
+	n, err := io.Copy(ProgressWriter(&buf, writeBar), ProgressReader(iotest.HalfReader(strings.NewReader(data)), readBar))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(data)), n)
+	assert.Equal(t, data, buf.String())
+	assert.Equal(t, uint64(len(data)), readBar.Snapshot().Completed)
+	assert.Equal(t, uint64(len(data)), writeBar.Snapshot().Completed)
+
+	// The bytes which come with an error are still counted.
+	readBar, err = NewProgressBar("Read", 0, 10, WithTracking(0), WithTimeZone("Etc/UTC"), WithWriter(io.Discard))
+	assert.NoError(t, err)
+	got, err := io.ReadAll(ProgressReader(iotest.DataErrReader(strings.NewReader(data)), readBar))
+	assert.NoError(t, err)
+	assert.Len(t, got, len(data))
+	assert.Equal(t, uint64(len(data)), readBar.Snapshot().Completed)
+
+	// A short write only counts the bytes really written.
+	writeBar, err = NewProgressBar("Write", 0, 10, WithTracking(0), WithTimeZone("Etc/UTC"), WithWriter(io.Discard))
+	assert.NoError(t, err)
+	sw := &shortWriter{limit: 25}
+	_, err = ProgressWriter(sw, writeBar).Write([]byte(data))
+	assert.True(t, errors.Is(err, io.ErrShortWrite))
+	assert.Equal(t, uint64(25), writeBar.Snapshot().Completed)
+}
@@ -0,0 +1,257 @@
+package utilhub
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// =====================================================================================================================
+//                  🛠️ Progress Report (Tool)
+// Progress Report writes the summary of a progress bar as JSON, CSV or Markdown into any writer,
+// so the nightly runs can archive machine-readable summaries next to the records, besides the ANSI table of Report.
+// =====================================================================================================================
+
+// ReportFormat ⛏️ is the format written by WriteReport.
+type ReportFormat int
+
+// The formats of WriteReport.
+const (
+	ReportJSON     ReportFormat = iota // One JSON object, with the durations in seconds.
+	ReportCSV                          // Field and value rows, with the same field names as JSON.
+	ReportMarkdown                     // A Markdown table with the same rows as the ANSI table of Report.
+)
+
+// ParseReportFormat ⛏️ returns the format named "json", "csv" or "markdown", such as from a flag.
+func ParseReportFormat(name string) (ReportFormat, error) {
+	switch strings.ToLower(name) {
+	case "json":
+		return ReportJSON, nil
+	case "csv":
+		return ReportCSV, nil
+	case "markdown", "md":
+		return ReportMarkdown, nil
+	}
+	return 0, fmt.Errorf("unknown report format %q, the formats are json, csv and markdown", name)
+}
+
+// Extension ⛏️ returns the file extension of the format, such as ".json".
+func (f ReportFormat) Extension() string {
+	switch f {
+	case ReportCSV:
+		return ".csv"
+	case ReportMarkdown:
+		return ".md"
+	}
+	return ".json"
+}
+
+// Status ⛏️ returns "running", "completed", "interrupted" or "failed".
+func (r ProgressReport) Status() string {
+	switch {
+	case r.Failure != nil:
+		return "failed"
+	case r.Interrupted:
+		return "interrupted"
+	case r.EndTime.IsZero():
+		return "running"
+	}
+	return "completed"
+}
+
+// reportRecord ⛏️ is the machine-readable form of a report, written as JSON and CSV.
+type reportRecord struct {
+	Name           string         `json:"name"`
+	Status         string         `json:"status"`
+	Failure        string         `json:"failure,omitempty"`
+	StartTime      time.Time      `json:"startTime"`
+	EndTime        time.Time      `json:"endTime"`
+	ElapsedSeconds float64        `json:"elapsedSeconds"`
+	PausedSeconds  float64        `json:"pausedSeconds"`
+	Total          uint64         `json:"total"`
+	Completed      uint64         `json:"completed"`
+	Rate           float64        `json:"rate"`
+	SmoothedRate   float64        `json:"smoothedRate"`
+	Phases         []phaseRecord  `json:"phases,omitempty"`
+	Slowest        []slowRecord   `json:"slowest,omitempty"`
+	Memory         []memoryRecord `json:"memory,omitempty"`
+}
+
+// phaseRecord ⛏️ is the machine-readable form of a phase.
+type phaseRecord struct {
+	Name           string  `json:"name"`
+	Steps          uint64  `json:"steps"`
+	ElapsedSeconds float64 `json:"elapsedSeconds"`
+	Rate           float64 `json:"rate"`
+}
+
+// slowRecord ⛏️ is the machine-readable form of a slow operation.
+type slowRecord struct {
+	Op              string  `json:"op"`
+	Key             int64   `json:"key"`
+	Height          int     `json:"height"`
+	DurationSeconds float64 `json:"durationSeconds"`
+}
+
+// memoryRecord ⛏️ is the machine-readable form of a memory release.
+type memoryRecord struct {
+	Phase           string  `json:"phase"`
+	HeapBefore      uint64  `json:"heapBefore"`
+	HeapAfter       uint64  `json:"heapAfter"`
+	SysBefore       uint64  `json:"sysBefore"`
+	SysAfter        uint64  `json:"sysAfter"`
+	DurationSeconds float64 `json:"durationSeconds"`
+}
+
+// record ⛏️ converts the report into its machine-readable form.
+func (r ProgressReport) record() reportRecord {
+	rec := reportRecord{
+		Name:           r.Name,
+		Status:         r.Status(),
+		StartTime:      r.StartTime,
+		EndTime:        r.EndTime,
+		ElapsedSeconds: r.Elapsed.Seconds(),
+		PausedSeconds:  r.Paused.Seconds(),
+		Total:          r.Total,
+		Completed:      r.Completed,
+		Rate:           r.Rate,
+		SmoothedRate:   r.Smoothed,
+	}
+	if r.Failure != nil {
+		rec.Failure = r.Failure.Error()
+	}
+	for _, phase := range r.Phases {
+		rec.Phases = append(rec.Phases, phaseRecord{Name: phase.Name, Steps: phase.Steps, ElapsedSeconds: phase.Elapsed.Seconds(), Rate: phase.Rate()})
+	}
+	for _, release := range r.Memory {
+		rec.Memory = append(rec.Memory, memoryRecord{Phase: release.Phase, HeapBefore: release.HeapBefore, HeapAfter: release.HeapAfter,
+			SysBefore: release.SysBefore, SysAfter: release.SysAfter, DurationSeconds: release.Duration.Seconds()})
+	}
+	for _, op := range r.Slowest {
+		rec.Slowest = append(rec.Slowest, slowRecord{Op: op.Op, Key: op.Key, Height: op.Height, DurationSeconds: op.Duration.Seconds()})
+	}
+	return rec
+}
+
+// WriteReport ⛏️ writes the report of a finished progress bar into w in the format.
+// Unlike Report, it prints no colors, and it does not use the writer of the progress bar.
+func (pb *ProgressBar) WriteReport(w io.Writer, format ReportFormat) error {
+	report := pb.report()
+	if report.EndTime.IsZero() {
+		return errors.New("progress is not yet complete")
+	}
+
+	switch format {
+	case ReportJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report.record())
+	case ReportCSV:
+		return writeReportCSV(w, report.record())
+	case ReportMarkdown:
+		return pb.writeReportMarkdown(w, report)
+	}
+	return fmt.Errorf("unknown report format %d", format)
+}
+
+// writeReportCSV ⛏️ writes the field and value rows, the phases are named such as "phases.Insert.rate",
+// the memory releases such as "memory.1.heapAfter", and the slowest operations such as "slowest.1.key".
+func writeReportCSV(w io.Writer, rec reportRecord) error {
+	formatFloat := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	rows := [][]string{
+		{"field", "value"},
+		{"name", rec.Name},
+		{"status", rec.Status},
+		{"failure", rec.Failure},
+		{"startTime", rec.StartTime.Format(time.RFC3339Nano)},
+		{"endTime", rec.EndTime.Format(time.RFC3339Nano)},
+		{"elapsedSeconds", formatFloat(rec.ElapsedSeconds)},
+		{"pausedSeconds", formatFloat(rec.PausedSeconds)},
+		{"total", strconv.FormatUint(rec.Total, 10)},
+		{"completed", strconv.FormatUint(rec.Completed, 10)},
+		{"rate", formatFloat(rec.Rate)},
+		{"smoothedRate", formatFloat(rec.SmoothedRate)},
+	}
+	for _, phase := range rec.Phases {
+		prefix := "phases." + phase.Name + "."
+		rows = append(rows,
+			[]string{prefix + "steps", strconv.FormatUint(phase.Steps, 10)},
+			[]string{prefix + "elapsedSeconds", formatFloat(phase.ElapsedSeconds)},
+			[]string{prefix + "rate", formatFloat(phase.Rate)},
+		)
+	}
+	for i, release := range rec.Memory {
+		prefix := "memory." + strconv.Itoa(i+1) + "."
+		rows = append(rows,
+			[]string{prefix + "phase", release.Phase},
+			[]string{prefix + "heapBefore", strconv.FormatUint(release.HeapBefore, 10)},
+			[]string{prefix + "heapAfter", strconv.FormatUint(release.HeapAfter, 10)},
+			[]string{prefix + "sysBefore", strconv.FormatUint(release.SysBefore, 10)},
+			[]string{prefix + "sysAfter", strconv.FormatUint(release.SysAfter, 10)},
+			[]string{prefix + "durationSeconds", formatFloat(release.DurationSeconds)},
+		)
+	}
+	for i, op := range rec.Slowest {
+		prefix := "slowest." + strconv.Itoa(i+1) + "."
+		rows = append(rows,
+			[]string{prefix + "op", op.Op},
+			[]string{prefix + "key", strconv.FormatInt(op.Key, 10)},
+			[]string{prefix + "height", strconv.Itoa(op.Height)},
+			[]string{prefix + "durationSeconds", formatFloat(op.DurationSeconds)},
+		)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// writeReportMarkdown ⛏️ writes the rows of the ANSI table as a Markdown table, with the numbers of the bar's format.
+func (pb *ProgressBar) writeReportMarkdown(w io.Writer, report ProgressReport) error {
+	rate := report.Smoothed
+	if rate == 0 {
+		rate = report.Rate
+	}
+	status := report.Status()
+	if report.Failure != nil {
+		status = "Failed: " + report.Failure.Error()
+	}
+	rows := [][2]string{
+		{"Task Name", report.Name},
+		{"Status", status},
+		{"Start Time", report.StartTime.Format(time.RFC1123)},
+		{"End Time", report.EndTime.Format(time.RFC1123)},
+		{"Elapsed Time", report.Elapsed.String()},
+		{"Paused Time", report.Paused.String()},
+		{"Total Tasks", pb.formatSteps(report.Total)},
+		{"Completed Tasks", pb.formatSteps(report.Completed)},
+		{"Rate", pb.formatRate(rate)},
+	}
+	for _, phase := range report.Phases {
+		value := fmt.Sprintf("%s ops/s (%s in %s)", pb.numbers.Float(phase.Rate(), 1), pb.numbers.Uint(phase.Steps), phase.Elapsed.Round(time.Millisecond))
+		rows = append(rows, [2]string{phase.Name + " Rate", value})
+	}
+	for _, release := range report.Memory {
+		rows = append(rows, [2]string{"GC " + release.Phase, release.format(pb.numbers)})
+	}
+	for i, op := range report.Slowest {
+		rows = append(rows, [2]string{fmt.Sprintf("Slowest #%d", i+1), op.String()})
+	}
+
+	// The pipes in the values would break the table, so they are escaped.
+	var b strings.Builder
+	b.WriteString("| Field | Value |\n| --- | --- |\n")
+	escape := strings.NewReplacer("|", `\|`, "\n", " ")
+	for _, row := range rows {
+		fmt.Fprintf(&b, "| %s | %s |\n", escape.Replace(row[0]), escape.Replace(row[1]))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
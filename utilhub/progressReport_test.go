@@ -0,0 +1,120 @@
+package utilhub
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"runtime/debug"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test_ProgressBar_WriteReport tests writing the report as JSON, CSV and Markdown.
+func Test_ProgressBar_WriteReport(t *testing.T) {
+	en, _ := NumberFormatByLocale("en")
+	progressBar, err := NewProgressBar("Mode 1", 12500, 10, WithTimeControl(0), WithTimeZone("Etc/UTC"), WithWriter(&bytes.Buffer{}), WithNumberFormat(en))
+	assert.NoError(t, err)
+	progressBar.Start()
+
+	// A running bar has no report yet.
+	var buf bytes.Buffer
+	assert.Error(t, progressBar.WriteReport(&buf, ReportJSON))
+
+	progressBar.AddSpecificTimes(5000)
+	progressBar.Checkpoint("Insert", 5000, 2*time.Second)
+	progressBar.Fail(errors.New("key 7 | 9 is missing"))
+	<-progressBar.WaitForPrinterStop()
+
+	// JSON keeps the raw numbers and the durations in seconds.
+	buf.Reset()
+	assert.NoError(t, progressBar.WriteReport(&buf, ReportJSON))
+	var rec reportRecord
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &rec))
+	assert.Equal(t, "Mode 1", rec.Name)
+	assert.Equal(t, "failed", rec.Status)
+	assert.Equal(t, "key 7 | 9 is missing", rec.Failure)
+	assert.Equal(t, uint64(12500), rec.Total)
+	assert.Equal(t, uint64(5000), rec.Completed)
+	assert.Equal(t, []phaseRecord{{Name: "Insert", Steps: 5000, ElapsedSeconds: 2, Rate: 2500}}, rec.Phases)
+
+	// CSV has the same field names.
+	buf.Reset()
+	assert.NoError(t, progressBar.WriteReport(&buf, ReportCSV))
+	rows, err := csv.NewReader(&buf).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"field", "value"}, rows[0])
+	assert.Contains(t, rows, []string{"status", "failed"})
+	assert.Contains(t, rows, []string{"total", "12500"})
+	assert.Contains(t, rows, []string{"phases.Insert.rate", "2500"})
+
+	// Markdown groups the numbers like the table and escapes the pipes.
+	buf.Reset()
+	assert.NoError(t, progressBar.WriteReport(&buf, ReportMarkdown))
+	assert.Contains(t, buf.String(), "| Field | Value |\n| --- | --- |\n| Task Name | Mode 1 |\n")
+	assert.Contains(t, buf.String(), "| Status | Failed: key 7 \\| 9 is missing |\n")
+	assert.Contains(t, buf.String(), "| Total Tasks | 12,500 |\n")
+	assert.Contains(t, buf.String(), "| Insert Rate | 2,500.0 ops/s (5,000 in 2s) |\n")
+	assert.NotContains(t, buf.String(), "\033[")
+}
+
+// Test_ParseReportFormat tests the format names and extensions.
+func Test_ParseReportFormat(t *testing.T) {
+	for name, want := range map[string]ReportFormat{"json": ReportJSON, "CSV": ReportCSV, "md": ReportMarkdown, "markdown": ReportMarkdown} {
+		format, err := ParseReportFormat(name)
+		assert.NoError(t, err)
+		assert.Equal(t, want, format)
+	}
+	_, err := ParseReportFormat("xml")
+	assert.Error(t, err)
+	assert.Equal(t, ".json", ReportJSON.Extension())
+	assert.Equal(t, ".csv", ReportCSV.Extension())
+	assert.Equal(t, ".md", ReportMarkdown.Extension())
+	assert.Equal(t, "completed", ProgressReport{EndTime: time.Now()}.Status())
+	assert.Equal(t, "running", ProgressReport{}.Status())
+}
+
+// Test_ProgressBar_MemoryRelease tests releasing the memory at a phase boundary and reporting it.
+func Test_ProgressBar_MemoryRelease(t *testing.T) {
+	// The modes are parsed from the config, and off releases nothing.
+	for name, expected := range map[string]ReleaseMode{"off": ReleaseOff, "GC": ReleaseGC, "os": ReleaseOS} {
+		mode, err := ParseReleaseMode(name)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, mode)
+	}
+	_, err := ParseReleaseMode("all")
+	assert.Error(t, err)
+	_, released := ReleaseMemory("before run", ReleaseOff)
+	assert.False(t, released)
+
+	// The garbage left behind is collected, and no background collection takes it before.
+	defer debug.SetGCPercent(debug.SetGCPercent(-1))
+	garbage := make([][]byte, 64)
+	for i := range garbage {
+		garbage[i] = make([]byte, 1<<20)
+	}
+	garbage = nil
+	release, released := ReleaseMemory("before run", ReleaseOS)
+	assert.True(t, released)
+	assert.Equal(t, "before run", release.Phase)
+	assert.Greater(t, release.Freed(), uint64(32<<20))
+
+	// The report shows every release.
+	var buf bytes.Buffer
+	progressBar, err := NewProgressBar("Mode 1", 10, 10, WithTimeControl(0), WithTimeZone("Etc/UTC"), WithWriter(&buf))
+	assert.NoError(t, err)
+	progressBar.Start()
+	progressBar.AddMemoryRelease(MemoryRelease{Phase: "before run", HeapBefore: 3 << 30, HeapAfter: 120 << 20, Duration: 410 * time.Millisecond})
+	assert.NoError(t, progressBar.Complete())
+	<-progressBar.WaitForPrinterStop()
+	assert.NoError(t, progressBar.Report(50))
+	assert.Contains(t, buf.String(), "| GC before run        | heap 3.0 GiB -> 120.0 MiB, freed 2.9 GiB in 410ms")
+
+	buf.Reset()
+	assert.NoError(t, progressBar.WriteReport(&buf, ReportJSON))
+	var rec reportRecord
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &rec))
+	assert.Equal(t, []memoryRecord{{Phase: "before run", HeapBefore: 3 << 30, HeapAfter: 120 << 20, DurationSeconds: 0.41}}, rec.Memory)
+}
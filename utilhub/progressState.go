@@ -0,0 +1,120 @@
+package utilhub
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/panhongrainbow/go-algorithm/simhub"
+)
+
+// =====================================================================================================================
+//                  🛠️ Progress State (Tool)
+// Progress State saves the progress of a bar into a file and restores it in a new process,
+// so a multi-day endurance test which is restarted continues the bar instead of restarting at 0%.
+// =====================================================================================================================
+
+// ProgressState ⛏️ is the saved progress of a progress bar.
+type ProgressState struct {
+	Name      string        `json:"name"`      // The name of the progress bar.
+	BarLength int           `json:"barLength"` // The visual length of the progress bar.
+	Total     uint64        `json:"total"`     // The total number of steps.
+	Current   uint64        `json:"current"`   // The number of completed steps.
+	StartTime time.Time     `json:"startTime"` // When the progress bar started in the first process.
+	Paused    time.Duration `json:"paused"`    // The total paused time until the state was saved.
+	SavedAt   time.Time     `json:"savedAt"`   // When the state was saved.
+}
+
+// SaveState ⛏️ writes the progress into a file, which can be restored with ResumeProgressBar.
+// The file is written to a temporary file first and then renamed, so a crash never leaves a broken state behind.
+func (pb *ProgressBar) SaveState(path string) error {
+	pb.mu.Lock()
+	now := simhub.Now()
+	state := ProgressState{
+		Name:      pb.name,
+		BarLength: pb.barLength,
+		Total:     atomic.LoadUint64(&pb.total),
+		Current:   atomic.LoadUint64(&pb.currentProcess),
+		StartTime: pb.startTime,
+		Paused:    pb.pausedTime,
+		SavedAt:   now,
+	}
+	// A pause still going on is counted until now.
+	if pb.paused {
+		state.Paused += now.Sub(pb.pausedAt)
+	}
+	pb.mu.Unlock()
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// A simulation writes into memory, where a write is atomic already.
+	if simhub.Active() {
+		return simhub.WriteFile(path, data, filePermission)
+	}
+
+	// Write to a temporary file in the same directory, so the rename is atomic.
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadProgressState ⛏️ reads a state written by SaveState.
+func LoadProgressState(path string) (state ProgressState, err error) {
+	data, err := simhub.ReadFile(path)
+	if err != nil {
+		return
+	}
+	if err = json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("parse progress state %s: %w", path, err)
+	}
+	if state.Current > state.Total {
+		return state, fmt.Errorf("progress state %s: current %d is over total %d", path, state.Current, state.Total)
+	}
+	return
+}
+
+// ResumeProgressBar ⛏️ creates a progress bar which continues from the state saved in the file.
+// The options are applied like NewProgressBar, since they are not saved.
+// The time while no process was running is counted as paused, so the throughput and the ETA are not dragged down.
+func ResumeProgressBar(path string, opts ...BarOption) (*ProgressBar, error) {
+	state, err := LoadProgressState(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pb, err := NewProgressBar(state.Name, state.Total, state.BarLength, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Restore the progress and the timing, and force the next refresh to show it.
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	atomic.StoreUint64(&pb.currentProcess, state.Current)
+	atomic.StoreInt64(&pb.lastFilledLength, -1)
+	pb.startTime = state.StartTime.In(pb.location)
+	pb.sampleAt, pb.sampleDone = simhub.Now(), state.Current
+	pb.pausedTime = state.Paused
+	if downtime := simhub.Since(state.SavedAt); downtime > 0 {
+		pb.pausedTime += downtime
+	}
+
+	return pb, nil
+}
@@ -0,0 +1,49 @@
+package utilhub
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+)
+
+// =====================================================================================================================
+//                  🛠️ Progress Testing (Tool)
+// Progress Testing sends the output of a progress bar through t.Log, so a long test run under CI, (测试日志输出)
+// such as the bptree accuracy tests, keeps the go test output well-formed: the lines show with -v or when the test fails,
+// and they stay under the test which printed them, even when the tests run in parallel.
+// =====================================================================================================================
+
+// testingWriter ⛏️ logs every complete line written into it with t.Log.
+type testingWriter struct {
+	mu      sync.Mutex
+	t       testing.TB
+	pending []byte // The start of a line whose end has not been written yet.
+}
+
+// Write ⛏️ logs the complete lines, and keeps the rest until its end is written.
+func (w *testingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pending = append(w.pending, p...)
+	for {
+		end := bytes.IndexByte(w.pending, '\n')
+		if end < 0 {
+			return len(p), nil
+		}
+		w.t.Log(string(bytes.TrimRight(w.pending[:end], "\r")))
+		w.pending = w.pending[end+1:]
+	}
+}
+
+// AttachTesting ⛏️ sends the refresh lines and the report of the progress bar through t.Log instead of the writer.
+// Every refresh becomes a timestamped plain line like in log mode, without the colors, since t.Log is not a terminal.
+// It must be called before Start, and the bar must be completed or closed before the test ends,
+// since t.Log can not be called afterwards.
+func (pb *ProgressBar) AttachTesting(t testing.TB) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	pb.writer = &testingWriter{t: t}
+	pb.logMode = true
+	pb.palette = MonochromePalette
+	pb.barColor, pb.resetColor, pb.thresholds = "", "", nil
+}
@@ -0,0 +1,62 @@
+package utilhub
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// logRecorder records the lines logged with t.Log.
+type logRecorder struct {
+	testing.TB
+	mu    sync.Mutex
+	lines []string
+}
+
+// Log records the line instead of logging it.
+func (r *logRecorder) Log(args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lines = append(r.lines, fmt.Sprint(args...))
+}
+
+// Test_ProcessBar_AttachTesting tests sending the refresh lines and the report of a progress bar through t.Log.
+func Test_ProcessBar_AttachTesting(t *testing.T) {
+	recorder := &logRecorder{TB: t}
+	progressBar, err := NewProgressBar("Run", 4, 4, WithTracking(0), WithTimeControl(0), WithDisplay(BrightGreen), WithTimeZone("Etc/UTC"))
+	assert.NoError(t, err)
+	progressBar.AttachTesting(recorder)
+	progressBar.Start()
+	progressBar.AddSpecificTimes(2)
+	assert.NoError(t, progressBar.Complete())
+	<-progressBar.WaitForPrinterStop()
+	assert.NoError(t, progressBar.Report(32))
+
+	// Every line is logged on its own, without a line break, a carriage return or a color code.
+	assert.Greater(t, len(recorder.lines), 3)
+	assert.True(t, strings.HasSuffix(recorder.lines[0], " Run: [████] 100%"), recorder.lines[0])
+	for _, line := range recorder.lines {
+		assert.NotContains(t, line, "\n")
+		assert.NotContains(t, line, "\r")
+		assert.NotContains(t, line, "\033[")
+	}
+
+	// A line written in pieces is logged once it ends.
+	writer := &testingWriter{t: recorder}
+	recorder.lines = nil
+	_, _ = fmt.Fprint(writer, "first ")
+	assert.Empty(t, recorder.lines)
+	_, _ = fmt.Fprint(writer, "line\r\nsecond line\nthird")
+	assert.Equal(t, []string{"first line", "second line"}, recorder.lines)
+
+	// A real test logs the lines too.
+	progressBar, err = NewProgressBar("Run", 4, 4, WithTracking(0), WithTimeControl(0), WithTimeZone("Etc/UTC"))
+	assert.NoError(t, err)
+	progressBar.AttachTesting(t)
+	progressBar.Start()
+	assert.NoError(t, progressBar.Complete())
+	<-progressBar.WaitForPrinterStop()
+}
@@ -1,8 +1,7 @@
 package utilhub
 
 import (
-	"math/rand"
-	"time"
+	"github.com/panhongrainbow/go-algorithm/simhub"
 )
 
 // =====================================================================================================================
@@ -13,9 +12,8 @@ import (
 // ShuffleSlice ⛏️ randomly shuffles the elements in the slice.
 func ShuffleSlice(slice []int64) {
 
-	// Initialize a random number generator.
-	source := rand.NewSource(time.Now().UnixNano())
-	random := rand.New(source)
+	// Initialize a random number generator, seeded by the simulation when one is running.
+	random := simhub.NewRand()
 
 	// Iterate through the slice in reverse order, starting from the last element.
 	for i := len(slice) - 1; i > 0; i-- {
@@ -0,0 +1,73 @@
+package utilhub
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// =====================================================================================================================
+//                  🛠️ Record Log (Tool)
+// Record Log writes the detailed log of a test mode, such as its milestones, its validation results and its warnings,
+// into a file of the record directory, so stdout is left to the progress bars. (模式日志)
+// The records are leveled and structured, one line of key=value pairs each, and the level comes from the config.
+// =====================================================================================================================
+
+// LevelOff ⛏️ is the level above every other level, which logs nothing.
+const LevelOff = slog.Level(math.MaxInt32)
+
+// ParseLogLevel ⛏️ returns the level named "debug", "info", "warn", "error" or "off", such as from a config.
+func ParseLogLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	case "off":
+		return LevelOff, nil
+	}
+	return LevelOff, fmt.Errorf("unknown log level %q, the levels are debug, info, warn, error and off", name)
+}
+
+// RecordLog ⛏️ is a leveled logger writing into a log file, it is safe for concurrent use like any slog.Logger.
+type RecordLog struct {
+	*slog.Logger
+	file io.WriteCloser // The log file, nil when the level is LevelOff.
+}
+
+// OpenLog ⛏️ opens the log file name.log in the current directory, such as mode1.log, and logs the records at the level and above.
+// The records are appended, so the prepare, the verify and the run phases of a mode can log into the same file.
+// LevelOff creates no file at all.
+func (fn FileNode) OpenLog(name string, level slog.Level) (*RecordLog, error) {
+	// Check if a previous error has occurred and return it if so.
+	if fn.err != nil {
+		return nil, fn.err
+	}
+
+	// Discard everything without creating the file.
+	if level >= LevelOff {
+		return &RecordLog{Logger: slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: level}))}, nil
+	}
+
+	file, err := fn.fs().OpenFile(filepath.Join(fn.transfer, name+".log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, filePermission)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log %s: %w", name, err)
+	}
+	return &RecordLog{Logger: slog.New(slog.NewTextHandler(file, &slog.HandlerOptions{Level: level})), file: file}, nil
+}
+
+// Close ⛏️ closes the log file, the records logged afterwards are lost.
+func (l *RecordLog) Close() error {
+	if l.file == nil {
+		return nil
+	}
+	return l.file.Close()
+}
@@ -0,0 +1,49 @@
+package utilhub
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test_RecordLog tests writing the leveled records of a mode into its log file.
+func Test_RecordLog(t *testing.T) {
+	// The levels are parsed from the config.
+	for name, expected := range map[string]slog.Level{"debug": slog.LevelDebug, "INFO": slog.LevelInfo, "warn": slog.LevelWarn, "error": slog.LevelError, "off": LevelOff} {
+		level, err := ParseLogLevel(name)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, level)
+	}
+	_, err := ParseLogLevel("trace")
+	assert.Error(t, err)
+
+	// The records below the level are dropped, and the phases of a mode append to the same file.
+	dir := FileNode{}.Goto(t.TempDir())
+	log, err := dir.OpenLog("mode1", slog.LevelInfo)
+	assert.NoError(t, err)
+	log.Debug("tree verified", "operations", 1000)
+	log.Info("run started", "width", 3)
+	assert.NoError(t, log.Close())
+	log, err = dir.OpenLog("mode1", slog.LevelInfo)
+	assert.NoError(t, err)
+	log.Warn("run interrupted", "operations", 2000)
+	assert.NoError(t, log.Close())
+
+	data, err := os.ReadFile(filepath.Join(dir.Path(), "mode1.log"))
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `level=INFO msg="run started" width=3`)
+	assert.Contains(t, lines[1], `level=WARN msg="run interrupted" operations=2000`)
+
+	// Off creates no file.
+	log, err = dir.OpenLog("mode2", LevelOff)
+	assert.NoError(t, err)
+	log.Error("lost")
+	assert.NoError(t, log.Close())
+	assert.NoFileExists(t, filepath.Join(dir.Path(), "mode2.log"))
+}
@@ -0,0 +1,128 @@
+package utilhub
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"slices"
+)
+
+// =====================================================================================================================
+//                  🛠️ Record Mask (Tool)
+// Record Mask rewrites the keys of a recorded trace, so a failure trace from a production-derived key set can be shared
+// without the real identifiers. The order of the keys is kept, so the masked trace builds a tree of the same shape
+// and fails in the same way. The sign of a key, which marks an insert or a delete, is kept too. (遮蔽键值，保留顺序)
+// =====================================================================================================================
+
+// KeyMasker ⛏️ rewrites the keys of a trace into other keys with the same order.
+// The same key always becomes the same masked key within one call, so a delete still matches its insert.
+type KeyMasker func(keys []int64) ([]int64, error)
+
+// OffsetMasker ⛏️ adds the offset to every key, and subtracts it from every delete.
+// It only hides the size of the keys, since the differences between the keys stay the same.
+func OffsetMasker(offset int64) KeyMasker {
+	return func(keys []int64) ([]int64, error) {
+		if offset < 0 {
+			return nil, fmt.Errorf("offset %d is negative, it could turn inserts into deletes", offset)
+		}
+		masked := make([]int64, len(keys))
+		for i, key := range keys {
+			magnitude, sign := keyMagnitude(key)
+			if magnitude < 0 {
+				return nil, errMinKey
+			}
+			if magnitude > math.MaxInt64-offset {
+				return nil, fmt.Errorf("key %d overflows with the offset %d", key, offset)
+			}
+			masked[i] = sign * (magnitude + offset)
+		}
+		return masked, nil
+	}
+}
+
+// HashMasker ⛏️ replaces every key with a sum of secret random gaps, one gap for each key up to it.
+// The gaps come from the HMAC-SHA256 of the keys, so the masked keys can not be reversed without the secret.
+// The masked value of a key depends on the smaller keys in the same trace, so the related traces must be masked together.
+func HashMasker(secret []byte) KeyMasker {
+	return func(keys []int64) ([]int64, error) {
+		if len(secret) == 0 {
+			return nil, errors.New("the secret of the hash masker is empty")
+		}
+
+		// Sort the distinct magnitudes, so every one gets a larger masked value than the ones before it.
+		magnitudes := make([]int64, 0, len(keys))
+		for _, key := range keys {
+			magnitude, _ := keyMagnitude(key)
+			if magnitude < 0 {
+				return nil, errMinKey
+			}
+			magnitudes = append(magnitudes, magnitude)
+		}
+		slices.Sort(magnitudes)
+		magnitudes = slices.Compact(magnitudes)
+
+		// Keep the sum of all gaps within int64, with every gap at least 1 so the order is strict.
+		maxGap := uint64(min(math.MaxInt64/int64(len(magnitudes)+1), 1<<32))
+		mac := hmac.New(sha256.New, secret)
+		mapping := make(map[int64]int64, len(magnitudes))
+		next := int64(-1) // The first key may become 0, which is still an insert.
+		for _, magnitude := range magnitudes {
+			mac.Reset()
+			_, _ = mac.Write(binary.LittleEndian.AppendUint64(nil, uint64(magnitude)))
+			next += 1 + int64(binary.LittleEndian.Uint64(mac.Sum(nil))%maxGap)
+			mapping[magnitude] = next
+		}
+
+		masked := make([]int64, len(keys))
+		for i, key := range keys {
+			magnitude, sign := keyMagnitude(key)
+			masked[i] = sign * mapping[magnitude]
+		}
+		return masked, nil
+	}
+}
+
+// errMinKey ⛏️ is returned for math.MinInt64, which has no magnitude within int64.
+var errMinKey = fmt.Errorf("key %d can not be masked", int64(math.MinInt64))
+
+// keyMagnitude ⛏️ splits a recorded key into the key itself and its sign, -1 for a delete and 1 for an insert.
+func keyMagnitude(key int64) (magnitude, sign int64) {
+	if key < 0 {
+		return -key, -1
+	}
+	return key, 1
+}
+
+// MaskRecord ⛏️ reads the record file src in the current directory, masks its keys and writes them into dst.
+// The whole record is read first, so dst may be the same as src.
+func (fn FileNode) MaskRecord(src, dst string, order binary.ByteOrder, masker KeyMasker) error {
+	// Check if a previous error has occurred and return it if so.
+	if fn.err != nil {
+		return fn.err
+	}
+
+	// Read the keys of the record.
+	data, err := fn.fs().ReadFile(filepath.Join(fn.transfer, src))
+	if err != nil {
+		return err
+	}
+	keys, err := BytesToInt64Slice(data, order)
+	if err != nil {
+		return fmt.Errorf("read record %s: %w", src, err)
+	}
+
+	// Mask the keys and write them with the same byte order.
+	if keys, err = masker(keys); err != nil {
+		return fmt.Errorf("mask record %s: %w", src, err)
+	}
+	if data, err = Int64SliceToBytes(keys, order); err != nil {
+		return fmt.Errorf("write record %s: %w", dst, err)
+	}
+	unlock := lockPath(filepath.Join(fn.transfer, dst))
+	defer unlock()
+	return fn.fs().WriteFile(filepath.Join(fn.transfer, dst), data, filePermission)
+}
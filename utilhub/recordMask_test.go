@@ -0,0 +1,96 @@
+package utilhub
+
+import (
+	"encoding/binary"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// assertSameOrder checks that the masked trace keeps the signs and the order of the keys.
+func assertSameOrder(t *testing.T, keys, masked []int64) {
+	assert.Equal(t, len(keys), len(masked))
+	for i := range keys {
+		assert.Equal(t, keys[i] < 0, masked[i] < 0, "the sign of key %d changed", keys[i])
+		for j := range keys {
+			a, _ := keyMagnitude(keys[i])
+			b, _ := keyMagnitude(keys[j])
+			x, _ := keyMagnitude(masked[i])
+			y, _ := keyMagnitude(masked[j])
+			assert.Equal(t, a < b, x < y)
+			assert.Equal(t, a == b, x == y)
+		}
+	}
+}
+
+// Test_OffsetMasker tests masking the keys by an offset.
+func Test_OffsetMasker(t *testing.T) {
+	keys := []int64{10, 30, 20, -30, 0, -10}
+	masked, err := OffsetMasker(1000)(keys)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1010, 1030, 1020, -1030, 1000, -1010}, masked)
+
+	// The offset can not be negative or overflow, and math.MinInt64 has no magnitude.
+	_, err = OffsetMasker(-1)(keys)
+	assert.Error(t, err)
+	_, err = OffsetMasker(2)([]int64{math.MaxInt64 - 1})
+	assert.Error(t, err)
+	_, err = OffsetMasker(0)([]int64{math.MinInt64})
+	assert.Error(t, err)
+}
+
+// Test_HashMasker tests masking the keys by secret gaps.
+func Test_HashMasker(t *testing.T) {
+	// A trace of random inserts and the deletes of some of them.
+	rng := rand.New(rand.NewSource(1))
+	var keys []int64
+	for i := 0; i < 300; i++ {
+		key := rng.Int63n(1 << 40)
+		keys = append(keys, key)
+		if i%3 == 0 {
+			keys = append(keys, -key)
+		}
+	}
+
+	masked, err := HashMasker([]byte("secret"))(keys)
+	assert.NoError(t, err)
+	assertSameOrder(t, keys, masked)
+	assert.NotEqual(t, keys, masked)
+
+	// The same secret gives the same keys, another secret gives other keys.
+	again, err := HashMasker([]byte("secret"))(keys)
+	assert.NoError(t, err)
+	assert.Equal(t, masked, again)
+	other, err := HashMasker([]byte("other"))(keys)
+	assert.NoError(t, err)
+	assert.NotEqual(t, masked, other)
+
+	// The secret can not be empty.
+	_, err = HashMasker(nil)(keys)
+	assert.Error(t, err)
+}
+
+// Test_MaskRecord tests masking a record file in place.
+func Test_MaskRecord(t *testing.T) {
+	dir := t.TempDir()
+	keys := []int64{5, 7, -5, 9, -7, -9}
+	data, err := Int64SliceToBytes(keys, binary.LittleEndian)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "mode1.do_not_open"), data, 0644))
+
+	node := FileNode{}.Goto(dir)
+	assert.NoError(t, node.MaskRecord("mode1.do_not_open", "mode1.do_not_open", binary.LittleEndian, HashMasker([]byte("secret"))))
+
+	data, err = os.ReadFile(filepath.Join(dir, "mode1.do_not_open"))
+	assert.NoError(t, err)
+	masked, err := BytesToInt64Slice(data, binary.LittleEndian)
+	assert.NoError(t, err)
+	assertSameOrder(t, keys, masked)
+
+	// A missing record is an error.
+	assert.Error(t, node.MaskRecord("missing", "out", binary.LittleEndian, OffsetMasker(1)))
+}
@@ -0,0 +1,188 @@
+package utilhub
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// =====================================================================================================================
+//                  🛠️ Shutdown Handler (Tool)
+// Shutdown Handler catches SIGINT and SIGTERM during the long accuracy and endurance runs, and shuts down in order:
+// it stops the workers, flushes what they wrote, marks the progress bars as interrupted and writes the artifacts,
+// so a run stopped by Ctrl+C or by the CI leaves usable records behind instead of half-written files.
+// =====================================================================================================================
+
+// ShutdownHandler ⛏️ runs the shutdown steps once, when the first signal arrives.
+type ShutdownHandler struct {
+	ctx       context.Context    // Cancelled when the shutdown begins, so the workers know to stop.
+	cancel    context.CancelFunc // Cancels ctx.
+	signals   chan os.Signal     // Receives the caught signals.
+	stop      chan struct{}      // Closed by Stop, when the run ends normally.
+	done      chan struct{}      // Closed after the shutdown steps have run.
+	grace     time.Duration      // How long the workers are waited for.
+	writer    io.Writer          // Destination of the shutdown messages, stderr by default.
+	exit      func(code int)     // Ends the process, os.Exit by default.
+	workers   sync.WaitGroup     // The running workers.
+	bars      []*ProgressBar     // The progress bars to be interrupted.
+	flushes   []shutdownStep     // The steps flushing the records, run after the workers stop.
+	artifacts []shutdownStep     // The steps writing the artifacts, run after the bars are interrupted.
+	stopOnce  sync.Once          // Makes Stop safe to call twice.
+	mu        sync.Mutex         // Protects bars, flushes and artifacts.
+}
+
+// shutdownStep ⛏️ is a named step of the shutdown, the name is printed when the step fails.
+type shutdownStep struct {
+	name string
+	fn   func() error
+}
+
+// NewShutdownHandler ⛏️ starts catching SIGINT and SIGTERM until Stop is called.
+// grace is how long the workers are waited for, 0 means 10 seconds. writer receives the messages, nil means stderr.
+func NewShutdownHandler(grace time.Duration, writer io.Writer) *ShutdownHandler {
+	if grace <= 0 {
+		grace = 10 * time.Second
+	}
+	if writer == nil {
+		writer = os.Stderr
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	h := &ShutdownHandler{
+		ctx:     ctx,
+		cancel:  cancel,
+		signals: make(chan os.Signal, 2),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+		grace:   grace,
+		writer:  writer,
+		exit:    os.Exit,
+	}
+	signal.Notify(h.signals, syscall.SIGINT, syscall.SIGTERM)
+	go h.listen()
+	return h
+}
+
+// Context ⛏️ returns the context cancelled when the shutdown begins, the workers stop when it is done.
+func (h *ShutdownHandler) Context() context.Context {
+	return h.ctx
+}
+
+// Worker ⛏️ registers a running worker, and the returned function marks it stopped.
+// The shutdown waits for the registered workers before it flushes anything, but no longer than the grace period.
+func (h *ShutdownHandler) Worker() (stopped func()) {
+	h.workers.Add(1)
+	var once sync.Once
+	return func() { once.Do(h.workers.Done) }
+}
+
+// TrackBar ⛏️ registers a progress bar, which is interrupted by the shutdown unless it is finished already.
+func (h *ShutdownHandler) TrackBar(pb *ProgressBar) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.bars = append(h.bars, pb)
+}
+
+// OnFlush ⛏️ registers a step flushing the records, such as syncing a file. The steps run in the registered order.
+func (h *ShutdownHandler) OnFlush(name string, fn func() error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.flushes = append(h.flushes, shutdownStep{name: name, fn: fn})
+}
+
+// OnArtifact ⛏️ registers a step writing the artifacts of the failed run, such as the reports of the interrupted bars.
+// The steps run in the registered order, after the bars are interrupted.
+func (h *ShutdownHandler) OnArtifact(name string, fn func() error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.artifacts = append(h.artifacts, shutdownStep{name: name, fn: fn})
+}
+
+// Interrupted ⛏️ reports whether a signal has started the shutdown.
+func (h *ShutdownHandler) Interrupted() bool {
+	return h.ctx.Err() != nil
+}
+
+// Stop ⛏️ stops catching the signals when the run ends normally, and the signals act as usual again.
+// When the shutdown has begun, Stop waits for it, so the run does not end before the shutdown steps.
+func (h *ShutdownHandler) Stop() {
+	h.stopOnce.Do(func() {
+		signal.Stop(h.signals)
+		close(h.stop)
+	})
+	if h.Interrupted() {
+		<-h.done
+	}
+}
+
+// listen ⛏️ waits for the first signal or for Stop.
+func (h *ShutdownHandler) listen() {
+	select {
+	case sig := <-h.signals:
+		h.shutdown(sig)
+	case <-h.stop:
+	}
+}
+
+// shutdown ⛏️ runs the shutdown steps in order and ends the process with 128 plus the signal number, like a shell.
+func (h *ShutdownHandler) shutdown(sig os.Signal) {
+	code := 1
+	if number, ok := sig.(syscall.Signal); ok {
+		code = 128 + int(number)
+	}
+
+	// 1. Stop the workers, and wait for them within the grace period.
+	h.cancel()
+	fmt.Fprintf(h.writer, "\nreceived %v, shutting down; send it again to exit at once\n", sig)
+
+	// A second signal means the user does not want to wait for the shutdown.
+	go func() {
+		select {
+		case <-h.signals:
+			h.exit(code)
+		case <-h.done:
+		}
+	}()
+
+	stopped := make(chan struct{})
+	go func() {
+		h.workers.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(h.grace):
+		fmt.Fprintf(h.writer, "workers did not stop within %v, shutting down anyway\n", h.grace)
+	}
+
+	h.mu.Lock()
+	flushes, bars, artifacts := h.flushes, h.bars, h.artifacts
+	h.mu.Unlock()
+
+	// 2. Flush the records, so nothing written by the workers is lost.
+	h.run(flushes)
+
+	// 3. Mark the progress bars as interrupted, the finished ones stay as they are.
+	for _, pb := range bars {
+		pb.Interrupt()
+	}
+
+	// 4. Write the artifacts, which can read the reports of the interrupted bars.
+	h.run(artifacts)
+
+	h.exit(code)
+	close(h.done)
+}
+
+// run ⛏️ runs the steps in order, and a failed step is printed without stopping the others.
+func (h *ShutdownHandler) run(steps []shutdownStep) {
+	for _, step := range steps {
+		if err := step.fn(); err != nil {
+			fmt.Fprintf(h.writer, "shutdown step %s failed: %v\n", step.name, err)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+//go:build !windows
+
+package utilhub
+
+import (
+	"bytes"
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test_ShutdownHandler tests the shutdown steps run in order after a signal.
+func Test_ShutdownHandler(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewShutdownHandler(time.Second, &buf)
+	defer handler.Stop()
+
+	// Capture the exit code instead of ending the test process.
+	exited := make(chan int, 1)
+	handler.exit = func(code int) { exited <- code }
+
+	// A worker stops when the context is done.
+	var order []string
+	stopped := handler.Worker()
+	go func() {
+		<-handler.Context().Done()
+		order = append(order, "worker")
+		stopped()
+	}()
+
+	progressBar, err := NewProgressBar("Run", 10, 10, WithTimeControl(0), WithWriter(&bytes.Buffer{}))
+	assert.NoError(t, err)
+	progressBar.Start()
+	progressBar.AddSpecificTimes(3)
+	handler.TrackBar(progressBar)
+
+	handler.OnFlush("records", func() error {
+		order = append(order, "flush")
+		return errors.New("disk full")
+	})
+	handler.OnArtifact("reports", func() error {
+		order = append(order, "artifact")
+		assert.True(t, progressBar.Snapshot().Interrupted)
+		return nil
+	})
+
+	// Send SIGTERM to the test process itself, which is caught by the handler.
+	assert.False(t, handler.Interrupted())
+	assert.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGTERM))
+	select {
+	case code := <-exited:
+		assert.Equal(t, 128+int(syscall.SIGTERM), code)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the handler did not exit after the signal")
+	}
+
+	// The steps ran in order, and a failed step did not stop the others.
+	assert.True(t, handler.Interrupted())
+	assert.Equal(t, []string{"worker", "flush", "artifact"}, order)
+	assert.Contains(t, buf.String(), "shutdown step records failed: disk full")
+	<-progressBar.WaitForPrinterStop()
+	assert.Equal(t, uint64(3), progressBar.Snapshot().Completed)
+}
+
+// Test_ShutdownHandler_Stop tests that a stopped handler runs no steps.
+func Test_ShutdownHandler_Stop(t *testing.T) {
+	handler := NewShutdownHandler(0, &bytes.Buffer{})
+	handler.exit = func(int) { t.Error("a stopped handler must not exit") }
+	handler.OnFlush("records", func() error {
+		t.Error("a stopped handler must not flush")
+		return nil
+	})
+	handler.Stop()
+	handler.Stop()
+	assert.False(t, handler.Interrupted())
+}
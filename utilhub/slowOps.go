@@ -0,0 +1,90 @@
+package utilhub
+
+import (
+	"cmp"
+	"container/heap"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// =====================================================================================================================
+//                  🛠️ Slow Ops (Tool)
+// Slow Ops keeps the N slowest operations of an instrumented run with their keys and the tree height at the time,
+// so the report can point at the pathological keys, such as the ones triggering cascading merges. (最慢的操作)
+// The operations are kept in a bounded min-heap, so the fastest of the kept ones is replaced first.
+// =====================================================================================================================
+
+// SlowOp ⛏️ is one timed operation.
+type SlowOp struct {
+	Op       string        // The operation, such as Insert or Delete.
+	Key      int64         // The key of the operation.
+	Height   int           // The height of the tree right after the operation.
+	Duration time.Duration // How long the operation took.
+}
+
+// String ⛏️ describes the operation, such as "Delete key 42 at height 5 in 1.2ms".
+func (op SlowOp) String() string {
+	return fmt.Sprintf("%s key %d at height %d in %s", op.Op, op.Key, op.Height, op.Duration)
+}
+
+// SlowOps ⛏️ keeps the slowest operations observed, it is safe for concurrent use and a nil SlowOps keeps nothing.
+type SlowOps struct {
+	mu    sync.Mutex
+	limit int
+	ops   slowOpHeap
+}
+
+// NewSlowOps ⛏️ keeps the limit slowest operations, it returns nil when the limit is not positive.
+func NewSlowOps(limit int) *SlowOps {
+	if limit <= 0 {
+		return nil
+	}
+	return &SlowOps{limit: limit, ops: make(slowOpHeap, 0, limit)}
+}
+
+// Observe ⛏️ records one operation, it is kept only when it is slower than the fastest kept one.
+func (s *SlowOps) Observe(op string, key int64, height int, duration time.Duration) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := SlowOp{Op: op, Key: key, Height: height, Duration: duration}
+	switch {
+	case len(s.ops) < s.limit:
+		heap.Push(&s.ops, entry)
+	case duration > s.ops[0].Duration:
+		s.ops[0] = entry
+		heap.Fix(&s.ops, 0)
+	}
+}
+
+// Slowest ⛏️ returns the kept operations, the slowest first.
+func (s *SlowOps) Slowest() []SlowOp {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ops := slices.Clone([]SlowOp(s.ops))
+	slices.SortFunc(ops, func(a, b SlowOp) int { return cmp.Compare(b.Duration, a.Duration) })
+	return ops
+}
+
+// slowOpHeap ⛏️ is a min-heap by duration, the fastest kept operation is at the top.
+type slowOpHeap []SlowOp
+
+func (h slowOpHeap) Len() int           { return len(h) }
+func (h slowOpHeap) Less(i, j int) bool { return h[i].Duration < h[j].Duration }
+func (h slowOpHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *slowOpHeap) Push(x any)        { *h = append(*h, x.(SlowOp)) }
+func (h *slowOpHeap) Pop() any {
+	old := *h
+	last := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return last
+}
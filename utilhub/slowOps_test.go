@@ -0,0 +1,65 @@
+package utilhub
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test_SlowOps tests keeping the slowest operations in a bounded heap.
+func Test_SlowOps(t *testing.T) {
+	// A limit of 0 keeps nothing, and the nil SlowOps is safe to use.
+	none := NewSlowOps(0)
+	assert.Nil(t, none)
+	none.Observe("Insert", 1, 1, time.Second)
+	assert.Empty(t, none.Slowest())
+
+	// Only the 3 slowest operations are kept, the slowest first.
+	slowOps := NewSlowOps(3)
+	for i, duration := range []time.Duration{5, 1, 9, 3, 7, 2} {
+		slowOps.Observe("Insert", int64(i), i%3+1, duration*time.Millisecond)
+	}
+	slowOps.Observe("Delete", 42, 5, 8*time.Millisecond)
+	assert.Equal(t, []SlowOp{
+		{Op: "Insert", Key: 2, Height: 3, Duration: 9 * time.Millisecond},
+		{Op: "Delete", Key: 42, Height: 5, Duration: 8 * time.Millisecond},
+		{Op: "Insert", Key: 4, Height: 2, Duration: 7 * time.Millisecond},
+	}, slowOps.Slowest())
+	assert.Equal(t, "Delete key 42 at height 5 in 8ms", slowOps.Slowest()[1].String())
+}
+
+// Test_SlowOps_Report tests printing and writing the slowest operations in the report.
+func Test_SlowOps_Report(t *testing.T) {
+	slowOps := NewSlowOps(2)
+	var buf bytes.Buffer
+	progressBar, err := NewProgressBar("Mode 1", 10, 10, WithTimeControl(0), WithTimeZone("Etc/UTC"), WithWriter(&buf), WithSlowOps(slowOps))
+	assert.NoError(t, err)
+	progressBar.Start()
+	slowOps.Observe("Insert", 7, 2, 3*time.Millisecond)
+	slowOps.Observe("Delete", 9, 3, 5*time.Millisecond)
+	progressBar.Complete()
+	<-progressBar.WaitForPrinterStop()
+
+	// The table shows the slowest operation first.
+	assert.NoError(t, progressBar.Report(30))
+	assert.Contains(t, buf.String(), "Slowest #1           | Delete key 9 at height 3 in 5ms")
+	assert.Contains(t, buf.String(), "Slowest #2           | Insert key 7 at height 2 in 3ms")
+
+	// JSON and CSV keep the raw numbers.
+	buf.Reset()
+	assert.NoError(t, progressBar.WriteReport(&buf, ReportJSON))
+	var rec reportRecord
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &rec))
+	assert.Equal(t, []slowRecord{{Op: "Delete", Key: 9, Height: 3, DurationSeconds: 0.005}, {Op: "Insert", Key: 7, Height: 2, DurationSeconds: 0.003}}, rec.Slowest)
+
+	buf.Reset()
+	assert.NoError(t, progressBar.WriteReport(&buf, ReportCSV))
+	rows, err := csv.NewReader(&buf).ReadAll()
+	assert.NoError(t, err)
+	assert.Contains(t, rows, []string{"slowest.1.key", "9"})
+	assert.Contains(t, rows, []string{"slowest.2.height", "2"})
+}
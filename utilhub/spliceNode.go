@@ -5,15 +5,18 @@ import (
 	"fmt"
 	"os"
 	"path"
+
+	"github.com/panhongrainbow/go-algorithm/simhub"
 )
 
 // LinuxSpliceStreamWrite wraps the LinuxSpliceStreamWrite function to write a file stream to a file.
+// A filesystem other than the disk, such as memory in a simulation, is written with plain writes, since Splice needs a real file.
 func (fn FileNode) LinuxSpliceStreamWrite(filename string, fileFlag int, filePerm os.FileMode) (dataChan chan [][]byte, finishChan chan struct{}, err error) {
 	// Construct the absolute path of the file by joining the transfer directory and the filename.
 	absPath := path.Join(fn.transfer, filename)
 
 	// Check if the directory exists.
-	info, err := os.Stat(absPath)
+	info, err := fn.fs().Stat(absPath)
 	if os.IsNotExist(err) {
 		return nil, nil, fmt.Errorf("file does not exist: %s", absPath)
 	}
@@ -23,10 +26,48 @@ func (fn FileNode) LinuxSpliceStreamWrite(filename string, fileFlag int, filePer
 		return nil, nil, fmt.Errorf("path is not a file: %s", absPath)
 	}
 
+	// Write through the filesystem when it is not the disk.
+	if _, onDisk := fn.fs().(simhub.OSFS); !onDisk {
+		file, err := fn.fs().OpenFile(absPath, fileFlag, filePerm)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open file: %w", err)
+		}
+		dataChan, finishChan = streamWrite(file)
+		return dataChan, finishChan, nil
+	}
+
 	// Call the LinuxSpliceStreamWrite function with the absolute path and other parameters.
 	return LinuxSpliceStreamWrite(absPath, fileFlag, filePerm)
 }
 
+// streamWrite returns a channel to send data to be written to the file with plain writes, and closes the file when the channel is closed.
+func streamWrite(file simhub.File) (dataChan chan [][]byte, finishChan chan struct{}) {
+	dataChan = make(chan [][]byte, 100)
+	finishChan = make(chan struct{})
+
+	go func() {
+		defer func() {
+			// Sync the file to ensure data is written to disk before closing it.
+			_ = file.Sync()
+			if err := file.Close(); err != nil {
+				fmt.Printf("failed to close file: %v\n", err)
+			}
+			finishChan <- struct{}{}
+		}()
+
+		for val := range dataChan {
+			for _, chunk := range val {
+				if _, err := file.Write(chunk); err != nil {
+					fmt.Printf("failed to write data: %v\n", err)
+					return
+				}
+			}
+		}
+	}()
+
+	return dataChan, finishChan
+}
+
 // ReadBytesInChunks uses a goroutine to perform the file reading, allowing it to run concurrently with the main program flow.
 func (fn FileNode) ReadBytesInChunks(filename string, chunkSize int) (<-chan []byte, <-chan error) {
 	// Create channels to hold the chunked data and errors.
@@ -37,7 +78,7 @@ func (fn FileNode) ReadBytesInChunks(filename string, chunkSize int) (<-chan []b
 	absPath := path.Join(fn.transfer, filename)
 
 	// Check if the directory exists.
-	info, err := os.Stat(absPath)
+	info, err := fn.fs().Stat(absPath)
 	if os.IsNotExist(err) {
 		// If the directory does not exist, send an error on the errChan and return.
 		errChan <- fmt.Errorf("file does not exist: %s", absPath)
@@ -58,7 +99,7 @@ func (fn FileNode) ReadBytesInChunks(filename string, chunkSize int) (<-chan []b
 		defer close(errChan)
 
 		// Open the file for reading.
-		file, err := os.Open(absPath)
+		file, err := fn.fs().Open(absPath)
 		if err != nil {
 			// If an error occurs while opening the file, send it on the errChan and return.
 			errChan <- err
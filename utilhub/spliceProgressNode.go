@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 )
 
 // =====================================================================================================================
@@ -17,6 +18,8 @@ import (
 // =====================================================================================================================
 
 // LinuxSpliceProgressStreamWrite is a function that writes data to a file using Linux splicing and displays a progress bar.
+// The guard checks the free space before and during the writing, and its *LowDiskSpaceError is returned as it is.
+// Two writes of the same file take turns, the later one starts when the earlier one has finished.
 func (fn FileNode) LinuxSpliceProgressStreamWrite(
 	// [Inputs]
 	// <----- original data
@@ -26,8 +29,19 @@ func (fn FileNode) LinuxSpliceProgressStreamWrite(
 	order binary.ByteOrder, spliceBlockLength, spliceBlockWidth int,
 	// <----- for ProgressBar function
 	barTitle, barColor string, barLength int, // 进度条参数
+	// <----- for DiskGuard, nil checks nothing
+	guard *DiskGuard, // 磁盘空间检查
 ) error { // [Outputs]
 
+	// Take turns with the other goroutines writing the same file.
+	unlock := lockPath(filepath.Join(fn.transfer, filename))
+	defer unlock()
+
+	// Check the free space for the whole data set before the file is created, 8 bytes for every int64.
+	if err := guard.Ensure(uint64(len(testDataSet))*8, nil); err != nil {
+		return err
+	}
+
 	// #################################################################################################
 	// Initialize linux splice stream writer and set up some parameters for data writing. (初始化)
 	// #################################################################################################
@@ -52,12 +66,13 @@ func (fn FileNode) LinuxSpliceProgressStreamWrite(
 	// ▓▒░ Create a progress bar with optional configurations.
 	progressBar, err := NewProgressBar(
 		barTitle,                    // Progress bar title.
-		uint32(len(testDataSet)),    // Total number of operations.
+		uint64(len(testDataSet))*8,  // Total number of bytes, 8 for each int64.
 		barLength,                   // Progress bar width.
 		WithTracking(5),             // Update interval.
 		WithTimeZone("Asia/Taipei"), // Time zone.
 		WithTimeControl(500),        // Update interval in milliseconds.
 		WithDisplay(barColor),       // Display style.
+		WithUnits(Bytes),            // Show the bytes written, such as "1.2 GiB / 4.0 GiB".
 	)
 
 	if err != nil {
@@ -65,9 +80,7 @@ func (fn FileNode) LinuxSpliceProgressStreamWrite(
 	}
 
 	// ▓▒░ Start the progress bar printer in a separate goroutine.
-	go func() {
-		progressBar.ListenPrinter()
-	}()
+	progressBar.Start()
 
 	// Write data to the file in blocks until the entire data set is written.
 	for !spliceWritingFinished {
@@ -76,6 +89,16 @@ func (fn FileNode) LinuxSpliceProgressStreamWrite(
 		// Convert the data set to a block of bytes. (把数据转换为字节块，并决定端序)
 		// #################################################################################################
 
+		// Check the free space for the rest of the data set, the progress bar is paused while waiting for space.
+		if err = guard.Ensure(uint64(max(len(testDataSet)-spliceWritingPoint, 0))*8, progressBar); err != nil {
+			// Stop the writer and end the progress bar where it is.
+			close(spliceDataChan)
+			<-spliceFinishChan
+			progressBar.Interrupt()
+			<-progressBar.WaitForPrinterStop()
+			return err
+		}
+
 		// Convert the data set to a block of bytes using the Int64SliceToBlockBytes method in utilhub.
 		// This method converts a slice of int64 values to a block of bytes.
 		var block [][]byte
@@ -88,7 +111,7 @@ func (fn FileNode) LinuxSpliceProgressStreamWrite(
 		spliceDataChan <- block
 
 		// Update the progress bar with the number of bytes written.
-		progressBar.AddSpecificTimes(uint32(spliceBlockLength * spliceBlockWidth))
+		progressBar.AddSpecificTimes(uint64(spliceBlockLength*spliceBlockWidth) * 8)
 	}
 
 	// #################################################################################################
@@ -120,7 +143,7 @@ func (fn FileNode) LinuxSpliceProgressStreamWrite(
 func (fn FileNode) ReadAllBytesWithProgress(
 	// [Inputs]
 	// <----- original data
-	dataLength uint32, // 资料长度
+	dataLength uint64, // 资料长度
 	// <----- parameters for reading
 	filename string, // 档名
 	chunkSize int, // 快取大小
@@ -152,9 +175,7 @@ func (fn FileNode) ReadAllBytesWithProgress(
 	}
 
 	// Start the progress bar printer in a separate goroutine.
-	go func() {
-		progressBar.ListenPrinter()
-	}()
+	progressBar.Start()
 
 	// #################################################################################################
 	// Read data from the file in chunks until the entire data set is read. (开始读取数据)
@@ -186,7 +207,7 @@ Loop:
 			result = append(result, data...)
 
 			// Update the progress bar with the number of bytes written.
-			progressBar.AddSpecificTimes(uint32(len(result)))
+			progressBar.AddSpecificTimes(uint64(len(result)))
 		}
 	}
 
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package utilhub
+
+import "os"
+
+// notifyResize ⛏️ does nothing without SIGWINCH, the bar is fitted to the width found when the printer started.
+func notifyResize(c chan<- os.Signal) {}
+
+// stopResize ⛏️ does nothing without SIGWINCH.
+func stopResize(c chan<- os.Signal) {}
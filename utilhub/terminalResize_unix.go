@@ -0,0 +1,19 @@
+//go:build unix
+
+package utilhub
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyResize ⛏️ sends SIGWINCH into the channel, which the terminal raises when its window is resized.
+func notifyResize(c chan<- os.Signal) {
+	signal.Notify(c, syscall.SIGWINCH)
+}
+
+// stopResize ⛏️ stops sending SIGWINCH into the channel.
+func stopResize(c chan<- os.Signal) {
+	signal.Stop(c)
+}
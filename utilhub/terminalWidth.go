@@ -0,0 +1,46 @@
+package utilhub
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// terminalWidth ⛏️ returns the number of columns of the terminal behind the writer.
+// It falls back to the COLUMNS environment variable, and returns 0 when the width is unknown.
+func terminalWidth(w io.Writer) int {
+	if file, ok := w.(*os.File); ok {
+		if width := fileTerminalWidth(file); width > 0 {
+			return width
+		}
+	}
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+	return 0
+}
+
+// isTerminal ⛏️ reports whether the writer is a terminal.
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	return ok && fileTerminalWidth(file) > 0
+}
+
+// supportsUnicode ⛏️ reports whether the terminal is expected to show the block runes of the bar.
+// The first set one of LC_ALL, LC_CTYPE and LANG must name UTF-8, such as "C.UTF-8" or "en_US.utf8",
+// and the terminals which only know ASCII, such as "dumb" and the serial consoles "vt100" and "vt220", are excluded.
+// Without any locale variable, Unicode is assumed like the Go runtime does.
+func supportsUnicode() bool {
+	switch os.Getenv("TERM") {
+	case "dumb", "vt100", "vt102", "vt220":
+		return false
+	}
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if locale := os.Getenv(name); locale != "" {
+			locale = strings.ToUpper(locale)
+			return strings.Contains(locale, "UTF-8") || strings.Contains(locale, "UTF8")
+		}
+	}
+	return true
+}
@@ -0,0 +1,18 @@
+//go:build linux
+
+package utilhub
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// fileTerminalWidth ⛏️ asks the terminal for its window size, and returns 0 when the file is not a terminal.
+func fileTerminalWidth(file *os.File) int {
+	size, err := unix.IoctlGetWinsize(int(file.Fd()), unix.TIOCGWINSZ)
+	if err != nil {
+		return 0
+	}
+	return int(size.Col)
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !windows
+
+package utilhub
+
+import "os"
+
+// fileTerminalWidth ⛏️ is not available outside Linux and Windows, the COLUMNS environment variable is used instead.
+func fileTerminalWidth(file *os.File) int {
+	return 0
+}
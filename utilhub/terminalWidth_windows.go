@@ -0,0 +1,18 @@
+//go:build windows
+
+package utilhub
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// fileTerminalWidth ⛏️ asks the console for the width of its window, and returns 0 when the file is not a console.
+func fileTerminalWidth(file *os.File) int {
+	var info windows.ConsoleScreenBufferInfo
+	if err := windows.GetConsoleScreenBufferInfo(windows.Handle(file.Fd()), &info); err != nil {
+		return 0
+	}
+	return int(info.Window.Right-info.Window.Left) + 1
+}
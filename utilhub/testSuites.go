@@ -0,0 +1,61 @@
+package utilhub
+
+import (
+	"sort"
+	"strings"
+)
+
+// =====================================================================================================================
+//                  🛠️ Test Suites (Tool)
+// Test Suites selects which suites of a long test run, such as only mode 1 and mode 3 of the accuracy test,
+// from a flag, a config or the code of another test driver. (选择测试套件)
+// =====================================================================================================================
+
+// SuiteSelection ⛏️ is a set of suite names, the empty selection selects every suite.
+type SuiteSelection struct {
+	names map[string]struct{} // The selected names in lower case, nil selects every suite.
+}
+
+// SelectSuites ⛏️ selects the named suites, such as SelectSuites("mode1", "mode3"), and no name selects every suite.
+// The names are not case-sensitive.
+func SelectSuites(names ...string) SuiteSelection {
+	var selection SuiteSelection
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if selection.names == nil {
+			selection.names = make(map[string]struct{})
+		}
+		selection.names[name] = struct{}{}
+	}
+	return selection
+}
+
+// ParseSuites ⛏️ selects the suites of a comma-separated list, such as "mode1,mode3" from a flag, and "" selects every suite.
+func ParseSuites(list string) SuiteSelection {
+	return SelectSuites(strings.Split(list, ",")...)
+}
+
+// Selected ⛏️ reports whether the suite runs.
+func (s SuiteSelection) Selected(name string) bool {
+	if s.names == nil {
+		return true
+	}
+	_, ok := s.names[strings.ToLower(name)]
+	return ok
+}
+
+// String ⛏️ lists the selected suites in alphabetical order, or "all".
+func (s SuiteSelection) String() string {
+	if s.names == nil {
+		return "all"
+	}
+	names := make([]string, 0, len(s.names))
+	for name := range s.names {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,24 @@
+package utilhub
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test_SuiteSelection tests selecting the suites by name, from the code and from a flag.
+func Test_SuiteSelection(t *testing.T) {
+	// No name selects every suite.
+	for _, all := range []SuiteSelection{SelectSuites(), ParseSuites(""), ParseSuites(" , ")} {
+		assert.True(t, all.Selected("mode1"))
+		assert.Equal(t, "all", all.String())
+	}
+
+	// The names are trimmed and not case-sensitive.
+	selection := ParseSuites("Mode3, mode1")
+	assert.True(t, selection.Selected("mode1"))
+	assert.True(t, selection.Selected("MODE3"))
+	assert.False(t, selection.Selected("mode2"))
+	assert.Equal(t, "mode1,mode3", selection.String())
+	assert.Equal(t, selection, SelectSuites("mode1", "mode3"))
+}
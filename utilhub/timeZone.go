@@ -4,6 +4,8 @@ import (
 	"io/ioutil"
 	"path/filepath"
 	"time"
+
+	"github.com/panhongrainbow/go-algorithm/simhub"
 )
 
 // ListTimezones returns all available timezones in the zoneinfo directory.
@@ -52,7 +54,7 @@ func GetNowTimeString(format string, timeZone string) (string, error) {
 	}
 
 	// Get the current time and convert it to the specified time zone
-	currentTime := time.Now().In(location)
+	currentTime := simhub.Now().In(location)
 
 	// Format the date string
 	dateStr := currentTime.Format(format)